@@ -0,0 +1,141 @@
+// Command eval scores the description and recommendation prompts against a golden
+// dataset, and compares a candidate set of prompt templates with the baseline.
+//
+//	go run ./cmd/eval -candidate ./prompts-next -record eval/answers.json
+//	go run ./cmd/eval -candidate ./prompts-next -replay eval/answers.json
+//	go run ./cmd/eval -replay eval/recordings/baseline.json
+//
+// The AI provider is configured like the server (AI_PROVIDER, AI_MODEL, ...). With
+// -replay the recorded answers are scored again and no provider is called.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/eval"
+	"atalariq/menu-api/internal/service"
+)
+
+// Names of the variants in reports and recordings
+const (
+	variantBaseline  = "baseline"
+	variantCandidate = "candidate"
+)
+
+func main() {
+	datasetPath := flag.String("dataset", "eval/golden.json", "golden dataset")
+	baselineDir := flag.String("baseline", "", "prompt template directory of the baseline, the built-in templates when empty")
+	candidateDir := flag.String("candidate", "", "prompt template directory to compare with the baseline")
+	recordPath := flag.String("record", "", "save the answers to this file")
+	replayPath := flag.String("replay", "", "score the answers saved in this file instead of calling the AI provider")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	failOnRegression := flag.Bool("fail-on-regression", false, "exit with status 1 when the candidate scores lower than the baseline")
+	flag.Parse()
+	log.SetFlags(0)
+
+	if *recordPath != "" && *replayPath != "" {
+		log.Fatal("-record and -replay cannot be used together")
+	}
+	dataset, err := eval.LoadDataset(*datasetPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var recording eval.Recording
+	if *replayPath != "" {
+		if recording, err = eval.LoadRecording(*replayPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	variants := map[string]string{variantBaseline: *baselineDir}
+	if *candidateDir != "" {
+		variants[variantCandidate] = *candidateDir
+	}
+	results := make(map[string]eval.Result)
+	recorded := make(eval.Recording)
+	for _, variant := range []string{variantBaseline, variantCandidate} {
+		dir, ok := variants[variant]
+		if !ok {
+			continue
+		}
+		var ai service.AIService
+		if recording != nil {
+			answers, ok := recording[variant]
+			if !ok {
+				log.Fatalf("%s has no answers of the %s", *replayPath, variant)
+			}
+			ai = eval.NewReplay(answers)
+		} else if ai, err = newAIService(dir); err != nil {
+			log.Fatal(err)
+		}
+
+		recorder := eval.NewRecorder(ai)
+		results[variant] = eval.Run(ctx, variant, recorder, dataset)
+		recorded[variant] = recorder.Answers()
+		if err := ai.Close(); err != nil {
+			log.Println("Failed to close AI provider:", err)
+		}
+	}
+
+	if *recordPath != "" {
+		if err := recorded.Save(*recordPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	candidate, ok := results[variantCandidate]
+	if !ok {
+		report(results[variantBaseline], *asJSON, eval.WriteResult)
+		return
+	}
+	comparison := eval.Compare(results[variantBaseline], candidate)
+	report(comparison, *asJSON, eval.WriteComparison)
+	if *failOnRegression && comparison.Regressed() {
+		os.Exit(1)
+	}
+}
+
+// newAIService builds the configured provider rendering the templates of dir
+func newAIService(dir string) (service.AIService, error) {
+	cfg, err := config.LoadAI(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	ai, err := service.NewAIService(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure AI provider: %w", err)
+	}
+	prompts, err := service.NewPromptStore(dir, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompt templates of %q: %w", dir, err)
+	}
+	if promptUser, ok := ai.(service.PromptUser); ok {
+		promptUser.UsePrompts(prompts)
+	}
+	return ai, nil
+}
+
+func report[T any](value T, asJSON bool, write func(io.Writer, T) error) {
+	var err error
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(value)
+	} else {
+		err = write(os.Stdout, value)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -1,15 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"atalariq/menu-api/internal/config"
 	"atalariq/menu-api/internal/controller"
+	"atalariq/menu-api/internal/embedding"
+	"atalariq/menu-api/internal/middleware"
 	"atalariq/menu-api/internal/model"
 	"atalariq/menu-api/internal/repository"
 	"atalariq/menu-api/internal/service"
+	"atalariq/menu-api/internal/storage"
 
 	_ "atalariq/menu-api/docs"
 
@@ -27,7 +37,20 @@ import (
 // @BasePath      /
 // @contact.name  Atalariq (Author)
 // @contact.email atalariq.dev@outlook.com
+//
+// @securityDefinitions.apikey TenantAPIKey
+// @in                         header
+// @name                       Authorization
+// @description                "Bearer <tenant api key>". X-Tenant-ID alone selects the tenant only with ALLOW_TENANT_HEADER=true
+//
+// @securityDefinitions.apikey AdminKey
+// @in                         header
+// @name                       X-Admin-Key
 func main() {
+	// Cancelled on SIGINT/SIGTERM, e.g. when Fly.io stops the machine
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	port := os.Getenv("PORT")
 
 	if port == "" {
@@ -45,15 +68,226 @@ func main() {
 		log.Fatal("Failed to connect to PostgreSQL:", err)
 	}
 
-	if err := db.AutoMigrate(&model.Menu{}); err != nil {
+	// Placeholders stored before description statuses existed are flagged once, see below
+	legacyDescriptions := !db.Migrator().HasColumn(&model.Menu{}, "DescriptionStatus")
+	if err := db.AutoMigrate(&model.Tenant{}, &model.Branch{}, &model.Menu{}, &model.BranchMenuOverride{}, &model.MenuTranslation{}, &model.Tag{}, &model.RecommendationSession{}, &model.AICacheEntry{}, &model.Job{}, &model.PromptTemplate{}, &model.AIUsageRecord{}, &model.EnrichmentProposal{}, &model.Review{}); err != nil {
 		log.Fatal("Migration failed:", err)
 	}
 
 	// 2. Dependency Injection
 	menuRepository := repository.NewMenuRepository(db)
-	geminiService := service.NewGeminiService()
-	menuService := service.NewMenuService(menuRepository, geminiService)
-	menuController := controller.NewMenuController(menuService)
+	tenantRepository := repository.NewTenantRepository(db)
+	tagRepository := repository.NewTagRepository(db)
+	aiConfig, err := config.LoadAI(os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	aiService, err := service.NewAIService(aiConfig)
+	if err != nil {
+		log.Fatal("Failed to configure AI provider:", err)
+	}
+	log.Printf("Using AI provider %q", aiConfig.Provider)
+
+	// Prompts are rendered from templates, the files can be replaced with PROMPT_TEMPLATE_DIR
+	// and versions activated through the admin API override them
+	promptStore, err := service.NewPromptStore(os.Getenv("PROMPT_TEMPLATE_DIR"), repository.NewPromptTemplateRepository(db))
+	if err != nil {
+		log.Fatal("Failed to load prompt templates:", err)
+	}
+	if promptUser, ok := aiService.(service.PromptUser); ok {
+		promptUser.UsePrompts(promptStore)
+	}
+	defer func() {
+		if err := aiService.Close(); err != nil {
+			log.Println("Failed to close AI provider:", err)
+		}
+	}()
+
+	// Transient provider errors are retried with backoff (AI_RETRIES), and after
+	// AI_BREAKER_THRESHOLD failures in a row the provider is left alone for AI_BREAKER_COOLDOWN
+	aiResilienceConfig, err := config.LoadAIResilience(os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	aiBreaker := service.NewResilientAIService(aiService, aiResilienceConfig)
+	aiService = aiBreaker
+
+	// Repeated AI calls are answered from an LRU cache, AI_CACHE_STORE=db also keeps
+	// the answers in the database so they survive restarts and are shared by instances
+	aiCacheConfig, err := config.LoadAICache(os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var aiCache service.CachedAIService
+	if aiCacheConfig.Size > 0 {
+		var cacheStore service.AICacheStore
+		if aiCacheConfig.Store == "db" {
+			cacheStore = repository.NewAICacheRepository(db)
+		}
+		aiCache = service.NewCachedAIService(aiService, aiCacheConfig, aiConfig.Provider+"/"+aiConfig.Model, cacheStore)
+		aiCache.UsePrompts(promptStore)
+		aiService = aiCache
+	}
+
+	// Every AI call is recorded with its tokens for GET /admin/ai/usage, the AI endpoints
+	// are refused once AI_BUDGET_MONTHLY_TOKENS or AI_BUDGET_TENANT_MONTHLY_TOKENS is used up
+	aiBudgetConfig, err := config.LoadAIBudget(os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	aiUsage := service.NewMeteredAIService(aiService, aiBudgetConfig, aiConfig.Provider+"/"+aiConfig.Model, repository.NewAIUsageRepository(db))
+	aiService = aiUsage
+	menuService := service.NewMenuService(menuRepository, aiService)
+	if aiCache != nil {
+		menuService.AddListener(aiCache)
+	}
+	tenantService := service.NewTenantService(tenantRepository, menuRepository)
+
+	// Descriptions of new menus are generated by JOB_WORKERS background workers, placeholders
+	// left by failed generations are retried every DESCRIPTION_SWEEP_INTERVAL
+	jobWorkers := service.DefaultJobWorkers
+	if v := os.Getenv("JOB_WORKERS"); v != "" {
+		if jobWorkers, err = strconv.Atoi(v); err != nil || jobWorkers < 1 {
+			log.Fatal("Invalid JOB_WORKERS:", v)
+		}
+	}
+	sweepInterval := service.DefaultDescriptionSweepInterval
+	if v := os.Getenv("DESCRIPTION_SWEEP_INTERVAL"); v != "" {
+		if sweepInterval, err = time.ParseDuration(v); err != nil || sweepInterval <= 0 {
+			log.Fatal("Invalid DESCRIPTION_SWEEP_INTERVAL:", v)
+		}
+	}
+	jobQueue := service.NewJobQueue(repository.NewJobRepository(db))
+	menuService.UseJobQueue(jobQueue)
+	if legacyDescriptions {
+		if _, err := menuService.MarkLegacyPlaceholders(); err != nil {
+			log.Fatal("Failed to flag placeholder descriptions:", err)
+		}
+	}
+
+	// Menu embeddings power semantic search and similar menus, they are kept in
+	// pgvector when the extension is available and in memory otherwise
+	embeddingConfig, err := config.LoadEmbedding(os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	embedder, err := embedding.New(embeddingConfig)
+	if err != nil {
+		log.Fatal("Failed to configure embedding provider:", err)
+	}
+	defer func() {
+		if err := embedder.Close(); err != nil {
+			log.Println("Failed to close embedding provider:", err)
+		}
+	}()
+	embeddingStore, err := embedding.NewPgvectorStore(db)
+	if err != nil {
+		log.Printf("Keeping menu embeddings in memory: %v", err)
+		embeddingStore = embedding.NewMemoryStore()
+	}
+	log.Printf("Using embedding model %q", embedder.Model())
+	embeddingService := service.NewEmbeddingService(menuRepository, menuService, embedder, embeddingStore)
+	menuService.AddListener(embeddingService)
+	go func() {
+		indexed, err := embeddingService.Backfill(ctx)
+		if err != nil {
+			log.Println("Menu embedding backfill failed:", err)
+		}
+		log.Printf("Embedded %d menus", indexed)
+	}()
+
+	menuController := controller.NewMenuController(menuService, embeddingService)
+	tenantController := controller.NewTenantController(tenantService)
+	aiController := controller.NewAIController(aiCache, aiUsage)
+	promptController := controller.NewPromptController(promptStore)
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal("Failed to get the database connection:", err)
+	}
+	healthController := controller.NewHealthController(sqlDB, aiBreaker)
+	tagService := service.NewTagService(tagRepository, menuService)
+
+	// Recommendation sessions expire after RECOMMENDATION_SESSION_TTL without a message
+	sessionTTL := service.DefaultRecommendationSessionTTL
+	if v := os.Getenv("RECOMMENDATION_SESSION_TTL"); v != "" {
+		if sessionTTL, err = time.ParseDuration(v); err != nil || sessionTTL <= 0 {
+			log.Fatal("Invalid RECOMMENDATION_SESSION_TTL:", v)
+		}
+	}
+	sessionService := service.NewRecommendationSessionService(repository.NewRecommendationSessionRepository(db), menuService, sessionTTL)
+	recommendationController := controller.NewRecommendationController(sessionService)
+	tagController := controller.NewTagController(tagService)
+	enrichmentService := service.NewEnrichmentService(aiService, menuRepository, tagRepository, repository.NewEnrichmentProposalRepository(db), menuService)
+	menuController.UseEnrichment(enrichmentService)
+	enrichmentController := controller.NewEnrichmentController(enrichmentService)
+	reviewController := controller.NewReviewController(service.NewReviewService(repository.NewReviewRepository(db), menuRepository, aiService))
+
+	// Menu images are stored on disk by default, STORAGE_DRIVER=s3 uses any S3-compatible bucket
+	imageStorage, localImageDir, err := newImageStorage()
+	if err != nil {
+		log.Fatal("Failed to configure image storage:", err)
+	}
+	maxImageBytes := int64(5 << 20)
+	if v := os.Getenv("IMAGE_MAX_BYTES"); v != "" {
+		if maxImageBytes, err = strconv.ParseInt(v, 10, 64); err != nil || maxImageBytes <= 0 {
+			log.Fatal("Invalid IMAGE_MAX_BYTES:", v)
+		}
+	}
+	imageService := service.NewImageService(menuRepository, menuService, imageStorage, maxImageBytes)
+	menuService.AddListener(imageService)
+	imageController := controller.NewImageController(imageService)
+
+	// The default tenant is reached with DEFAULT_TENANT_API_KEY, or the key generated on a
+	// fresh database. The tenant may be selected with X-Tenant-ID only behind a trusted gateway.
+	tenancy, err := config.LoadTenancy(os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	generatedKey, err := tenantService.EnsureDefaultTenant(tenancy.DefaultTenantAPIKey)
+	if err != nil {
+		log.Fatal("Failed to create default tenant:", err)
+	}
+	switch {
+	case generatedKey != "":
+		log.Printf("WARNING: created the default tenant with the API key %s, it is not shown again. Keep it, or set DEFAULT_TENANT_API_KEY", generatedKey)
+	case !tenancy.Reachable():
+		// The key logged when the default tenant was created may still be known, so only warn
+		log.Print("WARNING: no tenant key is configured, requests need the API key logged when the default tenant was created. Set DEFAULT_TENANT_API_KEY, or ADMIN_API_KEY to manage tenant keys")
+	}
+	tenantMiddleware := middleware.Tenant(tenantService, tenancy.AllowHeader)
+
+	// Base menu text is written in DEFAULT_LOCALE, other SUPPORTED_LOCALES are served from translations
+	defaultLocale := os.Getenv("DEFAULT_LOCALE")
+	if defaultLocale == "" {
+		defaultLocale = "en"
+	}
+	supportedLocales := strings.Split(os.Getenv("SUPPORTED_LOCALES"), ",")
+	if os.Getenv("SUPPORTED_LOCALES") == "" {
+		supportedLocales = []string{"en", "id"}
+	}
+	localeMiddleware := middleware.Locale(supportedLocales, defaultLocale)
+	aiUsageMiddleware := middleware.AIUsage(aiUsage)
+
+	// Daily stock reset (e.g. STOCK_RESET_TIME=06:00, STOCK_RESET_TIMEZONE=Asia/Jakarta)
+	resetTime := os.Getenv("STOCK_RESET_TIME")
+	if resetTime == "" {
+		resetTime = "00:00"
+	}
+	hour, minute, err := service.ParseResetTime(resetTime)
+	if err != nil {
+		log.Fatal(err)
+	}
+	location := time.Local
+	if tz := os.Getenv("STOCK_RESET_TIMEZONE"); tz != "" {
+		if location, err = time.LoadLocation(tz); err != nil {
+			log.Fatal("Invalid STOCK_RESET_TIMEZONE:", err)
+		}
+	}
+	service.StartDailyStockReset(ctx, menuService, hour, minute, location)
+
+	// Workers start once every menu listener is registered
+	jobQueue.Start(ctx, jobWorkers)
+	service.StartDescriptionSweep(ctx, menuService, sweepInterval)
 
 	// 3. Router
 	r := gin.Default()
@@ -68,7 +302,44 @@ func main() {
 		})
 	})
 
-	api := r.Group("/menu")
+	r.GET("/health", healthController.Health)
+
+	if localImageDir != "" {
+		r.Static("/uploads", localImageDir)
+	}
+
+	admin := r.Group("/admin", middleware.Admin(tenancy.AdminAPIKey))
+	{
+		admin.POST("/tenants", tenantController.CreateTenant)
+		admin.GET("/tenants", tenantController.ListTenants)
+		admin.POST("/tenants/:id/api-key", tenantController.RotateAPIKey)
+		admin.GET("/ai/cache", aiController.CacheStats)
+		admin.GET("/ai/usage", aiController.Usage)
+		admin.GET("/prompts", promptController.ListPrompts)
+		admin.GET("/prompts/:name/versions", promptController.ListVersions)
+		admin.POST("/prompts/:name/versions", promptController.CreateVersion)
+		admin.POST("/prompts/:name/versions/:version/activate", promptController.ActivateVersion)
+		admin.POST("/prompts/:name/preview", promptController.Preview)
+	}
+
+	branches := r.Group("/branches", tenantMiddleware)
+	{
+		branches.POST("", tenantController.CreateBranch)
+		branches.GET("", tenantController.ListBranches)
+		branches.GET("/:branch_id/overrides", tenantController.ListOverrides)
+		branches.PUT("/:branch_id/overrides/:menu_id", tenantController.SetOverride)
+		branches.DELETE("/:branch_id/overrides/:menu_id", tenantController.DeleteOverride)
+	}
+
+	tags := r.Group("/tags", tenantMiddleware)
+	{
+		tags.POST("", tagController.CreateTag)
+		tags.GET("", tagController.ListTags)
+		tags.PUT("/:id", tagController.UpdateTag)
+		tags.DELETE("/:id", tagController.DeleteTag)
+	}
+
+	api := r.Group("/menu", tenantMiddleware, localeMiddleware)
 	{
 		api.POST("", menuController.Create)
 		api.GET("", menuController.GetList)
@@ -77,13 +348,104 @@ func main() {
 		api.DELETE("/:id", menuController.Delete)
 		api.GET("/group-by-category", menuController.GroupByCategory)
 		api.GET("/search", menuController.Search)
+		api.GET("/:id/similar", menuController.Similar)
+
+		// Stock Routes
+		api.POST("/:id/stock/decrement", menuController.DecrementStock)
+		api.POST("/:id/stock/restock", menuController.Restock)
+		api.PUT("/:id/availability", menuController.SetAvailability)
+
+		// Image Routes
+		api.POST("/:id/image", imageController.Upload)
+		api.DELETE("/:id/image", imageController.Delete)
+
+		// Tag Routes
+		api.PUT("/:id/tags", tagController.SetMenuTags)
+
+		// Paper menus read into drafts, then imported in bulk
+		api.POST("/extract", aiUsageMiddleware, menuController.ExtractMenus)
+		api.POST("/import", menuController.ImportMenus)
+
+		// Enrichment suggestions, applied at once or reviewed as proposals
+		api.POST("/enrichment/suggest", aiUsageMiddleware, enrichmentController.Suggest)
+		api.POST("/:id/enrichment", aiUsageMiddleware, enrichmentController.Propose)
+		api.POST("/enrichment/proposals", aiUsageMiddleware, enrichmentController.GenerateProposals)
+		api.GET("/enrichment/proposals", enrichmentController.ListProposals)
+		api.POST("/enrichment/proposals/:proposal_id/apply", enrichmentController.ApplyProposal)
+		api.POST("/enrichment/proposals/:proposal_id/reject", enrichmentController.RejectProposal)
+
+		// Reviews, counted in the rating of the menu once approved
+		api.POST("/:id/reviews", reviewController.CreateReview)
+		api.GET("/:id/reviews", reviewController.ListMenuReviews)
+		api.POST("/:id/reviews/summary", aiUsageMiddleware, reviewController.SummarizeReviews)
+		api.GET("/reviews", reviewController.ListReviews)
+		api.POST("/reviews/:review_id/moderate", reviewController.ModerateReview)
+
+		// Translation Routes
+		api.GET("/:id/translations", menuController.ListTranslations)
+		api.PUT("/:id/translations/:locale", menuController.SaveTranslation)
+		api.DELETE("/:id/translations/:locale", menuController.DeleteTranslation)
+		api.POST("/translations/generate", aiUsageMiddleware, menuController.GenerateTranslations)
 
 		// AI Routes
-		api.POST("/generate-description", menuController.GenerateDescription)
-		api.POST("/recommendations", menuController.GetRecommendations)
+		api.GET("/:id/description/job", menuController.DescriptionJob)
+		api.POST("/:id/description/apply", menuController.ApplyDescription)
+		api.POST("/generate-description", aiUsageMiddleware, menuController.GenerateDescription)
+		api.POST("/generate-description/stream", aiUsageMiddleware, menuController.GenerateDescriptionStream)
+		api.POST("/recommendations", aiUsageMiddleware, menuController.GetRecommendations)
+		api.POST("/recommendations/stream", aiUsageMiddleware, menuController.GetRecommendationsStream)
+		api.POST("/recommendations/sessions", aiUsageMiddleware, recommendationController.StartSession)
+		api.GET("/recommendations/sessions/:session_id", recommendationController.GetSession)
+		api.POST("/recommendations/sessions/:session_id/messages", aiUsageMiddleware, recommendationController.SendMessage)
+	}
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%s", port),
+		Handler: r,
 	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Failed to run server:", err)
+		}
+	}()
 
-	if err := r.Run(fmt.Sprintf(":%s", port)); err != nil {
-		log.Fatal("Failed to run server:", err)
+	<-ctx.Done()
+	log.Println("Shutting down, waiting for in-flight requests")
+
+	// In-flight requests keep their AI calls until they finish or this deadline cancels them
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Println("Forced shutdown:", err)
+	}
+}
+
+// newImageStorage builds the storage backend from the environment. The local
+// directory is returned so the router can serve it, it is empty for S3.
+func newImageStorage() (storage.Storage, string, error) {
+	switch os.Getenv("STORAGE_DRIVER") {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "./uploads"
+		}
+		baseURL := os.Getenv("STORAGE_PUBLIC_URL")
+		if baseURL == "" {
+			baseURL = "/uploads"
+		}
+		local, err := storage.NewLocalStorage(dir, baseURL)
+		return local, dir, err
+	case "s3":
+		s3, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		})
+		return s3, "", err
+	default:
+		return nil, "", fmt.Errorf("unknown STORAGE_DRIVER %q", os.Getenv("STORAGE_DRIVER"))
 	}
 }
@@ -18,57 +18,87 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/menu": {
+        "/admin/ai/cache": {
             "get": {
-                "description": "Get menu list with filtering, sorting, and pagination",
+                "description": "Hits, misses and hit rate of the AI response cache since the server started, in total and per method",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "menu"
+                    "admin"
                 ],
-                "summary": "List menus (Browsing)",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Filter by category",
-                        "name": "category",
-                        "in": "query"
+                "summary": "AI cache statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AICacheStats"
+                        }
                     },
-                    {
-                        "type": "number",
-                        "description": "Minimum price",
-                        "name": "min_price",
-                        "in": "query"
+                    "401": {
+                        "description": "Admin key required",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
                     },
+                    "404": {
+                        "description": "Cache disabled",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
                     {
-                        "type": "number",
-                        "description": "Maximum price",
-                        "name": "max_price",
+                        "AdminKey": []
+                    }
+                ]
+            }
+        },
+        "/admin/ai/usage": {
+            "get": {
+                "description": "Calls, errors, cache hits, tokens, average latency and estimated cost of the AI calls, grouped by UTC day, endpoint or client (tenant ID). The monthly budgets show the tokens used this month, AI endpoints answer 402 once the budget of the tenant is used up and 429 once the platform budget is.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "AI usage and cost",
+                "parameters": [
+                    {
+                        "enum": [
+                            "day",
+                            "endpoint",
+                            "client"
+                        ],
+                        "type": "string",
+                        "description": "Grouping, day by default",
+                        "name": "group_by",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Maximum calories",
-                        "name": "max_cal",
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD, the first day of the month by default",
+                        "name": "from",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Sort (e.g., price:asc)",
-                        "name": "sort",
+                        "description": "Last day, YYYY-MM-DD, today by default",
+                        "name": "to",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Page number (default 1)",
-                        "name": "page",
+                        "description": "Only the calls of this tenant, with its budget",
+                        "name": "tenant_id",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Items per page (default 10)",
-                        "name": "per_page",
+                        "type": "string",
+                        "description": "Only the calls of this endpoint, e.g. POST /menu/recommendations",
+                        "name": "endpoint",
                         "in": "query"
                     }
                 ],
@@ -76,65 +106,63 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.MenuPaginationResponse"
+                            "$ref": "#/definitions/model.AIUsageReportResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid query",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Admin key required",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
-                }
-            },
-            "post": {
-                "description": "Create a new menu item with ingredients",
-                "consumes": [
-                    "application/json"
-                ],
+                },
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ]
+            }
+        },
+        "/admin/prompts": {
+            "get": {
+                "description": "Named prompt templates with their active and latest version. Version 0 is the template file shipped with the server or read from PROMPT_TEMPLATE_DIR.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "menu"
-                ],
-                "summary": "Create a new menu",
-                "parameters": [
-                    {
-                        "description": "Menu Request",
-                        "name": "menu",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.Menu"
-                        }
-                    }
+                    "admin"
                 ],
+                "summary": "List prompt templates",
                 "responses": {
-                    "201": {
-                        "description": "Typed Response",
-                        "schema": {
-                            "$ref": "#/definitions/model.MenuSuccessResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Validation Error",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.ErrorResponse"
+                            "$ref": "#/definitions/model.PromptTemplateListResponse"
                         }
                     },
-                    "500": {
-                        "description": "Server Error",
+                    "401": {
+                        "description": "Admin key required",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ]
             }
         },
-        "/menu/generate-description": {
+        "/admin/prompts/{name}/preview": {
             "post": {
-                "description": "Use Gemini AI to create a marketing description based on name and ingredients",
+                "description": "Render a template against a sample menu without calling the AI: the body given, else the version given, else the active version. Empty menu fields use a built-in sample.",
                 "consumes": [
                     "application/json"
                 ],
@@ -142,17 +170,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "AI"
+                    "admin"
                 ],
-                "summary": "Generate Menu Description",
+                "summary": "Preview a prompt",
                 "parameters": [
                     {
-                        "description": "Input Data",
+                        "enum": [
+                            "description",
+                            "recommendation",
+                            "translation"
+                        ],
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Preview",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.GenerateDescriptionRequest"
+                            "$ref": "#/definitions/model.PromptPreviewRequest"
                         }
                     }
                 ],
@@ -160,75 +200,86 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.GenerateDescriptionResponse"
+                            "$ref": "#/definitions/model.PromptPreviewResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input format",
+                        "description": "Invalid template",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "AI service error",
+                    "401": {
+                        "description": "Admin key required",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown template or version",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ]
             }
         },
-        "/menu/group-by-category": {
+        "/admin/prompts/{name}/versions": {
             "get": {
-                "description": "Get menu counts or lists grouped by category",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "menu"
+                    "admin"
                 ],
-                "summary": "Group menus by category",
+                "summary": "List prompt template versions",
                 "parameters": [
                     {
+                        "enum": [
+                            "description",
+                            "recommendation",
+                            "translation"
+                        ],
                         "type": "string",
-                        "description": "Mode: 'count' or 'list'",
-                        "name": "mode",
-                        "in": "query",
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit item per category (default 5)",
-                        "name": "per_category",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/model.PromptVersionListResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid mode",
+                    "401": {
+                        "description": "Admin key required",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Server Error",
+                    "404": {
+                        "description": "Unknown template",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/menu/recommendations": {
+                },
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ]
+            },
             "post": {
-                "description": "Get menu recommendations based on user preference using Gemini AI",
+                "description": "Store a new text/template version, inactive until activated. The template must render the sample data of its name, see the preview endpoint.",
                 "consumes": [
                     "application/json"
                 ],
@@ -236,154 +287,364 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "AI"
+                    "admin"
                 ],
-                "summary": "Get Menu Recommendations",
+                "summary": "Create a prompt template version",
                 "parameters": [
                     {
-                        "description": "User Preference",
-                        "name": "request",
+                        "enum": [
+                            "description",
+                            "recommendation",
+                            "translation"
+                        ],
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.RecommendationRequest"
+                            "$ref": "#/definitions/model.CreatePromptVersionRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Typed Response",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/model.RecommendationListResponse"
+                            "$ref": "#/definitions/model.PromptTemplateResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid template",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Admin key required",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     },
-                    "502": {
-                        "description": "AI service unavailable",
+                    "404": {
+                        "description": "Unknown template",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ]
             }
         },
-        "/menu/search": {
-            "get": {
-                "description": "Search menu by name or description (Full Text Search intent)",
+        "/admin/prompts/{name}/versions/{version}/activate": {
+            "post": {
+                "description": "Send the AI this version from now on, version 0 goes back to the template file. Other instances pick it up within 30 seconds.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "menu"
+                    "admin"
                 ],
-                "summary": "Search menus",
+                "summary": "Activate a prompt template version",
                 "parameters": [
                     {
+                        "enum": [
+                            "description",
+                            "recommendation",
+                            "translation"
+                        ],
                         "type": "string",
-                        "description": "Search keyword",
-                        "name": "q",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by category",
-                        "name": "category",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Minimum price",
-                        "name": "min_price",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "description": "Maximum price",
-                        "name": "max_price",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Sort (e.g., price:asc)",
-                        "name": "sort",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number (default 1)",
-                        "name": "page",
-                        "in": "query"
+                        "description": "Template name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Items per page (default 10)",
-                        "name": "per_page",
-                        "in": "query"
+                        "description": "Version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.MenuPaginationResponse"
+                            "$ref": "#/definitions/model.PromptTemplateResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid version",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Admin key required",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown template or version",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ]
             }
         },
-        "/menu/{id}": {
+        "/admin/tenants": {
             "get": {
-                "description": "Get details of a specific menu item by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "menu"
-                ],
-                "summary": "Get menu detail",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Menu ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "List tenants",
                 "responses": {
                     "200": {
-                        "description": "Typed Response",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.MenuDetailResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Invalid ID",
+                    "401": {
+                        "description": "Admin key required",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Register a restaurant, the generated API key is only returned once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create a tenant",
+                "parameters": [
+                    {
+                        "description": "Tenant",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateTenantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.TenantCreatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Admin key required",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
+                    }
+                },
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ]
+            }
+        },
+        "/admin/tenants/{id}/api-key": {
+            "post": {
+                "description": "Generate a new API key, the previous one stops working immediately",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Rotate tenant API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tenant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
                     "404": {
-                        "description": "Menu Not Found",
+                        "description": "Tenant Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ]
+            }
+        },
+        "/branches": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "List branches",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
             },
+            "post": {
+                "description": "Add an outlet to the current tenant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "Create a branch",
+                "parameters": [
+                    {
+                        "description": "Branch",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateBranchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/branches/{branch_id}/overrides": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "List branch overrides",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Branch ID",
+                        "name": "branch_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Branch Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/branches/{branch_id}/overrides/{menu_id}": {
             "put": {
-                "description": "Update an existing menu item",
+                "description": "Set branch specific price and/or availability on top of the base menu",
                 "consumes": [
                     "application/json"
                 ],
@@ -391,62 +652,82 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "menu"
+                    "branch"
                 ],
-                "summary": "Update menu",
+                "summary": "Override menu for a branch",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Branch ID",
+                        "name": "branch_id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "description": "Menu ID",
-                        "name": "id",
+                        "name": "menu_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update Data",
-                        "name": "menu",
+                        "description": "Override",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Menu"
+                            "$ref": "#/definitions/model.BranchOverrideRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Typed Response",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.MenuSuccessResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Validation Error",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Menu Not Found",
+                        "description": "Branch or Menu Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
             },
             "delete": {
-                "description": "Delete a menu item by ID",
+                "description": "The branch falls back to the base menu price and availability",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "menu"
+                    "branch"
                 ],
-                "summary": "Delete menu",
+                "summary": "Remove branch override",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Branch ID",
+                        "name": "branch_id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "description": "Menu ID",
-                        "name": "id",
+                        "name": "menu_id",
                         "in": "path",
                         "required": true
                     }
@@ -458,205 +739,4312 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.GeneralResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid ID",
+                    "404": {
+                        "description": "Override Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.ErrorResponse"
                         }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "State of the database and of the circuit breaker in front of the AI provider. An open breaker only degrades the service, AI endpoints answer with rule-based fallbacks, while an unreachable database makes it unavailable.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "ok or degraded",
+                        "schema": {
+                            "$ref": "#/definitions/model.HealthResponse"
+                        }
                     },
-                    "404": {
-                        "description": "Menu Not Found",
+                    "503": {
+                        "description": "Database unreachable",
                         "schema": {
-                            "$ref": "#/definitions/model.ErrorResponse"
+                            "$ref": "#/definitions/model.HealthResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "Invalid input format or ID not found"
-                }
-            }
         },
-        "model.GeneralResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
+        "/menu": {
+            "get": {
+                "description": "Get menu list with filtering, sorting, and pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "List menus (Browsing)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum price",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum price",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum calories",
+                        "name": "max_cal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Exclude sold-out menus",
+                        "name": "hide_sold_out",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum average rating of the approved reviews, from 0 to 5",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated tag slugs (e.g., spicy,new)",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag matching: 'or' (any tag, default) or 'and' (every tag)",
+                        "name": "tags_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include facet counts (default true)",
+                        "name": "facets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale, overrides Accept-Language (e.g., id)",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort (e.g., price:asc, rating:desc)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 10)",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuPaginationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Create a new menu item with ingredients. Without a description one is generated in the background (description_status \"pending\"), see /menu/{id}/description/job.\nWith enrich=true the category and calories left empty, the dietary tags and the allergens are filled from AI suggestions at least 0.6 sure, returned as \"enrichment\". The menu is created without them when the AI budget is used up.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "Create a new menu",
+                "parameters": [
+                    {
+                        "description": "Menu Request",
+                        "name": "menu",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Menu"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Fill the empty fields from AI suggestions",
+                        "name": "enrich",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Typed Response",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/enrichment/proposals": {
+            "get": {
+                "description": "Proposals of the tenant, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "List enrichment proposals",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending, applied, rejected or superseded",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the proposals of this menu",
+                        "name": "menu_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.EnrichmentProposalListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status or menu ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Make a pending proposal for each menu given, or for up to limit menus without a category or calories. It stops at the first failure, the proposals made before it are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Propose details for many menus",
+                "parameters": [
+                    {
+                        "description": "Menus",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.GenerateProposalsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.EnrichmentProposalListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Monthly AI budget of the tenant used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Monthly AI budget of the platform used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/enrichment/proposals/{proposal_id}/apply": {
+            "post": {
+                "description": "Apply the fields given, every field by default, of a pending proposal with at least min_confidence (default 0.6). The category and calories are replaced, the dietary tags and allergens are added to those of the menu. Missing dietary tags are created.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Apply an enrichment proposal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Proposal ID",
+                        "name": "proposal_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to apply",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.ApplyProposalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.AppliedProposalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Proposal or menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Proposal no longer pending",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/enrichment/proposals/{proposal_id}/reject": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Reject an enrichment proposal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Proposal ID",
+                        "name": "proposal_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.EnrichmentProposalResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Proposal not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Proposal no longer pending",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/enrichment/suggest": {
+            "post": {
+                "description": "Suggest the category (one the tenant already uses, when it has any), estimated calories, dietary tags and likely allergens of a menu from its name and ingredients, each with a confidence from 0 to 1. Nothing is saved. When the AI provider fails the suggestions come from ingredient rules with source \"fallback\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Suggest menu details",
+                "parameters": [
+                    {
+                        "description": "Menu",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.EnrichmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.EnrichmentSuggestionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Monthly AI budget of the tenant used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Monthly AI budget of the platform used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/extract": {
+            "post": {
+                "description": "Read the items of a photo (JPEG, PNG or WebP) or PDF of a paper menu into draft menus with a name, price, category and guessed ingredients. Nothing is saved: review the drafts, then send them to /menu/import. Categories the tenant already uses keep their spelling.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Read menus from a paper menu",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Photo or PDF of the menu, up to 10 MB",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuExtractionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing file",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Monthly AI budget of the tenant used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported file type",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Monthly AI budget of the platform used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "501": {
+                        "description": "The AI provider cannot read files",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/generate-description": {
+            "post": {
+                "description": "Use the configured AI provider to create a marketing description based on name and ingredients. The tone (casual, premium or playful, premium by default), maximum length in words (20 by default), language (a locale tag, English by default) and target audience shape the description. With \"candidates\" up to 5 alternatives are returned to pick from, the chosen one is saved with /menu/{id}/description/apply. When the AI provider is unavailable the candidates are written from templates and \"source\" is \"fallback\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Generate Menu Description",
+                "parameters": [
+                    {
+                        "description": "Input Data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.GenerateDescriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GenerateDescriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format or language",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Monthly AI budget of the tenant used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Monthly AI budget of the platform used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to generate the description",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/generate-description/stream": {
+            "post": {
+                "description": "Same as /menu/generate-description with a single candidate, streamed as Server-Sent Events: \"token\" events with {\"text\"} as the description is written, then a \"done\" event with the full description and the token usage. An \"error\" event ends the stream when the AI fails midway. The generation stops when the client disconnects.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Stream a Menu Description",
+                "parameters": [
+                    {
+                        "description": "Input Data",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.GenerateDescriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payload of the done event",
+                        "schema": {
+                            "$ref": "#/definitions/model.DescriptionStreamDone"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format or language",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Monthly AI budget of the tenant used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Monthly AI budget of the platform used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "AI service error before the first token",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "AI provider unavailable, the circuit breaker is open",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/group-by-category": {
+            "get": {
+                "description": "Get menu counts or lists grouped by category",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "Group menus by category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Mode: 'count' or 'list'",
+                        "name": "mode",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit item per category (default 5)",
+                        "name": "per_category",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid mode",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/import": {
+            "post": {
+                "description": "Create up to 200 menus at once, all of them or none, e.g. the drafts of /menu/extract once reviewed. Menus without a description get one generated in the background.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "Import menus in bulk",
+                "parameters": [
+                    {
+                        "description": "Menus",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuImportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/recommendations": {
+            "post": {
+                "description": "Get up to 3 menu recommendations with a confidence score, based on user preference using the configured AI provider. When the AI gives no usable answer, menus are ranked by keywords instead (source \"fallback\").",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Get Menu Recommendations",
+                "parameters": [
+                    {
+                        "description": "User Preference",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RecommendationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Typed Response",
+                        "schema": {
+                            "$ref": "#/definitions/model.RecommendationListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or preference longer than 500 characters",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Monthly AI budget of the tenant used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No available menus",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Monthly AI budget of the platform used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get recommendations",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/recommendations/sessions": {
+            "post": {
+                "description": "Start a conversation where every message refines the previous recommendations (e.g., \"something cheaper\", \"no, without dairy\"). The first message is optional. Sessions expire after a period without messages.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Start a recommendation session",
+                "parameters": [
+                    {
+                        "description": "First message",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.StartRecommendationSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.RecommendationSessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Monthly AI budget of the tenant used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No available menus",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Monthly AI budget of the platform used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get recommendations",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/recommendations/sessions/{session_id}": {
+            "get": {
+                "description": "The messages of the session and the menus suggested for each",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Get a recommendation session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RecommendationSessionResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found or expired",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/recommendations/sessions/{session_id}/messages": {
+            "post": {
+                "description": "Recommend menus for the message, knowing the earlier messages of the session and what was suggested for them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Send a message to a recommendation session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RecommendationMessageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RecommendationSessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Monthly AI budget of the tenant used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found or expired, or no available menus",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Monthly AI budget of the platform used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get recommendations",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/recommendations/stream": {
+            "post": {
+                "description": "Same as /menu/recommendations, streamed as Server-Sent Events: a \"recommendation\" event for each menu as soon as the AI answer names it, in the order given by the AI, then a \"done\" event with the count and the token usage. The fallback ranking is streamed when the AI gives nothing usable. The AI call stops when the client disconnects.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Stream Menu Recommendations",
+                "parameters": [
+                    {
+                        "description": "User Preference",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RecommendationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payload of the done event",
+                        "schema": {
+                            "$ref": "#/definitions/model.RecommendationStreamDone"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or preference longer than 500 characters",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Monthly AI budget of the tenant used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No available menus",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Monthly AI budget of the platform used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get recommendations",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/reviews": {
+            "get": {
+                "description": "Reviews of every menu of the tenant, newest first, the pending ones by default",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "List reviews to moderate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "pending (default), approved or rejected",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ReviewListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/reviews/{review_id}/moderate": {
+            "post": {
+                "description": "Approve or reject a review, the rating of its menu is updated at once. A moderated review can be moderated again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Moderate a review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Review ID",
+                        "name": "review_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ModerateReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ReviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Review not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/search": {
+            "get": {
+                "description": "Search menu by name or description (Full Text Search intent). With mode=semantic the\nmenus closest in meaning to q come first, each with its similarity score.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "Search menus",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search keyword",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "'lexical' (default) or 'semantic'",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum price",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum price",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Exclude sold-out menus",
+                        "name": "hide_sold_out",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum average rating of the approved reviews, from 0 to 5",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated tag slugs (e.g., spicy,new)",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag matching: 'or' (any tag, default) or 'and' (every tag)",
+                        "name": "tags_mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include facet counts (default true)",
+                        "name": "facets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale, overrides Accept-Language (e.g., id)",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort (e.g., price:asc, rating:desc)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 10)",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuPaginationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/translations/generate": {
+            "post": {
+                "description": "Ask the AI service to translate every menu without a translation for the given locales",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Translate missing locales with AI",
+                "parameters": [
+                    {
+                        "description": "Locales",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.GenerateTranslationsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or locale",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Monthly AI budget of the tenant used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Monthly AI budget of the platform used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}": {
+            "get": {
+                "description": "Get details of a specific menu item by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "Get menu detail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale, overrides Accept-Language (e.g., id)",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Typed Response",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            },
+            "put": {
+                "description": "Update an existing menu item. Availability, stock and daily_stock keep their values when omitted, null stops tracking stock.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "Update menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Data",
+                        "name": "menu",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateMenuRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Typed Response",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Delete a menu item by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "Delete menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GeneralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/availability": {
+            "put": {
+                "description": "Manually mark a menu as available or sold out",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Set menu availability",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Availability",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AvailabilityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/description/apply": {
+            "post": {
+                "description": "Save the description picked among the candidates of /menu/generate-description, possibly edited, to a menu",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Apply a generated description",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Chosen description",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ApplyDescriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Typed Response",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or input",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/description/job": {
+            "get": {
+                "description": "Latest background job generating the description of a menu created without one. Failed attempts are retried with backoff, after the last one the menu keeps a placeholder (description_status \"fallback\") that is generated again by a periodic sweep.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Description generation status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.JobResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu or job not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/enrichment": {
+            "post": {
+                "description": "Store the suggestions for an existing menu as a pending proposal to review, see /menu/enrichment/proposals/{proposal_id}/apply. Earlier pending proposals of the menu are superseded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Propose details for a menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.EnrichmentProposalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Monthly AI budget of the tenant used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Monthly AI budget of the platform used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/image": {
+            "post": {
+                "description": "Upload a JPEG, PNG, GIF or WebP photo. A large variant and a JPEG thumbnail are generated, in WebP too when it is smaller than the JPEG. The previous image is replaced.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "Upload menu image",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image file",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing file",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Image too large",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported image format",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "Delete menu image",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GeneralResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/reviews": {
+            "get": {
+                "description": "Reviews of the menu, newest first, the approved ones by default",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "List the reviews of a menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "approved (default), pending or rejected",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ReviewListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or status",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Store the rating from 1 to 5 and the review of a diner. The review is pending until it is approved, only approved reviews are listed by default and count in the rating of the menu.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "review"
+                ],
+                "summary": "Review a menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.ReviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/reviews/summary": {
+            "post": {
+                "description": "Sum up the pros and cons of the newest 30 approved reviews of the menu with the AI. The summary is stored on the menu as review_summary, until the next one replaces it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "AI"
+                ],
+                "summary": "Summarize the reviews of a menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ReviewSummaryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Monthly AI budget of the tenant used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "No approved reviews",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Monthly AI budget of the platform used up",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "AI provider unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/similar": {
+            "get": {
+                "description": "Menus closest in meaning to the given menu, best first, each with its similarity score",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "menu"
+                ],
+                "summary": "Similar menus",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of menus (default 5, max 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale, overrides Accept-Language (e.g., id)",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/stock/decrement": {
+            "post": {
+                "description": "Reduce stock of a menu, it is marked as sold out automatically when stock reaches zero",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Decrement menu stock",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Quantity",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.StockAdjustmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Stock not tracked or insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/stock/restock": {
+            "post": {
+                "description": "Add stock to a menu and mark it as available again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stock"
+                ],
+                "summary": "Restock menu",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Quantity",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.StockAdjustmentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/tags": {
+            "put": {
+                "description": "Replace the tags of a menu with the given slugs, an empty list removes every tag",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Set menu tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tag slugs",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuTagsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.MenuDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown tag",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/translations": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "translation"
+                ],
+                "summary": "List menu translations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/menu/{id}/translations/{locale}": {
+            "put": {
+                "description": "Create or replace the name and description of a menu for a locale",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "translation"
+                ],
+                "summary": "Save menu translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale (e.g., id)",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Translation",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TranslationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or locale",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Menu Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "translation"
+                ],
+                "summary": "Delete menu translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Menu ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale (e.g., id)",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GeneralResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Translation Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "List the tags of the tenant with the number of menus using each",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "List tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Create a label such as \"spicy\" or \"chef's pick\", the slug is derived from the name when omitted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Create a tag",
+                "parameters": [
+                    {
+                        "description": "Tag",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Slug already used",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        },
+        "/tags/{id}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Rename a tag",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tag",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Slug already used",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Delete a tag and remove it from every menu",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "summary": "Delete a tag",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GeneralResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Tag Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "TenantAPIKey": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "model.AIBreakerStatus": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "type": "integer"
+                },
+                "opened_at": {
+                    "type": "string"
+                },
+                "opens": {
+                    "description": "since the server started",
+                    "type": "integer"
+                },
+                "probe_at": {
+                    "description": "when an open breaker lets a probe through",
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "closed"
+                }
+            }
+        },
+        "model.AIBudgetStatus": {
+            "type": "object",
+            "properties": {
+                "exceeded": {
+                    "type": "boolean"
+                },
+                "month": {
+                    "type": "string",
+                    "example": "2026-10"
+                },
+                "tenant_id": {
+                    "description": "empty for the platform budget",
+                    "type": "integer"
+                },
+                "token_limit": {
+                    "type": "integer"
+                },
+                "tokens_used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.AICacheMethodStats": {
+            "type": "object",
+            "properties": {
+                "hit_rate": {
+                    "type": "number"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.AICacheStats": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "description": "in memory",
+                    "type": "integer"
+                },
+                "hit_rate": {
+                    "type": "number"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "invalidations": {
+                    "description": "recommendation entries dropped after menu changes",
+                    "type": "integer"
+                },
+                "methods": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.AICacheMethodStats"
+                    }
+                },
+                "misses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.AIUsage": {
+            "type": "object",
+            "properties": {
+                "completion_tokens": {
+                    "type": "integer"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.AIUsageBucket": {
+            "type": "object",
+            "properties": {
+                "avg_latency_ms": {
+                    "type": "number"
+                },
+                "cached": {
+                    "type": "integer"
+                },
+                "calls": {
+                    "type": "integer"
+                },
+                "completion_tokens": {
+                    "type": "integer"
+                },
+                "cost": {
+                    "description": "estimated from AI_PRICE_PROMPT and AI_PRICE_COMPLETION",
+                    "type": "number"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "2026-10-18"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.AIUsageReport": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AIUsageBucket"
+                    }
+                },
+                "budgets": {
+                    "description": "the platform, and the tenant when tenant_id is given",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AIBudgetStatus"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "group_by": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "total": {
+                    "$ref": "#/definitions/model.AIUsageBucket"
+                }
+            }
+        },
+        "model.AIUsageReportResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.AIUsageReport"
+                }
+            }
+        },
+        "model.AppliedProposalResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.EnrichmentProposal"
+                },
+                "menu": {
+                    "$ref": "#/definitions/model.MenuResponse"
+                }
+            }
+        },
+        "model.ApplyDescriptionRequest": {
+            "type": "object",
+            "required": [
+                "description"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                }
+            }
+        },
+        "model.ApplyProposalRequest": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "category",
+                        "allergens"
+                    ]
+                },
+                "min_confidence": {
+                    "description": "default 0.6",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0,
+                    "example": 0.6
+                }
+            }
+        },
+        "model.AvailabilityRequest": {
+            "type": "object",
+            "required": [
+                "availability"
+            ],
+            "properties": {
+                "availability": {
+                    "type": "string",
+                    "enum": [
+                        "available",
+                        "sold_out"
+                    ],
+                    "example": "sold_out"
+                }
+            }
+        },
+        "model.BranchOverrideRequest": {
+            "type": "object",
+            "properties": {
+                "availability": {
+                    "type": "string",
+                    "enum": [
+                        "available",
+                        "sold_out"
+                    ],
+                    "example": "sold_out"
+                },
+                "price": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 27000
+                }
+            }
+        },
+        "model.CreateBranchRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "Jl. Ir. H. Juanda No. 1, Bandung"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Dago"
+                }
+            }
+        },
+        "model.CreatePromptVersionRequest": {
+            "type": "object",
+            "required": [
+                "body"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "maxLength": 20000
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "Shorter, more playful copy"
+                }
+            }
+        },
+        "model.CreateTenantRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Kopi Kenangan"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "kopi-kenangan"
+                }
+            }
+        },
+        "model.DescriptionStreamDone": {
+            "type": "object",
+            "properties": {
+                "generated_description": {
+                    "type": "string"
+                },
+                "usage": {
+                    "$ref": "#/definitions/model.AIUsage"
+                }
+            }
+        },
+        "model.EnrichmentProposal": {
+            "type": "object",
+            "properties": {
+                "applied_fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "menu_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "suggestion": {
+                    "$ref": "#/definitions/model.EnrichmentSuggestion"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.EnrichmentProposalListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.EnrichmentProposal"
+                    }
+                }
+            }
+        },
+        "model.EnrichmentProposalResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.EnrichmentProposal"
+                }
+            }
+        },
+        "model.EnrichmentRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "ingredients": {
+                    "type": "array",
+                    "maxItems": 30,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "espresso",
+                        "milk",
+                        "palm sugar"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Kopi Susu"
+                }
+            }
+        },
+        "model.EnrichmentSuggestion": {
+            "type": "object",
+            "properties": {
+                "allergens": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SuggestedLabel"
+                    }
+                },
+                "calories": {
+                    "description": "0 when unknown",
+                    "type": "integer",
+                    "example": 180
+                },
+                "calories_confidence": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "category": {
+                    "type": "string",
+                    "example": "drinks"
+                },
+                "category_confidence": {
+                    "type": "number",
+                    "example": 0.9
+                },
+                "dietary_tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SuggestedLabel"
+                    }
+                },
+                "source": {
+                    "type": "string",
+                    "example": "ai"
+                }
+            }
+        },
+        "model.EnrichmentSuggestionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.EnrichmentSuggestion"
+                }
+            }
+        },
+        "model.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Invalid input format or ID not found"
+                }
+            }
+        },
+        "model.FacetCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GeneralResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GenerateDescriptionRequest": {
+            "type": "object",
+            "required": [
+                "ingredients",
+                "name"
+            ],
+            "properties": {
+                "audience": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "office workers on a lunch break"
+                },
+                "candidates": {
+                    "description": "alternatives to pick from, 1 by default",
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1,
+                    "example": 3
+                },
+                "ingredients": {
+                    "type": "array",
+                    "maxItems": 30,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "language": {
+                    "description": "locale tag, English by default",
+                    "type": "string",
+                    "example": "en"
+                },
+                "max_words": {
+                    "type": "integer",
+                    "maximum": 80,
+                    "minimum": 5,
+                    "example": 20
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "tone": {
+                    "type": "string",
+                    "enum": [
+                        "casual",
+                        "premium",
+                        "playful"
+                    ],
+                    "example": "premium"
+                }
+            }
+        },
+        "model.GenerateDescriptionResponse": {
+            "type": "object",
+            "properties": {
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "generated_description": {
+                    "description": "the first candidate",
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "ai"
+                }
+            }
+        },
+        "model.GenerateProposalsRequest": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "description": "default 20",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 20
+                },
+                "menu_ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "model.GenerateTranslationsRequest": {
+            "type": "object",
+            "required": [
+                "locales"
+            ],
+            "properties": {
+                "limit": {
+                    "description": "maximum menus translated per locale, default 100",
+                    "type": "integer",
+                    "example": 100
+                },
+                "locales": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "id"
+                    ]
+                }
+            }
+        },
+        "model.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "ai": {
+                    "$ref": "#/definitions/model.AIBreakerStatus"
+                },
+                "database": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "model.ImageVariant": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Job": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "menu_id": {
+                    "type": "integer"
+                },
+                "run_at": {
+                    "description": "next attempt",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.JobResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.Job"
+                }
+            }
+        },
+        "model.Menu": {
+            "type": "object",
+            "properties": {
+                "allergens": {
+                    "description": "see model.Allergens",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "availability": {
+                    "type": "string"
+                },
+                "calories": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "daily_stock": {
+                    "description": "stock restored by the daily reset",
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "description_status": {
+                    "description": "DescriptionStatus is managed by the service, it is ignored on input",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "image": {
+                    "$ref": "#/definitions/model.MenuImage"
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "rating_average": {
+                    "description": "The rating of the approved reviews and their summary are managed by the review\nservice, they are ignored on input",
+                    "type": "number"
+                },
+                "rating_count": {
+                    "type": "integer"
+                },
+                "review_summary": {
+                    "$ref": "#/definitions/model.ReviewSummary"
+                },
+                "stock": {
+                    "description": "nil means stock is not tracked",
+                    "type": "integer"
+                },
+                "tags": {
+                    "description": "assigned through PUT /menu/{id}/tags",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Tag"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MenuDetailResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.MenuResponse"
+                }
+            }
+        },
+        "model.MenuDraft": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "food"
+                },
+                "confidence": {
+                    "description": "Confidence is how well the AI could read the item, from 0 to 1, ignored on import",
+                    "type": "number",
+                    "example": 0.9
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "ingredients": {
+                    "description": "guessed from the name",
+                    "type": "array",
+                    "maxItems": 30,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "rice",
+                        "egg",
+                        "sambal"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Nasi Goreng"
+                },
+                "price": {
+                    "description": "0 when the price cannot be read",
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 25000
+                }
+            }
+        },
+        "model.MenuExtractionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MenuDraft"
+                    }
+                }
+            }
+        },
+        "model.MenuFacets": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FacetCount"
+                    }
+                },
+                "price_buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PriceBucket"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TagFacetCount"
+                    }
+                }
+            }
+        },
+        "model.MenuImage": {
+            "type": "object",
+            "properties": {
+                "variants": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.ImageVariant"
+                    }
+                }
+            }
+        },
+        "model.MenuImageResponse": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "type": "integer"
+                },
+                "large_url": {
+                    "type": "string"
+                },
+                "thumbnail_url": {
+                    "type": "string"
+                },
+                "thumbnail_webp_url": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.MenuImportRequest": {
+            "type": "object",
+            "required": [
+                "menus"
+            ],
+            "properties": {
+                "menus": {
+                    "type": "array",
+                    "maxItems": 200,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/model.MenuDraft"
+                    }
+                }
+            }
+        },
+        "model.MenuImportResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Menu"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MenuListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MenuResponse"
+                    }
+                }
+            }
+        },
+        "model.MenuPaginationResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.MenuResponse"
+                    }
+                },
+                "facets": {
+                    "$ref": "#/definitions/model.MenuFacets"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "per_page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.MenuResponse": {
+            "type": "object",
+            "properties": {
+                "allergens": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "availability": {
+                    "type": "string"
+                },
+                "calories": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "description_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "image": {
+                    "$ref": "#/definitions/model.MenuImageResponse"
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "locale": {
+                    "description": "set when name and description are translated",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "rating_average": {
+                    "type": "number"
+                },
+                "rating_count": {
+                    "type": "integer"
+                },
+                "review_summary": {
+                    "$ref": "#/definitions/model.ReviewSummary"
+                },
+                "score": {
+                    "description": "similarity, only set by semantic search and similar menus",
+                    "type": "number"
+                },
+                "sold_out": {
+                    "type": "boolean"
+                },
+                "stock": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Tag"
+                    }
+                }
+            }
+        },
+        "model.MenuSuccessResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.Menu"
+                },
+                "enrichment": {
+                    "description": "only with enrich=true",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.EnrichmentSuggestion"
+                        }
+                    ]
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.MenuTagsRequest": {
+            "type": "object",
+            "required": [
+                "tags"
+            ],
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "spicy",
+                        "chefs-pick"
+                    ]
+                }
+            }
+        },
+        "model.ModerateReviewRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "approved",
+                        "rejected"
+                    ],
+                    "example": "approved"
+                }
+            }
+        },
+        "model.PriceBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.PromptPreview": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "prompt": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "empty for a body not saved yet",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PromptPreviewRequest": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "maxLength": 20000
+                },
+                "menu": {
+                    "$ref": "#/definitions/model.PromptSampleMenu"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "model.PromptPreviewResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.PromptPreview"
+                }
+            }
+        },
+        "model.PromptSampleMenu": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "food"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Fried rice with a fried egg"
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "rice",
+                        "egg",
+                        "sambal"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Nasi Goreng"
+                }
+            }
+        },
+        "model.PromptTemplate": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PromptTemplateListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PromptTemplateSummary"
+                    }
+                }
+            }
+        },
+        "model.PromptTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.PromptTemplate"
+                }
+            }
+        },
+        "model.PromptTemplateSummary": {
+            "type": "object",
+            "properties": {
+                "active_version": {
+                    "type": "integer"
+                },
+                "latest_version": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PromptVersionListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PromptTemplate"
+                    }
+                }
+            }
+        },
+        "model.RecommendationListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RecommendationResponse"
+                    }
+                }
+            }
+        },
+        "model.RecommendationMessageRequest": {
+            "type": "object",
+            "required": [
+                "message"
+            ],
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Something cheaper, without dairy"
+                }
+            }
+        },
+        "model.RecommendationRequest": {
+            "type": "object",
+            "required": [
+                "preference"
+            ],
+            "properties": {
+                "preference": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "model.RecommendationResponse": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "type": "number"
+                },
+                "menu": {
+                    "$ref": "#/definitions/model.MenuResponse"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "source": {
                     "type": "string"
                 }
             }
         },
-        "model.GenerateDescriptionRequest": {
+        "model.RecommendationSessionResponse": {
             "type": "object",
-            "required": [
-                "ingredients",
-                "name"
-            ],
             "properties": {
-                "ingredients": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "recommendations": {
+                    "description": "for the latest message",
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/model.RecommendationResponse"
                     }
                 },
-                "name": {
+                "session_id": {
                     "type": "string"
+                },
+                "turns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RecommendationTurn"
+                    }
                 }
             }
         },
-        "model.GenerateDescriptionResponse": {
+        "model.RecommendationStreamDone": {
             "type": "object",
             "properties": {
-                "generated_description": {
-                    "type": "string"
+                "count": {
+                    "type": "integer"
+                },
+                "usage": {
+                    "$ref": "#/definitions/model.AIUsage"
                 }
             }
         },
-        "model.Menu": {
+        "model.RecommendationTurn": {
             "type": "object",
             "properties": {
-                "calories": {
-                    "type": "integer"
+                "created_at": {
+                    "type": "string"
                 },
-                "category": {
+                "message": {
                     "type": "string"
                 },
-                "created_at": {
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SuggestedMenu"
+                    }
+                }
+            }
+        },
+        "model.Review": {
+            "type": "object",
+            "properties": {
+                "author": {
                     "type": "string"
                 },
-                "description": {
+                "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "ingredients": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "menu_id": {
+                    "type": "integer"
                 },
-                "name": {
+                "rating": {
+                    "type": "integer"
+                },
+                "status": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
+                "text": {
+                    "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "model.MenuDetailResponse": {
+        "model.ReviewListResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "$ref": "#/definitions/model.MenuResponse"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Review"
+                    }
                 }
             }
         },
-        "model.MenuPaginationResponse": {
+        "model.ReviewRequest": {
+            "type": "object",
+            "required": [
+                "rating"
+            ],
+            "properties": {
+                "author": {
+                    "description": "\"Anonymous\" when empty",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Ayu"
+                },
+                "rating": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1,
+                    "example": 5
+                },
+                "text": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "Smoky and just spicy enough"
+                }
+            }
+        },
+        "model.ReviewResponse": {
             "type": "object",
             "properties": {
                 "data": {
+                    "$ref": "#/definitions/model.Review"
+                }
+            }
+        },
+        "model.ReviewSummary": {
+            "type": "object",
+            "properties": {
+                "cons": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.MenuResponse"
-                    }
+                        "type": "string"
+                    },
+                    "example": [
+                        "oily"
+                    ]
                 },
-                "page": {
-                    "type": "integer"
+                "generated_at": {
+                    "type": "string"
                 },
-                "per_page": {
-                    "type": "integer"
+                "pros": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "smoky flavor",
+                        "generous portion"
+                    ]
                 },
-                "total": {
-                    "type": "integer"
+                "review_count": {
+                    "description": "reviews summarized",
+                    "type": "integer",
+                    "example": 12
                 },
-                "total_pages": {
-                    "type": "integer"
+                "summary": {
+                    "type": "string",
+                    "example": "Loved for its smoky flavor, some find it too oily."
                 }
             }
         },
-        "model.MenuResponse": {
+        "model.ReviewSummaryResponse": {
             "type": "object",
             "properties": {
-                "calories": {
+                "data": {
+                    "$ref": "#/definitions/model.ReviewSummary"
+                }
+            }
+        },
+        "model.StartRecommendationSessionRequest": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "I need something to wake me up"
+                }
+            }
+        },
+        "model.StockAdjustmentRequest": {
+            "type": "object",
+            "required": [
+                "quantity"
+            ],
+            "properties": {
+                "quantity": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                }
+            }
+        },
+        "model.SuggestedLabel": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "type": "number",
+                    "example": 0.9
+                },
+                "name": {
+                    "type": "string",
+                    "example": "milk"
+                }
+            }
+        },
+        "model.SuggestedMenu": {
+            "type": "object",
+            "properties": {
+                "menu_id": {
                     "type": "integer"
                 },
-                "category": {
+                "name": {
                     "type": "string"
                 },
-                "description": {
+                "price": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Tag": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "ingredients": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.TagFacetCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
+                "slug": {
+                    "type": "string"
                 }
             }
         },
-        "model.MenuSuccessResponse": {
+        "model.TagRequest": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/model.Menu"
+                "name": {
+                    "type": "string",
+                    "example": "Chef's Pick"
                 },
-                "message": {
+                "slug": {
+                    "type": "string",
+                    "example": "chefs-pick"
+                }
+            }
+        },
+        "model.Tenant": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "model.RecommendationListResponse": {
+        "model.TenantCreatedResponse": {
             "type": "object",
             "properties": {
+                "api_key": {
+                    "type": "string"
+                },
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.RecommendationResponse"
-                    }
+                    "$ref": "#/definitions/model.Tenant"
                 }
             }
         },
-        "model.RecommendationRequest": {
+        "model.TranslationRequest": {
             "type": "object",
             "required": [
-                "preference"
+                "name"
             ],
             "properties": {
-                "preference": {
-                    "type": "string"
+                "description": {
+                    "type": "string",
+                    "example": "Espresso lembut dengan susu segar dan manis gula aren."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Kopi Susu Gula Aren"
                 }
             }
         },
-        "model.RecommendationResponse": {
+        "model.UpdateMenuRequest": {
             "type": "object",
             "properties": {
-                "menu": {
-                    "$ref": "#/definitions/model.MenuResponse"
+                "allergens": {
+                    "description": "see model.Allergens",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "reason": {
+                "availability": {
+                    "type": "string"
+                },
+                "calories": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "daily_stock": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "description_status": {
+                    "description": "DescriptionStatus is managed by the service, it is ignored on input",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "image": {
+                    "$ref": "#/definitions/model.MenuImage"
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "rating_average": {
+                    "description": "The rating of the approved reviews and their summary are managed by the review\nservice, they are ignored on input",
+                    "type": "number"
+                },
+                "rating_count": {
+                    "type": "integer"
+                },
+                "review_summary": {
+                    "$ref": "#/definitions/model.ReviewSummary"
+                },
+                "stock": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "description": "assigned through PUT /menu/{id}/tags",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Tag"
+                    }
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminKey": {
+            "type": "apiKey",
+            "name": "X-Admin-Key",
+            "in": "header"
+        },
+        "TenantAPIKey": {
+            "description": "\"Bearer \u003ctenant api key\u003e\". X-Tenant-ID alone selects the tenant only with ALLOW_TENANT_HEADER=true",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -0,0 +1,244 @@
+// Package cassette records the HTTP exchanges of an AI provider to a file and
+// replays them, so tests exercise the real response parsing without the network
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Mode tells a Transport whether to call the provider or answer from the cassette
+type Mode int
+
+const (
+	Replay Mode = iota
+	Record
+)
+
+var ErrNoInteraction = errors.New("cassette: no recorded interaction")
+
+// Query parameters never written to a cassette. Headers are not recorded at all,
+// so keys sent as headers (Authorization, x-goog-api-key) stay out as well.
+var secretParams = []string{"key", "api_key", "access_token"}
+
+// Cassette is the file of recorded interactions, in the order they happened
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string  `json:"method"`
+	URL    string  `json:"url"`
+	Body   Payload `json:"body,omitempty"`
+}
+
+type Response struct {
+	Status      int     `json:"status"`
+	ContentType string  `json:"content_type,omitempty"`
+	Body        Payload `json:"body,omitempty"`
+}
+
+// Payload is written as JSON when it is JSON, so that cassettes stay readable and
+// can be edited by hand, and as a string otherwise (e.g. server-sent events)
+type Payload []byte
+
+func (p Payload) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return []byte(`""`), nil
+	}
+	if json.Valid(p) {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, p); err != nil {
+			return nil, err
+		}
+		return compact.Bytes(), nil
+	}
+	var text bytes.Buffer
+	encoder := json.NewEncoder(&text)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(string(p)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(text.Bytes(), []byte("\n")), nil
+}
+
+func (p *Payload) UnmarshalJSON(raw []byte) error {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		*p = Payload(text)
+		return nil
+	}
+	*p = append(Payload(nil), raw...)
+	return nil
+}
+
+func Load(path string) (*Cassette, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(raw, &cassette); err != nil {
+		return nil, fmt.Errorf("cassette %s: %w", path, err)
+	}
+	return &cassette, nil
+}
+
+func (c *Cassette) Save(path string) error {
+	// Prompts are full of <tags>, keep them readable
+	var raw bytes.Buffer
+	encoder := json.NewEncoder(&raw)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(c); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw.Bytes(), 0o644)
+}
+
+// Transport is an http.RoundTripper playing a cassette. In Replay mode every request
+// is answered by the next unused interaction with the same method and URL, and fails
+// with ErrNoInteraction when there is none. In Record mode requests go through Real
+// and are kept until Save.
+type Transport struct {
+	Mode Mode
+	Real http.RoundTripper // used in Record mode, http.DefaultTransport when nil
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New replays the cassette at path
+func New(path string) (*Transport, error) {
+	cassette, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Transport{Mode: Replay, cassette: cassette, used: make([]bool, len(cassette.Interactions))}, nil
+}
+
+// NewRecorder records a new cassette through real
+func NewRecorder(real http.RoundTripper) *Transport {
+	return &Transport{Mode: Record, Real: real, cassette: &Cassette{}}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	recorded := Request{Method: req.Method, URL: redact(req.URL), Body: body}
+
+	if t.Mode == Record {
+		return t.record(req, recorded, body)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, interaction := range t.cassette.Interactions {
+		if t.used[i] || interaction.Request.Method != recorded.Method || interaction.Request.URL != recorded.URL {
+			continue
+		}
+		t.used[i] = true
+		return interaction.Response.toHTTP(req), nil
+	}
+	return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, recorded.Method, recorded.URL)
+}
+
+func (t *Transport) record(req *http.Request, recorded Request, body []byte) (*http.Response, error) {
+	real := t.Real
+	if real == nil {
+		real = http.DefaultTransport
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := real.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	response := Response{Status: resp.StatusCode, ContentType: resp.Header.Get("Content-Type"), Body: respBody}
+	t.mu.Lock()
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{Request: recorded, Response: response})
+	t.mu.Unlock()
+	return response.toHTTP(req), nil
+}
+
+// Unused returns the interactions not replayed yet, to assert that a test made every
+// call it was recorded with. A recording has none.
+func (t *Transport) Unused() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var unused []Interaction
+	for i, interaction := range t.cassette.Interactions {
+		if t.Mode == Replay && !t.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// Save writes the recorded interactions to path
+func (t *Transport) Save(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cassette.Save(path)
+}
+
+func (r Response) toHTTP(req *http.Request) *http.Response {
+	header := make(http.Header)
+	if r.ContentType != "" {
+		header.Set("Content-Type", r.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// redact drops the secret query parameters and sorts the others
+func redact(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	for _, param := range secretParams {
+		query.Del(param)
+	}
+	redacted.RawQuery = query.Encode()
+	redacted.User = nil
+	return strings.TrimSuffix(redacted.String(), "?")
+}
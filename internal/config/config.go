@@ -0,0 +1,321 @@
+// Package config reads runtime settings from the environment
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAITimeout bounds a single call to the AI provider
+const DefaultAITimeout = 30 * time.Second
+
+// AI selects and configures the AIService provider
+type AI struct {
+	Provider string        // AI_PROVIDER: gemini (default), openai or offline
+	Model    string        // AI_MODEL, empty uses the provider default
+	APIKey   string        // AI_API_KEY, falls back to GEMINI_API_KEY or OPENAI_API_KEY
+	BaseURL  string        // AI_BASE_URL, e.g. http://localhost:11434/v1 for Ollama
+	Timeout  time.Duration // AI_TIMEOUT, e.g. 30s
+
+	// Transport carries the provider's HTTP requests, nil uses http.DefaultTransport.
+	// Tests set it to a cassette.Transport to replay recorded answers.
+	Transport http.RoundTripper
+}
+
+// LoadAI reads the AI settings with getenv, usually os.Getenv
+func LoadAI(getenv func(string) string) (AI, error) {
+	cfg := AI{
+		Provider: strings.ToLower(strings.TrimSpace(getenv("AI_PROVIDER"))),
+		Model:    getenv("AI_MODEL"),
+		APIKey:   getenv("AI_API_KEY"),
+		BaseURL:  strings.TrimSuffix(getenv("AI_BASE_URL"), "/"),
+		Timeout:  DefaultAITimeout,
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = "gemini"
+	}
+
+	if cfg.APIKey == "" {
+		switch cfg.Provider {
+		case "gemini":
+			cfg.APIKey = getenv("GEMINI_API_KEY")
+		case "openai":
+			cfg.APIKey = getenv("OPENAI_API_KEY")
+		}
+	}
+
+	if raw := getenv("AI_TIMEOUT"); raw != "" {
+		timeout, err := time.ParseDuration(raw)
+		if err != nil || timeout <= 0 {
+			return AI{}, fmt.Errorf("invalid AI_TIMEOUT %q, expected a duration such as 30s", raw)
+		}
+		cfg.Timeout = timeout
+	}
+
+	return cfg, nil
+}
+
+// MinTenantAPIKeyLength is the shortest DEFAULT_TENANT_API_KEY accepted
+const MinTenantAPIKeyLength = 16
+
+// Tenancy configures how requests are matched to a tenant
+type Tenancy struct {
+	// AllowHeader lets unauthenticated requests select their tenant with X-Tenant-ID
+	// (ALLOW_TENANT_HEADER=true). It is off by default, only turn it on behind a trusted gateway.
+	AllowHeader bool
+	// DefaultTenantAPIKey is the API key of the default tenant (DEFAULT_TENANT_API_KEY),
+	// set at startup so that the catalog is reachable without the admin API
+	DefaultTenantAPIKey string
+	AdminAPIKey         string // ADMIN_API_KEY, empty disables the /admin routes
+}
+
+// LoadTenancy reads the tenancy settings with getenv, usually os.Getenv
+func LoadTenancy(getenv func(string) string) (Tenancy, error) {
+	cfg := Tenancy{
+		AllowHeader:         strings.EqualFold(strings.TrimSpace(getenv("ALLOW_TENANT_HEADER")), "true"),
+		DefaultTenantAPIKey: strings.TrimSpace(getenv("DEFAULT_TENANT_API_KEY")),
+		AdminAPIKey:         getenv("ADMIN_API_KEY"),
+	}
+	if cfg.DefaultTenantAPIKey != "" && len(cfg.DefaultTenantAPIKey) < MinTenantAPIKeyLength {
+		return Tenancy{}, fmt.Errorf("DEFAULT_TENANT_API_KEY must be at least %d characters", MinTenantAPIKeyLength)
+	}
+	return cfg, nil
+}
+
+// Reachable reports whether a client can be given access to a tenant without knowing a key
+// generated earlier: through the default tenant key, the admin API or the tenant header
+func (t Tenancy) Reachable() bool {
+	return t.DefaultTenantAPIKey != "" || t.AdminAPIKey != "" || t.AllowHeader
+}
+
+// Embedding selects and configures the provider that turns menu text into vectors
+type Embedding struct {
+	Provider   string        // EMBEDDING_PROVIDER: hashing (default, offline), openai or gemini
+	Model      string        // EMBEDDING_MODEL, empty uses the provider default
+	APIKey     string        // EMBEDDING_API_KEY, falls back to GEMINI_API_KEY, OPENAI_API_KEY or AI_API_KEY
+	BaseURL    string        // EMBEDDING_BASE_URL, for OpenAI-compatible servers
+	Dimensions int           // EMBEDDING_DIMENSIONS, 0 uses the model default
+	Timeout    time.Duration // shared with AI_TIMEOUT
+}
+
+// LoadEmbedding reads the embedding settings with getenv, usually os.Getenv
+func LoadEmbedding(getenv func(string) string) (Embedding, error) {
+	cfg := Embedding{
+		Provider: strings.ToLower(strings.TrimSpace(getenv("EMBEDDING_PROVIDER"))),
+		Model:    getenv("EMBEDDING_MODEL"),
+		APIKey:   getenv("EMBEDDING_API_KEY"),
+		BaseURL:  strings.TrimSuffix(getenv("EMBEDDING_BASE_URL"), "/"),
+		Timeout:  DefaultAITimeout,
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = "hashing"
+	}
+
+	if cfg.APIKey == "" {
+		switch cfg.Provider {
+		case "gemini":
+			cfg.APIKey = getenv("GEMINI_API_KEY")
+		case "openai":
+			cfg.APIKey = getenv("OPENAI_API_KEY")
+		}
+	}
+	if cfg.APIKey == "" {
+		cfg.APIKey = getenv("AI_API_KEY")
+	}
+
+	if raw := getenv("EMBEDDING_DIMENSIONS"); raw != "" {
+		dimensions, err := strconv.Atoi(raw)
+		if err != nil || dimensions <= 0 {
+			return Embedding{}, fmt.Errorf("invalid EMBEDDING_DIMENSIONS %q, expected a positive number", raw)
+		}
+		cfg.Dimensions = dimensions
+	}
+
+	if raw := getenv("AI_TIMEOUT"); raw != "" {
+		timeout, err := time.ParseDuration(raw)
+		if err != nil || timeout <= 0 {
+			return Embedding{}, fmt.Errorf("invalid AI_TIMEOUT %q, expected a duration such as 30s", raw)
+		}
+		cfg.Timeout = timeout
+	}
+
+	return cfg, nil
+}
+
+// Defaults of the AI response cache
+const (
+	DefaultAICacheSize            = 1000
+	DefaultDescriptionCacheTTL    = 24 * time.Hour
+	DefaultRecommendationCacheTTL = 15 * time.Minute
+	DefaultTranslationCacheTTL    = 7 * 24 * time.Hour
+)
+
+// AICache configures the cache in front of the AI provider
+type AICache struct {
+	Size              int           // AI_CACHE_SIZE, answers kept in memory, 0 disables the cache
+	Store             string        // AI_CACHE_STORE: memory (default), or db to also keep answers in the database
+	DescriptionTTL    time.Duration // AI_CACHE_TTL_DESCRIPTION, 0 stops caching descriptions
+	RecommendationTTL time.Duration // AI_CACHE_TTL_RECOMMENDATIONS
+	TranslationTTL    time.Duration // AI_CACHE_TTL_TRANSLATIONS
+}
+
+// LoadAICache reads the AI cache settings with getenv, usually os.Getenv
+func LoadAICache(getenv func(string) string) (AICache, error) {
+	cfg := AICache{
+		Size:              DefaultAICacheSize,
+		Store:             strings.ToLower(strings.TrimSpace(getenv("AI_CACHE_STORE"))),
+		DescriptionTTL:    DefaultDescriptionCacheTTL,
+		RecommendationTTL: DefaultRecommendationCacheTTL,
+		TranslationTTL:    DefaultTranslationCacheTTL,
+	}
+	if cfg.Store == "" {
+		cfg.Store = "memory"
+	}
+	if cfg.Store != "memory" && cfg.Store != "db" {
+		return AICache{}, fmt.Errorf("invalid AI_CACHE_STORE %q, expected memory or db", cfg.Store)
+	}
+
+	if raw := getenv("AI_CACHE_SIZE"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 0 {
+			return AICache{}, fmt.Errorf("invalid AI_CACHE_SIZE %q, expected a number of entries", raw)
+		}
+		cfg.Size = size
+	}
+
+	ttls := map[string]*time.Duration{
+		"AI_CACHE_TTL_DESCRIPTION":     &cfg.DescriptionTTL,
+		"AI_CACHE_TTL_RECOMMENDATIONS": &cfg.RecommendationTTL,
+		"AI_CACHE_TTL_TRANSLATIONS":    &cfg.TranslationTTL,
+	}
+	for name, ttl := range ttls {
+		raw := getenv(name)
+		if raw == "" {
+			continue
+		}
+		value, err := time.ParseDuration(raw)
+		if err != nil || value < 0 {
+			return AICache{}, fmt.Errorf("invalid %s %q, expected a duration such as 1h, or 0 to disable", name, raw)
+		}
+		*ttl = value
+	}
+
+	return cfg, nil
+}
+
+// AIBudget limits the tokens spent per calendar month (UTC) and prices them in the usage report
+type AIBudget struct {
+	MonthlyTokens       int64   // AI_BUDGET_MONTHLY_TOKENS, for the whole platform, 0 is unlimited
+	TenantMonthlyTokens int64   // AI_BUDGET_TENANT_MONTHLY_TOKENS, for each tenant, 0 is unlimited
+	PromptPrice         float64 // AI_PRICE_PROMPT, cost of one million prompt tokens
+	CompletionPrice     float64 // AI_PRICE_COMPLETION, cost of one million completion tokens
+}
+
+// LoadAIBudget reads the AI budget settings with getenv, usually os.Getenv
+func LoadAIBudget(getenv func(string) string) (AIBudget, error) {
+	var cfg AIBudget
+
+	limits := map[string]*int64{
+		"AI_BUDGET_MONTHLY_TOKENS":        &cfg.MonthlyTokens,
+		"AI_BUDGET_TENANT_MONTHLY_TOKENS": &cfg.TenantMonthlyTokens,
+	}
+	for name, limit := range limits {
+		raw := getenv(name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || value < 0 {
+			return AIBudget{}, fmt.Errorf("invalid %s %q, expected a number of tokens, or 0 for no limit", name, raw)
+		}
+		*limit = value
+	}
+
+	prices := map[string]*float64{
+		"AI_PRICE_PROMPT":     &cfg.PromptPrice,
+		"AI_PRICE_COMPLETION": &cfg.CompletionPrice,
+	}
+	for name, price := range prices {
+		raw := getenv(name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || value < 0 {
+			return AIBudget{}, fmt.Errorf("invalid %s %q, expected the price of one million tokens", name, raw)
+		}
+		*price = value
+	}
+
+	return cfg, nil
+}
+
+// Defaults of the retries and circuit breaker around the AI provider
+const (
+	DefaultAIRetries          = 2
+	DefaultAIRetryBaseDelay   = 200 * time.Millisecond
+	DefaultAIRetryMaxDelay    = 2 * time.Second
+	DefaultAIBreakerThreshold = 5
+	DefaultAIBreakerCooldown  = 30 * time.Second
+)
+
+// AIResilience configures how failing AI calls are retried and when the provider is given a rest
+type AIResilience struct {
+	Retries          int           // AI_RETRIES, extra attempts after a transient error, 0 disables retries
+	RetryBaseDelay   time.Duration // AI_RETRY_BASE_DELAY, doubled after every attempt
+	RetryMaxDelay    time.Duration // AI_RETRY_MAX_DELAY
+	BreakerThreshold int           // AI_BREAKER_THRESHOLD, failed calls in a row that open the breaker, 0 disables it
+	BreakerCooldown  time.Duration // AI_BREAKER_COOLDOWN, time open before one probe call is let through
+}
+
+// LoadAIResilience reads the retry and circuit breaker settings with getenv, usually os.Getenv
+func LoadAIResilience(getenv func(string) string) (AIResilience, error) {
+	cfg := AIResilience{
+		Retries:          DefaultAIRetries,
+		RetryBaseDelay:   DefaultAIRetryBaseDelay,
+		RetryMaxDelay:    DefaultAIRetryMaxDelay,
+		BreakerThreshold: DefaultAIBreakerThreshold,
+		BreakerCooldown:  DefaultAIBreakerCooldown,
+	}
+
+	counts := map[string]*int{
+		"AI_RETRIES":           &cfg.Retries,
+		"AI_BREAKER_THRESHOLD": &cfg.BreakerThreshold,
+	}
+	for name, count := range counts {
+		raw := getenv(name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return AIResilience{}, fmt.Errorf("invalid %s %q, expected a number, or 0 to disable", name, raw)
+		}
+		*count = value
+	}
+
+	durations := map[string]*time.Duration{
+		"AI_RETRY_BASE_DELAY": &cfg.RetryBaseDelay,
+		"AI_RETRY_MAX_DELAY":  &cfg.RetryMaxDelay,
+		"AI_BREAKER_COOLDOWN": &cfg.BreakerCooldown,
+	}
+	for name, duration := range durations {
+		raw := getenv(name)
+		if raw == "" {
+			continue
+		}
+		value, err := time.ParseDuration(raw)
+		if err != nil || value <= 0 {
+			return AIResilience{}, fmt.Errorf("invalid %s %q, expected a duration such as 500ms", name, raw)
+		}
+		*duration = value
+	}
+	if cfg.RetryMaxDelay < cfg.RetryBaseDelay {
+		cfg.RetryMaxDelay = cfg.RetryBaseDelay
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AIController serves the platform endpoints about the AI provider
+type AIController struct {
+	cache service.CachedAIService // nil when the cache is disabled
+	usage service.MeteredAIService
+}
+
+func NewAIController(cache service.CachedAIService, usage service.MeteredAIService) *AIController {
+	return &AIController{cache, usage}
+}
+
+// aiFailure picks the status and message of a failed AI feature. The errors of the
+// provider are logged, their text is not shown to the client, and status and message
+// are used for them.
+func aiFailure(err error, status int, message string) (int, string) {
+	switch {
+	case errors.Is(err, service.ErrTenantAIBudgetExceeded):
+		return http.StatusPaymentRequired, err.Error()
+	case errors.Is(err, service.ErrAIBudgetExceeded):
+		return http.StatusTooManyRequests, err.Error()
+	case errors.Is(err, service.ErrAIUnavailable):
+		return http.StatusServiceUnavailable, err.Error()
+	}
+	log.Printf("%s: %v", message, err)
+	return status, message
+}
+
+// CacheStats godoc
+//
+// @Summary    AI cache statistics
+// @Description  Hits, misses and hit rate of the AI response cache since the server started, in total and per method
+// @Tags     admin
+// @Produce    json
+// @Security   AdminKey
+// @Success    200   {object}  model.AICacheStats
+// @Failure    401   {object}  model.ErrorResponse  "Admin key required"
+// @Failure    404   {object}  model.ErrorResponse  "Cache disabled"
+// @Router     /admin/ai/cache [get]
+func (c *AIController) CacheStats(ctx *gin.Context) {
+	if c.cache == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "AI cache is disabled, set AI_CACHE_SIZE to enable it"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": c.cache.Stats()})
+}
+
+// Usage godoc
+//
+// @Summary    AI usage and cost
+// @Description  Calls, errors, cache hits, tokens, average latency and estimated cost of the AI calls, grouped by UTC day, endpoint or client (tenant ID). The monthly budgets show the tokens used this month, AI endpoints answer 402 once the budget of the tenant is used up and 429 once the platform budget is.
+// @Tags     admin
+// @Produce    json
+// @Security   AdminKey
+// @Param      group_by   query     string  false  "Grouping, day by default"  Enums(day, endpoint, client)
+// @Param      from       query     string  false  "First day, YYYY-MM-DD, the first day of the month by default"
+// @Param      to         query     string  false  "Last day, YYYY-MM-DD, today by default"
+// @Param      tenant_id  query     int     false  "Only the calls of this tenant, with its budget"
+// @Param      endpoint   query     string  false  "Only the calls of this endpoint, e.g. POST /menu/recommendations"
+// @Success    200        {object}  model.AIUsageReportResponse
+// @Failure    400        {object}  model.ErrorResponse  "Invalid query"
+// @Failure    401        {object}  model.ErrorResponse  "Admin key required"
+// @Router     /admin/ai/usage [get]
+func (c *AIController) Usage(ctx *gin.Context) {
+	var query model.AIUsageQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if query.From != "" && query.To != "" && query.From > query.To {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	report, err := c.usage.Usage(query)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": report})
+}
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type EnrichmentController struct {
+	service service.EnrichmentService
+}
+
+func NewEnrichmentController(service service.EnrichmentService) *EnrichmentController {
+	return &EnrichmentController{service}
+}
+
+func respondEnrichmentError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Menu or proposal not found"})
+	case errors.Is(err, service.ErrProposalNotPending):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		status, message := aiFailure(err, http.StatusInternalServerError, message)
+		ctx.JSON(status, gin.H{"error": message})
+	}
+}
+
+// Suggest godoc
+//
+// @Summary    Suggest menu details
+// @Description  Suggest the category (one the tenant already uses, when it has any), estimated calories, dietary tags and likely allergens of a menu from its name and ingredients, each with a confidence from 0 to 1. Nothing is saved. When the AI provider fails the suggestions come from ingredient rules with source "fallback".
+// @Tags       AI
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      input body      model.EnrichmentRequest  true  "Menu"
+// @Success    200   {object}  model.EnrichmentSuggestionResponse
+// @Failure    400   {object}  model.ErrorResponse  "Validation Error"
+// @Failure    402   {object}  model.ErrorResponse  "Monthly AI budget of the tenant used up"
+// @Failure    429   {object}  model.ErrorResponse  "Monthly AI budget of the platform used up"
+// @Failure    500   {object}  model.ErrorResponse  "Server Error"
+// @Router     /menu/enrichment/suggest [post]
+func (c *EnrichmentController) Suggest(ctx *gin.Context) {
+	var input model.EnrichmentRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	suggestion, err := c.service.Suggest(ctx.Request.Context(), middleware.Scope(ctx), input)
+	if err != nil {
+		respondEnrichmentError(ctx, err, "Failed to suggest menu details")
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": suggestion})
+}
+
+// Propose godoc
+//
+// @Summary    Propose details for a menu
+// @Description  Store the suggestions for an existing menu as a pending proposal to review, see /menu/enrichment/proposals/{proposal_id}/apply. Earlier pending proposals of the menu are superseded.
+// @Tags       AI
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id    path      int  true  "Menu ID"
+// @Success    201   {object}  model.EnrichmentProposalResponse
+// @Failure    400   {object}  model.ErrorResponse  "Invalid ID format"
+// @Failure    404   {object}  model.ErrorResponse  "Menu not found"
+// @Failure    402   {object}  model.ErrorResponse  "Monthly AI budget of the tenant used up"
+// @Failure    429   {object}  model.ErrorResponse  "Monthly AI budget of the platform used up"
+// @Router     /menu/{id}/enrichment [post]
+func (c *EnrichmentController) Propose(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	proposal, err := c.service.Propose(ctx.Request.Context(), middleware.Scope(ctx), uint(id))
+	if err != nil {
+		respondEnrichmentError(ctx, err, "Failed to propose menu details")
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": proposal})
+}
+
+// GenerateProposals godoc
+//
+// @Summary    Propose details for many menus
+// @Description  Make a pending proposal for each menu given, or for up to limit menus without a category or calories. It stops at the first failure, the proposals made before it are kept.
+// @Tags       AI
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      input body      model.GenerateProposalsRequest  true  "Menus"
+// @Success    201   {object}  model.EnrichmentProposalListResponse
+// @Failure    400   {object}  model.ErrorResponse  "Validation Error"
+// @Failure    404   {object}  model.ErrorResponse  "Menu not found"
+// @Failure    402   {object}  model.ErrorResponse  "Monthly AI budget of the tenant used up"
+// @Failure    429   {object}  model.ErrorResponse  "Monthly AI budget of the platform used up"
+// @Router     /menu/enrichment/proposals [post]
+func (c *EnrichmentController) GenerateProposals(ctx *gin.Context) {
+	var input model.GenerateProposalsRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	proposals, err := c.service.ProposeMany(ctx.Request.Context(), middleware.Scope(ctx), input)
+	if err != nil {
+		respondEnrichmentError(ctx, err, "Failed to propose menu details")
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": proposals})
+}
+
+// ListProposals godoc
+//
+// @Summary    List enrichment proposals
+// @Description  Proposals of the tenant, newest first
+// @Tags       AI
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      status   query     string  false  "pending, applied, rejected or superseded"
+// @Param      menu_id  query     int     false  "Only the proposals of this menu"
+// @Success    200      {object}  model.EnrichmentProposalListResponse
+// @Failure    400      {object}  model.ErrorResponse  "Invalid status or menu ID"
+// @Router     /menu/enrichment/proposals [get]
+func (c *EnrichmentController) ListProposals(ctx *gin.Context) {
+	status := ctx.Query("status")
+	switch status {
+	case "", model.ProposalStatusPending, model.ProposalStatusApplied, model.ProposalStatusRejected, model.ProposalStatusSuperseded:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+	var menuID uint64
+	if raw := ctx.Query("menu_id"); raw != "" {
+		var err error
+		if menuID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid menu ID"})
+			return
+		}
+	}
+
+	proposals, err := c.service.ListProposals(middleware.Scope(ctx), status, uint(menuID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": proposals})
+}
+
+// ApplyProposal godoc
+//
+// @Summary    Apply an enrichment proposal
+// @Description  Apply the fields given, every field by default, of a pending proposal with at least min_confidence (default 0.6). The category and calories are replaced, the dietary tags and allergens are added to those of the menu. Missing dietary tags are created.
+// @Tags       AI
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      proposal_id  path      int                         true   "Proposal ID"
+// @Param      input        body      model.ApplyProposalRequest  false  "Fields to apply"
+// @Success    200          {object}  model.AppliedProposalResponse
+// @Failure    400          {object}  model.ErrorResponse  "Validation Error"
+// @Failure    404          {object}  model.ErrorResponse  "Proposal or menu not found"
+// @Failure    409          {object}  model.ErrorResponse  "Proposal no longer pending"
+// @Router     /menu/enrichment/proposals/{proposal_id}/apply [post]
+func (c *EnrichmentController) ApplyProposal(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("proposal_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	var input model.ApplyProposalRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&input); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	proposal, menu, err := c.service.ApplyProposal(middleware.Scope(ctx), uint(id), input)
+	if err != nil {
+		respondEnrichmentError(ctx, err, "Failed to apply the proposal")
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": proposal, "menu": menu})
+}
+
+// RejectProposal godoc
+//
+// @Summary    Reject an enrichment proposal
+// @Tags       AI
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      proposal_id  path      int  true  "Proposal ID"
+// @Success    200          {object}  model.EnrichmentProposalResponse
+// @Failure    404          {object}  model.ErrorResponse  "Proposal not found"
+// @Failure    409          {object}  model.ErrorResponse  "Proposal no longer pending"
+// @Router     /menu/enrichment/proposals/{proposal_id}/reject [post]
+func (c *EnrichmentController) RejectProposal(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("proposal_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	proposal, err := c.service.RejectProposal(middleware.Scope(ctx), uint(id))
+	if err != nil {
+		respondEnrichmentError(ctx, err, "Failed to reject the proposal")
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": proposal})
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pinger checks the database connection, it is implemented by *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// HealthController reports whether the server can serve requests
+type HealthController struct {
+	db Pinger
+	ai service.ResilientAIService
+}
+
+func NewHealthController(db Pinger, ai service.ResilientAIService) *HealthController {
+	return &HealthController{db, ai}
+}
+
+// Health godoc
+//
+// @Summary    Health check
+// @Description  State of the database and of the circuit breaker in front of the AI provider. An open breaker only degrades the service, AI endpoints answer with rule-based fallbacks, while an unreachable database makes it unavailable.
+// @Tags     health
+// @Produce    json
+// @Success    200   {object}  model.HealthResponse  "ok or degraded"
+// @Failure    503   {object}  model.HealthResponse  "Database unreachable"
+// @Router     /health [get]
+func (c *HealthController) Health(ctx *gin.Context) {
+	health := model.HealthResponse{
+		Status:   model.HealthOK,
+		Database: model.HealthOK,
+		AI:       c.ai.Breaker(),
+	}
+	if health.AI.State != model.BreakerClosed {
+		health.Status = model.HealthDegraded
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx.Request.Context(), 2*time.Second)
+	defer cancel()
+	if err := c.db.PingContext(pingCtx); err != nil {
+		log.Printf("Health check failed to reach the database: %v", err)
+		health.Status = model.HealthUnavailable
+		health.Database = model.HealthUnavailable
+		ctx.JSON(http.StatusServiceUnavailable, health)
+		return
+	}
+	ctx.JSON(http.StatusOK, health)
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"atalariq/menu-api/internal/imaging"
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// multipartOverhead leaves room for the multipart boundaries and headers around the file
+const multipartOverhead = 64 << 10
+
+type ImageController struct {
+	service service.ImageService
+}
+
+func NewImageController(service service.ImageService) *ImageController {
+	return &ImageController{service}
+}
+
+// Upload godoc
+//
+// @Summary    Upload menu image
+// @Description  Upload a JPEG, PNG, GIF or WebP photo. A large variant and a JPEG thumbnail are generated, in WebP too when it is smaller than the JPEG. The previous image is replaced.
+// @Tags     menu
+// @Accept     multipart/form-data
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id     path      int   true  "Menu ID"
+// @Param      image  formData  file  true  "Image file"
+// @Success    200    {object}  model.MenuDetailResponse
+// @Failure    400    {object}  model.ErrorResponse  "Missing file"
+// @Failure    404    {object}  model.ErrorResponse  "Menu Not Found"
+// @Failure    413    {object}  model.ErrorResponse  "Image too large"
+// @Failure    415    {object}  model.ErrorResponse  "Unsupported image format"
+// @Router     /menu/{id}/image [post]
+func (c *ImageController) Upload(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, c.service.MaxBytes()+multipartOverhead)
+	file, err := ctx.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrImageTooLarge.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Field 'image' is required"})
+		return
+	}
+	if file.Size > c.service.MaxBytes() {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrImageTooLarge.Error()})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	menu, err := c.service.Upload(ctx.Request.Context(), middleware.Scope(ctx), uint(id), data)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImageTooLarge):
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, imaging.ErrUnsupportedFormat):
+			ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"message": "Menu not found"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Image uploaded successfully",
+		"data":    menu,
+	})
+}
+
+// Delete godoc
+//
+// @Summary    Delete menu image
+// @Tags     menu
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id  path      int  true  "Menu ID"
+// @Success    200 {object}  model.GeneralResponse
+// @Failure    404 {object}  model.ErrorResponse  "Menu Not Found"
+// @Failure    500 {object}  model.ErrorResponse  "Server Error"
+// @Router     /menu/{id}/image [delete]
+func (c *ImageController) Delete(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := c.service.Delete(ctx.Request.Context(), middleware.Scope(ctx), uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"message": "Menu not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Image deleted successfully"})
+}
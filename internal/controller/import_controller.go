@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExtractMenus godoc
+//
+// @Summary    Read menus from a paper menu
+// @Description  Read the items of a photo (JPEG, PNG or WebP) or PDF of a paper menu into draft menus with a name, price, category and guessed ingredients. Nothing is saved: review the drafts, then send them to /menu/import. Categories the tenant already uses keep their spelling.
+// @Tags       AI
+// @Accept     multipart/form-data
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      file  formData  file  true  "Photo or PDF of the menu, up to 10 MB"
+// @Success    200   {object}  model.MenuExtractionResponse
+// @Failure    400   {object}  model.ErrorResponse  "Missing file"
+// @Failure    413   {object}  model.ErrorResponse  "File too large"
+// @Failure    415   {object}  model.ErrorResponse  "Unsupported file type"
+// @Failure    501   {object}  model.ErrorResponse  "The AI provider cannot read files"
+// @Failure    402   {object}  model.ErrorResponse  "Monthly AI budget of the tenant used up"
+// @Failure    429   {object}  model.ErrorResponse  "Monthly AI budget of the platform used up"
+// @Router     /menu/extract [post]
+func (c *MenuController) ExtractMenus(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, service.MaxMenuDocumentBytes+multipartOverhead)
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrMenuDocumentTooLarge.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Field 'file' is required"})
+		return
+	}
+	if file.Size > service.MaxMenuDocumentBytes {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrMenuDocumentTooLarge.Error()})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	document, err := service.SniffMenuDocument(data)
+	if err != nil {
+		status := http.StatusUnsupportedMediaType
+		if errors.Is(err, service.ErrMenuDocumentTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	drafts, err := c.service.ExtractMenus(ctx.Request.Context(), middleware.Scope(ctx), document)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentsNotSupported) || errors.Is(err, service.ErrNotSupportedOffline) {
+			ctx.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		status, message := aiFailure(err, http.StatusInternalServerError, "Failed to read the menu")
+		ctx.JSON(status, gin.H{"error": message})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": drafts})
+}
+
+// ImportMenus godoc
+//
+// @Summary    Import menus in bulk
+// @Description  Create up to 200 menus at once, all of them or none, e.g. the drafts of /menu/extract once reviewed. Menus without a description get one generated in the background.
+// @Tags       menu
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      input  body      model.MenuImportRequest  true  "Menus"
+// @Success    201    {object}  model.MenuImportResponse
+// @Failure    400    {object}  model.ErrorResponse  "Validation Error"
+// @Failure    500    {object}  model.ErrorResponse  "Server Error"
+// @Router     /menu/import [post]
+func (c *MenuController) ImportMenus(ctx *gin.Context) {
+	var input model.MenuImportRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	menus, err := c.service.Import(middleware.Scope(ctx), input.Menus)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Menus imported successfully",
+		"data":    menus,
+	})
+}
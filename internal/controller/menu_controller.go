@@ -2,31 +2,45 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"atalariq/menu-api/internal/middleware"
 	"atalariq/menu-api/internal/model"
 	"atalariq/menu-api/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type MenuController struct {
-	service service.MenuService
+	service    service.MenuService
+	embeddings service.EmbeddingService
+	enrichment service.EnrichmentService // nil ignores enrich=true
 }
 
-func NewMenuController(service service.MenuService) *MenuController {
-	return &MenuController{service}
+func NewMenuController(service service.MenuService, embeddings service.EmbeddingService) *MenuController {
+	return &MenuController{service: service, embeddings: embeddings}
+}
+
+// UseEnrichment lets POST /menu?enrich=true fill the empty fields of the new menu
+func (c *MenuController) UseEnrichment(enrichment service.EnrichmentService) {
+	c.enrichment = enrichment
 }
 
 // Create godoc
 //
 // @Summary    Create a new menu
-// @Description  Create a new menu item with ingredients
+// @Description  Create a new menu item with ingredients. Without a description one is generated in the background (description_status "pending"), see /menu/{id}/description/job.
+// @Description  With enrich=true the category and calories left empty, the dietary tags and the allergens are filled from AI suggestions at least 0.6 sure, returned as "enrichment". The menu is created without them when the AI budget is used up.
 // @Tags     menu
 // @Accept     json
 // @Produce    json
-// @Param      menu  body    model.Menu          true  "Menu Request"
+// @Security   TenantAPIKey
+// @Param      menu    body    model.Menu          true  "Menu Request"
+// @Param      enrich  query   bool                false "Fill the empty fields from AI suggestions"
 // @Success    201   {object}  model.MenuSuccessResponse "Typed Response"
 // @Failure    400  {object}  model.ErrorResponse  "Validation Error"
 // @Failure    500  {object}  model.ErrorResponse  "Server Error"
@@ -37,8 +51,25 @@ func (c *MenuController) Create(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	enrich, _ := strconv.ParseBool(ctx.Query("enrich"))
+	scope := middleware.Scope(ctx)
+
+	if enrich && c.enrichment != nil {
+		caller := service.AICaller{TenantID: scope.TenantID, Endpoint: ctx.Request.Method + " " + ctx.FullPath()}
+		result, suggestion, err := c.enrichment.CreateMenu(service.WithAICaller(ctx.Request.Context(), caller), scope, input)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusCreated, gin.H{
+			"message":    "Menu created successfully",
+			"data":       result,
+			"enrichment": suggestion,
+		})
+		return
+	}
 
-	result, err := c.service.Create(input)
+	result, err := c.service.Create(ctx.Request.Context(), scope, input)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -55,11 +86,18 @@ func (c *MenuController) Create(ctx *gin.Context) {
 // @Description  Get menu list with filtering, sorting, and pagination
 // @Tags         menu
 // @Produce      json
+// @Security     TenantAPIKey
 // @Param        category   query     string  false  "Filter by category"
 // @Param        min_price  query     number  false  "Minimum price"
 // @Param        max_price  query     number  false  "Maximum price"
 // @Param        max_cal    query     int     false  "Maximum calories"
-// @Param        sort       query     string  false  "Sort (e.g., price:asc)"
+// @Param        hide_sold_out query  bool    false  "Exclude sold-out menus"
+// @Param        min_rating query     number  false  "Minimum average rating of the approved reviews, from 0 to 5"
+// @Param        tags       query     string  false  "Comma separated tag slugs (e.g., spicy,new)"
+// @Param        tags_mode  query     string  false  "Tag matching: 'or' (any tag, default) or 'and' (every tag)"
+// @Param        facets     query     bool    false  "Include facet counts (default true)"
+// @Param        lang       query     string  false  "Locale, overrides Accept-Language (e.g., id)"
+// @Param        sort       query     string  false  "Sort (e.g., price:asc, rating:desc)"
 // @Param        page       query     int     false  "Page number (default 1)"
 // @Param        per_page   query     int     false  "Items per page (default 10)"
 // @Success      200        {object}  model.MenuPaginationResponse
@@ -81,9 +119,15 @@ func (c *MenuController) GetList(ctx *gin.Context) {
 		Sort:     params.Sort,
 		Page:     params.Page,
 		PerPage:  params.PerPage,
+
+		AvailableOnly: params.HideSoldOut,
+		MinRating:     params.MinRating,
+		Tags:          splitTags(params.Tags),
+		TagsMode:      params.TagsMode,
+		WithFacets:    params.Facets == nil || *params.Facets,
 	}
 
-	result, err := c.service.GetList(filter)
+	result, err := c.service.GetList(middleware.Scope(ctx), filter)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -94,14 +138,23 @@ func (c *MenuController) GetList(ctx *gin.Context) {
 
 // Search godoc
 // @Summary      Search menus
-// @Description  Search menu by name or description (Full Text Search intent)
+// @Description  Search menu by name or description (Full Text Search intent). With mode=semantic the
+// @Description  menus closest in meaning to q come first, each with its similarity score.
 // @Tags         menu
 // @Produce      json
+// @Security     TenantAPIKey
 // @Param        q          query     string  false   "Search keyword"
+// @Param        mode       query     string  false  "'lexical' (default) or 'semantic'"
 // @Param        category   query     string  false  "Filter by category"
 // @Param        min_price  query     number  false  "Minimum price"
 // @Param        max_price  query     number  false  "Maximum price"
-// @Param        sort       query     string  false  "Sort (e.g., price:asc)"
+// @Param        hide_sold_out query  bool    false  "Exclude sold-out menus"
+// @Param        min_rating query     number  false  "Minimum average rating of the approved reviews, from 0 to 5"
+// @Param        tags       query     string  false  "Comma separated tag slugs (e.g., spicy,new)"
+// @Param        tags_mode  query     string  false  "Tag matching: 'or' (any tag, default) or 'and' (every tag)"
+// @Param        facets     query     bool    false  "Include facet counts (default true)"
+// @Param        lang       query     string  false  "Locale, overrides Accept-Language (e.g., id)"
+// @Param        sort       query     string  false  "Sort (e.g., price:asc, rating:desc)"
 // @Param        page       query     int     false  "Page number (default 1)"
 // @Param        per_page   query     int     false  "Items per page (default 10)"
 // @Success      200        {object}  model.MenuPaginationResponse
@@ -129,9 +182,21 @@ func (c *MenuController) Search(ctx *gin.Context) {
 		Sort:     params.Sort,
 		Page:     params.Page,
 		PerPage:  params.PerPage,
+
+		AvailableOnly: params.HideSoldOut,
+		MinRating:     params.MinRating,
+		Tags:          splitTags(params.Tags),
+		TagsMode:      params.TagsMode,
+		WithFacets:    params.Facets == nil || *params.Facets,
 	}
 
-	result, err := c.service.GetList(filter)
+	var result model.MenuPaginationResponse
+	var err error
+	if params.Mode == model.SearchModeSemantic {
+		result, err = c.embeddings.SemanticSearch(ctx.Request.Context(), middleware.Scope(ctx), filter)
+	} else {
+		result, err = c.service.GetList(middleware.Scope(ctx), filter)
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -140,13 +205,51 @@ func (c *MenuController) Search(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, result)
 }
 
+// Similar godoc
+//
+// @Summary      Similar menus
+// @Description  Menus closest in meaning to the given menu, best first, each with its similarity score
+// @Tags         menu
+// @Produce      json
+// @Security     TenantAPIKey
+// @Param        id     path      int     true   "Menu ID"
+// @Param        limit  query     int     false  "Number of menus (default 5, max 20)"
+// @Param        lang   query     string  false  "Locale, overrides Accept-Language (e.g., id)"
+// @Success      200    {object}  model.MenuListResponse
+// @Failure      400    {object}  model.ErrorResponse  "Invalid ID"
+// @Failure      404    {object}  model.ErrorResponse  "Menu Not Found"
+// @Failure      500    {object}  model.ErrorResponse
+// @Router       /menu/{id}/similar [get]
+func (c *MenuController) Similar(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+
+	menus, err := c.embeddings.Similar(ctx.Request.Context(), middleware.Scope(ctx), uint(id), limit)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Menu not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.MenuListResponse{Data: menus})
+}
+
 // GetByID godoc
 //
 // @Summary    Get menu detail
 // @Description  Get details of a specific menu item by ID
 // @Tags     menu
 // @Produce    json
+// @Security   TenantAPIKey
 // @Param      id  path    int             true  "Menu ID"
+// @Param      lang query  string          false "Locale, overrides Accept-Language (e.g., id)"
 // @Success    200 {object}  model.MenuDetailResponse  "Typed Response"
 // @Failure    400 {object}  model.ErrorResponse  "Invalid ID"
 // @Failure    404 {object}  model.ErrorResponse  "Menu Not Found"
@@ -158,7 +261,7 @@ func (c *MenuController) GetByID(ctx *gin.Context) {
 		return
 	}
 
-	menu, err := c.service.GetDetail(uint(id))
+	menu, err := c.service.GetDetail(middleware.Scope(ctx), uint(id))
 	if err != nil {
 		// Asumsi service return error jika not found
 		ctx.JSON(http.StatusNotFound, gin.H{"message": "Menu not found"})
@@ -171,12 +274,13 @@ func (c *MenuController) GetByID(ctx *gin.Context) {
 // Update godoc
 //
 // @Summary    Update menu
-// @Description  Update an existing menu item
+// @Description  Update an existing menu item. Availability, stock and daily_stock keep their values when omitted, null stops tracking stock.
 // @Tags     menu
 // @Accept     json
 // @Produce    json
+// @Security   TenantAPIKey
 // @Param      id    path      int             true  "Menu ID"
-// @Param      menu  body      model.Menu          true  "Update Data"
+// @Param      menu  body      model.UpdateMenuRequest          true  "Update Data"
 // @Success    200   {object}  model.MenuSuccessResponse "Typed Response"
 // @Failure    400   {object}  model.ErrorResponse  "Invalid ID"
 // @Failure    404   {object}  model.ErrorResponse  "Menu Not Found"
@@ -188,13 +292,13 @@ func (c *MenuController) Update(ctx *gin.Context) {
 		return
 	}
 
-	var input model.Menu
+	var input model.UpdateMenuRequest
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	updatedMenu, err := c.service.Update(uint(id), input)
+	updatedMenu, err := c.service.Update(middleware.Scope(ctx), uint(id), input)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"message": "Menu not found or update failed"})
 		return
@@ -212,6 +316,7 @@ func (c *MenuController) Update(ctx *gin.Context) {
 // @Description  Delete a menu item by ID
 // @Tags     menu
 // @Produce    json
+// @Security   TenantAPIKey
 // @Param      id  path    int true  "Menu ID"
 // @Success    200 {object}  model.GeneralResponse
 // @Failure    400   {object}  model.ErrorResponse  "Invalid ID"
@@ -224,7 +329,7 @@ func (c *MenuController) Delete(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.service.Delete(uint(id)); err != nil {
+	if err := c.service.Delete(middleware.Scope(ctx), uint(id)); err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"message": "Menu not found"})
 		return
 	}
@@ -238,6 +343,7 @@ func (c *MenuController) Delete(ctx *gin.Context) {
 // @Description  Get menu counts or lists grouped by category
 // @Tags     menu
 // @Produce    json
+// @Security   TenantAPIKey
 // @Param      mode      query   string  true  "Mode: 'count' or 'list'"
 // @Param      per_category  query   int   false "Limit item per category (default 5)"
 // @Success    200       {object}  map[string]any
@@ -256,7 +362,7 @@ func (c *MenuController) GroupByCategory(ctx *gin.Context) {
 		return
 	}
 
-	result, err := c.service.GetGrouped(mode, limitPerCategory)
+	result, err := c.service.GetGrouped(middleware.Scope(ctx), mode, limitPerCategory)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -265,17 +371,185 @@ func (c *MenuController) GroupByCategory(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"data": result})
 }
 
+// DecrementStock godoc
+//
+// @Summary    Decrement menu stock
+// @Description  Reduce stock of a menu, it is marked as sold out automatically when stock reaches zero
+// @Tags     stock
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id    path      int                           true  "Menu ID"
+// @Param      input body      model.StockAdjustmentRequest  true  "Quantity"
+// @Success    200   {object}  model.MenuDetailResponse
+// @Failure    400   {object}  model.ErrorResponse  "Invalid input"
+// @Failure    404   {object}  model.ErrorResponse  "Menu Not Found"
+// @Failure    409   {object}  model.ErrorResponse  "Stock not tracked or insufficient"
+// @Failure    500   {object}  model.ErrorResponse  "Server Error"
+// @Router     /menu/{id}/stock/decrement [post]
+func (c *MenuController) DecrementStock(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var input model.StockAdjustmentRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	menu, err := c.service.DecrementStock(middleware.Scope(ctx), uint(id), input.Quantity)
+	if err != nil {
+		respondStockError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": menu})
+}
+
+// Restock godoc
+//
+// @Summary    Restock menu
+// @Description  Add stock to a menu and mark it as available again
+// @Tags     stock
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id    path      int                           true  "Menu ID"
+// @Param      input body      model.StockAdjustmentRequest  true  "Quantity"
+// @Success    200   {object}  model.MenuDetailResponse
+// @Failure    400   {object}  model.ErrorResponse  "Invalid input"
+// @Failure    404   {object}  model.ErrorResponse  "Menu Not Found"
+// @Failure    500   {object}  model.ErrorResponse  "Server Error"
+// @Router     /menu/{id}/stock/restock [post]
+func (c *MenuController) Restock(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var input model.StockAdjustmentRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	menu, err := c.service.Restock(middleware.Scope(ctx), uint(id), input.Quantity)
+	if err != nil {
+		respondStockError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": menu})
+}
+
+// SetAvailability godoc
+//
+// @Summary    Set menu availability
+// @Description  Manually mark a menu as available or sold out
+// @Tags     stock
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id    path      int                        true  "Menu ID"
+// @Param      input body      model.AvailabilityRequest  true  "Availability"
+// @Success    200   {object}  model.MenuDetailResponse
+// @Failure    400   {object}  model.ErrorResponse  "Invalid input"
+// @Failure    404   {object}  model.ErrorResponse  "Menu Not Found"
+// @Failure    500   {object}  model.ErrorResponse  "Server Error"
+// @Router     /menu/{id}/availability [put]
+func (c *MenuController) SetAvailability(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var input model.AvailabilityRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	menu, err := c.service.SetAvailability(middleware.Scope(ctx), uint(id), input.Availability)
+	if err != nil {
+		respondStockError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": menu})
+}
+
+// splitTags parses `tags=spicy,new` into slugs
+func splitTags(raw string) []string {
+	var tags []string
+	for _, tag := range strings.Split(raw, ",") {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func respondStockError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrStockNotTracked), errors.Is(err, service.ErrInsufficientStock):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Menu not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// DescriptionJob godoc
+//
+// @Summary    Description generation status
+// @Description  Latest background job generating the description of a menu created without one. Failed attempts are retried with backoff, after the last one the menu keeps a placeholder (description_status "fallback") that is generated again by a periodic sweep.
+// @Tags       AI
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id   path      int  true  "Menu ID"
+// @Success    200  {object}  model.JobResponse
+// @Failure    404  {object}  model.ErrorResponse  "Menu or job not found"
+// @Router     /menu/{id}/description/job [get]
+func (c *MenuController) DescriptionJob(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	job, err := c.service.DescriptionJob(middleware.Scope(ctx), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "No description job for this menu"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": job})
+}
+
 // GenerateDescriptionAI godoc
 //
 // @Summary    Generate Menu Description
-// @Description  Use Gemini AI to create a marketing description based on name and ingredients
+// @Description  Use the configured AI provider to create a marketing description based on name and ingredients. The tone (casual, premium or playful, premium by default), maximum length in words (20 by default), language (a locale tag, English by default) and target audience shape the description. With "candidates" up to 5 alternatives are returned to pick from, the chosen one is saved with /menu/{id}/description/apply. When the AI provider is unavailable the candidates are written from templates and "source" is "fallback".
 // @Tags       AI
 // @Accept     json
 // @Produce    json
+// @Security   TenantAPIKey
 // @Param      input body      model.GenerateDescriptionRequest  true  "Input Data"
 // @Success    200   {object}  model.GenerateDescriptionResponse
-// @Failure    400   {object}  model.ErrorResponse  "Invalid input format"
-// @Failure    500   {object}  model.ErrorResponse  "AI service error"
+// @Failure    400   {object}  model.ErrorResponse  "Invalid input format or language"
+// @Failure    500   {object}  model.ErrorResponse  "Failed to generate the description"
+// @Failure    402   {object}  model.ErrorResponse  "Monthly AI budget of the tenant used up"
+// @Failure    429   {object}  model.ErrorResponse  "Monthly AI budget of the platform used up"
 // @Router     /menu/generate-description [post]
 func (c *MenuController) GenerateDescription(ctx *gin.Context) {
 	var input model.GenerateDescriptionRequest
@@ -284,28 +558,122 @@ func (c *MenuController) GenerateDescription(ctx *gin.Context) {
 		return
 	}
 
-	desc, err := c.service.GenerateDescription(input.Name, input.Ingredients)
+	generated, err := c.service.GenerateDescriptions(ctx.Request.Context(), input)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLocale) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		status, message := aiFailure(err, http.StatusInternalServerError, "Failed to generate the description")
+		ctx.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, generated)
+}
+
+// ApplyDescription godoc
+//
+// @Summary    Apply a generated description
+// @Description  Save the description picked among the candidates of /menu/generate-description, possibly edited, to a menu
+// @Tags       AI
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id     path      int                            true  "Menu ID"
+// @Param      input  body      model.ApplyDescriptionRequest  true  "Chosen description"
+// @Success    200    {object}  model.MenuSuccessResponse "Typed Response"
+// @Failure    400    {object}  model.ErrorResponse  "Invalid ID or input"
+// @Failure    404    {object}  model.ErrorResponse  "Menu Not Found"
+// @Router     /menu/{id}/description/apply [post]
+func (c *MenuController) ApplyDescription(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "AI Service Error: " + err.Error()})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var input model.ApplyDescriptionRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	menu, err := c.service.ApplyDescription(middleware.Scope(ctx), uint(id), strings.TrimSpace(input.Description))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"message": "Menu not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"generated_description": desc,
+		"message": "Description applied successfully",
+		"data":    menu,
+	})
+}
+
+// GenerateDescriptionStream godoc
+//
+// @Summary    Stream a Menu Description
+// @Description  Same as /menu/generate-description with a single candidate, streamed as Server-Sent Events: "token" events with {"text"} as the description is written, then a "done" event with the full description and the token usage. An "error" event ends the stream when the AI fails midway. The generation stops when the client disconnects.
+// @Tags       AI
+// @Accept     json
+// @Produce    text/event-stream
+// @Security   TenantAPIKey
+// @Param      input body      model.GenerateDescriptionRequest  true  "Input Data"
+// @Success    200   {object}  model.DescriptionStreamDone  "Payload of the done event"
+// @Failure    400   {object}  model.ErrorResponse  "Invalid input format or language"
+// @Failure    500   {object}  model.ErrorResponse  "AI service error before the first token"
+// @Failure    503   {object}  model.ErrorResponse  "AI provider unavailable, the circuit breaker is open"
+// @Failure    402   {object}  model.ErrorResponse  "Monthly AI budget of the tenant used up"
+// @Failure    429   {object}  model.ErrorResponse  "Monthly AI budget of the platform used up"
+// @Router     /menu/generate-description/stream [post]
+func (c *MenuController) GenerateDescriptionStream(ctx *gin.Context) {
+	var input model.GenerateDescriptionRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+		return
+	}
+
+	stream := &eventStream{ctx: ctx}
+	var description strings.Builder
+	usage, err := c.service.GenerateDescriptionStream(ctx.Request.Context(), input, func(token string) error {
+		description.WriteString(token)
+		return stream.send(model.StreamEventToken, model.StreamToken{Text: token})
+	})
+	if errors.Is(err, service.ErrInvalidLocale) {
+		stream.fail(http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		stream.fail(aiFailure(err, http.StatusInternalServerError, "Failed to generate the description"))
+		return
+	}
+
+	stream.send(model.StreamEventDone, model.DescriptionStreamDone{
+		Description: strings.Trim(strings.TrimSpace(description.String()), "\""),
+		Usage:       usage,
 	})
 }
 
 // GetRecommendations godoc
 //
 // @Summary      Get Menu Recommendations
-// @Description  Get menu recommendations based on user preference using Gemini AI
+// @Description  Get up to 3 menu recommendations with a confidence score, based on user preference using the configured AI provider. When the AI gives no usable answer, menus are ranked by keywords instead (source "fallback").
 // @Tags       AI
 // @Accept     json
 // @Produce    json
+// @Security   TenantAPIKey
 // @Param      request body    model.RecommendationRequest     true  "User Preference"
 // @Success    200   {object}  model.RecommendationListResponse  "Typed Response"
-// @Failure    400  {object}  model.ErrorResponse
-// @Failure    502  {object}  model.ErrorResponse  "AI service unavailable"
+// @Failure    400  {object}  model.ErrorResponse  "Invalid input or preference longer than 500 characters"
+// @Failure    404  {object}  model.ErrorResponse  "No available menus"
+// @Failure    500  {object}  model.ErrorResponse  "Failed to get recommendations"
+// @Failure    402  {object}  model.ErrorResponse  "Monthly AI budget of the tenant used up"
+// @Failure    429  {object}  model.ErrorResponse  "Monthly AI budget of the platform used up"
 // @Router     /menu/recommendations [post]
 func (c *MenuController) GetRecommendations(ctx *gin.Context) {
 	var request model.RecommendationRequest
@@ -314,9 +682,18 @@ func (c *MenuController) GetRecommendations(ctx *gin.Context) {
 		return
 	}
 
-	recommendations, err := c.service.GetRecommendations(request)
+	recommendations, err := c.service.GetRecommendations(ctx.Request.Context(), middleware.Scope(ctx), request)
 	if err != nil {
-		ctx.JSON(http.StatusBadGateway, gin.H{"error": "AI Service unavailable: " + err.Error()})
+		if errors.Is(err, service.ErrPreferenceTooLong) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrNoMenusAvailable) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		status, message := aiFailure(err, http.StatusInternalServerError, "Failed to get recommendations")
+		ctx.JSON(status, gin.H{"error": message})
 		return
 	}
 
@@ -324,3 +701,48 @@ func (c *MenuController) GetRecommendations(ctx *gin.Context) {
 		"recommendations": recommendations,
 	})
 }
+
+// GetRecommendationsStream godoc
+//
+// @Summary      Stream Menu Recommendations
+// @Description  Same as /menu/recommendations, streamed as Server-Sent Events: a "recommendation" event for each menu as soon as the AI answer names it, in the order given by the AI, then a "done" event with the count and the token usage. The fallback ranking is streamed when the AI gives nothing usable. The AI call stops when the client disconnects.
+// @Tags       AI
+// @Accept     json
+// @Produce    text/event-stream
+// @Security   TenantAPIKey
+// @Param      request body    model.RecommendationRequest     true  "User Preference"
+// @Success    200   {object}  model.RecommendationStreamDone  "Payload of the done event"
+// @Failure    400  {object}  model.ErrorResponse  "Invalid input or preference longer than 500 characters"
+// @Failure    404  {object}  model.ErrorResponse  "No available menus"
+// @Failure    500  {object}  model.ErrorResponse  "Failed to get recommendations"
+// @Failure    402  {object}  model.ErrorResponse  "Monthly AI budget of the tenant used up"
+// @Failure    429  {object}  model.ErrorResponse  "Monthly AI budget of the platform used up"
+// @Router     /menu/recommendations/stream [post]
+func (c *MenuController) GetRecommendationsStream(ctx *gin.Context) {
+	var request model.RecommendationRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stream := &eventStream{ctx: ctx}
+	count := 0
+	usage, err := c.service.GetRecommendationsStream(ctx.Request.Context(), middleware.Scope(ctx), request, func(recommendation model.RecommendationResponse) error {
+		count++
+		return stream.send(model.StreamEventRecommendation, recommendation)
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrPreferenceTooLong) {
+			stream.fail(http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrNoMenusAvailable) {
+			stream.fail(http.StatusNotFound, err.Error())
+			return
+		}
+		stream.fail(aiFailure(err, http.StatusInternalServerError, "Failed to get recommendations"))
+		return
+	}
+
+	stream.send(model.StreamEventDone, model.RecommendationStreamDone{Count: count, Usage: usage})
+}
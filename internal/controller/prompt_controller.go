@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PromptController manages the prompt templates sent to the AI provider
+type PromptController struct {
+	store service.PromptStore
+}
+
+func NewPromptController(store service.PromptStore) *PromptController {
+	return &PromptController{store}
+}
+
+func respondPromptError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUnknownPrompt):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Prompt version not found"})
+	case errors.Is(err, service.ErrInvalidPrompt):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPromptReadOnly):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// ListPrompts godoc
+//
+// @Summary    List prompt templates
+// @Description  Named prompt templates with their active and latest version. Version 0 is the template file shipped with the server or read from PROMPT_TEMPLATE_DIR.
+// @Tags     admin
+// @Produce    json
+// @Security   AdminKey
+// @Success    200   {object}  model.PromptTemplateListResponse
+// @Failure    401   {object}  model.ErrorResponse  "Admin key required"
+// @Router     /admin/prompts [get]
+func (c *PromptController) ListPrompts(ctx *gin.Context) {
+	templates, err := c.store.Templates()
+	if err != nil {
+		respondPromptError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": templates})
+}
+
+// ListVersions godoc
+//
+// @Summary    List prompt template versions
+// @Tags     admin
+// @Produce    json
+// @Security   AdminKey
+// @Param      name  path      string  true  "Template name"  Enums(description, recommendation, translation)
+// @Success    200   {object}  model.PromptVersionListResponse
+// @Failure    401   {object}  model.ErrorResponse  "Admin key required"
+// @Failure    404   {object}  model.ErrorResponse  "Unknown template"
+// @Router     /admin/prompts/{name}/versions [get]
+func (c *PromptController) ListVersions(ctx *gin.Context) {
+	versions, err := c.store.Versions(ctx.Param("name"))
+	if err != nil {
+		respondPromptError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": versions})
+}
+
+// CreateVersion godoc
+//
+// @Summary    Create a prompt template version
+// @Description  Store a new text/template version, inactive until activated. The template must render the sample data of its name, see the preview endpoint.
+// @Tags     admin
+// @Accept     json
+// @Produce    json
+// @Security   AdminKey
+// @Param      name   path      string                            true  "Template name"  Enums(description, recommendation, translation)
+// @Param      input  body      model.CreatePromptVersionRequest  true  "Template"
+// @Success    201    {object}  model.PromptTemplateResponse
+// @Failure    400    {object}  model.ErrorResponse  "Invalid template"
+// @Failure    401    {object}  model.ErrorResponse  "Admin key required"
+// @Failure    404    {object}  model.ErrorResponse  "Unknown template"
+// @Router     /admin/prompts/{name}/versions [post]
+func (c *PromptController) CreateVersion(ctx *gin.Context) {
+	var input model.CreatePromptVersionRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := c.store.CreateVersion(ctx.Param("name"), input.Body, input.Note)
+	if err != nil {
+		respondPromptError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": template})
+}
+
+// ActivateVersion godoc
+//
+// @Summary    Activate a prompt template version
+// @Description  Send the AI this version from now on, version 0 goes back to the template file. Other instances pick it up within 30 seconds.
+// @Tags     admin
+// @Produce    json
+// @Security   AdminKey
+// @Param      name     path      string  true  "Template name"  Enums(description, recommendation, translation)
+// @Param      version  path      int     true  "Version"
+// @Success    200      {object}  model.PromptTemplateResponse
+// @Failure    400      {object}  model.ErrorResponse  "Invalid version"
+// @Failure    401      {object}  model.ErrorResponse  "Admin key required"
+// @Failure    404      {object}  model.ErrorResponse  "Unknown template or version"
+// @Router     /admin/prompts/{name}/versions/{version}/activate [post]
+func (c *PromptController) ActivateVersion(ctx *gin.Context) {
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil || version < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return
+	}
+
+	template, err := c.store.Activate(ctx.Param("name"), version)
+	if err != nil {
+		respondPromptError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": template})
+}
+
+// Preview godoc
+//
+// @Summary    Preview a prompt
+// @Description  Render a template against a sample menu without calling the AI: the body given, else the version given, else the active version. Empty menu fields use a built-in sample.
+// @Tags     admin
+// @Accept     json
+// @Produce    json
+// @Security   AdminKey
+// @Param      name   path      string                      true  "Template name"  Enums(description, recommendation, translation)
+// @Param      input  body      model.PromptPreviewRequest  true  "Preview"
+// @Success    200    {object}  model.PromptPreviewResponse
+// @Failure    400    {object}  model.ErrorResponse  "Invalid template"
+// @Failure    401    {object}  model.ErrorResponse  "Admin key required"
+// @Failure    404    {object}  model.ErrorResponse  "Unknown template or version"
+// @Router     /admin/prompts/{name}/preview [post]
+func (c *PromptController) Preview(ctx *gin.Context) {
+	var input model.PromptPreviewRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preview, err := c.store.Preview(ctx.Param("name"), input)
+	if err != nil {
+		respondPromptError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": preview})
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecommendationController struct {
+	service service.RecommendationSessionService
+}
+
+func NewRecommendationController(service service.RecommendationSessionService) *RecommendationController {
+	return &RecommendationController{service}
+}
+
+// StartSession godoc
+//
+// @Summary      Start a recommendation session
+// @Description  Start a conversation where every message refines the previous recommendations (e.g., "something cheaper", "no, without dairy"). The first message is optional. Sessions expire after a period without messages.
+// @Tags         AI
+// @Accept       json
+// @Produce      json
+// @Security     TenantAPIKey
+// @Param        request body      model.StartRecommendationSessionRequest  false  "First message"
+// @Success      201     {object}  model.RecommendationSessionResponse
+// @Failure      400     {object}  model.ErrorResponse
+// @Failure      404     {object}  model.ErrorResponse  "No available menus"
+// @Failure      500     {object}  model.ErrorResponse  "Failed to get recommendations"
+// @Failure      402     {object}  model.ErrorResponse  "Monthly AI budget of the tenant used up"
+// @Failure      429     {object}  model.ErrorResponse  "Monthly AI budget of the platform used up"
+// @Router       /menu/recommendations/sessions [post]
+func (c *RecommendationController) StartSession(ctx *gin.Context) {
+	var request model.StartRecommendationSessionRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	session, err := c.service.Start(ctx.Request.Context(), middleware.Scope(ctx), request.Message)
+	if err != nil {
+		respondSessionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, session)
+}
+
+// SendMessage godoc
+//
+// @Summary      Send a message to a recommendation session
+// @Description  Recommend menus for the message, knowing the earlier messages of the session and what was suggested for them
+// @Tags         AI
+// @Accept       json
+// @Produce      json
+// @Security     TenantAPIKey
+// @Param        session_id  path      string                              true  "Session ID"
+// @Param        request     body      model.RecommendationMessageRequest  true  "Message"
+// @Success      200         {object}  model.RecommendationSessionResponse
+// @Failure      400         {object}  model.ErrorResponse
+// @Failure      404         {object}  model.ErrorResponse  "Session not found or expired, or no available menus"
+// @Failure      500         {object}  model.ErrorResponse  "Failed to get recommendations"
+// @Failure      402         {object}  model.ErrorResponse  "Monthly AI budget of the tenant used up"
+// @Failure      429         {object}  model.ErrorResponse  "Monthly AI budget of the platform used up"
+// @Router       /menu/recommendations/sessions/{session_id}/messages [post]
+func (c *RecommendationController) SendMessage(ctx *gin.Context) {
+	var request model.RecommendationMessageRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := c.service.Send(ctx.Request.Context(), middleware.Scope(ctx), ctx.Param("session_id"), request.Message)
+	if err != nil {
+		respondSessionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, session)
+}
+
+// GetSession godoc
+//
+// @Summary      Get a recommendation session
+// @Description  The messages of the session and the menus suggested for each
+// @Tags         AI
+// @Produce      json
+// @Security     TenantAPIKey
+// @Param        session_id  path      string  true  "Session ID"
+// @Success      200         {object}  model.RecommendationSessionResponse
+// @Failure      404         {object}  model.ErrorResponse  "Session not found or expired"
+// @Router       /menu/recommendations/sessions/{session_id} [get]
+func (c *RecommendationController) GetSession(ctx *gin.Context) {
+	session, err := c.service.Get(middleware.Scope(ctx), ctx.Param("session_id"))
+	if err != nil {
+		respondSessionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, session)
+}
+
+func respondSessionError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPreferenceTooLong):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSessionNotFound), errors.Is(err, service.ErrNoMenusAvailable):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		status, message := aiFailure(err, http.StatusInternalServerError, "Failed to get recommendations")
+		ctx.JSON(status, gin.H{"error": message})
+	}
+}
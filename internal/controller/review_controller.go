@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReviewController struct {
+	service service.ReviewService
+}
+
+func NewReviewController(service service.ReviewService) *ReviewController {
+	return &ReviewController{service}
+}
+
+func respondReviewError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Menu or review not found"})
+	case errors.Is(err, service.ErrNoReviewsToSummarize):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		status, message := aiFailure(err, http.StatusInternalServerError, message)
+		ctx.JSON(status, gin.H{"error": message})
+	}
+}
+
+// reviewStatus reads the status query, defaulting to fallback
+func reviewStatus(ctx *gin.Context, fallback string) (string, bool) {
+	status := ctx.DefaultQuery("status", fallback)
+	switch status {
+	case model.ReviewStatusPending, model.ReviewStatusApproved, model.ReviewStatusRejected:
+		return status, true
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+	return "", false
+}
+
+// CreateReview godoc
+//
+// @Summary    Review a menu
+// @Description  Store the rating from 1 to 5 and the review of a diner. The review is pending until it is approved, only approved reviews are listed by default and count in the rating of the menu.
+// @Tags       review
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id    path      int                  true  "Menu ID"
+// @Param      input body      model.ReviewRequest  true  "Review"
+// @Success    201   {object}  model.ReviewResponse
+// @Failure    400   {object}  model.ErrorResponse  "Validation Error"
+// @Failure    404   {object}  model.ErrorResponse  "Menu not found"
+// @Router     /menu/{id}/reviews [post]
+func (c *ReviewController) CreateReview(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	var input model.ReviewRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := c.service.CreateReview(middleware.Scope(ctx), uint(id), input)
+	if err != nil {
+		respondReviewError(ctx, err, "Failed to save the review")
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"data": review})
+}
+
+// ListMenuReviews godoc
+//
+// @Summary    List the reviews of a menu
+// @Description  Reviews of the menu, newest first, the approved ones by default
+// @Tags       review
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id      path      int     true   "Menu ID"
+// @Param      status  query     string  false  "approved (default), pending or rejected"
+// @Success    200     {object}  model.ReviewListResponse
+// @Failure    400     {object}  model.ErrorResponse  "Invalid ID or status"
+// @Failure    404     {object}  model.ErrorResponse  "Menu not found"
+// @Router     /menu/{id}/reviews [get]
+func (c *ReviewController) ListMenuReviews(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	status, ok := reviewStatus(ctx, model.ReviewStatusApproved)
+	if !ok {
+		return
+	}
+
+	reviews, err := c.service.ListReviews(middleware.Scope(ctx), uint(id), status)
+	if err != nil {
+		respondReviewError(ctx, err, "Failed to list the reviews")
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": reviews})
+}
+
+// ListReviews godoc
+//
+// @Summary    List reviews to moderate
+// @Description  Reviews of every menu of the tenant, newest first, the pending ones by default
+// @Tags       review
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      status  query     string  false  "pending (default), approved or rejected"
+// @Success    200     {object}  model.ReviewListResponse
+// @Failure    400     {object}  model.ErrorResponse  "Invalid status"
+// @Router     /menu/reviews [get]
+func (c *ReviewController) ListReviews(ctx *gin.Context) {
+	status, ok := reviewStatus(ctx, model.ReviewStatusPending)
+	if !ok {
+		return
+	}
+
+	reviews, err := c.service.ListReviews(middleware.Scope(ctx), 0, status)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": reviews})
+}
+
+// ModerateReview godoc
+//
+// @Summary    Moderate a review
+// @Description  Approve or reject a review, the rating of its menu is updated at once. A moderated review can be moderated again.
+// @Tags       review
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      review_id  path      int                          true  "Review ID"
+// @Param      input      body      model.ModerateReviewRequest  true  "New status"
+// @Success    200        {object}  model.ReviewResponse
+// @Failure    400        {object}  model.ErrorResponse  "Validation Error"
+// @Failure    404        {object}  model.ErrorResponse  "Review not found"
+// @Router     /menu/reviews/{review_id}/moderate [post]
+func (c *ReviewController) ModerateReview(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("review_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	var input model.ModerateReviewRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := c.service.ModerateReview(middleware.Scope(ctx), uint(id), input.Status)
+	if err != nil {
+		respondReviewError(ctx, err, "Failed to moderate the review")
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": review})
+}
+
+// SummarizeReviews godoc
+//
+// @Summary    Summarize the reviews of a menu
+// @Description  Sum up the pros and cons of the newest 30 approved reviews of the menu with the AI. The summary is stored on the menu as review_summary, until the next one replaces it.
+// @Tags       AI
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id    path      int  true  "Menu ID"
+// @Success    200   {object}  model.ReviewSummaryResponse
+// @Failure    400   {object}  model.ErrorResponse  "Invalid ID format"
+// @Failure    404   {object}  model.ErrorResponse  "Menu not found"
+// @Failure    409   {object}  model.ErrorResponse  "No approved reviews"
+// @Failure    402   {object}  model.ErrorResponse  "Monthly AI budget of the tenant used up"
+// @Failure    429   {object}  model.ErrorResponse  "Monthly AI budget of the platform used up"
+// @Failure    503   {object}  model.ErrorResponse  "AI provider unavailable"
+// @Router     /menu/{id}/reviews/summary [post]
+func (c *ReviewController) SummarizeReviews(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	summary, err := c.service.SummarizeReviews(ctx.Request.Context(), middleware.Scope(ctx), uint(id))
+	if err != nil {
+		respondReviewError(ctx, err, "Failed to summarize the reviews")
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": summary})
+}
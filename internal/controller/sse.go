@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"net/http"
+
+	"atalariq/menu-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventStream writes Server-Sent Events. The headers are only sent with the first
+// event, so a request that fails before it is still answered with a status code.
+type eventStream struct {
+	ctx     *gin.Context
+	started bool
+}
+
+// send writes and flushes one event, it fails once the client has disconnected
+func (s *eventStream) send(event string, data any) error {
+	if err := s.ctx.Request.Context().Err(); err != nil {
+		return err
+	}
+	if !s.started {
+		header := s.ctx.Writer.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no") // nginx would otherwise buffer the whole stream
+		s.ctx.Status(http.StatusOK)
+		s.started = true
+	}
+	s.ctx.SSEvent(event, data)
+	s.ctx.Writer.Flush()
+	return nil
+}
+
+// fail answers with status and the error, as an error event when the stream has started.
+// Nothing is written when the client is gone.
+func (s *eventStream) fail(status int, message string) {
+	if s.ctx.Request.Context().Err() != nil {
+		return
+	}
+	if !s.started {
+		s.ctx.JSON(status, gin.H{"error": message})
+		return
+	}
+	s.send(model.StreamEventError, model.ErrorResponse{Error: message})
+}
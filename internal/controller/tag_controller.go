@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TagController struct {
+	service service.TagService
+}
+
+func NewTagController(service service.TagService) *TagController {
+	return &TagController{service}
+}
+
+// CreateTag godoc
+//
+// @Summary    Create a tag
+// @Description  Create a label such as "spicy" or "chef's pick", the slug is derived from the name when omitted
+// @Tags     tag
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      input body      model.TagRequest  true  "Tag"
+// @Success    201   {object}  map[string]any
+// @Failure    400   {object}  model.ErrorResponse  "Validation Error"
+// @Failure    409   {object}  model.ErrorResponse  "Slug already used"
+// @Router     /tags [post]
+func (c *TagController) CreateTag(ctx *gin.Context) {
+	var input model.TagRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := c.service.CreateTag(middleware.Scope(ctx).TenantID, input)
+	if err != nil {
+		respondTagError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": tag})
+}
+
+// ListTags godoc
+//
+// @Summary    List tags
+// @Description  List the tags of the tenant with the number of menus using each
+// @Tags     tag
+// @Produce    json
+// @Security   TenantAPIKey
+// @Success    200   {object}  map[string]any
+// @Router     /tags [get]
+func (c *TagController) ListTags(ctx *gin.Context) {
+	tags, err := c.service.ListTags(middleware.Scope(ctx).TenantID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// UpdateTag godoc
+//
+// @Summary    Rename a tag
+// @Tags     tag
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id    path      int               true  "Tag ID"
+// @Param      input body      model.TagRequest  true  "Tag"
+// @Success    200   {object}  map[string]any
+// @Failure    400   {object}  model.ErrorResponse  "Validation Error"
+// @Failure    404   {object}  model.ErrorResponse  "Tag Not Found"
+// @Failure    409   {object}  model.ErrorResponse  "Slug already used"
+// @Router     /tags/{id} [put]
+func (c *TagController) UpdateTag(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var input model.TagRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := c.service.UpdateTag(middleware.Scope(ctx).TenantID, uint(id), input)
+	if err != nil {
+		respondTagError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": tag})
+}
+
+// DeleteTag godoc
+//
+// @Summary    Delete a tag
+// @Description  Delete a tag and remove it from every menu
+// @Tags     tag
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id  path      int  true  "Tag ID"
+// @Success    200 {object}  model.GeneralResponse
+// @Failure    404 {object}  model.ErrorResponse  "Tag Not Found"
+// @Router     /tags/{id} [delete]
+func (c *TagController) DeleteTag(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := c.service.DeleteTag(middleware.Scope(ctx).TenantID, uint(id)); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Tag not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
+
+// SetMenuTags godoc
+//
+// @Summary    Set menu tags
+// @Description  Replace the tags of a menu with the given slugs, an empty list removes every tag
+// @Tags     tag
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id    path      int                    true  "Menu ID"
+// @Param      input body      model.MenuTagsRequest  true  "Tag slugs"
+// @Success    200   {object}  model.MenuDetailResponse
+// @Failure    400   {object}  model.ErrorResponse  "Unknown tag"
+// @Failure    404   {object}  model.ErrorResponse  "Menu Not Found"
+// @Router     /menu/{id}/tags [put]
+func (c *TagController) SetMenuTags(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var input model.MenuTagsRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	menu, err := c.service.SetMenuTags(middleware.Scope(ctx), uint(id), input.Tags)
+	if err != nil {
+		respondTagError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": menu})
+}
+
+func respondTagError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSlug), errors.Is(err, service.ErrUnknownTag):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTagExists):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
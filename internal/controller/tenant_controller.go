@@ -0,0 +1,253 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantController struct {
+	service service.TenantService
+}
+
+func NewTenantController(service service.TenantService) *TenantController {
+	return &TenantController{service}
+}
+
+// CreateTenant godoc
+//
+// @Summary    Create a tenant
+// @Description  Register a restaurant, the generated API key is only returned once
+// @Tags     admin
+// @Accept     json
+// @Produce    json
+// @Security   AdminKey
+// @Param      input body      model.CreateTenantRequest  true  "Tenant"
+// @Success    201   {object}  model.TenantCreatedResponse
+// @Failure    400   {object}  model.ErrorResponse  "Validation Error"
+// @Failure    401   {object}  model.ErrorResponse  "Admin key required"
+// @Router     /admin/tenants [post]
+func (c *TenantController) CreateTenant(ctx *gin.Context) {
+	var input model.CreateTenantRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenant, apiKey, err := c.service.CreateTenant(input)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSlug) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"data":    tenant,
+		"api_key": apiKey,
+	})
+}
+
+// ListTenants godoc
+//
+// @Summary    List tenants
+// @Tags     admin
+// @Produce    json
+// @Security   AdminKey
+// @Success    200   {object}  map[string]any
+// @Failure    401   {object}  model.ErrorResponse  "Admin key required"
+// @Router     /admin/tenants [get]
+func (c *TenantController) ListTenants(ctx *gin.Context) {
+	tenants, err := c.service.ListTenants()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": tenants})
+}
+
+// RotateAPIKey godoc
+//
+// @Summary    Rotate tenant API key
+// @Description  Generate a new API key, the previous one stops working immediately
+// @Tags     admin
+// @Produce    json
+// @Security   AdminKey
+// @Param      id  path      int  true  "Tenant ID"
+// @Success    200 {object}  map[string]string
+// @Failure    404 {object}  model.ErrorResponse  "Tenant Not Found"
+// @Router     /admin/tenants/{id}/api-key [post]
+func (c *TenantController) RotateAPIKey(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	apiKey, err := c.service.RotateAPIKey(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Tenant not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"api_key": apiKey})
+}
+
+// CreateBranch godoc
+//
+// @Summary    Create a branch
+// @Description  Add an outlet to the current tenant
+// @Tags     branch
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      input body      model.CreateBranchRequest  true  "Branch"
+// @Success    201   {object}  map[string]any
+// @Failure    400   {object}  model.ErrorResponse  "Validation Error"
+// @Router     /branches [post]
+func (c *TenantController) CreateBranch(ctx *gin.Context) {
+	var input model.CreateBranchRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	branch, err := c.service.CreateBranch(middleware.Scope(ctx).TenantID, input)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": branch})
+}
+
+// ListBranches godoc
+//
+// @Summary    List branches
+// @Tags     branch
+// @Produce    json
+// @Security   TenantAPIKey
+// @Success    200   {object}  map[string]any
+// @Router     /branches [get]
+func (c *TenantController) ListBranches(ctx *gin.Context) {
+	branches, err := c.service.ListBranches(middleware.Scope(ctx).TenantID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": branches})
+}
+
+// ListOverrides godoc
+//
+// @Summary    List branch overrides
+// @Tags     branch
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      branch_id  path      int  true  "Branch ID"
+// @Success    200        {object}  map[string]any
+// @Failure    404        {object}  model.ErrorResponse  "Branch Not Found"
+// @Router     /branches/{branch_id}/overrides [get]
+func (c *TenantController) ListOverrides(ctx *gin.Context) {
+	branchID, err := strconv.Atoi(ctx.Param("branch_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	overrides, err := c.service.ListOverrides(middleware.Scope(ctx).TenantID, uint(branchID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Branch not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": overrides})
+}
+
+// SetOverride godoc
+//
+// @Summary    Override menu for a branch
+// @Description  Set branch specific price and/or availability on top of the base menu
+// @Tags     branch
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      branch_id  path      int                          true  "Branch ID"
+// @Param      menu_id    path      int                          true  "Menu ID"
+// @Param      input      body      model.BranchOverrideRequest  true  "Override"
+// @Success    200        {object}  map[string]any
+// @Failure    400        {object}  model.ErrorResponse  "Validation Error"
+// @Failure    404        {object}  model.ErrorResponse  "Branch or Menu Not Found"
+// @Router     /branches/{branch_id}/overrides/{menu_id} [put]
+func (c *TenantController) SetOverride(ctx *gin.Context) {
+	branchID, err := strconv.Atoi(ctx.Param("branch_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	menuID, err := strconv.Atoi(ctx.Param("menu_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var input model.BranchOverrideRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	override, err := c.service.SetOverride(middleware.Scope(ctx).TenantID, uint(branchID), uint(menuID), input)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyOverride) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Branch or menu not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": override})
+}
+
+// DeleteOverride godoc
+//
+// @Summary    Remove branch override
+// @Description  The branch falls back to the base menu price and availability
+// @Tags     branch
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      branch_id  path      int  true  "Branch ID"
+// @Param      menu_id    path      int  true  "Menu ID"
+// @Success    200        {object}  model.GeneralResponse
+// @Failure    404        {object}  model.ErrorResponse  "Override Not Found"
+// @Router     /branches/{branch_id}/overrides/{menu_id} [delete]
+func (c *TenantController) DeleteOverride(ctx *gin.Context) {
+	branchID, err := strconv.Atoi(ctx.Param("branch_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	menuID, err := strconv.Atoi(ctx.Param("menu_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := c.service.DeleteOverride(middleware.Scope(ctx).TenantID, uint(branchID), uint(menuID)); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Override not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Override deleted successfully"})
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListTranslations godoc
+//
+// @Summary    List menu translations
+// @Tags     translation
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id  path      int  true  "Menu ID"
+// @Success    200 {object}  map[string]any
+// @Failure    404 {object}  model.ErrorResponse  "Menu Not Found"
+// @Router     /menu/{id}/translations [get]
+func (c *MenuController) ListTranslations(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	translations, err := c.service.ListTranslations(middleware.Scope(ctx), uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Menu not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": translations})
+}
+
+// SaveTranslation godoc
+//
+// @Summary    Save menu translation
+// @Description  Create or replace the name and description of a menu for a locale
+// @Tags     translation
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id      path      int                       true  "Menu ID"
+// @Param      locale  path      string                    true  "Locale (e.g., id)"
+// @Param      input   body      model.TranslationRequest  true  "Translation"
+// @Success    200     {object}  map[string]any
+// @Failure    400     {object}  model.ErrorResponse  "Invalid input or locale"
+// @Failure    404     {object}  model.ErrorResponse  "Menu Not Found"
+// @Router     /menu/{id}/translations/{locale} [put]
+func (c *MenuController) SaveTranslation(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var input model.TranslationRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	translation, err := c.service.SaveTranslation(middleware.Scope(ctx), uint(id), ctx.Param("locale"), input)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLocale) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Menu not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": translation})
+}
+
+// DeleteTranslation godoc
+//
+// @Summary    Delete menu translation
+// @Tags     translation
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      id      path      int     true  "Menu ID"
+// @Param      locale  path      string  true  "Locale (e.g., id)"
+// @Success    200     {object}  model.GeneralResponse
+// @Failure    404     {object}  model.ErrorResponse  "Translation Not Found"
+// @Router     /menu/{id}/translations/{locale} [delete]
+func (c *MenuController) DeleteTranslation(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := c.service.DeleteTranslation(middleware.Scope(ctx), uint(id), ctx.Param("locale")); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "Translation not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Translation deleted successfully"})
+}
+
+// GenerateTranslations godoc
+//
+// @Summary    Translate missing locales with AI
+// @Description  Ask the AI service to translate every menu without a translation for the given locales
+// @Tags       AI
+// @Accept     json
+// @Produce    json
+// @Security   TenantAPIKey
+// @Param      input body      model.GenerateTranslationsRequest  true  "Locales"
+// @Success    200   {object}  map[string]any
+// @Failure    400   {object}  model.ErrorResponse  "Invalid input or locale"
+// @Failure    500   {object}  model.ErrorResponse  "Server Error"
+// @Failure    402   {object}  model.ErrorResponse  "Monthly AI budget of the tenant used up"
+// @Failure    429   {object}  model.ErrorResponse  "Monthly AI budget of the platform used up"
+// @Router     /menu/translations/generate [post]
+func (c *MenuController) GenerateTranslations(ctx *gin.Context) {
+	var input model.GenerateTranslationsRequest
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := c.service.GenerateTranslations(ctx.Request.Context(), middleware.Scope(ctx), input)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLocale) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": results})
+}
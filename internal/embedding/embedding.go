@@ -0,0 +1,125 @@
+// Package embedding turns menu text into vectors and finds the nearest ones
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"atalariq/menu-api/internal/config"
+)
+
+var ErrNotFound = errors.New("embedding: vector not found")
+
+// Embedder computes unit-length vectors, one per text
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Model identifies the vector space, vectors of different models are never compared
+	Model() string
+	Close() error
+}
+
+// Vector is the embedding of one menu
+type Vector struct {
+	TenantID uint
+	MenuID   uint
+	Model    string
+	Checksum string // of the model and the embedded text, tells when the vector is stale
+	Values   []float32
+}
+
+// Query finds the vectors of a tenant closest to Values
+type Query struct {
+	TenantID uint
+	Model    string
+	Values   []float32
+	Limit    int
+	Exclude  uint // menu left out of the results, e.g. the one similar menus are found for
+}
+
+// Match is a menu and its cosine similarity to the query
+type Match struct {
+	MenuID uint
+	Score  float64
+}
+
+// Store keeps one vector per menu
+type Store interface {
+	Upsert(ctx context.Context, vector Vector) error
+	Delete(ctx context.Context, menuID uint) error
+	Get(ctx context.Context, tenantID, menuID uint) (Vector, error)
+	Checksums(ctx context.Context, menuIDs []uint) (map[uint]string, error)
+	Search(ctx context.Context, query Query) ([]Match, error)
+}
+
+// ProviderFactory builds an Embedder from the embedding config
+type ProviderFactory func(cfg config.Embedding) (Embedder, error)
+
+// providers maps EMBEDDING_PROVIDER values to their implementation
+var providers = map[string]ProviderFactory{
+	"hashing": func(cfg config.Embedding) (Embedder, error) {
+		return NewHashingEmbedder(cfg.Dimensions), nil
+	},
+	"openai": NewOpenAIEmbedder,
+	"gemini": NewGeminiEmbedder,
+}
+
+// RegisterProvider adds or replaces a provider selectable with EMBEDDING_PROVIDER
+func RegisterProvider(name string, factory ProviderFactory) {
+	providers[strings.ToLower(name)] = factory
+}
+
+// Providers lists the registered provider names
+func Providers() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New builds the provider selected by cfg.Provider
+func New(cfg config.Embedding) (Embedder, error) {
+	factory, ok := providers[cfg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown embedding provider %q, available: %s", cfg.Provider, strings.Join(Providers(), ", "))
+	}
+	return factory(cfg)
+}
+
+// Normalize scales v to unit length in place, a zero vector is left as is
+func Normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
+// Cosine is the cosine similarity of two vectors, 0 when their lengths differ
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
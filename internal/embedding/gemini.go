@@ -0,0 +1,79 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+const defaultGeminiModel = "text-embedding-004"
+
+// geminiBatchLimit is the most texts the Gemini API embeds in one call
+const geminiBatchLimit = 100
+
+type geminiEmbedder struct {
+	client  *genai.Client
+	model   string
+	timeout time.Duration
+}
+
+func NewGeminiEmbedder(cfg config.Embedding) (Embedder, error) {
+	if cfg.APIKey == "" {
+		return nil, errors.New("gemini embeddings require EMBEDDING_API_KEY or GEMINI_API_KEY, use EMBEDDING_PROVIDER=hashing to run without a key")
+	}
+
+	client, err := genai.NewClient(context.Background(), option.WithAPIKey(cfg.APIKey))
+	if err != nil {
+		return nil, err
+	}
+
+	e := &geminiEmbedder{client: client, model: cfg.Model, timeout: cfg.Timeout}
+	if e.model == "" {
+		e.model = defaultGeminiModel
+	}
+	if e.timeout <= 0 {
+		e.timeout = config.DefaultAITimeout
+	}
+	return e, nil
+}
+
+func (e *geminiEmbedder) Model() string {
+	return e.model
+}
+
+func (e *geminiEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	model := e.client.EmbeddingModel(e.model)
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += geminiBatchLimit {
+		batch := model.NewBatch()
+		for _, text := range texts[start:min(start+geminiBatchLimit, len(texts))] {
+			batch.AddContent(genai.Text(text))
+		}
+
+		resp, err := model.BatchEmbedContents(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, embedding := range resp.Embeddings {
+			vectors = append(vectors, Normalize(embedding.Values))
+		}
+	}
+
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedding provider returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+func (e *geminiEmbedder) Close() error {
+	return e.client.Close()
+}
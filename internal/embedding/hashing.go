@@ -0,0 +1,68 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// DefaultHashingDimensions is the vector size of the hashing embedder
+const DefaultHashingDimensions = 256
+
+// hashingEmbedder hashes words and their letter trigrams into a fixed-size vector.
+// It needs no model or network and is deterministic, which makes it the choice for
+// development and tests. It only captures shared words and spellings, not meaning.
+type hashingEmbedder struct {
+	dimensions int
+}
+
+func NewHashingEmbedder(dimensions int) Embedder {
+	if dimensions <= 0 {
+		dimensions = DefaultHashingDimensions
+	}
+	return &hashingEmbedder{dimensions: dimensions}
+}
+
+func (e *hashingEmbedder) Model() string {
+	return fmt.Sprintf("hashing-%d", e.dimensions)
+}
+
+func (e *hashingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *hashingEmbedder) embed(text string) []float32 {
+	v := make([]float32, e.dimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		e.add(v, "w:"+word, 1)
+		padded := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			e.add(v, "t:"+string(padded[i:i+3]), 0.5)
+		}
+	}
+	return Normalize(v)
+}
+
+// add uses the sign bit of the hash so collisions cancel out instead of piling up
+func (e *hashingEmbedder) add(v []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	v[sum%uint64(e.dimensions)] += weight
+}
+
+func (e *hashingEmbedder) Close() error {
+	return nil
+}
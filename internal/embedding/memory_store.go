@@ -0,0 +1,78 @@
+package embedding
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// memoryStore compares the query with every vector of the tenant. It is fast enough
+// for catalogs of a few thousand menus and is rebuilt by the backfill on startup.
+type memoryStore struct {
+	mu      sync.RWMutex
+	vectors map[uint]Vector
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{vectors: make(map[uint]Vector)}
+}
+
+func (s *memoryStore) Upsert(ctx context.Context, vector Vector) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vectors[vector.MenuID] = vector
+	return nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, menuID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.vectors, menuID)
+	return nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, tenantID, menuID uint) (Vector, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	vector, ok := s.vectors[menuID]
+	if !ok || vector.TenantID != tenantID {
+		return Vector{}, ErrNotFound
+	}
+	return vector, nil
+}
+
+func (s *memoryStore) Checksums(ctx context.Context, menuIDs []uint) (map[uint]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	checksums := make(map[uint]string, len(menuIDs))
+	for _, id := range menuIDs {
+		if vector, ok := s.vectors[id]; ok {
+			checksums[id] = vector.Checksum
+		}
+	}
+	return checksums, nil
+}
+
+func (s *memoryStore) Search(ctx context.Context, query Query) ([]Match, error) {
+	s.mu.RLock()
+	var matches []Match
+	for _, vector := range s.vectors {
+		if vector.TenantID != query.TenantID || vector.Model != query.Model || vector.MenuID == query.Exclude {
+			continue
+		}
+		matches = append(matches, Match{MenuID: vector.MenuID, Score: Cosine(query.Values, vector.Values)})
+	}
+	s.mu.RUnlock()
+
+	// Map order is random, ties are broken by ID so results are stable
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].MenuID < matches[j].MenuID
+	})
+	if query.Limit > 0 && len(matches) > query.Limit {
+		matches = matches[:query.Limit]
+	}
+	return matches, nil
+}
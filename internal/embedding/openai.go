@@ -0,0 +1,138 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = "text-embedding-3-small"
+)
+
+// openAIEmbedder calls any OpenAI-compatible embeddings endpoint, such as OpenAI
+// itself or Ollama (http://localhost:11434/v1 with EMBEDDING_MODEL=nomic-embed-text)
+type openAIEmbedder struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	dimensions int
+	timeout    time.Duration
+	client     *http.Client
+}
+
+func NewOpenAIEmbedder(cfg config.Embedding) (Embedder, error) {
+	e := &openAIEmbedder{
+		baseURL:    cfg.BaseURL,
+		apiKey:     cfg.APIKey,
+		model:      cfg.Model,
+		dimensions: cfg.Dimensions,
+		timeout:    cfg.Timeout,
+		client:     &http.Client{},
+	}
+	if e.baseURL == "" {
+		e.baseURL = defaultOpenAIBaseURL
+	}
+	if e.model == "" {
+		e.model = defaultOpenAIModel
+	}
+	if e.timeout <= 0 {
+		e.timeout = config.DefaultAITimeout
+	}
+	if e.apiKey == "" && e.baseURL == defaultOpenAIBaseURL {
+		return nil, errors.New("openai embeddings require EMBEDDING_API_KEY or OPENAI_API_KEY, or EMBEDDING_BASE_URL of a local server")
+	}
+	return e, nil
+}
+
+func (e *openAIEmbedder) Model() string {
+	if e.dimensions > 0 {
+		return fmt.Sprintf("%s-%d", e.model, e.dimensions)
+	}
+	return e.model
+}
+
+type embeddingsRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+type embeddingsResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	body, err := json.Marshal(embeddingsRequest{Model: e.model, Input: texts, Dimensions: e.dimensions})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	var result embeddingsResponse
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("embedding provider returned %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if resp.StatusCode != http.StatusOK {
+		if result.Error != nil {
+			return nil, fmt.Errorf("embedding provider returned %d: %s", resp.StatusCode, result.Error.Message)
+		}
+		return nil, fmt.Errorf("embedding provider returned %d", resp.StatusCode)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range result.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding provider returned an unknown index %d", item.Index)
+		}
+		vectors[item.Index] = Normalize(item.Embedding)
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("embedding provider returned no vector for input %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+func (e *openAIEmbedder) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
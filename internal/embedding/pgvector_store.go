@@ -0,0 +1,139 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// pgvectorStore keeps the vectors in PostgreSQL with the pgvector extension, the
+// nearest ones are found by the database. The column has no fixed dimensions so the
+// model can change, rows of other models are skipped by every query.
+type pgvectorStore struct {
+	db *gorm.DB
+}
+
+// NewPgvectorStore creates the extension and the menu_embeddings table when missing
+func NewPgvectorStore(db *gorm.DB) (Store, error) {
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS vector").Error; err != nil {
+		return nil, fmt.Errorf("embedding: pgvector is not available: %w", err)
+	}
+	err := db.Exec(`CREATE TABLE IF NOT EXISTS menu_embeddings (
+		menu_id bigint PRIMARY KEY,
+		tenant_id bigint NOT NULL,
+		model text NOT NULL,
+		checksum text NOT NULL,
+		embedding vector NOT NULL,
+		updated_at timestamptz NOT NULL DEFAULT now()
+	)`).Error
+	if err == nil {
+		err = db.Exec("CREATE INDEX IF NOT EXISTS idx_menu_embeddings_tenant_model ON menu_embeddings (tenant_id, model)").Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pgvectorStore{db: db}, nil
+}
+
+func (s *pgvectorStore) Upsert(ctx context.Context, vector Vector) error {
+	return s.db.WithContext(ctx).Exec(`INSERT INTO menu_embeddings (menu_id, tenant_id, model, checksum, embedding, updated_at)
+		VALUES (?, ?, ?, ?, ?::vector, now())
+		ON CONFLICT (menu_id) DO UPDATE SET
+			tenant_id = EXCLUDED.tenant_id,
+			model = EXCLUDED.model,
+			checksum = EXCLUDED.checksum,
+			embedding = EXCLUDED.embedding,
+			updated_at = EXCLUDED.updated_at`,
+		vector.MenuID, vector.TenantID, vector.Model, vector.Checksum, formatVector(vector.Values)).Error
+}
+
+func (s *pgvectorStore) Delete(ctx context.Context, menuID uint) error {
+	return s.db.WithContext(ctx).Exec("DELETE FROM menu_embeddings WHERE menu_id = ?", menuID).Error
+}
+
+func (s *pgvectorStore) Get(ctx context.Context, tenantID, menuID uint) (Vector, error) {
+	var row struct {
+		Model     string
+		Checksum  string
+		Embedding string
+	}
+	err := s.db.WithContext(ctx).Raw("SELECT model, checksum, embedding::text AS embedding FROM menu_embeddings WHERE menu_id = ? AND tenant_id = ?",
+		menuID, tenantID).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Vector{}, ErrNotFound
+	}
+	if err != nil {
+		return Vector{}, err
+	}
+
+	values, err := parseVector(row.Embedding)
+	if err != nil {
+		return Vector{}, err
+	}
+	return Vector{TenantID: tenantID, MenuID: menuID, Model: row.Model, Checksum: row.Checksum, Values: values}, nil
+}
+
+func (s *pgvectorStore) Checksums(ctx context.Context, menuIDs []uint) (map[uint]string, error) {
+	checksums := make(map[uint]string, len(menuIDs))
+	if len(menuIDs) == 0 {
+		return checksums, nil
+	}
+
+	var rows []struct {
+		MenuID   uint
+		Checksum string
+	}
+	err := s.db.WithContext(ctx).Raw("SELECT menu_id, checksum FROM menu_embeddings WHERE menu_id IN ?", menuIDs).Scan(&rows).Error
+	for _, row := range rows {
+		checksums[row.MenuID] = row.Checksum
+	}
+	return checksums, err
+}
+
+// Search orders by cosine distance (<=>), the similarity is 1 minus the distance
+func (s *pgvectorStore) Search(ctx context.Context, query Query) ([]Match, error) {
+	vector := formatVector(query.Values)
+	limit := query.Limit
+	if limit <= 0 {
+		limit = math.MaxInt32
+	}
+	var matches []Match
+	err := s.db.WithContext(ctx).Raw(`SELECT menu_id, 1 - (embedding <=> ?::vector) AS score
+		FROM menu_embeddings
+		WHERE tenant_id = ? AND model = ? AND menu_id <> ?
+		ORDER BY embedding <=> ?::vector, menu_id
+		LIMIT ?`,
+		vector, query.TenantID, query.Model, query.Exclude, vector, limit).Scan(&matches).Error
+	return matches, err
+}
+
+// formatVector writes the pgvector text form, e.g. [0.1,0.2,0.3]
+func formatVector(values []float32) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.FormatFloat(float64(v), 'g', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func parseVector(text string) ([]float32, error) {
+	text = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(text), "["), "]")
+	if text == "" {
+		return nil, nil
+	}
+	parts := strings.Split(text, ",")
+	values := make([]float32, len(parts))
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("embedding: invalid vector: %w", err)
+		}
+		values[i] = float32(v)
+	}
+	return values, nil
+}
@@ -0,0 +1,115 @@
+// Package eval scores the answers of an AIService against a golden dataset, so that a
+// prompt or provider change can be compared with the current one before it ships
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"atalariq/menu-api/internal/model"
+)
+
+// DefaultBannedWords are the filler words no description should use
+var DefaultBannedWords = []string{"delicious", "tasty"}
+
+// Dataset is the golden set of cases, recommendations are picked from Catalog
+type Dataset struct {
+	Catalog         []model.Menu         `json:"catalog"`
+	BannedWords     []string             `json:"banned_words"` // DefaultBannedWords when empty
+	Descriptions    []DescriptionCase    `json:"descriptions"`
+	Recommendations []RecommendationCase `json:"recommendations"`
+}
+
+// DescriptionCase asks for Count descriptions of one menu
+type DescriptionCase struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Ingredients []string `json:"ingredients"`
+	model.DescriptionOptions
+	Count       int      `json:"count"`        // 1 by default, more is answered as a JSON array
+	MustMention []string `json:"must_mention"` // words every description contains
+}
+
+// RecommendationCase asks for recommendations from the catalog
+type RecommendationCase struct {
+	ID         string                     `json:"id"`
+	Preference string                     `json:"preference"`
+	Locale     string                     `json:"locale"`
+	History    []model.RecommendationTurn `json:"history"`
+	Expect     []string                   `json:"expect"` // names of menus, at least one should be recommended
+	Avoid      []string                   `json:"avoid"`  // names of menus that must not be recommended
+}
+
+// LoadDataset reads a dataset from a JSON file, catalog menus without an ID are numbered
+// from 1 in order
+func LoadDataset(path string) (Dataset, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Dataset{}, err
+	}
+	var dataset Dataset
+	if err := json.Unmarshal(raw, &dataset); err != nil {
+		return Dataset{}, fmt.Errorf("dataset %s: %w", path, err)
+	}
+	return dataset, dataset.validate()
+}
+
+func (d *Dataset) validate() error {
+	ids := make(map[string]bool)
+	checkID := func(id string) error {
+		if id == "" {
+			return fmt.Errorf("every case needs an id")
+		}
+		if ids[id] {
+			return fmt.Errorf("case id %q is used twice", id)
+		}
+		ids[id] = true
+		return nil
+	}
+	for i := range d.Catalog {
+		if d.Catalog[i].ID == 0 {
+			d.Catalog[i].ID = uint(i + 1)
+		}
+	}
+	for i, c := range d.Descriptions {
+		if err := checkID(c.ID); err != nil {
+			return err
+		}
+		if c.Name == "" {
+			return fmt.Errorf("description case %q has no name", c.ID)
+		}
+		if c.Count <= 0 {
+			d.Descriptions[i].Count = 1
+		}
+	}
+	for _, c := range d.Recommendations {
+		if err := checkID(c.ID); err != nil {
+			return err
+		}
+		if c.Preference == "" {
+			return fmt.Errorf("recommendation case %q has no preference", c.ID)
+		}
+		for _, name := range append(append([]string{}, c.Expect...), c.Avoid...) {
+			if d.menuNamed(name) == nil {
+				return fmt.Errorf("recommendation case %q names %q, which is not in the catalog", c.ID, name)
+			}
+		}
+	}
+	if len(d.Recommendations) > 0 && len(d.Catalog) == 0 {
+		return fmt.Errorf("recommendation cases need a catalog")
+	}
+	if len(d.BannedWords) == 0 {
+		d.BannedWords = DefaultBannedWords
+	}
+	return nil
+}
+
+func (d *Dataset) menuNamed(name string) *model.Menu {
+	for i := range d.Catalog {
+		if d.Catalog[i].Name == name {
+			return &d.Catalog[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+)
+
+// MaxRecommendations is the most recommendations the API shows
+const MaxRecommendations = 3
+
+// Kinds of cases
+const (
+	KindDescription    = "description"
+	KindRecommendation = "recommendation"
+)
+
+// Names of the checks
+const (
+	CheckValidJSON   = "valid_json"   // the answer parsed, after the repair prompt if needed
+	CheckCount       = "count"        // as many descriptions as asked, 1 to 3 recommendations
+	CheckWordCount   = "word_count"   // every description within max_words
+	CheckBannedWords = "banned_words" // no banned word
+	CheckMentions    = "must_mention" // every description contains the words asked for
+	CheckInCatalog   = "in_catalog"   // every recommended menu exists
+	CheckConfidence  = "confidence"   // every confidence from 0 to 1
+	CheckExpected    = "expected"     // at least one expected menu is recommended
+	CheckAvoided     = "avoided"      // no menu to avoid is recommended
+)
+
+type Check struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"` // why it failed
+}
+
+// CaseResult is the answer to one case with its checks, a case that failed with another
+// error than an unparsable answer has no checks and scores 0
+type CaseResult struct {
+	ID     string   `json:"id"`
+	Kind   string   `json:"kind"`
+	Output []string `json:"output"`
+	Error  string   `json:"error,omitempty"`
+	Checks []Check  `json:"checks"`
+	Score  float64  `json:"score"` // share of the checks passed
+}
+
+// CheckSummary counts how often a check passed over the cases it applies to
+type CheckSummary struct {
+	Passed int `json:"passed"`
+	Total  int `json:"total"`
+}
+
+// Result of one variant over the whole dataset
+type Result struct {
+	Variant string                  `json:"variant"`
+	Score   float64                 `json:"score"` // mean score of the cases
+	Errors  int                     `json:"errors"`
+	Checks  map[string]CheckSummary `json:"checks"`
+	Cases   []CaseResult            `json:"cases"`
+}
+
+// Run answers every case of the dataset with ai and scores the answers. Cases run one
+// after the other, the context of each call carries the case for Recorder and Replay.
+func Run(ctx context.Context, variant string, ai service.AIService, dataset Dataset) Result {
+	result := Result{Variant: variant, Checks: make(map[string]CheckSummary)}
+	for _, c := range dataset.Descriptions {
+		result.Cases = append(result.Cases, runDescription(ctx, ai, dataset, c))
+	}
+	for _, c := range dataset.Recommendations {
+		result.Cases = append(result.Cases, runRecommendation(ctx, ai, dataset, c))
+	}
+
+	total := 0.0
+	for _, c := range result.Cases {
+		total += c.Score
+		if c.Error != "" && len(c.Checks) == 0 {
+			result.Errors++
+		}
+		for _, check := range c.Checks {
+			summary := result.Checks[check.Name]
+			summary.Total++
+			if check.Passed {
+				summary.Passed++
+			}
+			result.Checks[check.Name] = summary
+		}
+	}
+	if len(result.Cases) > 0 {
+		result.Score = total / float64(len(result.Cases))
+	}
+	return result
+}
+
+func runDescription(ctx context.Context, ai service.AIService, dataset Dataset, c DescriptionCase) CaseResult {
+	result := CaseResult{ID: c.ID, Kind: KindDescription}
+	ctx = withCase(ctx, KindDescription, c.ID, c)
+	descriptions, err := ai.GenerateDescriptions(ctx, c.Name, c.Ingredients, c.DescriptionOptions, c.Count)
+	if !answered(&result, err) {
+		return result
+	}
+	result.Output = descriptions
+
+	options := c.DescriptionOptions.WithDefaults()
+	if c.Count > 1 {
+		result.add(CheckValidJSON, "")
+	}
+	result.add(CheckCount, failIf(len(descriptions) != c.Count, "%d of %d descriptions", len(descriptions), c.Count))
+
+	var tooLong, banned, missing []string
+	for i, description := range descriptions {
+		words := wordsOf(description)
+		if len(words) > options.MaxWords {
+			tooLong = append(tooLong, fmt.Sprintf("#%d has %d words", i+1, len(words)))
+		}
+		for _, word := range dataset.BannedWords {
+			if slices.Contains(words, strings.ToLower(word)) {
+				banned = append(banned, fmt.Sprintf("#%d says %q", i+1, word))
+			}
+		}
+		for _, word := range c.MustMention {
+			if !strings.Contains(strings.ToLower(description), strings.ToLower(word)) {
+				missing = append(missing, fmt.Sprintf("#%d lacks %q", i+1, word))
+			}
+		}
+	}
+	result.add(CheckWordCount, failIf(len(tooLong) > 0, "over %d words: %s", options.MaxWords, strings.Join(tooLong, ", ")))
+	result.add(CheckBannedWords, failIf(len(banned) > 0, "%s", strings.Join(banned, ", ")))
+	if len(c.MustMention) > 0 {
+		result.add(CheckMentions, failIf(len(missing) > 0, "%s", strings.Join(missing, ", ")))
+	}
+	result.score()
+	return result
+}
+
+func runRecommendation(ctx context.Context, ai service.AIService, dataset Dataset, c RecommendationCase) CaseResult {
+	result := CaseResult{ID: c.ID, Kind: KindRecommendation}
+	ctx = withCase(ctx, KindRecommendation, c.ID, c)
+	request := model.RecommendationRequest{Preference: c.Preference, Locale: c.Locale, History: c.History}
+	raws, err := ai.GetRecommendations(ctx, request, dataset.Catalog)
+	if !answered(&result, err) {
+		return result
+	}
+
+	var unknown, outOfRange, avoided []string
+	expected := false
+	for _, raw := range raws {
+		menu := dataset.menuByID(raw.MenuID)
+		if menu == nil && raw.MenuName != "" {
+			menu = dataset.menuNamed(raw.MenuName) // the service resolves a missing ID by name
+		}
+		name := raw.MenuName
+		if menu == nil {
+			unknown = append(unknown, fmt.Sprintf("menu %d %q", raw.MenuID, raw.MenuName))
+		} else {
+			name = menu.Name
+			expected = expected || slices.Contains(c.Expect, name)
+			if slices.Contains(c.Avoid, name) {
+				avoided = append(avoided, name)
+			}
+		}
+		if raw.Confidence < 0 || raw.Confidence > 1 {
+			outOfRange = append(outOfRange, fmt.Sprintf("%s at %g", name, raw.Confidence))
+		}
+		result.Output = append(result.Output, fmt.Sprintf("%s (%.2f): %s", name, raw.Confidence, raw.Reason))
+	}
+
+	result.add(CheckValidJSON, "")
+	result.add(CheckCount, failIf(len(raws) == 0 || len(raws) > MaxRecommendations, "%d recommendations", len(raws)))
+	result.add(CheckInCatalog, failIf(len(unknown) > 0, "not in the catalog: %s", strings.Join(unknown, ", ")))
+	result.add(CheckConfidence, failIf(len(outOfRange) > 0, "%s", strings.Join(outOfRange, ", ")))
+	if len(c.Expect) > 0 {
+		result.add(CheckExpected, failIf(!expected, "none of %s", strings.Join(c.Expect, ", ")))
+	}
+	if len(c.Avoid) > 0 {
+		result.add(CheckAvoided, failIf(len(avoided) > 0, "recommended %s", strings.Join(avoided, ", ")))
+	}
+	result.score()
+	return result
+}
+
+// answered records an error, an unparsable answer fails the JSON check and every other
+// error leaves the case unscored
+func answered(result *CaseResult, err error) bool {
+	if err == nil {
+		return true
+	}
+	result.Error = err.Error()
+	if errors.Is(err, service.ErrUnparsableAnswer) {
+		result.Checks = []Check{{Name: CheckValidJSON, Detail: err.Error()}}
+	}
+	return false
+}
+
+// failIf returns the detail of a failed check, or "" when cond is false
+func failIf(cond bool, format string, args ...any) string {
+	if !cond {
+		return ""
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// add appends a check that passed when detail is empty
+func (r *CaseResult) add(name, detail string) {
+	r.Checks = append(r.Checks, Check{Name: name, Passed: detail == "", Detail: detail})
+}
+
+func (r *CaseResult) score() {
+	passed := 0
+	for _, check := range r.Checks {
+		if check.Passed {
+			passed++
+		}
+	}
+	r.Score = float64(passed) / float64(len(r.Checks))
+}
+
+// wordsOf splits text into lowercase words, punctuation is dropped
+func wordsOf(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '\''
+	})
+}
+
+func (d *Dataset) menuByID(id uint) *model.Menu {
+	for i := range d.Catalog {
+		if d.Catalog[i].ID == id {
+			return &d.Catalog[i]
+		}
+	}
+	return nil
+}
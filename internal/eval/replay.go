@@ -0,0 +1,207 @@
+package eval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+)
+
+var (
+	ErrNotRecorded    = errors.New("no recorded answer")
+	ErrStaleRecording = errors.New("the case changed since its answer was recorded")
+)
+
+// Recording keeps the answers of each variant by case, "description/<id>" or
+// "recommendation/<id>", so that a run can be scored again without the AI provider
+type Recording map[string]map[string]Answer
+
+// Answer is what the AI answered to one case
+type Answer struct {
+	Input           string                            `json:"input"` // hash of the case, to notice edits
+	Descriptions    []string                          `json:"descriptions,omitempty"`
+	Recommendations []model.RecommendationResponseRaw `json:"recommendations,omitempty"`
+	Error           string                            `json:"error,omitempty"`
+	Unparsable      bool                              `json:"unparsable,omitempty"` // the error was an unparsable answer
+}
+
+func LoadRecording(path string) (Recording, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var recording Recording
+	if err := json.Unmarshal(raw, &recording); err != nil {
+		return nil, fmt.Errorf("recording %s: %w", path, err)
+	}
+	return recording, nil
+}
+
+func (r Recording) Save(path string) error {
+	raw, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
+
+type caseKey struct{}
+
+type caseRef struct {
+	key   string
+	input string
+}
+
+// withCase tells the Recorder and Replay which case a call answers
+func withCase(ctx context.Context, kind, id string, input any) context.Context {
+	raw, _ := json.Marshal(input)
+	sum := sha256.Sum256(raw)
+	return context.WithValue(ctx, caseKey{}, caseRef{key: kind + "/" + id, input: hex.EncodeToString(sum[:8])})
+}
+
+func caseOf(ctx context.Context) (caseRef, bool) {
+	ref, ok := ctx.Value(caseKey{}).(caseRef)
+	return ref, ok
+}
+
+// Recorder passes the calls of Run to the AIService and keeps the answers, calls made
+// outside Run are not recorded
+type Recorder struct {
+	service.AIService
+
+	mu      sync.Mutex
+	answers map[string]Answer
+}
+
+func NewRecorder(ai service.AIService) *Recorder {
+	return &Recorder{AIService: ai, answers: make(map[string]Answer)}
+}
+
+// Answers returns the recorded answers by case
+func (r *Recorder) Answers() map[string]Answer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	answers := make(map[string]Answer, len(r.answers))
+	for key, answer := range r.answers {
+		answers[key] = answer
+	}
+	return answers
+}
+
+func (r *Recorder) record(ctx context.Context, answer Answer, err error) {
+	ref, ok := caseOf(ctx)
+	if !ok {
+		return
+	}
+	answer.Input = ref.input
+	if err != nil {
+		answer.Error = err.Error()
+		answer.Unparsable = errors.Is(err, service.ErrUnparsableAnswer)
+	}
+	r.mu.Lock()
+	r.answers[ref.key] = answer
+	r.mu.Unlock()
+}
+
+func (r *Recorder) GenerateDescriptions(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, count int) ([]string, error) {
+	descriptions, err := r.AIService.GenerateDescriptions(ctx, name, ingredients, options, count)
+	r.record(ctx, Answer{Descriptions: descriptions}, err)
+	return descriptions, err
+}
+
+func (r *Recorder) GetRecommendations(ctx context.Context, request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error) {
+	recommendations, err := r.AIService.GetRecommendations(ctx, request, menus)
+	r.record(ctx, Answer{Recommendations: recommendations}, err)
+	return recommendations, err
+}
+
+// replayService answers the calls of Run from recorded answers. Only the methods Run
+// calls are replayed, the others return ErrNotRecorded.
+type replayService struct {
+	answers map[string]Answer
+}
+
+// NewReplay returns an AIService answering from the answers of one variant of a Recording
+func NewReplay(answers map[string]Answer) service.AIService {
+	return &replayService{answers: answers}
+}
+
+// recordedError keeps an unparsable answer recognizable with errors.Is
+type recordedError struct {
+	message    string
+	unparsable bool
+}
+
+func (e *recordedError) Error() string { return e.message }
+
+func (e *recordedError) Unwrap() error {
+	if e.unparsable {
+		return service.ErrUnparsableAnswer
+	}
+	return nil
+}
+
+func (s *replayService) answer(ctx context.Context) (Answer, error) {
+	ref, ok := caseOf(ctx)
+	if !ok {
+		return Answer{}, ErrNotRecorded
+	}
+	answer, ok := s.answers[ref.key]
+	if !ok {
+		return Answer{}, fmt.Errorf("%w for %s", ErrNotRecorded, ref.key)
+	}
+	if answer.Input != ref.input {
+		return Answer{}, fmt.Errorf("%w: %s, record it again", ErrStaleRecording, ref.key)
+	}
+	if answer.Error != "" {
+		return answer, &recordedError{answer.Error, answer.Unparsable}
+	}
+	return answer, nil
+}
+
+func (s *replayService) GenerateDescriptions(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, count int) ([]string, error) {
+	answer, err := s.answer(ctx)
+	return answer.Descriptions, err
+}
+
+func (s *replayService) GetRecommendations(ctx context.Context, request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error) {
+	answer, err := s.answer(ctx)
+	return answer.Recommendations, err
+}
+
+func (s *replayService) GenerateDescription(ctx context.Context, name string, ingredients []string) (string, error) {
+	return "", ErrNotRecorded
+}
+
+func (s *replayService) TranslateMenus(ctx context.Context, items []model.TranslationItem, locale string) ([]model.TranslationItem, error) {
+	return nil, ErrNotRecorded
+}
+
+func (s *replayService) SuggestEnrichment(ctx context.Context, menu model.Menu, categories []string) (model.EnrichmentSuggestion, error) {
+	return model.EnrichmentSuggestion{}, ErrNotRecorded
+}
+
+func (s *replayService) ExtractMenus(ctx context.Context, document model.MenuDocument, categories []string) ([]model.MenuDraft, error) {
+	return nil, ErrNotRecorded
+}
+
+func (s *replayService) SummarizeReviews(ctx context.Context, menu model.Menu, reviews []model.Review) (model.ReviewSummary, error) {
+	return model.ReviewSummary{}, ErrNotRecorded
+}
+
+func (s *replayService) GenerateDescriptionStream(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, emit func(string) error) (model.AIUsage, error) {
+	return model.AIUsage{}, ErrNotRecorded
+}
+
+func (s *replayService) GetRecommendationsStream(ctx context.Context, request model.RecommendationRequest, menus []model.Menu, emit func(model.RecommendationResponseRaw) error) (model.AIUsage, error) {
+	return model.AIUsage{}, ErrNotRecorded
+}
+
+func (s *replayService) Close() error { return nil }
@@ -0,0 +1,164 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Comparison sets a candidate variant against the baseline
+type Comparison struct {
+	Baseline  Result      `json:"baseline"`
+	Candidate Result      `json:"candidate"`
+	Checks    []CheckDiff `json:"checks"`
+	Cases     []CaseDiff  `json:"cases"` // only the cases whose score changed
+}
+
+// CheckDiff compares the pass rate of one check, from 0 to 1
+type CheckDiff struct {
+	Name      string  `json:"name"`
+	Baseline  float64 `json:"baseline"`
+	Candidate float64 `json:"candidate"`
+}
+
+// CaseDiff is a case scored differently by the two variants
+type CaseDiff struct {
+	ID        string   `json:"id"`
+	Kind      string   `json:"kind"`
+	Baseline  float64  `json:"baseline"`
+	Candidate float64  `json:"candidate"`
+	Broken    []string `json:"broken,omitempty"` // checks passed by the baseline only
+	Fixed     []string `json:"fixed,omitempty"`  // checks passed by the candidate only
+}
+
+// Regressed reports whether the candidate scores lower than the baseline
+func (c Comparison) Regressed() bool {
+	return c.Candidate.Score < c.Baseline.Score
+}
+
+func Compare(baseline, candidate Result) Comparison {
+	comparison := Comparison{Baseline: baseline, Candidate: candidate}
+
+	names := make(map[string]bool)
+	for name := range baseline.Checks {
+		names[name] = true
+	}
+	for name := range candidate.Checks {
+		names[name] = true
+	}
+	for name := range names {
+		comparison.Checks = append(comparison.Checks, CheckDiff{Name: name, Baseline: passRate(baseline.Checks[name]), Candidate: passRate(candidate.Checks[name])})
+	}
+	sort.Slice(comparison.Checks, func(i, j int) bool { return comparison.Checks[i].Name < comparison.Checks[j].Name })
+
+	before := make(map[string]CaseResult, len(baseline.Cases))
+	for _, c := range baseline.Cases {
+		before[c.Kind+"/"+c.ID] = c
+	}
+	for _, after := range candidate.Cases {
+		c, ok := before[after.Kind+"/"+after.ID]
+		if !ok || c.Score == after.Score {
+			continue
+		}
+		passedBefore, passedAfter := passedChecks(c), passedChecks(after)
+		diff := CaseDiff{ID: after.ID, Kind: after.Kind, Baseline: c.Score, Candidate: after.Score}
+		for name := range passedBefore {
+			if !passedAfter[name] {
+				diff.Broken = append(diff.Broken, name)
+			}
+		}
+		for name := range passedAfter {
+			if !passedBefore[name] {
+				diff.Fixed = append(diff.Fixed, name)
+			}
+		}
+		sort.Strings(diff.Broken)
+		sort.Strings(diff.Fixed)
+		comparison.Cases = append(comparison.Cases, diff)
+	}
+	return comparison
+}
+
+func passRate(summary CheckSummary) float64 {
+	if summary.Total == 0 {
+		return 0
+	}
+	return float64(summary.Passed) / float64(summary.Total)
+}
+
+func passedChecks(c CaseResult) map[string]bool {
+	passed := make(map[string]bool)
+	for _, check := range c.Checks {
+		if check.Passed {
+			passed[check.Name] = true
+		}
+	}
+	return passed
+}
+
+// WriteResult prints the score, the pass rate of every check and the failed checks
+func WriteResult(w io.Writer, result Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Variant %s: score %.1f%%, %d cases, %d errors\n\n", result.Variant, 100*result.Score, len(result.Cases), result.Errors)
+	fmt.Fprintln(tw, "CHECK\tPASSED")
+	for _, name := range sortedChecks(result.Checks) {
+		summary := result.Checks[name]
+		fmt.Fprintf(tw, "%s\t%d/%d\n", name, summary.Passed, summary.Total)
+	}
+
+	var failures []string
+	for _, c := range result.Cases {
+		if c.Error != "" {
+			failures = append(failures, fmt.Sprintf("%s/%s\terror\t%s", c.Kind, c.ID, c.Error))
+		}
+		for _, check := range c.Checks {
+			// An unparsable answer is shown by its error
+			if !check.Passed && c.Error == "" {
+				failures = append(failures, fmt.Sprintf("%s/%s\t%s\t%s", c.Kind, c.ID, check.Name, check.Detail))
+			}
+		}
+	}
+	if len(failures) > 0 {
+		fmt.Fprintln(tw, "\nCASE\tFAILED\tDETAIL")
+		fmt.Fprintln(tw, strings.Join(failures, "\n"))
+	}
+	return tw.Flush()
+}
+
+// WriteComparison prints the pass rates of both variants and the cases that changed
+func WriteComparison(w io.Writer, comparison Comparison) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	baseline, candidate := comparison.Baseline, comparison.Candidate
+	fmt.Fprintf(tw, "\t%s\t%s\tDELTA\n", baseline.Variant, candidate.Variant)
+	fmt.Fprintf(tw, "score\t%.1f%%\t%.1f%%\t%+.1f\n", 100*baseline.Score, 100*candidate.Score, 100*(candidate.Score-baseline.Score))
+	fmt.Fprintf(tw, "errors\t%d\t%d\t%+d\n", baseline.Errors, candidate.Errors, candidate.Errors-baseline.Errors)
+	for _, check := range comparison.Checks {
+		fmt.Fprintf(tw, "%s\t%.1f%%\t%.1f%%\t%+.1f\n", check.Name, 100*check.Baseline, 100*check.Candidate, 100*(check.Candidate-check.Baseline))
+	}
+
+	if len(comparison.Cases) > 0 {
+		fmt.Fprintln(tw, "\nCASE\tBASELINE\tCANDIDATE\tCHANGES")
+		for _, c := range comparison.Cases {
+			var changes []string
+			if len(c.Broken) > 0 {
+				changes = append(changes, "broke "+strings.Join(c.Broken, ", "))
+			}
+			if len(c.Fixed) > 0 {
+				changes = append(changes, "fixed "+strings.Join(c.Fixed, ", "))
+			}
+			fmt.Fprintf(tw, "%s/%s\t%.2f\t%.2f\t%s\n", c.Kind, c.ID, c.Baseline, c.Candidate, strings.Join(changes, "; "))
+		}
+	}
+	return tw.Flush()
+}
+
+func sortedChecks(checks map[string]CheckSummary) []string {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,93 @@
+// Package imaging decodes uploaded photos and produces resized and WebP variants in pure Go
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"net/http"
+
+	// Register decoders for image.Decode
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"golang.org/x/image/draw"
+)
+
+// MaxPixels guards against decompression bombs, a tiny file can declare a huge canvas
+const MaxPixels = 40_000_000
+
+var ErrUnsupportedFormat = errors.New("unsupported image format, use JPEG, PNG, GIF or WebP")
+
+// Supported content types, detected from the file content rather than the client header
+var supportedTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// SniffContentType detects the content type from the first bytes and returns it with its file extension
+func SniffContentType(data []byte) (string, string, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := supportedTypes[contentType]
+	if !ok {
+		return "", "", ErrUnsupportedFormat
+	}
+	return contentType, ext, nil
+}
+
+// Decode checks the declared dimensions before decoding the full image
+func Decode(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, fmt.Errorf("image is %dx%d, the limit is %d pixels", config.Width, config.Height, MaxPixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// Fit scales img down so that its longest side is at most maxSide, smaller images are returned as is
+func Fit(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*maxSide/width)
+		width = maxSide
+	} else {
+		width = max(1, width*maxSide/height)
+		height = maxSide
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// EncodeJPEGBytes encodes img as JPEG, transparent areas are flattened onto white as JPEG has no alpha
+func EncodeJPEGBytes(img image.Image, quality int) ([]byte, error) {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
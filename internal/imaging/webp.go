@@ -0,0 +1,418 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+	"math/bits"
+	"sort"
+)
+
+// VP8L (lossless WebP) bitstream constants
+const (
+	vp8lSignature      = 0x2f
+	vp8lMaxDimension   = 1 << 14
+	transformSubGreen  = 2
+	greenAlphabetSize  = 256 + 24 // literals + backward reference length prefixes
+	literalAlphabet    = 256
+	distanceAlphabet   = 40
+	maxCodeLength      = 15
+	maxCodeLengthCodes = 7
+	maxCopyLength      = 4096 // longest backward reference
+	minCopyLength      = 3    // shorter repeats are cheaper as literals
+	distanceCodeAbove  = 1    // distance codes of the pixel above and the pixel to the left
+	distanceCodeLeft   = 2
+)
+
+// Order in which code length code lengths are written, fixed by the format
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// EncodeWebP writes img as a lossless WebP (VP8L) file.
+// It applies the subtract-green transform, copies runs of the pixel to the left or
+// above with backward references and entropy codes the other pixels as literals,
+// which keeps the encoder small while producing files every WebP decoder accepts.
+//
+// golang.org/x/image/webp only decodes, and the full encoders either bind libwebp
+// through cgo, which would need libwebp in every build image, or are young
+// dependencies for a single feature. Without predictors, a color cache or general
+// LZ77 matching the output is small for flat artwork and logos but larger than a
+// JPEG for photos, so callers keep whichever encoding is smaller.
+func EncodeWebP(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > vp8lMaxDimension || height > vp8lMaxDimension {
+		return errors.New("imaging: webp dimensions must be between 1 and 16384")
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+
+	// Subtract green transform
+	pix := nrgba.Pix
+	hasAlpha := false
+	for i := 0; i < len(pix); i += 4 {
+		pix[i] -= pix[i+1]   // red - green
+		pix[i+2] -= pix[i+1] // blue - green
+		if pix[i+3] != 0xff {
+			hasAlpha = true
+		}
+	}
+
+	// Symbol histograms of the literals and backward references
+	tokens := copyTokens(pix, width)
+	greenHist := make([]int, greenAlphabetSize)
+	var red, blue, alpha [literalAlphabet]int
+	distanceHist := make([]int, distanceAlphabet)
+	for _, t := range tokens {
+		if t.length == 0 {
+			i := t.pixel * 4
+			greenHist[pix[i+1]]++
+			red[pix[i]]++
+			blue[pix[i+2]]++
+			alpha[pix[i+3]]++
+			continue
+		}
+		lengthSymbol, _, _ := prefixEncode(t.length)
+		greenHist[literalAlphabet+lengthSymbol]++
+		distanceSymbol, _, _ := prefixEncode(t.distanceCode)
+		distanceHist[distanceSymbol]++
+	}
+
+	bw := &bitWriter{}
+	bw.write(vp8lSignature, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	bw.write(boolBit(hasAlpha), 1)
+	bw.write(0, 3) // version
+
+	bw.write(1, 1) // transform present
+	bw.write(transformSubGreen, 2)
+	bw.write(0, 1) // no more transforms
+
+	bw.write(0, 1) // no color cache
+	bw.write(0, 1) // no meta prefix codes
+
+	greenCode := writePrefixCode(bw, greenHist)
+	redCode := writePrefixCode(bw, red[:])
+	blueCode := writePrefixCode(bw, blue[:])
+	alphaCode := writePrefixCode(bw, alpha[:])
+	distanceCode := writePrefixCode(bw, distanceHist)
+
+	for _, t := range tokens {
+		if t.length == 0 {
+			i := t.pixel * 4
+			greenCode.emit(bw, int(pix[i+1]))
+			redCode.emit(bw, int(pix[i]))
+			blueCode.emit(bw, int(pix[i+2]))
+			alphaCode.emit(bw, int(pix[i+3]))
+			continue
+		}
+		symbol, extraBits, extra := prefixEncode(t.length)
+		greenCode.emit(bw, literalAlphabet+symbol)
+		bw.write(extra, extraBits)
+		symbol, extraBits, extra = prefixEncode(t.distanceCode)
+		distanceCode.emit(bw, symbol)
+		bw.write(extra, extraBits)
+	}
+
+	data := bw.bytes()
+	chunkSize := len(data)
+	padding := chunkSize & 1
+
+	var header [20]byte
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(4+8+chunkSize+padding))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(chunkSize))
+
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if padding == 1 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+// EncodeWebPBytes is EncodeWebP into memory
+func EncodeWebPBytes(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := EncodeWebP(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// copyToken is a literal pixel when length is 0, else a backward reference copying
+// length pixels from the position given by distanceCode
+type copyToken struct {
+	pixel        int
+	length       int
+	distanceCode int
+}
+
+// copyTokens greedily replaces runs repeating the pixel to the left or the row above
+// with backward references
+func copyTokens(pix []byte, width int) []copyToken {
+	n := len(pix) / 4
+	same := func(i, j int) bool {
+		return pix[i*4] == pix[j*4] && pix[i*4+1] == pix[j*4+1] && pix[i*4+2] == pix[j*4+2] && pix[i*4+3] == pix[j*4+3]
+	}
+	matchLength := func(i, distance int) int {
+		length := 0
+		for i+length < n && length < maxCopyLength && same(i+length, i+length-distance) {
+			length++
+		}
+		return length
+	}
+
+	var tokens []copyToken
+	for i := 0; i < n; {
+		length, code := 0, 0
+		if i >= 1 {
+			length, code = matchLength(i, 1), distanceCodeLeft
+		}
+		if i >= width {
+			if above := matchLength(i, width); above > length {
+				length, code = above, distanceCodeAbove
+			}
+		}
+		if length < minCopyLength {
+			tokens = append(tokens, copyToken{pixel: i})
+			i++
+			continue
+		}
+		tokens = append(tokens, copyToken{pixel: i, length: length, distanceCode: code})
+		i += length
+	}
+	return tokens
+}
+
+// prefixEncode splits a length or distance code into its prefix symbol and extra bits
+func prefixEncode(v int) (symbol int, extraBits uint, extra uint32) {
+	value := v - 1
+	if value < 4 {
+		return value, 0, 0
+	}
+	highest := bits.Len(uint(value)) - 1
+	second := (value >> (highest - 1)) & 1
+	extraBits = uint(highest - 1)
+	return 2*highest + second, extraBits, uint32(value) & (1<<extraBits - 1)
+}
+
+// prefixCode stores the bit-reversed canonical code of each symbol, ready for an LSB-first writer
+type prefixCode struct {
+	codes   []uint32
+	lengths []uint8
+}
+
+func (p prefixCode) emit(bw *bitWriter, symbol int) {
+	if n := p.lengths[symbol]; n > 0 {
+		bw.write(p.codes[symbol], uint(n))
+	}
+}
+
+// writePrefixCode writes the prefix code for the histogram and returns it for emitting symbols
+func writePrefixCode(bw *bitWriter, histogram []int) prefixCode {
+	var used []int
+	for symbol, count := range histogram {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	// One or no symbol: simple code, symbols are then written with zero bits
+	if len(used) <= 1 {
+		symbol := 0
+		if len(used) == 1 {
+			symbol = used[0]
+		}
+		bw.write(1, 1) // simple code
+		bw.write(0, 1) // one symbol
+		if symbol < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(symbol), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(symbol), 8)
+		}
+		return prefixCode{codes: make([]uint32, len(histogram)), lengths: make([]uint8, len(histogram))}
+	}
+
+	lengths := huffmanLengths(histogram, maxCodeLength)
+
+	// Code lengths are themselves entropy coded with the code length code
+	clHistogram := make([]int, len(codeLengthCodeOrder))
+	for _, l := range lengths {
+		clHistogram[l]++
+	}
+	clLengths := huffmanLengths(clHistogram, maxCodeLengthCodes)
+
+	bw.write(0, 1) // normal code
+	nCodes := 4
+	for i, symbol := range codeLengthCodeOrder {
+		if clLengths[symbol] > 0 && i+1 > nCodes {
+			nCodes = i + 1
+		}
+	}
+	bw.write(uint32(nCodes-4), 4)
+	for _, symbol := range codeLengthCodeOrder[:nCodes] {
+		bw.write(uint32(clLengths[symbol]), 3)
+	}
+	bw.write(0, 1) // code lengths cover the whole alphabet
+
+	clCode := canonicalCode(clLengths)
+	for _, l := range lengths {
+		clCode.emit(bw, int(l))
+	}
+
+	return canonicalCode(lengths)
+}
+
+// canonicalCode assigns canonical Huffman codes, a lone symbol gets a zero-bit code like in the decoder
+func canonicalCode(lengths []uint8) prefixCode {
+	code := prefixCode{codes: make([]uint32, len(lengths)), lengths: make([]uint8, len(lengths))}
+
+	var count [maxCodeLength + 1]uint32
+	nonZero := 0
+	for _, l := range lengths {
+		if l > 0 {
+			count[l]++
+			nonZero++
+		}
+	}
+	if nonZero == 1 {
+		return code
+	}
+
+	var next [maxCodeLength + 1]uint32
+	c := uint32(0)
+	for bits := 1; bits <= maxCodeLength; bits++ {
+		c = (c + count[bits-1]) << 1
+		next[bits] = c
+	}
+
+	for symbol, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		code.codes[symbol] = reverseBits(next[l], l)
+		code.lengths[symbol] = l
+		next[l]++
+	}
+	return code
+}
+
+func reverseBits(v uint32, n uint8) uint32 {
+	r := uint32(0)
+	for i := uint8(0); i < n; i++ {
+		r = r<<1 | v&1
+		v >>= 1
+	}
+	return r
+}
+
+// huffmanLengths computes Huffman code lengths no longer than limit.
+// Frequencies are flattened until the tree fits, which always terminates for the alphabets used here.
+func huffmanLengths(histogram []int, limit uint8) []uint8 {
+	freqs := make([]int, len(histogram))
+	copy(freqs, histogram)
+
+	for {
+		lengths := treeLengths(freqs)
+		longest := uint8(0)
+		for _, l := range lengths {
+			longest = max(longest, l)
+		}
+		if longest <= limit {
+			return lengths
+		}
+		for i, f := range freqs {
+			if f > 0 {
+				freqs[i] = (f + 1) / 2
+			}
+		}
+	}
+}
+
+func treeLengths(freqs []int) []uint8 {
+	type node struct {
+		weight      int
+		symbol      int // -1 for internal nodes
+		left, right int
+	}
+
+	var nodes []node
+	var queue []int
+	for symbol, f := range freqs {
+		if f > 0 {
+			nodes = append(nodes, node{weight: f, symbol: symbol, left: -1, right: -1})
+			queue = append(queue, len(nodes)-1)
+		}
+	}
+
+	lengths := make([]uint8, len(freqs))
+	if len(queue) == 1 {
+		lengths[nodes[0].symbol] = 1
+		return lengths
+	}
+
+	for len(queue) > 1 {
+		sort.SliceStable(queue, func(i, j int) bool { return nodes[queue[i]].weight < nodes[queue[j]].weight })
+		a, b := queue[0], queue[1]
+		nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, symbol: -1, left: a, right: b})
+		queue = append(queue[2:], len(nodes)-1)
+	}
+
+	var walk func(n int, depth uint8)
+	walk = func(n int, depth uint8) {
+		if nodes[n].symbol >= 0 {
+			lengths[nodes[n].symbol] = depth
+			return
+		}
+		walk(nodes[n].left, depth+1)
+		walk(nodes[n].right, depth+1)
+	}
+	walk(queue[0], 0)
+	return lengths
+}
+
+// bitWriter packs bits least significant first, as VP8L requires
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nBits uint
+}
+
+func (b *bitWriter) write(value uint32, n uint) {
+	b.acc |= uint64(value&(1<<n-1)) << b.nBits
+	b.nBits += n
+	for b.nBits >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nBits -= 8
+	}
+}
+
+func (b *bitWriter) bytes() []byte {
+	if b.nBits > 0 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc, b.nBits = 0, 0
+	}
+	return b.buf
+}
+
+func boolBit(v bool) uint32 {
+	if v {
+		return 1
+	}
+	return 0
+}
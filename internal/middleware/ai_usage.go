@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AIUsage goes after Tenant on the routes calling the AI. It names the tenant and route
+// in the usage records and refuses the request once a monthly AI budget is used up:
+// 402 when the budget of the tenant is, 429 until next month when the platform budget is.
+func AIUsage(budget service.AIBudgetChecker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scope := Scope(ctx)
+		if err := budget.CheckBudget(scope.TenantID); err != nil {
+			status := http.StatusTooManyRequests
+			if errors.Is(err, service.ErrTenantAIBudgetExceeded) {
+				status = http.StatusPaymentRequired
+			} else {
+				retryAfter := time.Until(service.AIBudgetResetsAt(time.Now())) / time.Second
+				ctx.Header("Retry-After", strconv.FormatInt(int64(retryAfter)+1, 10))
+			}
+			ctx.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		caller := service.AICaller{TenantID: scope.TenantID, Endpoint: ctx.Request.Method + " " + ctx.FullPath()}
+		ctx.Request = ctx.Request.WithContext(service.WithAICaller(ctx.Request.Context(), caller))
+		ctx.Next()
+	}
+}
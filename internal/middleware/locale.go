@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"atalariq/menu-api/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+const localeKey = "locale"
+
+// Locale negotiates the response language from `lang=` or Accept-Language.
+// When the result is the default locale the base menu text is served untranslated.
+func Locale(supported []string, defaultLocale string) gin.HandlerFunc {
+	defaultLocale = model.NormalizeLocale(defaultLocale)
+
+	return func(ctx *gin.Context) {
+		locale := NegotiateLocale(ctx.Query("lang"), ctx.GetHeader("Accept-Language"), supported, defaultLocale)
+
+		ctx.Header("Content-Language", locale)
+		if locale != defaultLocale {
+			ctx.Set(localeKey, locale)
+		}
+		ctx.Next()
+	}
+}
+
+// NegotiateLocale picks the first supported locale from an explicit `lang` value,
+// then from Accept-Language by quality. A regional variant falls back to its base language.
+func NegotiateLocale(lang, acceptLanguage string, supported []string, defaultLocale string) string {
+	candidates := parseAcceptLanguage(acceptLanguage)
+	if lang != "" {
+		candidates = append([]string{lang}, candidates...)
+	}
+
+	for _, candidate := range candidates {
+		for _, option := range model.LocaleFallbacks(candidate) {
+			for _, s := range supported {
+				if model.NormalizeLocale(s) == option {
+					return option
+				}
+			}
+		}
+	}
+
+	return defaultLocale
+}
+
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+
+	var entries []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale := strings.TrimSpace(fields[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			entries = append(entries, weighted{locale, quality})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].quality > entries[j].quality })
+
+	locales := make([]string, len(entries))
+	for i, entry := range entries {
+		locales[i] = entry.locale
+	}
+	return locales
+}
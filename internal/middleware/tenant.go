@@ -0,0 +1,112 @@
+// Package middleware
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	TenantHeader = "X-Tenant-ID"
+	BranchHeader = "X-Branch-ID"
+	AdminHeader  = "X-Admin-Key"
+
+	scopeKey = "scope"
+)
+
+// Tenant resolves the tenant from `Authorization: Bearer <api key>` or, when allowHeader is set for
+// a trusted gateway, from the X-Tenant-ID header (ID or slug) alone. An optional branch is read from X-Branch-ID or `branch_id`.
+// A request authenticated with an API key can never address another tenant.
+func Tenant(tenants service.TenantService, allowHeader bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var tenant model.Tenant
+		var err error
+
+		headerValue := strings.TrimSpace(ctx.GetHeader(TenantHeader))
+		apiKey, hasKey := bearerToken(ctx)
+
+		switch {
+		case hasKey:
+			tenant, err = tenants.ResolveAPIKey(apiKey)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+				return
+			}
+			if headerValue != "" && headerValue != tenant.Slug && headerValue != strconv.FormatUint(uint64(tenant.ID), 10) {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key does not belong to the requested tenant"})
+				return
+			}
+		case headerValue != "" && allowHeader:
+			tenant, err = tenants.ResolveTenant(headerValue)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+				return
+			}
+		case allowHeader:
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Tenant is required, use an API key or the " + TenantHeader + " header"})
+			return
+		default:
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key required"})
+			return
+		}
+
+		scope := model.Scope{TenantID: tenant.ID}
+
+		branchValue := ctx.GetHeader(BranchHeader)
+		if branchValue == "" {
+			branchValue = ctx.Query("branch_id")
+		}
+		if branchValue != "" {
+			branchID, err := strconv.ParseUint(branchValue, 10, 64)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid branch ID format"})
+				return
+			}
+			// Branch of another tenant is reported the same way as a missing one
+			if _, err := tenants.FindBranch(tenant.ID, uint(branchID)); err != nil {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Branch not found"})
+				return
+			}
+			scope.BranchID = uint(branchID)
+		}
+
+		ctx.Set(scopeKey, scope)
+		ctx.Next()
+	}
+}
+
+// Scope returns the tenant scope resolved by the Tenant middleware, with the locale negotiated by Locale
+func Scope(ctx *gin.Context) model.Scope {
+	var scope model.Scope
+	if value, ok := ctx.Get(scopeKey); ok {
+		scope, _ = value.(model.Scope)
+	}
+	scope.Locale = ctx.GetString(localeKey)
+	return scope
+}
+
+// Admin protects platform endpoints with a static key, they are disabled when the key is empty
+func Admin(adminKey string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		provided := ctx.GetHeader(AdminHeader)
+		if adminKey == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(adminKey)) != 1 {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Admin key required"})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func bearerToken(ctx *gin.Context) (string, bool) {
+	header := ctx.GetHeader("Authorization")
+	token, found := strings.CutPrefix(header, "Bearer ")
+	token = strings.TrimSpace(token)
+	return token, found && token != ""
+}
@@ -1,31 +1,196 @@
 package model
 
+import "time"
+
+// MaxPreferenceLength is the longest preference or session message accepted, in characters
+const MaxPreferenceLength = 500
+
 // RecommendationRequest stores parameter for AI recommendation request
 type RecommendationRequest struct {
-	Preference string `json:"preference" binding:"required"`
+	Preference string               `json:"preference" binding:"required,max=500"`
+	Locale     string               `json:"-"` // language of the reasons, taken from the request scope
+	History    []RecommendationTurn `json:"-"` // earlier turns of a recommendation session, oldest first
 }
 
 // RecommendationResponseRaw is a helper to catch AI response (token saving and more accurate)
 type RecommendationResponseRaw struct {
-	MenuName string `json:"menu_name"`
-	Reason   string `json:"reason"`
+	MenuID     uint    `json:"menu_id"`
+	MenuName   string  `json:"menu_name,omitempty"` // only used when the ID is missing or unknown
+	Reason     string  `json:"reason"`
+	Confidence float64 `json:"confidence"` // 0 to 1
 }
 
+// Where a recommendation comes from
+const (
+	RecommendationSourceAI       = "ai"
+	RecommendationSourceFallback = "fallback" // deterministic ranking when the AI gave nothing usable
+)
+
 // MenuResponse used for the AI recommendation response
 type RecommendationResponse struct {
-	Menu   MenuResponse `json:"menu"`
-	Reason string       `json:"reason"`
+	Menu       MenuResponse `json:"menu"`
+	Reason     string       `json:"reason"`
+	Confidence float64      `json:"confidence"`
+	Source     string       `json:"source"`
 }
 
 type RecommendationListResponse struct {
 	Data []RecommendationResponse `json:"data"`
 }
 
+// Tones of a generated description
+const (
+	ToneCasual  = "casual"
+	TonePremium = "premium" // the default
+	TonePlayful = "playful"
+)
+
+// Defaults and limits of description generation
+const (
+	DefaultDescriptionWords  = 20
+	MaxDescriptionCandidates = 5
+)
+
+// DescriptionOptions shape a generated description, zero values use the defaults
+type DescriptionOptions struct {
+	Tone     string `json:"tone" binding:"omitempty,oneof=casual premium playful" example:"premium"`
+	MaxWords int    `json:"max_words" binding:"omitempty,min=5,max=80" example:"20"`
+	Language string `json:"language" example:"en"` // locale tag, English by default
+	Audience string `json:"audience" binding:"omitempty,max=100" example:"office workers on a lunch break"`
+}
+
+// WithDefaults fills the options left empty
+func (o DescriptionOptions) WithDefaults() DescriptionOptions {
+	if o.Tone == "" {
+		o.Tone = TonePremium
+	}
+	if o.MaxWords <= 0 {
+		o.MaxWords = DefaultDescriptionWords
+	}
+	o.Language = NormalizeLocale(o.Language)
+	if o.Language == "" {
+		o.Language = "en"
+	}
+	return o
+}
+
 type GenerateDescriptionRequest struct {
-	Name        string   `json:"name" binding:"required"`
-	Ingredients []string `json:"ingredients" binding:"required"`
+	Name        string   `json:"name" binding:"required,max=100"`
+	Ingredients []string `json:"ingredients" binding:"required,max=30,dive,max=100"`
+	DescriptionOptions
+	Candidates int `json:"candidates" binding:"omitempty,min=1,max=5" example:"3"` // alternatives to pick from, 1 by default
 }
 
+// Where a generated description comes from
+const (
+	DescriptionSourceAI       = "ai"
+	DescriptionSourceFallback = "fallback" // rule-based, the AI provider is unavailable
+)
+
 type GenerateDescriptionResponse struct {
-	Description string `json:"generated_description"`
+	Description string   `json:"generated_description"` // the first candidate
+	Candidates  []string `json:"candidates"`
+	Source      string   `json:"source" example:"ai"`
+}
+
+// ApplyDescriptionRequest saves the chosen candidate, it may have been edited
+type ApplyDescriptionRequest struct {
+	Description string `json:"description" binding:"required,max=1000"`
+}
+
+// AIUsage counts the tokens of an AI call as reported by the provider, the offline
+// provider estimates them from the number of words
+type AIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// AICacheEntry is an AI answer kept by the database cache. The key starts with the
+// method and, for recommendations, the tenant so their entries can be invalidated together.
+type AICacheEntry struct {
+	Key       string    `gorm:"column:cache_key;primaryKey;size:160"`
+	Value     []byte    `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// AICacheStats counts the cache lookups since the server started
+type AICacheStats struct {
+	Entries       int                           `json:"entries"` // in memory
+	Hits          int64                         `json:"hits"`
+	Misses        int64                         `json:"misses"`
+	HitRate       float64                       `json:"hit_rate"`
+	Invalidations int64                         `json:"invalidations"` // recommendation entries dropped after menu changes
+	Methods       map[string]AICacheMethodStats `json:"methods"`
+}
+
+type AICacheMethodStats struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// Events of the streaming endpoints, sent as Server-Sent Events
+const (
+	StreamEventToken          = "token"          // StreamToken, a piece of the generated text
+	StreamEventRecommendation = "recommendation" // RecommendationResponse, as soon as it is parsed
+	StreamEventDone           = "done"           // DescriptionStreamDone or RecommendationStreamDone
+	StreamEventError          = "error"          // ErrorResponse, the stream ends after it
+)
+
+type StreamToken struct {
+	Text string `json:"text"`
+}
+
+type DescriptionStreamDone struct {
+	Description string  `json:"generated_description"`
+	Usage       AIUsage `json:"usage"`
+}
+
+type RecommendationStreamDone struct {
+	Count int     `json:"count"`
+	Usage AIUsage `json:"usage"`
+}
+
+// RecommendationSession keeps the turns of a recommendation conversation until ExpiresAt,
+// which moves forward with every message
+type RecommendationSession struct {
+	ID        string               `gorm:"primaryKey;size:32" json:"id"`
+	TenantID  uint                 `gorm:"index;not null" json:"-"`
+	Turns     []RecommendationTurn `gorm:"serializer:json" json:"turns"`
+	ExpiresAt time.Time            `gorm:"index" json:"expires_at"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// RecommendationTurn is one message of the customer and the menus suggested for it
+type RecommendationTurn struct {
+	Message     string          `json:"message"`
+	Suggestions []SuggestedMenu `json:"suggestions"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// SuggestedMenu remembers what was suggested, as the customer saw it
+type SuggestedMenu struct {
+	MenuID uint    `json:"menu_id"`
+	Name   string  `json:"name"`
+	Price  float64 `json:"price"`
+	Reason string  `json:"reason"`
+}
+
+type RecommendationMessageRequest struct {
+	Message string `json:"message" binding:"required,max=500" example:"Something cheaper, without dairy"`
+}
+
+// StartRecommendationSessionRequest may carry the first message of the session
+type StartRecommendationSessionRequest struct {
+	Message string `json:"message" binding:"max=500" example:"I need something to wake me up"`
+}
+
+type RecommendationSessionResponse struct {
+	SessionID       string                   `json:"session_id"`
+	ExpiresAt       time.Time                `json:"expires_at"`
+	Recommendations []RecommendationResponse `json:"recommendations"` // for the latest message
+	Turns           []RecommendationTurn     `json:"turns"`
 }
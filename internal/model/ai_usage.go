@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+// Outcomes of a metered AI call
+const (
+	AIOutcomeOK        = "ok"
+	AIOutcomeError     = "error"
+	AIOutcomeCancelled = "cancelled" // the client went away or the deadline passed
+	AIOutcomeCached    = "cached"    // answered from the cache, the provider was not called
+)
+
+// AIUsageRecord is one AIService call with the tokens reported by the provider. Day is the
+// UTC date of the call, it groups the reports and sums the monthly budgets on any database.
+type AIUsageRecord struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	TenantID         uint      `gorm:"index:idx_ai_usage_tenant_day" json:"tenant_id"` // 0 for calls made without a tenant
+	Endpoint         string    `gorm:"size:128" json:"endpoint"`                       // route or background job that made the call
+	Method           string    `gorm:"size:64" json:"method"`
+	Model            string    `gorm:"size:128" json:"model"`
+	Outcome          string    `gorm:"size:16" json:"outcome"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	LatencyMs        int64     `json:"latency_ms"`
+	Day              string    `gorm:"size:10;index;index:idx_ai_usage_tenant_day" json:"day"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Groupings of the AI usage report
+const (
+	AIUsageByDay      = "day"
+	AIUsageByEndpoint = "endpoint"
+	AIUsageByClient   = "client" // the tenant that made the call
+)
+
+// AIUsageQuery selects the calls of the usage report, dates are UTC and inclusive
+type AIUsageQuery struct {
+	GroupBy  string `form:"group_by" binding:"omitempty,oneof=day endpoint client"` // default "day"
+	From     string `form:"from" binding:"omitempty,datetime=2006-01-02"`           // default the first day of the month
+	To       string `form:"to" binding:"omitempty,datetime=2006-01-02"`             // default today
+	TenantID uint   `form:"tenant_id"`
+	Endpoint string `form:"endpoint"`
+}
+
+// AIUsageBucket sums the calls of one day, endpoint or client
+type AIUsageBucket struct {
+	Key              string  `json:"key" example:"2026-10-18"`
+	Calls            int64   `json:"calls"`
+	Errors           int64   `json:"errors"`
+	Cached           int64   `json:"cached"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	AvgLatencyMs     float64 `json:"avg_latency_ms"`
+	Cost             float64 `json:"cost" gorm:"-"` // estimated from AI_PRICE_PROMPT and AI_PRICE_COMPLETION
+}
+
+// AIBudgetStatus is the use of a monthly token budget, a limit of 0 is unlimited
+type AIBudgetStatus struct {
+	TenantID   uint   `json:"tenant_id,omitempty"` // empty for the platform budget
+	Month      string `json:"month" example:"2026-10"`
+	TokenLimit int64  `json:"token_limit"`
+	TokensUsed int64  `json:"tokens_used"`
+	Exceeded   bool   `json:"exceeded"`
+}
+
+type AIUsageReport struct {
+	GroupBy string           `json:"group_by"`
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Total   AIUsageBucket    `json:"total"`
+	Buckets []AIUsageBucket  `json:"buckets"`
+	Budgets []AIBudgetStatus `json:"budgets"` // the platform, and the tenant when tenant_id is given
+}
+
+type AIUsageReportResponse struct {
+	Data AIUsageReport `json:"data"`
+}
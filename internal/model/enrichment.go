@@ -0,0 +1,115 @@
+package model
+
+import "time"
+
+// DietaryTags are the tag slugs an enrichment may suggest, applying one creates the tag
+// when the tenant does not have it yet
+var DietaryTags = map[string]string{
+	"vegetarian":  "Vegetarian",
+	"vegan":       "Vegan",
+	"gluten-free": "Gluten Free",
+	"dairy-free":  "Dairy Free",
+	"halal":       "Halal",
+	"spicy":       "Spicy",
+}
+
+// Allergens are the major food allergens an enrichment may suggest
+var Allergens = []string{
+	"gluten", "crustaceans", "eggs", "fish", "peanuts", "soy", "milk",
+	"tree-nuts", "celery", "mustard", "sesame", "sulphites", "lupin", "molluscs",
+}
+
+// Where an enrichment suggestion comes from
+const (
+	EnrichmentSourceAI       = "ai"
+	EnrichmentSourceFallback = "fallback" // ingredient rules, the AI provider is unavailable
+)
+
+// DefaultEnrichmentConfidence is the least confidence of the suggestions applied when
+// no other is asked for
+const DefaultEnrichmentConfidence = 0.6
+
+// Fields of a menu an enrichment fills
+const (
+	EnrichCategory    = "category"
+	EnrichCalories    = "calories"
+	EnrichDietaryTags = "dietary_tags"
+	EnrichAllergens   = "allergens"
+)
+
+// EnrichmentRequest describes a menu that may not exist yet
+type EnrichmentRequest struct {
+	Name        string   `json:"name" binding:"required,max=100" example:"Kopi Susu"`
+	Ingredients []string `json:"ingredients" binding:"max=30,dive,max=100" example:"espresso,milk,palm sugar"`
+	Description string   `json:"description" binding:"max=1000"`
+}
+
+// EnrichmentSuggestion is what the AI suggests for a menu, every confidence is from 0 to 1.
+// The category is one of the categories the tenant already uses, when it has any.
+type EnrichmentSuggestion struct {
+	Category           string           `json:"category" example:"drinks"`
+	CategoryConfidence float64          `json:"category_confidence" example:"0.9"`
+	Calories           int              `json:"calories" example:"180"` // 0 when unknown
+	CaloriesConfidence float64          `json:"calories_confidence" example:"0.5"`
+	DietaryTags        []SuggestedLabel `json:"dietary_tags"`
+	Allergens          []SuggestedLabel `json:"allergens"`
+	Source             string           `json:"source,omitempty" example:"ai"`
+}
+
+// SuggestedLabel is a dietary tag slug or an allergen
+type SuggestedLabel struct {
+	Name       string  `json:"name" example:"milk"`
+	Confidence float64 `json:"confidence" example:"0.9"`
+}
+
+// Status of an enrichment proposal
+const (
+	ProposalStatusPending    = "pending"
+	ProposalStatusApplied    = "applied"
+	ProposalStatusRejected   = "rejected"
+	ProposalStatusSuperseded = "superseded" // a newer proposal was made for the menu
+)
+
+// EnrichmentProposal keeps a suggestion for an existing menu until staff apply or reject it
+type EnrichmentProposal struct {
+	ID            uint                 `gorm:"primaryKey" json:"id"`
+	TenantID      uint                 `gorm:"index:idx_enrichment_proposals_tenant_status;not null" json:"-"`
+	MenuID        uint                 `gorm:"index;not null" json:"menu_id"`
+	Status        string               `gorm:"size:16;index:idx_enrichment_proposals_tenant_status" json:"status"`
+	Suggestion    EnrichmentSuggestion `gorm:"serializer:json" json:"suggestion"`
+	AppliedFields []string             `gorm:"serializer:json" json:"applied_fields,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+}
+
+// GenerateProposalsRequest picks the menus to make proposals for, the menus without a
+// category or calories when MenuIDs is empty
+type GenerateProposalsRequest struct {
+	MenuIDs []uint `json:"menu_ids" binding:"max=100" example:"1,2"`
+	Limit   int    `json:"limit" binding:"omitempty,min=1,max=100" example:"20"` // default 20
+}
+
+// ApplyProposalRequest applies the fields given, every field by default, leaving out the
+// suggestions less confident than MinConfidence
+type ApplyProposalRequest struct {
+	Fields        []string `json:"fields" binding:"omitempty,dive,oneof=category calories dietary_tags allergens" example:"category,allergens"`
+	MinConfidence *float64 `json:"min_confidence" binding:"omitempty,min=0,max=1" example:"0.6"` // default 0.6
+}
+
+type EnrichmentSuggestionResponse struct {
+	Data EnrichmentSuggestion `json:"data"`
+}
+
+type EnrichmentProposalResponse struct {
+	Data EnrichmentProposal `json:"data"`
+}
+
+type EnrichmentProposalListResponse struct {
+	Data []EnrichmentProposal `json:"data"`
+}
+
+// AppliedProposalResponse is the proposal once applied, with the menu it changed
+type AppliedProposalResponse struct {
+	Data EnrichmentProposal `json:"data"`
+	Menu MenuResponse       `json:"menu"`
+}
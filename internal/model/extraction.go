@@ -0,0 +1,48 @@
+package model
+
+// MaxExtractedMenus bounds the items read from one paper menu, it is also the most menus
+// imported at once
+const MaxExtractedMenus = 200
+
+// MenuDocument is a photo or PDF of a paper menu
+type MenuDocument struct {
+	Data        []byte
+	ContentType string // detected from the content, see service.SniffMenuDocument
+}
+
+// MenuDraft is a menu read from a paper menu or sent to the bulk import, nothing is
+// saved until it is imported
+type MenuDraft struct {
+	Name        string   `json:"name" binding:"required,max=100" example:"Nasi Goreng"`
+	Price       float64  `json:"price" binding:"min=0" example:"25000"` // 0 when the price cannot be read
+	Category    string   `json:"category" binding:"max=50" example:"food"`
+	Ingredients []string `json:"ingredients" binding:"max=30,dive,max=100" example:"rice,egg,sambal"` // guessed from the name
+	Description string   `json:"description" binding:"max=1000"`
+	// Confidence is how well the AI could read the item, from 0 to 1, ignored on import
+	Confidence float64 `json:"confidence,omitempty" example:"0.9"`
+}
+
+// ToMenu converts a reviewed draft into the menu to create
+func (d MenuDraft) ToMenu() Menu {
+	return Menu{
+		Name:        d.Name,
+		Price:       d.Price,
+		Category:    d.Category,
+		Ingredients: d.Ingredients,
+		Description: d.Description,
+	}
+}
+
+// MenuImportRequest creates many menus at once, all of them or none
+type MenuImportRequest struct {
+	Menus []MenuDraft `json:"menus" binding:"required,min=1,max=200,dive"`
+}
+
+type MenuExtractionResponse struct {
+	Data []MenuDraft `json:"data"`
+}
+
+type MenuImportResponse struct {
+	Message string `json:"message"`
+	Data    []Menu `json:"data"`
+}
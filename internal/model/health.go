@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// States of the circuit breaker in front of the AI provider
+const (
+	BreakerClosed   = "closed"    // calls go through
+	BreakerOpen     = "open"      // calls fail at once and the degraded answers are served
+	BreakerHalfOpen = "half_open" // one probe call decides whether it closes again
+)
+
+type AIBreakerStatus struct {
+	State               string     `json:"state" example:"closed"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	ProbeAt             *time.Time `json:"probe_at,omitempty"` // when an open breaker lets a probe through
+	Opens               int64      `json:"opens"`              // since the server started
+}
+
+// Health of the server and of what it depends on
+const (
+	HealthOK          = "ok"
+	HealthDegraded    = "degraded" // the AI provider is unavailable, AI endpoints answer with fallbacks
+	HealthUnavailable = "unavailable"
+)
+
+type HealthResponse struct {
+	Status   string          `json:"status" example:"ok"`
+	Database string          `json:"database" example:"ok"`
+	AI       AIBreakerStatus `json:"ai"`
+}
@@ -0,0 +1,50 @@
+package model
+
+// Image variants generated for every upload
+const (
+	ImageVariantOriginal      = "original"
+	ImageVariantLarge         = "large"          // WebP or JPEG, whichever is smaller, longest side 1280px
+	ImageVariantThumbnail     = "thumbnail"      // JPEG, longest side 320px
+	ImageVariantThumbnailWebP = "thumbnail_webp" // WebP, longest side 320px, only when smaller than the JPEG
+)
+
+// MenuImage stores the uploaded photo of a menu, keyed by variant
+type MenuImage struct {
+	Variants map[string]ImageVariant `json:"variants"`
+}
+
+type ImageVariant struct {
+	Key         string `json:"key"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int    `json:"size"`
+}
+
+// MenuImageResponse exposes the image URLs of a menu
+type MenuImageResponse struct {
+	URL              string `json:"url"`
+	LargeURL         string `json:"large_url,omitempty"`
+	ThumbnailURL     string `json:"thumbnail_url,omitempty"`
+	ThumbnailWebPURL string `json:"thumbnail_webp_url,omitempty"`
+	Width            int    `json:"width"`
+	Height           int    `json:"height"`
+}
+
+// ToResponse converts stored variants into public URLs
+func (i *MenuImage) ToResponse() *MenuImageResponse {
+	if i == nil || len(i.Variants) == 0 {
+		return nil
+	}
+
+	original := i.Variants[ImageVariantOriginal]
+	return &MenuImageResponse{
+		URL:              original.URL,
+		LargeURL:         i.Variants[ImageVariantLarge].URL,
+		ThumbnailURL:     i.Variants[ImageVariantThumbnail].URL,
+		ThumbnailWebPURL: i.Variants[ImageVariantThumbnailWebP].URL,
+		Width:            original.Width,
+		Height:           original.Height,
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Job status
+const (
+	JobStatusQueued  = "queued"  // waiting for RunAt
+	JobStatusRunning = "running" // claimed by a worker until LockedUntil
+	JobStatusDone    = "done"
+	JobStatusFailed  = "failed" // every attempt failed
+)
+
+// Job is a unit of background work kept in the database, so it survives restarts
+// and a job left running by a crashed worker is picked up again once its lease ends
+type Job struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	TenantID    uint       `gorm:"index;not null" json:"-"`
+	Type        string     `gorm:"size:64;index:idx_jobs_type_menu" json:"type"`
+	MenuID      uint       `gorm:"index:idx_jobs_type_menu" json:"menu_id"`
+	Status      string     `gorm:"size:16;index:idx_jobs_due" json:"status"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	RunAt       time.Time  `gorm:"index:idx_jobs_due" json:"run_at"` // next attempt
+	LockedUntil *time.Time `json:"-"`
+	LastError   string     `json:"last_error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type JobResponse struct {
+	Data Job `json:"data"`
+}
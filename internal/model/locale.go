@@ -0,0 +1,45 @@
+package model
+
+import "strings"
+
+var languageNames = map[string]string{
+	"en": "English",
+	"id": "Indonesian",
+	"ms": "Malay",
+	"ja": "Japanese",
+	"zh": "Chinese",
+	"ko": "Korean",
+}
+
+// NormalizeLocale turns "id_ID" or "ID-id" into "id-id"
+func NormalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
+
+// LocaleFallbacks returns the lookup order for a locale, e.g. "id-id" then "id"
+func LocaleFallbacks(locale string) []string {
+	locale = NormalizeLocale(locale)
+	if locale == "" {
+		return nil
+	}
+
+	fallbacks := []string{locale}
+	if base, _, found := strings.Cut(locale, "-"); found && base != "" {
+		fallbacks = append(fallbacks, base)
+	}
+	return fallbacks
+}
+
+// LanguageName returns the English name of a locale for AI prompts
+func LanguageName(locale string) string {
+	fallbacks := LocaleFallbacks(locale)
+	for _, l := range fallbacks {
+		if name, ok := languageNames[l]; ok {
+			return name
+		}
+	}
+	if len(fallbacks) == 0 {
+		return "English"
+	}
+	return fallbacks[0]
+}
@@ -1,62 +1,148 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
+
+// Availability status of a menu
+const (
+	AvailabilityAvailable = "available"
+	AvailabilitySoldOut   = "sold_out"
+)
+
+// Description status of a menu
+const (
+	DescriptionStatusReady    = "ready"    // written by hand or generated
+	DescriptionStatusPending  = "pending"  // waiting for a generation job
+	DescriptionStatusFallback = "fallback" // placeholder after generation failed, retried by the sweep
+)
 
 // Menu represents database entity
 type Menu struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	Name        string    `json:"name"`
-	Category    string    `json:"category"`
-	Calories    int       `json:"calories"`
-	Price       float64   `json:"price"`
-	Ingredients []string  `gorm:"serializer:json" json:"ingredients"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-}
-
-// MenuResponse used for the API response
-type MenuResponse struct {
-	ID          uint     `json:"id"`
+	ID          uint     `gorm:"primaryKey" json:"id"`
+	TenantID    uint     `gorm:"index;not null;default:1" json:"-"`
 	Name        string   `json:"name"`
 	Category    string   `json:"category"`
 	Calories    int      `json:"calories"`
 	Price       float64  `json:"price"`
-	Ingredients []string `json:"ingredients"`
+	Ingredients []string `gorm:"serializer:json" json:"ingredients"`
+	Allergens   []string `gorm:"serializer:json" json:"allergens"` // see model.Allergens
 	Description string   `json:"description"`
+	// DescriptionStatus is managed by the service, it is ignored on input
+	DescriptionStatus string     `gorm:"default:ready;index" json:"description_status"`
+	DescriptionSweeps int        `gorm:"default:0" json:"-"` // generations queued by the sweep, which gives up after a few
+	Availability      string     `gorm:"default:available;index" json:"availability"`
+	Stock             *int       `json:"stock"`       // nil means stock is not tracked
+	DailyStock        *int       `json:"daily_stock"` // stock restored by the daily reset
+	Image             *MenuImage `gorm:"serializer:json" json:"image"`
+	Tags              []Tag      `gorm:"many2many:menu_tags" json:"tags"` // assigned through PUT /menu/{id}/tags
+	// The rating of the approved reviews and their summary are managed by the review
+	// service, they are ignored on input
+	RatingAverage float64        `gorm:"default:0;index" json:"rating_average"`
+	RatingCount   int            `gorm:"default:0" json:"rating_count"`
+	ReviewSummary *ReviewSummary `gorm:"serializer:json" json:"review_summary"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// IsSoldOut reports whether the menu cannot be ordered right now
+func (m *Menu) IsSoldOut() bool {
+	if m.Availability == AvailabilitySoldOut {
+		return true
+	}
+	return m.Stock != nil && *m.Stock <= 0
+}
+
+// MenuResponse used for the API response
+type MenuResponse struct {
+	ID                uint               `json:"id"`
+	Name              string             `json:"name"`
+	Category          string             `json:"category"`
+	Calories          int                `json:"calories"`
+	Price             float64            `json:"price"`
+	Ingredients       []string           `json:"ingredients"`
+	Allergens         []string           `json:"allergens"`
+	Description       string             `json:"description"`
+	DescriptionStatus string             `json:"description_status"`
+	Availability      string             `json:"availability"`
+	Stock             *int               `json:"stock,omitempty"`
+	SoldOut           bool               `json:"sold_out"`
+	Locale            string             `json:"locale,omitempty"` // set when name and description are translated
+	Image             *MenuImageResponse `json:"image,omitempty"`
+	Tags              []Tag              `json:"tags"`
+	RatingAverage     float64            `json:"rating_average"`
+	RatingCount       int                `json:"rating_count"`
+	ReviewSummary     *ReviewSummary     `json:"review_summary,omitempty"`
+	Score             float64            `json:"score,omitempty"` // similarity, only set by semantic search and similar menus
 }
 
 // Helper method to convert Model to Response
 func (m *Menu) ToResponse() MenuResponse {
+	tags := m.Tags
+	if tags == nil {
+		tags = []Tag{}
+	}
+	allergens := m.Allergens
+	if allergens == nil {
+		allergens = []string{}
+	}
 	return MenuResponse{
-		ID:          m.ID,
-		Name:        m.Name,
-		Category:    m.Category,
-		Calories:    m.Calories,
-		Price:       m.Price,
-		Ingredients: m.Ingredients,
-		Description: m.Description,
+		ID:                m.ID,
+		Name:              m.Name,
+		Category:          m.Category,
+		Calories:          m.Calories,
+		Price:             m.Price,
+		Ingredients:       m.Ingredients,
+		Allergens:         allergens,
+		Description:       m.Description,
+		DescriptionStatus: m.DescriptionStatus,
+		Availability:      m.Availability,
+		Stock:             m.Stock,
+		SoldOut:           m.IsSoldOut(),
+		Image:             m.Image.ToResponse(),
+		Tags:              tags,
+		RatingAverage:     m.RatingAverage,
+		RatingCount:       m.RatingCount,
+		ReviewSummary:     m.ReviewSummary,
 	}
 }
 
 type MenuSuccessResponse struct {
-	Message string `json:"message"`
-	Data    Menu   `json:"data"`
+	Message    string                `json:"message"`
+	Data       Menu                  `json:"data"`
+	Enrichment *EnrichmentSuggestion `json:"enrichment,omitempty"` // only with enrich=true
 }
 
 type MenuDetailResponse struct {
 	Data MenuResponse `json:"data"`
 }
 
+type MenuListResponse struct {
+	Data []MenuResponse `json:"data"`
+}
+
+// Search modes of GET /menu/search
+const (
+	SearchModeLexical  = "lexical"  // words of the query found in the name or description
+	SearchModeSemantic = "semantic" // closest meaning, through menu embeddings
+)
+
 type MenuQueryRequest struct {
-	Q        string  `form:"q"`
-	Category string  `form:"category"`
-	MinPrice float64 `form:"min_price"`
-	MaxPrice float64 `form:"max_price"`
-	MaxCal   int     `form:"max_cal"`
-	Sort     string  `form:"sort"`
-	Page     int     `form:"page,default=1"`
-	PerPage  int     `form:"per_page,default=10"`
+	Q           string  `form:"q"`
+	Category    string  `form:"category"`
+	MinPrice    float64 `form:"min_price"`
+	MaxPrice    float64 `form:"max_price"`
+	MaxCal      int     `form:"max_cal"`
+	MinRating   float64 `form:"min_rating" binding:"omitempty,min=0,max=5"`
+	HideSoldOut bool    `form:"hide_sold_out"`
+	Tags        string  `form:"tags"`                                            // comma separated tag slugs
+	TagsMode    string  `form:"tags_mode" binding:"omitempty,oneof=and or"`      // default "or"
+	Facets      *bool   `form:"facets"`                                          // default true
+	Mode        string  `form:"mode" binding:"omitempty,oneof=lexical semantic"` // search only, default "lexical"
+	Sort        string  `form:"sort"`
+	Page        int     `form:"page,default=1"`
+	PerPage     int     `form:"per_page,default=10"`
 }
 
 // MenuFilter stores search paramter from query param
@@ -69,6 +155,44 @@ type MenuFilter struct {
 	Sort     string
 	Page     int
 	PerPage  int
+
+	AvailableOnly bool     // exclude sold-out menus
+	MinRating     float64  // average rating of the approved reviews, unrated menus are excluded
+	Tags          []string // tag slugs
+	TagsMode      string   // TagMatchAny or TagMatchAll
+	WithFacets    bool
+	IDs           []uint // restricts the result to these menus, e.g. semantic search matches
+}
+
+// StockAdjustmentRequest stores quantity for decrement and restock endpoints
+type StockAdjustmentRequest struct {
+	Quantity int `json:"quantity" binding:"required,min=1" example:"1"`
+}
+
+// UpdateMenuRequest replaces a menu with PUT /menu/{id}. Availability, stock and daily_stock
+// are changed by their own endpoints and keep their current values when absent, a null
+// stock or daily_stock stops tracking it.
+type UpdateMenuRequest struct {
+	Menu
+	Availability *string     `json:"availability"`
+	Stock        OptionalInt `json:"stock" swaggertype:"integer"`
+	DailyStock   OptionalInt `json:"daily_stock" swaggertype:"integer"`
+}
+
+// OptionalInt tells an absent JSON field from null, Set is true when the field was sent
+type OptionalInt struct {
+	Set   bool
+	Value *int
+}
+
+func (o *OptionalInt) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+// AvailabilityRequest stores manual availability change from staff
+type AvailabilityRequest struct {
+	Availability string `json:"availability" binding:"required,oneof=available sold_out" example:"sold_out"`
 }
 
 // MenuPaginationResponse helper for output
@@ -78,4 +202,5 @@ type MenuPaginationResponse struct {
 	PerPage    int            `json:"per_page"`
 	TotalPages int            `json:"total_pages"`
 	Data       []MenuResponse `json:"data"`
+	Facets     *MenuFacets    `json:"facets,omitempty"`
 }
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// Where a prompt template version comes from
+const (
+	PromptSourceFile     = "file"     // version 0, shipped with the server or read from PROMPT_TEMPLATE_DIR
+	PromptSourceDatabase = "database" // versions 1 and up, created through the admin API
+)
+
+// PromptTemplate is a version of a named text/template prompt. Version 0 is the file
+// template, at most one database version per name is active and replaces it.
+type PromptTemplate struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	Name      string    `gorm:"size:64;not null;uniqueIndex:idx_prompt_templates_version" json:"name"`
+	Version   int       `gorm:"not null;uniqueIndex:idx_prompt_templates_version" json:"version"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
+	Note      string    `gorm:"size:200" json:"note,omitempty"`
+	Active    bool      `gorm:"not null;default:false" json:"active"`
+	Source    string    `gorm:"-" json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PromptTemplateSummary describes a named prompt and its active version
+type PromptTemplateSummary struct {
+	Name          string `json:"name"`
+	ActiveVersion int    `json:"active_version"`
+	LatestVersion int    `json:"latest_version"`
+}
+
+type CreatePromptVersionRequest struct {
+	Body string `json:"body" binding:"required,max=20000"`
+	Note string `json:"note" binding:"max=200" example:"Shorter, more playful copy"`
+}
+
+// PromptSampleMenu is the menu a preview is rendered for, empty fields use a built-in sample
+type PromptSampleMenu struct {
+	Name        string   `json:"name" example:"Nasi Goreng"`
+	Description string   `json:"description" example:"Fried rice with a fried egg"`
+	Category    string   `json:"category" example:"food"`
+	Ingredients []string `json:"ingredients" example:"rice,egg,sambal"`
+}
+
+// PromptPreviewRequest renders Body when set, else Version, else the active version
+type PromptPreviewRequest struct {
+	Version *int             `json:"version" example:"2"`
+	Body    string           `json:"body" binding:"max=20000"`
+	Menu    PromptSampleMenu `json:"menu"`
+}
+
+type PromptPreview struct {
+	Name    string `json:"name"`
+	Version *int   `json:"version,omitempty"` // empty for a body not saved yet
+	Prompt  string `json:"prompt"`
+}
+
+type PromptTemplateListResponse struct {
+	Data []PromptTemplateSummary `json:"data"`
+}
+
+type PromptVersionListResponse struct {
+	Data []PromptTemplate `json:"data"`
+}
+
+type PromptTemplateResponse struct {
+	Data PromptTemplate `json:"data"`
+}
+
+type PromptPreviewResponse struct {
+	Data PromptPreview `json:"data"`
+}
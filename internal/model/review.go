@@ -0,0 +1,59 @@
+package model
+
+import "time"
+
+// Moderation status of a review, only approved reviews are shown to diners and counted
+// in the rating of the menu
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// Review is the feedback of a diner on one menu
+type Review struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  uint      `gorm:"index:idx_reviews_tenant_menu_status;not null" json:"-"`
+	MenuID    uint      `gorm:"index:idx_reviews_tenant_menu_status;not null" json:"menu_id"`
+	Rating    int       `gorm:"not null" json:"rating"`
+	Text      string    `json:"text"`
+	Author    string    `gorm:"size:100" json:"author"`
+	Status    string    `gorm:"size:16;index:idx_reviews_tenant_menu_status" json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReviewRequest is a review written by a diner, it waits for moderation
+type ReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5" example:"5"`
+	Text   string `json:"text" binding:"max=2000" example:"Smoky and just spicy enough"`
+	Author string `json:"author" binding:"max=100" example:"Ayu"` // "Anonymous" when empty
+}
+
+// ModerateReviewRequest approves or rejects a review, a moderated review can be
+// moderated again
+type ModerateReviewRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected" example:"approved"`
+}
+
+// ReviewSummary is a short blurb of what the approved reviews of a menu praise and
+// criticize, written by the AI
+type ReviewSummary struct {
+	Summary     string    `json:"summary" example:"Loved for its smoky flavor, some find it too oily."`
+	Pros        []string  `json:"pros" example:"smoky flavor,generous portion"`
+	Cons        []string  `json:"cons" example:"oily"`
+	ReviewCount int       `json:"review_count" example:"12"` // reviews summarized
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+type ReviewResponse struct {
+	Data Review `json:"data"`
+}
+
+type ReviewListResponse struct {
+	Data []Review `json:"data"`
+}
+
+type ReviewSummaryResponse struct {
+	Data ReviewSummary `json:"data"`
+}
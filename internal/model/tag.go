@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// Tag is a cross-cutting label such as "spicy" or "chef's pick", assigned to many menus
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  uint      `gorm:"uniqueIndex:idx_tenant_tag_slug;not null" json:"-"`
+	Name      string    `gorm:"not null" json:"name"`
+	Slug      string    `gorm:"uniqueIndex:idx_tenant_tag_slug;not null" json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TagWithCount is a tag along with the number of menus using it
+type TagWithCount struct {
+	Tag
+	MenuCount int64 `json:"menu_count"`
+}
+
+// TagRequest creates or renames a tag, the slug is derived from the name when empty
+type TagRequest struct {
+	Name string `json:"name" binding:"required" example:"Chef's Pick"`
+	Slug string `json:"slug" example:"chefs-pick"`
+}
+
+// MenuTagsRequest replaces every tag of a menu
+type MenuTagsRequest struct {
+	Tags []string `json:"tags" binding:"required" example:"spicy,chefs-pick"`
+}
+
+// Tag filter modes of `tags_mode=`
+const (
+	TagMatchAny = "or"  // menus having at least one of the tags
+	TagMatchAll = "and" // menus having every tag
+)
+
+// MenuFacets counts the menus matching the current filters, per filter value.
+// Every group ignores its own filter so the sidebar keeps showing the alternatives.
+type MenuFacets struct {
+	Categories   []FacetCount    `json:"categories"`
+	Tags         []TagFacetCount `json:"tags"`
+	PriceBuckets []PriceBucket   `json:"price_buckets"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type TagFacetCount struct {
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// PriceBucket counts menus priced in [Min, Max)
+type PriceBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int64   `json:"count"`
+}
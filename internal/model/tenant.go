@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// DefaultTenantID owns menus created before multi-tenancy was introduced
+const DefaultTenantID uint = 1
+
+// Tenant represents a restaurant owning its own menu catalog
+type Tenant struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Name       string    `json:"name"`
+	Slug       string    `gorm:"uniqueIndex" json:"slug"`
+	APIKeyHash string    `gorm:"uniqueIndex" json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Branch represents an outlet of a tenant sharing the tenant base menu
+type Branch struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  uint      `gorm:"index;not null" json:"tenant_id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BranchMenuOverride replaces price and/or availability of a base menu for a single branch
+type BranchMenuOverride struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	TenantID     uint      `gorm:"index;not null" json:"-"`
+	BranchID     uint      `gorm:"uniqueIndex:idx_branch_menu;not null" json:"branch_id"`
+	MenuID       uint      `gorm:"uniqueIndex:idx_branch_menu;not null" json:"menu_id"`
+	Price        *float64  `json:"price"`
+	Availability *string   `json:"availability"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Scope identifies the tenant a request operates on, and optionally the branch and locale it reads
+type Scope struct {
+	TenantID uint
+	BranchID uint   // 0 means the shared base menu
+	Locale   string // empty means the base menu text
+}
+
+// Base returns the scope without branch overrides and translations, used for writes to the base menu
+func (s Scope) Base() Scope {
+	return Scope{TenantID: s.TenantID}
+}
+
+type CreateTenantRequest struct {
+	Name string `json:"name" binding:"required" example:"Kopi Kenangan"`
+	Slug string `json:"slug" binding:"required" example:"kopi-kenangan"`
+}
+
+// TenantCreatedResponse returns the API key once, it is never retrievable again
+type TenantCreatedResponse struct {
+	Data   Tenant `json:"data"`
+	APIKey string `json:"api_key"`
+}
+
+type CreateBranchRequest struct {
+	Name    string `json:"name" binding:"required" example:"Dago"`
+	Address string `json:"address" example:"Jl. Ir. H. Juanda No. 1, Bandung"`
+}
+
+type BranchOverrideRequest struct {
+	Price        *float64 `json:"price" binding:"omitempty,min=0" example:"27000"`
+	Availability *string  `json:"availability" binding:"omitempty,oneof=available sold_out" example:"sold_out"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// Translation sources
+const (
+	TranslationSourceManual = "manual"
+	TranslationSourceAI     = "ai"
+)
+
+// MenuTranslation stores the name and description of a menu in another locale
+type MenuTranslation struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	TenantID    uint      `gorm:"index;not null" json:"-"`
+	MenuID      uint      `gorm:"uniqueIndex:idx_menu_locale;not null" json:"menu_id"`
+	Locale      string    `gorm:"uniqueIndex:idx_menu_locale;size:16;not null" json:"locale"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Source      string    `json:"source"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type TranslationRequest struct {
+	Name        string `json:"name" binding:"required" example:"Kopi Susu Gula Aren"`
+	Description string `json:"description" example:"Espresso lembut dengan susu segar dan manis gula aren."`
+}
+
+// TranslationItem is exchanged with the AI service when translating menus in bulk
+type TranslationItem struct {
+	MenuID      uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type GenerateTranslationsRequest struct {
+	Locales []string `json:"locales" binding:"required,min=1,dive,required" example:"id"`
+	Limit   int      `json:"limit" example:"100"` // maximum menus translated per locale, default 100
+}
+
+// TranslationBatchResult summarizes bulk translation per locale
+type TranslationBatchResult struct {
+	Locale     string `json:"locale"`
+	Translated int    `json:"translated"`
+	Failed     int    `json:"failed"`
+	Error      string `json:"error,omitempty"`
+}
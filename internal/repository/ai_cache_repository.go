@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"time"
+
+	"atalariq/menu-api/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AICacheRepository interface {
+	// Find ignores expired entries, they behave as if they do not exist
+	Find(key string, now time.Time) (model.AICacheEntry, error)
+	// Save inserts the entry or replaces the one with the same key
+	Save(entry *model.AICacheEntry) error
+	DeletePrefix(prefix string) (int64, error)
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type aiCacheRepository struct {
+	db *gorm.DB
+}
+
+func NewAICacheRepository(db *gorm.DB) AICacheRepository {
+	return &aiCacheRepository{db}
+}
+
+func (r *aiCacheRepository) Find(key string, now time.Time) (model.AICacheEntry, error) {
+	var entry model.AICacheEntry
+	err := r.db.Where("cache_key = ? AND expires_at > ?", key, now).First(&entry).Error
+	return entry, err
+}
+
+func (r *aiCacheRepository) Save(entry *model.AICacheEntry) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cache_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "expires_at", "created_at"}),
+	}).Create(entry).Error
+}
+
+// DeletePrefix expects keys made of letters, digits and colons, so the prefix has no LIKE wildcards
+func (r *aiCacheRepository) DeletePrefix(prefix string) (int64, error) {
+	result := r.db.Where("cache_key LIKE ?", prefix+"%").Delete(&model.AICacheEntry{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *aiCacheRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now).Delete(&model.AICacheEntry{})
+	return result.RowsAffected, result.Error
+}
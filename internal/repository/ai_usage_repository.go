@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"atalariq/menu-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type AIUsageRepository interface {
+	Create(record *model.AIUsageRecord) error
+	// TotalTokens sums the tokens used since the day given, tenant 0 sums every tenant
+	TotalTokens(tenantID uint, since string) (int64, error)
+	// Aggregate groups the calls of the query, query.GroupBy must be set
+	Aggregate(query model.AIUsageQuery) ([]model.AIUsageBucket, error)
+}
+
+type aiUsageRepository struct {
+	db *gorm.DB
+}
+
+func NewAIUsageRepository(db *gorm.DB) AIUsageRepository {
+	return &aiUsageRepository{db}
+}
+
+// aiUsageGroups are the columns of each grouping, cast so that every key scans as text
+var aiUsageGroups = map[string]string{
+	model.AIUsageByDay:      "day",
+	model.AIUsageByEndpoint: "endpoint",
+	model.AIUsageByClient:   "CAST(tenant_id AS TEXT)",
+}
+
+func (r *aiUsageRepository) Create(record *model.AIUsageRecord) error {
+	return r.db.Create(record).Error
+}
+
+func (r *aiUsageRepository) TotalTokens(tenantID uint, since string) (int64, error) {
+	query := r.db.Model(&model.AIUsageRecord{}).Where("day >= ?", since)
+	if tenantID != 0 {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
+	var total int64
+	err := query.Select("COALESCE(SUM(total_tokens), 0)").Scan(&total).Error
+	return total, err
+}
+
+func (r *aiUsageRepository) Aggregate(query model.AIUsageQuery) ([]model.AIUsageBucket, error) {
+	group := aiUsageGroups[query.GroupBy]
+	if group == "" {
+		group = aiUsageGroups[model.AIUsageByDay]
+	}
+
+	db := r.db.Model(&model.AIUsageRecord{})
+	if query.From != "" {
+		db = db.Where("day >= ?", query.From)
+	}
+	if query.To != "" {
+		db = db.Where("day <= ?", query.To)
+	}
+	if query.TenantID != 0 {
+		db = db.Where("tenant_id = ?", query.TenantID)
+	}
+	if query.Endpoint != "" {
+		db = db.Where("endpoint = ?", query.Endpoint)
+	}
+
+	var buckets []model.AIUsageBucket
+	err := db.Select(group+" AS key, COUNT(*) AS calls,"+
+		" SUM(CASE WHEN outcome = ? THEN 1 ELSE 0 END) AS errors,"+
+		" SUM(CASE WHEN outcome = ? THEN 1 ELSE 0 END) AS cached,"+
+		" SUM(prompt_tokens) AS prompt_tokens, SUM(completion_tokens) AS completion_tokens,"+
+		" SUM(total_tokens) AS total_tokens, AVG(latency_ms) AS avg_latency_ms",
+		model.AIOutcomeError, model.AIOutcomeCached).
+		Group(group).Order(group).Scan(&buckets).Error
+	return buckets, err
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"atalariq/menu-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type EnrichmentProposalRepository interface {
+	// Create supersedes the pending proposals of the same menu
+	Create(proposal *model.EnrichmentProposal) error
+	FindByID(tenantID, id uint) (model.EnrichmentProposal, error)
+	// FindAll lists the proposals of the tenant, newest first, an empty status lists every status
+	FindAll(tenantID uint, status string, menuID uint) ([]model.EnrichmentProposal, error)
+	// UpdateStatus moves a pending proposal to status, it reports whether it was still pending
+	UpdateStatus(proposal *model.EnrichmentProposal) (bool, error)
+}
+
+type enrichmentProposalRepository struct {
+	db *gorm.DB
+}
+
+func NewEnrichmentProposalRepository(db *gorm.DB) EnrichmentProposalRepository {
+	return &enrichmentProposalRepository{db}
+}
+
+func (r *enrichmentProposalRepository) Create(proposal *model.EnrichmentProposal) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.EnrichmentProposal{}).
+			Where("tenant_id = ? AND menu_id = ? AND status = ?", proposal.TenantID, proposal.MenuID, model.ProposalStatusPending).
+			Update("status", model.ProposalStatusSuperseded).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(proposal).Error
+	})
+}
+
+func (r *enrichmentProposalRepository) FindByID(tenantID, id uint) (model.EnrichmentProposal, error) {
+	var proposal model.EnrichmentProposal
+	err := r.db.Where("tenant_id = ? AND id = ?", tenantID, id).First(&proposal).Error
+	return proposal, err
+}
+
+func (r *enrichmentProposalRepository) FindAll(tenantID uint, status string, menuID uint) ([]model.EnrichmentProposal, error) {
+	query := r.db.Where("tenant_id = ?", tenantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if menuID != 0 {
+		query = query.Where("menu_id = ?", menuID)
+	}
+	proposals := []model.EnrichmentProposal{}
+	err := query.Order("id desc").Find(&proposals).Error
+	return proposals, err
+}
+
+func (r *enrichmentProposalRepository) UpdateStatus(proposal *model.EnrichmentProposal) (bool, error) {
+	// A struct update, so the applied fields go through their JSON serializer
+	result := r.db.Model(proposal).
+		Where("tenant_id = ? AND status = ?", proposal.TenantID, model.ProposalStatusPending).
+		Select("status", "applied_fields", "updated_at").
+		Updates(proposal)
+	return result.RowsAffected > 0, result.Error
+}
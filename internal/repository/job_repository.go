@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"atalariq/menu-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// claimRetries bounds how often Claim looks for another job after losing one to a concurrent worker
+const claimRetries = 5
+
+// ErrJobLeaseLost is returned by Update when the job is no longer running under the lease of the worker
+var ErrJobLeaseLost = errors.New("job lease lost to another worker")
+
+type JobRepository interface {
+	Create(job *model.Job) error
+	// Claim marks the next due job as running until the lease ends and counts the attempt.
+	// It returns gorm.ErrRecordNotFound when no job is due.
+	Claim(now time.Time, lease time.Duration) (model.Job, error)
+	// Update records the outcome of a run while the job still runs under the lease the worker
+	// claimed it with, leasedUntil. It returns ErrJobLeaseLost once another worker claimed it.
+	Update(job *model.Job, leasedUntil time.Time) error
+	FindLatest(tenantID uint, jobType string, menuID uint) (model.Job, error)
+}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db}
+}
+
+func (r *jobRepository) Create(job *model.Job) error {
+	return r.db.Create(job).Error
+}
+
+// dueCondition matches queued jobs whose time has come and running jobs whose lease ended
+const dueCondition = "((status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?))"
+
+func (r *jobRepository) Claim(now time.Time, lease time.Duration) (model.Job, error) {
+	for range claimRetries {
+		var job model.Job
+		err := r.db.Where(dueCondition, model.JobStatusQueued, now, model.JobStatusRunning, now).
+			Order("run_at, id").First(&job).Error
+		if err != nil {
+			return job, err
+		}
+
+		// The condition is checked again by the update, only one worker wins the job. The lease
+		// is kept to the microsecond, as stored by PostgreSQL, so Update can match it.
+		lockedUntil := now.Add(lease).Truncate(time.Microsecond)
+		result := r.db.Model(&model.Job{}).
+			Where("id = ?", job.ID).
+			Where(dueCondition, model.JobStatusQueued, now, model.JobStatusRunning, now).
+			Updates(map[string]any{
+				"status":       model.JobStatusRunning,
+				"attempts":     gorm.Expr("attempts + 1"),
+				"locked_until": lockedUntil,
+				"updated_at":   now,
+			})
+		if result.Error != nil {
+			return job, result.Error
+		}
+		if result.RowsAffected == 1 {
+			job.Status = model.JobStatusRunning
+			job.Attempts++
+			job.LockedUntil = &lockedUntil
+			job.UpdatedAt = now
+			return job, nil
+		}
+	}
+	return model.Job{}, gorm.ErrRecordNotFound
+}
+
+func (r *jobRepository) Update(job *model.Job, leasedUntil time.Time) error {
+	result := r.db.Model(job).
+		Where("status = ? AND locked_until = ?", model.JobStatusRunning, leasedUntil).
+		Select("status", "attempts", "run_at", "locked_until", "last_error", "updated_at").
+		Updates(job)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrJobLeaseLost
+	}
+	return nil
+}
+
+func (r *jobRepository) FindLatest(tenantID uint, jobType string, menuID uint) (model.Job, error) {
+	var job model.Job
+	err := r.db.Where("tenant_id = ? AND type = ? AND menu_id = ?", tenantID, jobType, menuID).
+		Order("id DESC").First(&job).Error
+	return job, err
+}
@@ -6,19 +6,67 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 
 	"atalariq/menu-api/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Every method is scoped by tenant, a menu of another tenant behaves as if it does not exist
 type MenuRepository interface {
 	Create(menu *model.Menu) error
-	FindAll(filter model.MenuFilter) ([]model.Menu, model.MenuPaginationResponse, error)
-	FindByID(id uint) (model.Menu, error)
+	// CreateMany creates the menus in one transaction
+	CreateMany(menus []model.Menu) error
+	FindAll(scope model.Scope, filter model.MenuFilter) ([]model.Menu, model.MenuPaginationResponse, error)
+	FindByID(scope model.Scope, id uint) (model.Menu, error)
 	Update(menu *model.Menu) error
-	Delete(id uint) error
-	GroupBy(mode string, limit int) (any, error)
+	Delete(tenantID, id uint) error
+	GroupBy(tenantID uint, mode string, limit int) (any, error)
+	// FindCategories lists the categories the tenant uses, in order
+	FindCategories(tenantID uint) ([]string, error)
+	Facets(scope model.Scope, filter model.MenuFilter) (model.MenuFacets, error)
+
+	// FindBatch walks the menus of every tenant by ID, for background jobs
+	FindBatch(afterID uint, limit int) ([]model.Menu, error)
+
+	// Stock tracking
+	DecrementStock(tenantID, id uint, quantity int) (bool, error)
+	Restock(tenantID, id uint, quantity int) error
+	SetAvailability(tenantID, id uint, status string) error
+	ResetDailyStock() (int64, error)
+
+	// Descriptions generated in the background
+	// SetGeneratedDescription writes a description unless one was written by hand meanwhile,
+	// it reports whether the menu was updated
+	SetGeneratedDescription(tenantID, id uint, description, status string) (bool, error)
+	// MarkFallbackDescriptions flags placeholders stored before description statuses existed
+	MarkFallbackDescriptions(placeholderPrefix string) (int64, error)
+	// FindUngeneratedDescriptions walks the pending and fallback descriptions of every tenant by ID,
+	// leaving out menus swept maxSweeps times already and menus with an active job of jobType
+	FindUngeneratedDescriptions(jobType string, maxSweeps int, afterID uint, limit int) ([]model.Menu, error)
+	// CountDescriptionSweeps records that the sweep queued a generation for the menus
+	CountDescriptionSweeps(ids []uint) error
+
+	// Images
+	UpdateImage(tenantID, id uint, image *model.MenuImage) error
+
+	// Branch overrides
+	UpsertOverride(override *model.BranchMenuOverride) error
+	DeleteOverride(tenantID, branchID, menuID uint) error
+	FindOverrides(tenantID, branchID uint) ([]model.BranchMenuOverride, error)
+
+	// Translations
+	FindTranslations(tenantID uint, menuIDs []uint, locales []string) ([]model.MenuTranslation, error)
+	FindTranslationsForMenu(tenantID, menuID uint) ([]model.MenuTranslation, error)
+	UpsertTranslation(translation *model.MenuTranslation) error
+	DeleteTranslation(tenantID, menuID uint, locale string) error
+	FindMissingTranslations(tenantID uint, locale string, limit int) ([]model.Menu, error)
+
+	// Enrichment
+	// FindUnenriched returns menus of the tenant without a category or calories
+	FindUnenriched(tenantID uint, limit int) ([]model.Menu, error)
 }
 
 type menuRepository struct {
@@ -29,51 +77,151 @@ func NewMenuRepository(db *gorm.DB) MenuRepository {
 	return &menuRepository{db}
 }
 
+// Columns that can be used with `sort=field:direction`
+var sortableColumns = map[string]string{
+	"name":       "menus.name",
+	"category":   "menus.category",
+	"calories":   "menus.calories",
+	"price":      "", // resolved to the effective price of the scope
+	"rating":     "menus.rating_average",
+	"created_at": "menus.created_at",
+	"updated_at": "menus.updated_at",
+}
+
+// scoped restricts the query to a tenant and resolves branch overrides.
+// It returns the query along with the effective price and availability expressions.
+func (r *menuRepository) scoped(scope model.Scope) (*gorm.DB, string, string) {
+	db := r.db.Model(&model.Menu{}).Where("menus.tenant_id = ?", scope.TenantID)
+	if scope.BranchID == 0 {
+		return db, "menus.price", "menus.availability"
+	}
+
+	db = db.Joins("LEFT JOIN branch_menu_overrides bo ON bo.menu_id = menus.id AND bo.branch_id = ? AND bo.tenant_id = ?",
+		scope.BranchID, scope.TenantID)
+	return db, "COALESCE(bo.price, menus.price)", "COALESCE(bo.availability, menus.availability)"
+}
+
+// whereTextMatches searches the text a reader of the scope locale sees:
+// the translation when one exists, the base name and description otherwise
+func (r *menuRepository) whereTextMatches(db *gorm.DB, scope model.Scope, pattern string) *gorm.DB {
+	locales := model.LocaleFallbacks(scope.Locale)
+	if len(locales) == 0 {
+		return db.Where("menus.name LIKE ? OR menus.description LIKE ?", pattern, pattern)
+	}
+
+	translated := r.db.Model(&model.MenuTranslation{}).Select("menu_id").
+		Where("tenant_id = ? AND locale IN ?", scope.TenantID, locales)
+	matched := translated.Session(&gorm.Session{}).
+		Where("name LIKE ? OR description LIKE ?", pattern, pattern)
+
+	return db.Where(
+		r.db.Where("menus.id IN (?)", matched).
+			Or("menus.id NOT IN (?) AND (menus.name LIKE ? OR menus.description LIKE ?)", translated, pattern, pattern),
+	)
+}
+
+func selectEffective(db *gorm.DB, price, availability string) *gorm.DB {
+	return db.Select(fmt.Sprintf("menus.*, %s AS price, %s AS availability", price, availability))
+}
+
+func orderTags(db *gorm.DB) *gorm.DB {
+	return db.Order("tags.name")
+}
+
+// Tags are assigned through the tag repository only
 func (r *menuRepository) Create(menu *model.Menu) error {
-	return r.db.Create(menu).Error
+	return r.db.Omit(clause.Associations).Create(menu).Error
 }
 
-func (r *menuRepository) FindAll(filter model.MenuFilter) ([]model.Menu, model.MenuPaginationResponse, error) {
-	var menus []model.Menu
-	var total int64
+func (r *menuRepository) CreateMany(menus []model.Menu) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Omit(clause.Associations).CreateInBatches(&menus, 100).Error
+	})
+}
 
-	db := r.db.Model(&model.Menu{})
+// Facet groups, applyFilter skips the filter of the group being counted
+const (
+	facetNone     = ""
+	facetCategory = "category"
+	facetTags     = "tags"
+	facetPrice    = "price"
+)
 
-	// Apply Filters
+func (r *menuRepository) applyFilter(db *gorm.DB, scope model.Scope, filter model.MenuFilter, price, availability, skip string) *gorm.DB {
 	if filter.Query != "" {
-		db = db.Where("name LIKE ? OR description LIKE ?", "%"+filter.Query+"%", "%"+filter.Query+"%")
-	}
-	if filter.Category != "" {
-		db = db.Where("category = ?", filter.Category)
+		db = r.whereTextMatches(db, scope, "%"+filter.Query+"%")
 	}
-	if filter.MinPrice > 0 {
-		db = db.Where("price >= ?", filter.MinPrice)
+	if filter.Category != "" && skip != facetCategory {
+		db = db.Where("menus.category = ?", filter.Category)
 	}
-	if filter.MaxPrice > 0 {
-		db = db.Where("price <= ?", filter.MaxPrice)
+	if skip != facetPrice {
+		if filter.MinPrice > 0 {
+			db = db.Where(price+" >= ?", filter.MinPrice)
+		}
+		if filter.MaxPrice > 0 {
+			db = db.Where(price+" <= ?", filter.MaxPrice)
+		}
 	}
 	if filter.MaxCal > 0 {
-		db = db.Where("calories <= ?", filter.MaxCal)
+		db = db.Where("menus.calories <= ?", filter.MaxCal)
+	}
+	if filter.MinRating > 0 {
+		db = db.Where("menus.rating_average >= ?", filter.MinRating)
+	}
+	if filter.AvailableOnly {
+		db = db.Where(availability+" <> ? AND (menus.stock IS NULL OR menus.stock > 0)", model.AvailabilitySoldOut)
+	}
+	if len(filter.IDs) > 0 {
+		db = db.Where("menus.id IN ?", filter.IDs)
 	}
+	if len(filter.Tags) > 0 && skip != facetTags {
+		tagged := r.db.Table("menu_tags").Select("menu_tags.menu_id").
+			Joins("JOIN tags ON tags.id = menu_tags.tag_id").
+			Where("tags.tenant_id = ? AND tags.slug IN ?", scope.TenantID, filter.Tags)
+		if filter.TagsMode == model.TagMatchAll {
+			tagged = tagged.Group("menu_tags.menu_id").Having("COUNT(DISTINCT tags.id) = ?", len(uniqueStrings(filter.Tags)))
+		}
+		db = db.Where("menus.id IN (?)", tagged)
+	}
+	return db
+}
+
+func (r *menuRepository) FindAll(scope model.Scope, filter model.MenuFilter) ([]model.Menu, model.MenuPaginationResponse, error) {
+	var menus []model.Menu
+	var total int64
+
+	db, price, availability := r.scoped(scope)
+	db = r.applyFilter(db, scope, filter, price, availability, facetNone)
 
 	// Count Total (for pagination)
-	db.Count(&total)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, model.MenuPaginationResponse{}, err
+	}
+
+	db = selectEffective(db, price, availability)
 
 	// Sorting
+	order := "menus.created_at desc"
 	if filter.Sort != "" {
 		parts := strings.Split(filter.Sort, ":")
 		if len(parts) == 2 {
-			db = db.Order(fmt.Sprintf("%s %s", parts[0], parts[1]))
+			column, ok := sortableColumns[parts[0]]
+			if column == "" {
+				column = price
+			}
+			direction := strings.ToLower(parts[1])
+			if ok && (direction == "asc" || direction == "desc") {
+				order = fmt.Sprintf("%s %s", column, direction)
+			}
 		}
-	} else {
-		db = db.Order("created_at desc")
 	}
+	db = db.Order(order)
 
 	// Pagination Logic
 	offset := (filter.Page - 1) * filter.PerPage
 	db = db.Limit(filter.PerPage).Offset(offset)
 
-	err := db.Find(&menus).Error
+	err := db.Preload("Tags", orderTags).Find(&menus).Error
 
 	totalPages := int(math.Ceil(float64(total) / float64(filter.PerPage)))
 	pagination := model.MenuPaginationResponse{
@@ -86,21 +234,347 @@ func (r *menuRepository) FindAll(filter model.MenuFilter) ([]model.Menu, model.M
 	return menus, pagination, err
 }
 
-func (r *menuRepository) FindByID(id uint) (model.Menu, error) {
+func (r *menuRepository) FindBatch(afterID uint, limit int) ([]model.Menu, error) {
+	var menus []model.Menu
+	err := r.db.Where("id > ?", afterID).Order("id").Limit(limit).Find(&menus).Error
+	return menus, err
+}
+
+// Facets counts the menus matching the filter per category, tag and price bucket
+func (r *menuRepository) Facets(scope model.Scope, filter model.MenuFilter) (model.MenuFacets, error) {
+	facets := model.MenuFacets{
+		Categories:   []model.FacetCount{},
+		Tags:         []model.TagFacetCount{},
+		PriceBuckets: []model.PriceBucket{},
+	}
+
+	db, price, availability := r.scoped(scope)
+	err := r.applyFilter(db, scope, filter, price, availability, facetCategory).
+		Select("menus.category AS value, COUNT(*) AS count").
+		Group("menus.category").
+		Order("count DESC, value").
+		Scan(&facets.Categories).Error
+	if err != nil {
+		return facets, err
+	}
+
+	db, price, availability = r.scoped(scope)
+	err = r.applyFilter(db, scope, filter, price, availability, facetTags).
+		Joins("JOIN menu_tags ON menu_tags.menu_id = menus.id").
+		Joins("JOIN tags ON tags.id = menu_tags.tag_id").
+		Select("tags.slug AS slug, tags.name AS name, COUNT(*) AS count").
+		Group("tags.id, tags.slug, tags.name").
+		Order("count DESC, tags.name").
+		Scan(&facets.Tags).Error
+	if err != nil {
+		return facets, err
+	}
+
+	var prices []float64
+	db, price, availability = r.scoped(scope)
+	err = r.applyFilter(db, scope, filter, price, availability, facetPrice).
+		Select(price + " AS price").
+		Scan(&prices).Error
+	if err != nil {
+		return facets, err
+	}
+	facets.PriceBuckets = priceBuckets(prices)
+
+	return facets, nil
+}
+
+// priceBucketTarget is the number of buckets priceBuckets aims for
+const priceBucketTarget = 5
+
+// priceBuckets splits prices into equal ranges with a round width (1, 2, 2.5 or 5 times a power of ten)
+func priceBuckets(prices []float64) []model.PriceBucket {
+	buckets := []model.PriceBucket{}
+	if len(prices) == 0 {
+		return buckets
+	}
+
+	low, high := prices[0], prices[0]
+	for _, p := range prices {
+		low = math.Min(low, p)
+		high = math.Max(high, p)
+	}
+	step := niceStep((high - low) / priceBucketTarget)
+	if high == low {
+		step = niceStep(math.Max(high, 1))
+	}
+
+	start := math.Floor(low/step) * step
+	count := int(math.Floor((high-start)/step)) + 1
+	for i := 0; i < count; i++ {
+		buckets = append(buckets, model.PriceBucket{Min: start + float64(i)*step, Max: start + float64(i+1)*step})
+	}
+	for _, p := range prices {
+		i := min(int(math.Floor((p-start)/step)), count-1)
+		buckets[i].Count++
+	}
+	return buckets
+}
+
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, factor := range []float64{1, 2, 2.5, 5} {
+		if raw <= factor*magnitude {
+			return factor * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+func (r *menuRepository) FindByID(scope model.Scope, id uint) (model.Menu, error) {
 	var menu model.Menu
-	err := r.db.First(&menu, id).Error
+
+	db, price, availability := r.scoped(scope)
+	err := selectEffective(db, price, availability).Preload("Tags", orderTags).Where("menus.id = ?", id).First(&menu).Error
 	return menu, err
 }
 
+// Update saves every field of the menu, but only when it belongs to menu.TenantID
 func (r *menuRepository) Update(menu *model.Menu) error {
-	return r.db.Save(menu).Error
+	result := r.db.Model(menu).
+		Where("tenant_id = ?", menu.TenantID).
+		Select("*").Omit("id", "tenant_id", "image", "rating_average", "rating_count", "review_summary", "created_at", clause.Associations).
+		Updates(menu)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *menuRepository) Delete(tenantID, id uint) error {
+	result := r.db.Where("tenant_id = ?", tenantID).Delete(&model.Menu{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := r.db.Where("tenant_id = ? AND menu_id = ?", tenantID, id).Delete(&model.BranchMenuOverride{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Exec("DELETE FROM menu_tags WHERE menu_id = ?", id).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("tenant_id = ? AND menu_id = ?", tenantID, id).Delete(&model.Review{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where("tenant_id = ? AND menu_id = ?", tenantID, id).Delete(&model.MenuTranslation{}).Error
+}
+
+// DecrementStock reduces stock atomically and marks the menu sold out when it reaches zero.
+// It returns false when the menu does not track stock or has less than the requested quantity.
+func (r *menuRepository) DecrementStock(tenantID, id uint, quantity int) (bool, error) {
+	result := r.db.Model(&model.Menu{}).
+		Where("id = ? AND tenant_id = ? AND stock IS NOT NULL AND stock >= ?", id, tenantID, quantity).
+		Updates(map[string]any{
+			"stock": gorm.Expr("stock - ?", quantity),
+			"availability": gorm.Expr("CASE WHEN stock - ? <= 0 THEN ? ELSE availability END",
+				quantity, model.AvailabilitySoldOut),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *menuRepository) Restock(tenantID, id uint, quantity int) error {
+	return r.db.Model(&model.Menu{}).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		Updates(map[string]any{
+			"stock":        gorm.Expr("COALESCE(stock, 0) + ?", quantity),
+			"availability": model.AvailabilityAvailable,
+		}).Error
+}
+
+func (r *menuRepository) SetAvailability(tenantID, id uint, status string) error {
+	return r.db.Model(&model.Menu{}).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		Update("availability", status).Error
+}
+
+func (r *menuRepository) SetGeneratedDescription(tenantID, id uint, description, status string) (bool, error) {
+	result := r.db.Model(&model.Menu{}).
+		Where("id = ? AND tenant_id = ? AND description_status <> ?", id, tenantID, model.DescriptionStatusReady).
+		Updates(map[string]any{
+			"description":        description,
+			"description_status": status,
+			"updated_at":         time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *menuRepository) MarkFallbackDescriptions(placeholderPrefix string) (int64, error) {
+	result := r.db.Model(&model.Menu{}).
+		Where("description_status = ? AND description = ? || name", model.DescriptionStatusReady, placeholderPrefix).
+		Update("description_status", model.DescriptionStatusFallback)
+	return result.RowsAffected, result.Error
+}
+
+func (r *menuRepository) FindUngeneratedDescriptions(jobType string, maxSweeps int, afterID uint, limit int) ([]model.Menu, error) {
+	active := r.db.Model(&model.Job{}).Select("1").
+		Where("jobs.menu_id = menus.id AND jobs.type = ? AND jobs.status IN ?", jobType, []string{model.JobStatusQueued, model.JobStatusRunning})
+
+	var menus []model.Menu
+	err := r.db.Where("id > ? AND description_status IN ? AND description_sweeps < ?", afterID, []string{model.DescriptionStatusPending, model.DescriptionStatusFallback}, maxSweeps).
+		Where("NOT EXISTS (?)", active).
+		Order("id").Limit(limit).Find(&menus).Error
+	return menus, err
 }
 
-func (r *menuRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Menu{}, id).Error
+func (r *menuRepository) CountDescriptionSweeps(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.Menu{}).Where("id IN ?", ids).
+		UpdateColumn("description_sweeps", gorm.Expr("description_sweeps + 1")).Error
 }
 
-func (r *menuRepository) GroupBy(mode string, limit int) (any, error) {
+// UpdateImage replaces the image variants of a menu, a nil image removes it
+func (r *menuRepository) UpdateImage(tenantID, id uint, image *model.MenuImage) error {
+	result := r.db.Model(&model.Menu{ID: id}).
+		Where("tenant_id = ?", tenantID).
+		Select("image").
+		Updates(&model.Menu{Image: image})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ResetDailyStock restores daily stock and clears manual sold-out flags of every tenant.
+// Menus that track stock without a daily quantity keep their current state.
+func (r *menuRepository) ResetDailyStock() (int64, error) {
+	var affected int64
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		restocked := tx.Model(&model.Menu{}).
+			Where("daily_stock IS NOT NULL").
+			Updates(map[string]any{
+				"stock":        gorm.Expr("daily_stock"),
+				"availability": model.AvailabilityAvailable,
+			})
+		if restocked.Error != nil {
+			return restocked.Error
+		}
+
+		untracked := tx.Model(&model.Menu{}).
+			Where("stock IS NULL AND availability = ?", model.AvailabilitySoldOut).
+			Update("availability", model.AvailabilityAvailable)
+		if untracked.Error != nil {
+			return untracked.Error
+		}
+
+		affected = restocked.RowsAffected + untracked.RowsAffected
+		return nil
+	})
+
+	return affected, err
+}
+
+func (r *menuRepository) UpsertOverride(override *model.BranchMenuOverride) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "branch_id"}, {Name: "menu_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price", "availability", "updated_at"}),
+	}).Create(override).Error
+}
+
+func (r *menuRepository) DeleteOverride(tenantID, branchID, menuID uint) error {
+	result := r.db.
+		Where("tenant_id = ? AND branch_id = ? AND menu_id = ?", tenantID, branchID, menuID).
+		Delete(&model.BranchMenuOverride{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *menuRepository) FindOverrides(tenantID, branchID uint) ([]model.BranchMenuOverride, error) {
+	var overrides []model.BranchMenuOverride
+	err := r.db.Where("tenant_id = ? AND branch_id = ?", tenantID, branchID).
+		Order("menu_id asc").
+		Find(&overrides).Error
+	return overrides, err
+}
+
+func (r *menuRepository) FindTranslations(tenantID uint, menuIDs []uint, locales []string) ([]model.MenuTranslation, error) {
+	var translations []model.MenuTranslation
+	if len(menuIDs) == 0 || len(locales) == 0 {
+		return translations, nil
+	}
+
+	err := r.db.Where("tenant_id = ? AND menu_id IN ? AND locale IN ?", tenantID, menuIDs, locales).
+		Order("menu_id asc, locale asc").
+		Find(&translations).Error
+	return translations, err
+}
+
+func (r *menuRepository) FindTranslationsForMenu(tenantID, menuID uint) ([]model.MenuTranslation, error) {
+	var translations []model.MenuTranslation
+	err := r.db.Where("tenant_id = ? AND menu_id = ?", tenantID, menuID).
+		Order("locale asc").
+		Find(&translations).Error
+	return translations, err
+}
+
+func (r *menuRepository) UpsertTranslation(translation *model.MenuTranslation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "menu_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "source", "updated_at"}),
+	}).Create(translation).Error
+}
+
+func (r *menuRepository) DeleteTranslation(tenantID, menuID uint, locale string) error {
+	result := r.db.Where("tenant_id = ? AND menu_id = ? AND locale = ?", tenantID, menuID, locale).
+		Delete(&model.MenuTranslation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindMissingTranslations returns menus of the tenant without a translation for the locale
+func (r *menuRepository) FindMissingTranslations(tenantID uint, locale string, limit int) ([]model.Menu, error) {
+	var menus []model.Menu
+
+	translated := r.db.Model(&model.MenuTranslation{}).Select("menu_id").
+		Where("tenant_id = ? AND locale = ?", tenantID, locale)
+	err := r.db.Where("tenant_id = ? AND id NOT IN (?)", tenantID, translated).
+		Order("id asc").
+		Limit(limit).
+		Find(&menus).Error
+	return menus, err
+}
+
+func (r *menuRepository) GroupBy(tenantID uint, mode string, limit int) (any, error) {
 	if mode == "count" {
 		type Result struct {
 			Category string
@@ -109,6 +583,7 @@ func (r *menuRepository) GroupBy(mode string, limit int) (any, error) {
 		var results []Result
 
 		err := r.db.Model(&model.Menu{}).
+			Where("tenant_id = ?", tenantID).
 			Select("category, count(*) as count").
 			Group("category").
 			Scan(&results).Error
@@ -126,7 +601,7 @@ func (r *menuRepository) GroupBy(mode string, limit int) (any, error) {
 	if mode == "list" {
 		var menus []model.Menu
 
-		if err := r.db.Order("category asc, name asc").Find(&menus).Error; err != nil {
+		if err := r.db.Where("tenant_id = ?", tenantID).Order("category asc, name asc").Find(&menus).Error; err != nil {
 			return nil, err
 		}
 
@@ -148,3 +623,22 @@ func (r *menuRepository) GroupBy(mode string, limit int) (any, error) {
 
 	return nil, errors.New("invalid mode")
 }
+
+func (r *menuRepository) FindCategories(tenantID uint) ([]string, error) {
+	var categories []string
+	err := r.db.Model(&model.Menu{}).
+		Where("tenant_id = ? AND category <> ''", tenantID).
+		Distinct("category").
+		Order("category asc").
+		Pluck("category", &categories).Error
+	return categories, err
+}
+
+func (r *menuRepository) FindUnenriched(tenantID uint, limit int) ([]model.Menu, error) {
+	var menus []model.Menu
+	err := r.db.Where("tenant_id = ? AND (category = '' OR category IS NULL OR calories = 0)", tenantID).
+		Order("id asc").
+		Limit(limit).
+		Find(&menus).Error
+	return menus, err
+}
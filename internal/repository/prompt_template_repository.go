@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"atalariq/menu-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type PromptTemplateRepository interface {
+	FindActive() ([]model.PromptTemplate, error)
+	FindVersions(name string) ([]model.PromptTemplate, error)
+	FindVersion(name string, version int) (model.PromptTemplate, error)
+	// Create numbers the template after the latest version of its name
+	Create(template *model.PromptTemplate) error
+	// Activate makes version the only active one of its name, version 0 deactivates them all
+	Activate(name string, version int) error
+}
+
+type promptTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewPromptTemplateRepository(db *gorm.DB) PromptTemplateRepository {
+	return &promptTemplateRepository{db}
+}
+
+func (r *promptTemplateRepository) FindActive() ([]model.PromptTemplate, error) {
+	var templates []model.PromptTemplate
+	err := r.db.Where("active = ?", true).Order("name").Find(&templates).Error
+	return templates, err
+}
+
+func (r *promptTemplateRepository) FindVersions(name string) ([]model.PromptTemplate, error) {
+	var templates []model.PromptTemplate
+	err := r.db.Where("name = ?", name).Order("version").Find(&templates).Error
+	return templates, err
+}
+
+func (r *promptTemplateRepository) FindVersion(name string, version int) (model.PromptTemplate, error) {
+	var template model.PromptTemplate
+	err := r.db.Where("name = ? AND version = ?", name, version).First(&template).Error
+	return template, err
+}
+
+// Create relies on the unique (name, version) index, two concurrent creations fail
+// instead of sharing a number
+func (r *promptTemplateRepository) Create(template *model.PromptTemplate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&model.PromptTemplate{}).Where("name = ?", template.Name).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		template.ID = 0
+		template.Version = latest + 1
+		template.Active = false
+		return tx.Create(template).Error
+	})
+}
+
+func (r *promptTemplateRepository) Activate(name string, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if version > 0 {
+			var count int64
+			if err := tx.Model(&model.PromptTemplate{}).Where("name = ? AND version = ?", name, version).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		if err := tx.Model(&model.PromptTemplate{}).Where("name = ? AND active = ?", name, true).
+			Update("active", false).Error; err != nil {
+			return err
+		}
+		if version == 0 {
+			return nil
+		}
+		return tx.Model(&model.PromptTemplate{}).Where("name = ? AND version = ?", name, version).
+			Update("active", true).Error
+	})
+}
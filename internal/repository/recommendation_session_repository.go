@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"time"
+
+	"atalariq/menu-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type RecommendationSessionRepository interface {
+	Create(session *model.RecommendationSession) error
+	// Find ignores expired sessions, they behave as if they do not exist
+	Find(tenantID uint, id string, now time.Time) (model.RecommendationSession, error)
+	Update(session *model.RecommendationSession) error
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type recommendationSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewRecommendationSessionRepository(db *gorm.DB) RecommendationSessionRepository {
+	return &recommendationSessionRepository{db}
+}
+
+func (r *recommendationSessionRepository) Create(session *model.RecommendationSession) error {
+	return r.db.Create(session).Error
+}
+
+func (r *recommendationSessionRepository) Find(tenantID uint, id string, now time.Time) (model.RecommendationSession, error) {
+	var session model.RecommendationSession
+	err := r.db.Where("id = ? AND tenant_id = ? AND expires_at > ?", id, tenantID, now).First(&session).Error
+	return session, err
+}
+
+func (r *recommendationSessionRepository) Update(session *model.RecommendationSession) error {
+	// A struct update, so the turns go through their JSON serializer
+	return r.db.Model(session).
+		Where("tenant_id = ?", session.TenantID).
+		Select("turns", "expires_at", "updated_at").
+		Updates(session).Error
+}
+
+func (r *recommendationSessionRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now).Delete(&model.RecommendationSession{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"math"
+
+	"atalariq/menu-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ReviewRepository keeps the rating average and count of each menu in step with its
+// approved reviews, in the same transaction as the review
+type ReviewRepository interface {
+	Create(review *model.Review) error
+	FindByID(tenantID, id uint) (model.Review, error)
+	// FindAll lists the reviews of the tenant, newest first. A menuID of 0 lists every menu,
+	// an empty status every status and a limit of 0 every review.
+	FindAll(tenantID, menuID uint, status string, limit int) ([]model.Review, error)
+	// UpdateStatus moderates the review and refreshes the rating of its menu
+	UpdateStatus(review *model.Review) error
+	// SaveSummary stores the summary of the reviews on the menu
+	SaveSummary(tenantID, menuID uint, summary model.ReviewSummary) error
+}
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepository{db}
+}
+
+func (r *reviewRepository) Create(review *model.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		if review.Status != model.ReviewStatusApproved {
+			return nil
+		}
+		return refreshRating(tx, review.TenantID, review.MenuID)
+	})
+}
+
+func (r *reviewRepository) FindByID(tenantID, id uint) (model.Review, error) {
+	var review model.Review
+	err := r.db.Where("tenant_id = ? AND id = ?", tenantID, id).First(&review).Error
+	return review, err
+}
+
+func (r *reviewRepository) FindAll(tenantID, menuID uint, status string, limit int) ([]model.Review, error) {
+	query := r.db.Where("tenant_id = ?", tenantID)
+	if menuID != 0 {
+		query = query.Where("menu_id = ?", menuID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	reviews := []model.Review{}
+	err := query.Order("id desc").Find(&reviews).Error
+	return reviews, err
+}
+
+func (r *reviewRepository) UpdateStatus(review *model.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(review).
+			Where("tenant_id = ?", review.TenantID).
+			Select("status", "updated_at").
+			Updates(review)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return refreshRating(tx, review.TenantID, review.MenuID)
+	})
+}
+
+// refreshRating counts the approved reviews of the menu again. The columns are updated
+// without touching updated_at, a review does not change the menu itself.
+func refreshRating(tx *gorm.DB, tenantID, menuID uint) error {
+	var rating struct {
+		Average float64
+		Count   int
+	}
+	err := tx.Model(&model.Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("tenant_id = ? AND menu_id = ? AND status = ?", tenantID, menuID, model.ReviewStatusApproved).
+		Scan(&rating).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&model.Menu{}).
+		Where("tenant_id = ? AND id = ?", tenantID, menuID).
+		UpdateColumns(map[string]any{
+			"rating_average": math.Round(rating.Average*100) / 100,
+			"rating_count":   rating.Count,
+		}).Error
+}
+
+func (r *reviewRepository) SaveSummary(tenantID, menuID uint, summary model.ReviewSummary) error {
+	// A struct update, so the summary goes through its JSON serializer
+	result := r.db.Model(&model.Menu{ID: menuID}).
+		Where("tenant_id = ?", tenantID).
+		Select("review_summary").
+		UpdateColumns(&model.Menu{ReviewSummary: &summary})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
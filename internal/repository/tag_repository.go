@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"atalariq/menu-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Tags are scoped by tenant like menus, menu_tags is the many-to-many join table
+type TagRepository interface {
+	Create(tag *model.Tag) error
+	FindAll(tenantID uint) ([]model.TagWithCount, error)
+	FindByID(tenantID, id uint) (model.Tag, error)
+	FindBySlugs(tenantID uint, slugs []string) ([]model.Tag, error)
+	Update(tag *model.Tag) error
+	Delete(tenantID, id uint) error
+
+	ReplaceMenuTags(tenantID, menuID uint, tags []model.Tag) error
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db}
+}
+
+func (r *tagRepository) Create(tag *model.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *tagRepository) FindAll(tenantID uint) ([]model.TagWithCount, error) {
+	var tags []model.TagWithCount
+	err := r.db.Model(&model.Tag{}).
+		Select("tags.*, COUNT(menu_tags.menu_id) AS menu_count").
+		Joins("LEFT JOIN menu_tags ON menu_tags.tag_id = tags.id").
+		Where("tags.tenant_id = ?", tenantID).
+		Group("tags.id").
+		Order("tags.name").
+		Scan(&tags).Error
+	return tags, err
+}
+
+func (r *tagRepository) FindByID(tenantID, id uint) (model.Tag, error) {
+	var tag model.Tag
+	err := r.db.Where("tenant_id = ?", tenantID).First(&tag, id).Error
+	return tag, err
+}
+
+func (r *tagRepository) FindBySlugs(tenantID uint, slugs []string) ([]model.Tag, error) {
+	var tags []model.Tag
+	if len(slugs) == 0 {
+		return tags, nil
+	}
+	err := r.db.Where("tenant_id = ? AND slug IN ?", tenantID, slugs).Order("name").Find(&tags).Error
+	return tags, err
+}
+
+func (r *tagRepository) Update(tag *model.Tag) error {
+	result := r.db.Model(tag).
+		Where("tenant_id = ?", tag.TenantID).
+		Updates(map[string]any{"name": tag.Name, "slug": tag.Slug})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Delete removes the tag from every menu as well
+func (r *tagRepository) Delete(tenantID, id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("tenant_id = ?", tenantID).Delete(&model.Tag{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Exec("DELETE FROM menu_tags WHERE tag_id = ?", id).Error
+	})
+}
+
+// ReplaceMenuTags sets exactly the given tags on a menu of the tenant
+func (r *tagRepository) ReplaceMenuTags(tenantID, menuID uint, tags []model.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Menu{}).Where("id = ? AND tenant_id = ?", menuID, tenantID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Exec("DELETE FROM menu_tags WHERE menu_id = ?", menuID).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+
+		rows := make([]map[string]any, 0, len(tags))
+		for _, tag := range tags {
+			rows = append(rows, map[string]any{"menu_id": menuID, "tag_id": tag.ID})
+		}
+		return tx.Table("menu_tags").Create(rows).Error
+	})
+}
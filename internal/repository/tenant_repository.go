@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"atalariq/menu-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type TenantRepository interface {
+	Create(tenant *model.Tenant) error
+	FindAll() ([]model.Tenant, error)
+	FindByID(id uint) (model.Tenant, error)
+	FindBySlug(slug string) (model.Tenant, error)
+	FindByAPIKeyHash(hash string) (model.Tenant, error)
+	UpdateAPIKeyHash(id uint, hash string) error
+
+	CreateBranch(branch *model.Branch) error
+	FindBranches(tenantID uint) ([]model.Branch, error)
+	FindBranch(tenantID, id uint) (model.Branch, error)
+}
+
+type tenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) TenantRepository {
+	return &tenantRepository{db}
+}
+
+func (r *tenantRepository) Create(tenant *model.Tenant) error {
+	return r.db.Create(tenant).Error
+}
+
+func (r *tenantRepository) FindAll() ([]model.Tenant, error) {
+	var tenants []model.Tenant
+	err := r.db.Order("id asc").Find(&tenants).Error
+	return tenants, err
+}
+
+func (r *tenantRepository) FindByID(id uint) (model.Tenant, error) {
+	var tenant model.Tenant
+	err := r.db.First(&tenant, id).Error
+	return tenant, err
+}
+
+func (r *tenantRepository) FindBySlug(slug string) (model.Tenant, error) {
+	var tenant model.Tenant
+	err := r.db.Where("slug = ?", slug).First(&tenant).Error
+	return tenant, err
+}
+
+func (r *tenantRepository) FindByAPIKeyHash(hash string) (model.Tenant, error) {
+	var tenant model.Tenant
+	err := r.db.Where("api_key_hash = ?", hash).First(&tenant).Error
+	return tenant, err
+}
+
+func (r *tenantRepository) CreateBranch(branch *model.Branch) error {
+	return r.db.Create(branch).Error
+}
+
+func (r *tenantRepository) FindBranches(tenantID uint) ([]model.Branch, error) {
+	var branches []model.Branch
+	err := r.db.Where("tenant_id = ?", tenantID).Order("id asc").Find(&branches).Error
+	return branches, err
+}
+
+func (r *tenantRepository) FindBranch(tenantID, id uint) (model.Branch, error) {
+	var branch model.Branch
+	err := r.db.Where("tenant_id = ?", tenantID).First(&branch, id).Error
+	return branch, err
+}
+
+func (r *tenantRepository) UpdateAPIKeyHash(id uint, hash string) error {
+	result := r.db.Model(&model.Tenant{}).Where("id = ?", id).Update("api_key_hash", hash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
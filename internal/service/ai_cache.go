@@ -0,0 +1,483 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Cached AI methods, each key starts with one of them
+const (
+	aiCacheDescription     = "description"
+	aiCacheRecommendations = "recommendations"
+	aiCacheTranslations    = "translations"
+)
+
+// aiCachePurgeInterval is the number of saves between two purges of expired database entries
+const aiCachePurgeInterval = 100
+
+// AICacheStore keeps cached answers beyond the memory of one process, it is
+// implemented by repository.AICacheRepository
+type AICacheStore interface {
+	Find(key string, now time.Time) (model.AICacheEntry, error)
+	Save(entry *model.AICacheEntry) error
+	DeletePrefix(prefix string) (int64, error)
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+// CachedAIService answers repeated AI calls from a cache. Keys hash the normalized
+// inputs with the provider, the model and the active version of the prompt template,
+// so switching any of them never serves stale answers. Recommendations also depend on
+// the candidate menus, and the entries of a tenant are dropped whenever one of its
+// menus changes.
+type CachedAIService interface {
+	AIService
+	MenuListener
+	PromptUser
+	Stats() model.AICacheStats
+}
+
+type cachedAIService struct {
+	ai    AIService
+	cfg   config.AICache
+	model string // provider and model the answers come from
+	lru   *lruCache
+	store AICacheStore // nil keeps the answers in memory only
+	// prompts is the store the provider renders from, nil when it does not use templates
+	prompts PromptStore
+
+	mu            sync.Mutex
+	hits          map[string]int64
+	misses        map[string]int64
+	invalidations int64
+	saves         int
+}
+
+// NewCachedAIService wraps ai, providerModel names the provider and model that answer
+func NewCachedAIService(ai AIService, cfg config.AICache, providerModel string, store AICacheStore) CachedAIService {
+	return &cachedAIService{
+		ai:     ai,
+		cfg:    cfg,
+		model:  providerModel,
+		lru:    newLRUCache(cfg.Size),
+		store:  store,
+		hits:   make(map[string]int64),
+		misses: make(map[string]int64),
+	}
+}
+
+// UsePrompts keys the answers on the active versions of the templates of store
+func (s *cachedAIService) UsePrompts(store PromptStore) {
+	s.prompts = store
+}
+
+func (s *cachedAIService) ttl(method string) time.Duration {
+	switch method {
+	case aiCacheDescription:
+		return s.cfg.DescriptionTTL
+	case aiCacheRecommendations:
+		return s.cfg.RecommendationTTL
+	case aiCacheTranslations:
+		return s.cfg.TranslationTTL
+	}
+	return 0
+}
+
+// promptVersion is the active version of the template behind method, an activation
+// changes the keys so the answers of the previous prompt are no longer served
+func (s *cachedAIService) promptVersion(method string) int {
+	if s.prompts == nil {
+		return 0
+	}
+	switch method {
+	case aiCacheDescription:
+		return s.prompts.ActiveVersion(PromptDescription)
+	case aiCacheRecommendations:
+		return s.prompts.ActiveVersion(PromptRecommendation)
+	case aiCacheTranslations:
+		return s.prompts.ActiveVersion(PromptTranslation)
+	}
+	return 0
+}
+
+// key is "method:tenant:hash", tenant 0 for answers that do not depend on the catalog
+func (s *cachedAIService) key(method string, tenantID uint, inputs any) string {
+	encoded, _ := json.Marshal(inputs)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%s@%d\n%s", s.model, method, s.promptVersion(method), encoded)))
+	return fmt.Sprintf("%s:%d:%s", method, tenantID, hex.EncodeToString(sum[:]))
+}
+
+// lookup decodes the cached answer into out, from memory or else from the store
+func (s *cachedAIService) lookup(method, key string, out any) bool {
+	now := time.Now()
+	value, found := s.lru.get(key, now)
+	if !found && s.store != nil {
+		entry, err := s.store.Find(key, now)
+		switch {
+		case err == nil:
+			value, found = entry.Value, true
+			s.lru.set(key, value, entry.ExpiresAt)
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			log.Printf("AI cache lookup failed: %v", err)
+		}
+	}
+	if found && json.Unmarshal(value, out) != nil {
+		found = false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if found {
+		s.hits[method]++
+	} else {
+		s.misses[method]++
+	}
+	return found
+}
+
+func (s *cachedAIService) save(method, key string, answer any) {
+	value, err := json.Marshal(answer)
+	if err != nil {
+		return
+	}
+	expiresAt := time.Now().Add(s.ttl(method))
+	s.lru.set(key, value, expiresAt)
+	if s.store == nil {
+		return
+	}
+
+	if err := s.store.Save(&model.AICacheEntry{Key: key, Value: value, ExpiresAt: expiresAt}); err != nil {
+		log.Printf("AI cache save failed: %v", err)
+	}
+	s.mu.Lock()
+	s.saves++
+	purge := s.saves%aiCachePurgeInterval == 0
+	s.mu.Unlock()
+	if purge {
+		if _, err := s.store.DeleteExpired(time.Now()); err != nil {
+			log.Printf("Failed to delete expired AI cache entries: %v", err)
+		}
+	}
+}
+
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+func descriptionInputs(name string, ingredients []string, options model.DescriptionOptions, count int) any {
+	normalized := make([]string, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		if ingredient = normalizeText(ingredient); ingredient != "" {
+			normalized = append(normalized, ingredient)
+		}
+	}
+	sort.Strings(normalized)
+	options = options.WithDefaults()
+	options.Audience = normalizeText(options.Audience)
+	return []any{normalizeText(name), normalized, options, max(count, 1)}
+}
+
+// recommendationInputs identifies the candidates by ID and last update, any edit
+// of a menu that could change the answer also changes the key
+func recommendationInputs(request model.RecommendationRequest, menus []model.Menu) (uint, any) {
+	candidates := make([][2]int64, len(menus))
+	for i, m := range menus {
+		candidates[i] = [2]int64{int64(m.ID), m.UpdatedAt.UnixNano()}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i][0] < candidates[j][0] })
+
+	history := make([][]any, len(request.History))
+	for i, turn := range request.History {
+		suggested := make([]uint, len(turn.Suggestions))
+		for j, suggestion := range turn.Suggestions {
+			suggested[j] = suggestion.MenuID
+		}
+		history[i] = []any{normalizeText(turn.Message), suggested}
+	}
+
+	var tenantID uint
+	if len(menus) > 0 {
+		tenantID = menus[0].TenantID
+	}
+	return tenantID, []any{normalizeText(request.Preference), model.NormalizeLocale(request.Locale), history, candidates}
+}
+
+func (s *cachedAIService) GenerateDescription(ctx context.Context, name string, ingredients []string) (string, error) {
+	if s.ttl(aiCacheDescription) <= 0 {
+		return s.ai.GenerateDescription(ctx, name, ingredients)
+	}
+	key := s.key(aiCacheDescription, 0, descriptionInputs(name, ingredients, model.DescriptionOptions{}, 1))
+	var descriptions []string
+	if s.lookup(aiCacheDescription, key, &descriptions) && len(descriptions) > 0 {
+		return descriptions[0], nil
+	}
+
+	description, err := s.ai.GenerateDescription(ctx, name, ingredients)
+	if err == nil && description != "" {
+		s.save(aiCacheDescription, key, []string{description})
+	}
+	return description, err
+}
+
+// GenerateDescriptions keeps every answer as a list, one description shares its entry
+// with GenerateDescription and the streaming variant
+func (s *cachedAIService) GenerateDescriptions(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, count int) ([]string, error) {
+	if s.ttl(aiCacheDescription) <= 0 {
+		return s.ai.GenerateDescriptions(ctx, name, ingredients, options, count)
+	}
+	key := s.key(aiCacheDescription, 0, descriptionInputs(name, ingredients, options, count))
+	var descriptions []string
+	if s.lookup(aiCacheDescription, key, &descriptions) && len(descriptions) > 0 {
+		return descriptions, nil
+	}
+
+	descriptions, err := s.ai.GenerateDescriptions(ctx, name, ingredients, options, count)
+	if err == nil && len(descriptions) > 0 && descriptions[0] != "" {
+		s.save(aiCacheDescription, key, descriptions)
+	}
+	return descriptions, err
+}
+
+func (s *cachedAIService) GetRecommendations(ctx context.Context, request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error) {
+	if s.ttl(aiCacheRecommendations) <= 0 {
+		return s.ai.GetRecommendations(ctx, request, menus)
+	}
+	tenantID, inputs := recommendationInputs(request, menus)
+	key := s.key(aiCacheRecommendations, tenantID, inputs)
+	var recommendations []model.RecommendationResponseRaw
+	if s.lookup(aiCacheRecommendations, key, &recommendations) {
+		return recommendations, nil
+	}
+
+	recommendations, err := s.ai.GetRecommendations(ctx, request, menus)
+	if err == nil && len(recommendations) > 0 {
+		s.save(aiCacheRecommendations, key, recommendations)
+	}
+	return recommendations, err
+}
+
+func (s *cachedAIService) TranslateMenus(ctx context.Context, items []model.TranslationItem, locale string) ([]model.TranslationItem, error) {
+	if s.ttl(aiCacheTranslations) <= 0 {
+		return s.ai.TranslateMenus(ctx, items, locale)
+	}
+	key := s.key(aiCacheTranslations, 0, []any{model.NormalizeLocale(locale), items})
+	var translated []model.TranslationItem
+	if s.lookup(aiCacheTranslations, key, &translated) {
+		return translated, nil
+	}
+
+	translated, err := s.ai.TranslateMenus(ctx, items, locale)
+	if err == nil && len(translated) > 0 {
+		s.save(aiCacheTranslations, key, translated)
+	}
+	return translated, err
+}
+
+// SuggestEnrichment is not cached, its answers are kept as proposals or applied to the menu
+func (s *cachedAIService) SuggestEnrichment(ctx context.Context, menu model.Menu, categories []string) (model.EnrichmentSuggestion, error) {
+	return s.ai.SuggestEnrichment(ctx, menu, categories)
+}
+
+// ExtractMenus is not cached, a paper menu is read once and its drafts are reviewed
+func (s *cachedAIService) ExtractMenus(ctx context.Context, document model.MenuDocument, categories []string) ([]model.MenuDraft, error) {
+	return s.ai.ExtractMenus(ctx, document, categories)
+}
+
+// SummarizeReviews is not cached, the summary is stored on the menu
+func (s *cachedAIService) SummarizeReviews(ctx context.Context, menu model.Menu, reviews []model.Review) (model.ReviewSummary, error) {
+	return s.ai.SummarizeReviews(ctx, menu, reviews)
+}
+
+// GenerateDescriptionStream shares its entries with GenerateDescription, a cached
+// description is sent as a single token with no usage
+func (s *cachedAIService) GenerateDescriptionStream(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, emit func(string) error) (model.AIUsage, error) {
+	if s.ttl(aiCacheDescription) <= 0 {
+		return s.ai.GenerateDescriptionStream(ctx, name, ingredients, options, emit)
+	}
+	key := s.key(aiCacheDescription, 0, descriptionInputs(name, ingredients, options, 1))
+	var descriptions []string
+	if s.lookup(aiCacheDescription, key, &descriptions) && len(descriptions) > 0 {
+		return model.AIUsage{}, emit(descriptions[0])
+	}
+
+	var generated strings.Builder
+	usage, err := s.ai.GenerateDescriptionStream(ctx, name, ingredients, options, func(token string) error {
+		generated.WriteString(token)
+		return emit(token)
+	})
+	if description := strings.Trim(strings.TrimSpace(generated.String()), "\""); err == nil && description != "" {
+		s.save(aiCacheDescription, key, []string{description})
+	}
+	return usage, err
+}
+
+func (s *cachedAIService) GetRecommendationsStream(ctx context.Context, request model.RecommendationRequest, menus []model.Menu, emit func(model.RecommendationResponseRaw) error) (model.AIUsage, error) {
+	if s.ttl(aiCacheRecommendations) <= 0 {
+		return s.ai.GetRecommendationsStream(ctx, request, menus, emit)
+	}
+	tenantID, inputs := recommendationInputs(request, menus)
+	key := s.key(aiCacheRecommendations, tenantID, inputs)
+	var recommendations []model.RecommendationResponseRaw
+	if s.lookup(aiCacheRecommendations, key, &recommendations) {
+		for _, r := range recommendations {
+			if err := emit(r); err != nil {
+				return model.AIUsage{}, err
+			}
+		}
+		return model.AIUsage{}, nil
+	}
+
+	usage, err := s.ai.GetRecommendationsStream(ctx, request, menus, func(raw model.RecommendationResponseRaw) error {
+		recommendations = append(recommendations, raw)
+		return emit(raw)
+	})
+	if err == nil && len(recommendations) > 0 {
+		s.save(aiCacheRecommendations, key, recommendations)
+	}
+	return usage, err
+}
+
+func (s *cachedAIService) Close() error {
+	return s.ai.Close()
+}
+
+// invalidate drops the cached recommendations of a tenant
+func (s *cachedAIService) invalidate(tenantID uint) {
+	prefix := fmt.Sprintf("%s:%d:", aiCacheRecommendations, tenantID)
+	dropped := int64(s.lru.deletePrefix(prefix))
+	if s.store != nil {
+		n, err := s.store.DeletePrefix(prefix)
+		if err != nil {
+			log.Printf("Failed to invalidate cached recommendations of tenant %d: %v", tenantID, err)
+		}
+		dropped = max(dropped, n)
+	}
+
+	s.mu.Lock()
+	s.invalidations += dropped
+	s.mu.Unlock()
+}
+
+// MenuSaved implements MenuListener
+func (s *cachedAIService) MenuSaved(menu model.Menu) {
+	s.invalidate(menu.TenantID)
+}
+
+// MenuDeleted implements MenuListener
+func (s *cachedAIService) MenuDeleted(menu model.Menu) {
+	s.invalidate(menu.TenantID)
+}
+
+func (s *cachedAIService) Stats() model.AICacheStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := model.AICacheStats{
+		Entries:       s.lru.len(),
+		Invalidations: s.invalidations,
+		Methods:       make(map[string]model.AICacheMethodStats),
+	}
+	for _, method := range []string{aiCacheDescription, aiCacheRecommendations, aiCacheTranslations} {
+		hits, misses := s.hits[method], s.misses[method]
+		stats.Methods[method] = model.AICacheMethodStats{Hits: hits, Misses: misses, HitRate: hitRate(hits, misses)}
+		stats.Hits += hits
+		stats.Misses += misses
+	}
+	stats.HitRate = hitRate(stats.Hits, stats.Misses)
+	return stats
+}
+
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// lruCache keeps the most recently used answers up to its capacity
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List // most recently used first
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{capacity: capacity, items: make(map[string]*list.Element), order: list.New()}
+}
+
+func (c *lruCache) get(key string, now time.Time) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if !now.Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *lruCache) set(key string, value []byte, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.capacity <= 0 {
+		return
+	}
+	if element, ok := c.items[key]; ok {
+		element.Value = &lruEntry{key: key, value: value, expiresAt: expiresAt}
+		c.order.MoveToFront(element)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) deletePrefix(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	deleted := 0
+	for key, element := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.order.Remove(element)
+			delete(c.items, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+func (c *lruCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"atalariq/menu-api/internal/config"
+)
+
+// AIProviderFactory builds an AIService from the AI config
+type AIProviderFactory func(cfg config.AI) (AIService, error)
+
+// aiProviders maps AI_PROVIDER values to their implementation
+var aiProviders = map[string]AIProviderFactory{
+	"gemini": NewGeminiService,
+	"openai": NewOpenAIService,
+	"offline": func(config.AI) (AIService, error) {
+		return NewOfflineService(), nil
+	},
+}
+
+// RegisterAIProvider adds or replaces a provider selectable with AI_PROVIDER
+func RegisterAIProvider(name string, factory AIProviderFactory) {
+	aiProviders[strings.ToLower(name)] = factory
+}
+
+// AIProviders lists the registered provider names
+func AIProviders() []string {
+	names := make([]string, 0, len(aiProviders))
+	for name := range aiProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewAIService builds the provider selected by cfg.Provider
+func NewAIService(cfg config.AI) (AIService, error) {
+	factory, ok := aiProviders[cfg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown AI provider %q, available: %s", cfg.Provider, strings.Join(AIProviders(), ", "))
+	}
+	return factory(cfg)
+}
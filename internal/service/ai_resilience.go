@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/model"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var ErrAIUnavailable = errors.New("the AI provider is unavailable, try again later")
+
+// ProviderError is an HTTP error answered by the AI provider
+type ProviderError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ProviderError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("AI provider returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("AI provider returned %d: %s", e.StatusCode, e.Message)
+}
+
+// transientAIError tells the errors worth another attempt: timeouts, broken connections,
+// rate limits and server errors. Answers that could not be parsed are not, the repair
+// prompt already gave the model a second chance.
+func transientAIError(err error) bool {
+	var providerErr *ProviderError
+	var apiErr *googleapi.Error
+	var httpErr interface{ HTTPCode() int }
+	var netErr net.Error
+	switch {
+	case errors.As(err, &providerErr):
+		return retryableStatus(providerErr.StatusCode)
+	case errors.As(err, &apiErr):
+		return retryableStatus(apiErr.Code)
+	case errors.As(err, &httpErr) && httpErr.HTTPCode() > 0:
+		return retryableStatus(httpErr.HTTPCode())
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &netErr):
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.DeadlineExceeded, codes.Internal, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// callerError is a call failing on the caller's side, like a stream whose client went away.
+// It says nothing about the provider, so it is neither retried nor counted by the breaker.
+type callerError struct {
+	err error
+}
+
+func (e callerError) Error() string { return e.err.Error() }
+func (e callerError) Unwrap() error { return e.err }
+
+func retryableStatus(code int) bool {
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// circuitBreaker opens after threshold failed calls in a row, fails the calls at once for
+// cooldown, then lets one probe call through: its success closes the breaker, its failure
+// opens it for another cooldown
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	opens    int64
+}
+
+func (b *circuitBreaker) allow(now time.Time) error {
+	if b.threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case model.BreakerOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return ErrAIUnavailable
+		}
+		b.state = model.BreakerHalfOpen
+		log.Println("AI circuit breaker half-open, probing the provider")
+		return nil
+	case model.BreakerHalfOpen:
+		return ErrAIUnavailable // the probe is still on its way
+	}
+	return nil
+}
+
+// done records the outcome of a call let through, calls ended by the caller count for nothing
+func (b *circuitBreaker) done(now time.Time, err error, byCaller bool) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case byCaller:
+		if b.state == model.BreakerHalfOpen {
+			b.state = model.BreakerOpen // the next call probes again
+		}
+	case err == nil || !transientAIError(err):
+		// The provider answered, even an answer that could not be used shows it is up
+		if b.state != model.BreakerClosed {
+			log.Println("AI circuit breaker closed")
+		}
+		b.state = model.BreakerClosed
+		b.failures = 0
+	default:
+		b.failures++
+		if b.state == model.BreakerHalfOpen || b.failures >= b.threshold {
+			if b.state != model.BreakerOpen {
+				log.Printf("AI circuit breaker open after %d failures: %v", b.failures, err)
+				b.opens++
+			}
+			b.state = model.BreakerOpen
+			b.openedAt = now
+		}
+	}
+}
+
+func (b *circuitBreaker) status() model.AIBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := model.AIBreakerStatus{State: b.state, ConsecutiveFailures: b.failures, Opens: b.opens}
+	if b.state != model.BreakerClosed {
+		openedAt, probeAt := b.openedAt, b.openedAt.Add(b.cooldown)
+		status.OpenedAt, status.ProbeAt = &openedAt, &probeAt
+	}
+	return status
+}
+
+// ResilientAIService retries the transient errors of the provider with exponential backoff
+// and stops calling it for a while when it keeps failing. Calls refused by the open
+// breaker fail at once with ErrAIUnavailable, the callers then answer with their fallbacks.
+type ResilientAIService interface {
+	AIService
+	Breaker() model.AIBreakerStatus
+}
+
+type resilientAIService struct {
+	ai      AIService
+	cfg     config.AIResilience
+	breaker *circuitBreaker
+}
+
+func NewResilientAIService(ai AIService, cfg config.AIResilience) ResilientAIService {
+	return &resilientAIService{
+		ai:      ai,
+		cfg:     cfg,
+		breaker: &circuitBreaker{threshold: cfg.BreakerThreshold, cooldown: cfg.BreakerCooldown, state: model.BreakerClosed},
+	}
+}
+
+// backoff is the wait before the retry after the given attempt, with full jitter
+func (s *resilientAIService) backoff(attempt int) time.Duration {
+	delay := s.cfg.RetryBaseDelay << attempt
+	if delay <= 0 || delay > s.cfg.RetryMaxDelay {
+		delay = s.cfg.RetryMaxDelay
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// call runs attempt through the breaker, retrying transient errors. retryable reports
+// whether the attempt may run again, streams cannot once they emitted something.
+func (s *resilientAIService) call(ctx context.Context, attempt func() error, retryable func() bool) error {
+	if err := s.breaker.allow(time.Now()); err != nil {
+		return err
+	}
+
+	var err error
+	for i := 0; ; i++ {
+		err = attempt()
+		if err == nil || ctx.Err() != nil || errors.As(err, new(callerError)) || i >= s.cfg.Retries || !transientAIError(err) || !retryable() {
+			break
+		}
+		timer := time.NewTimer(s.backoff(i))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	byCaller := ctx.Err() != nil || errors.Is(err, context.Canceled)
+	var callerErr callerError
+	if errors.As(err, &callerErr) {
+		byCaller, err = true, callerErr.err
+	}
+	s.breaker.done(time.Now(), err, byCaller)
+	return err
+}
+
+func always() bool { return true }
+
+func (s *resilientAIService) Breaker() model.AIBreakerStatus {
+	return s.breaker.status()
+}
+
+func (s *resilientAIService) GenerateDescription(ctx context.Context, name string, ingredients []string) (string, error) {
+	var description string
+	err := s.call(ctx, func() (err error) {
+		description, err = s.ai.GenerateDescription(ctx, name, ingredients)
+		return err
+	}, always)
+	return description, err
+}
+
+func (s *resilientAIService) GenerateDescriptions(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, count int) ([]string, error) {
+	var descriptions []string
+	err := s.call(ctx, func() (err error) {
+		descriptions, err = s.ai.GenerateDescriptions(ctx, name, ingredients, options, count)
+		return err
+	}, always)
+	return descriptions, err
+}
+
+func (s *resilientAIService) GetRecommendations(ctx context.Context, request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error) {
+	var recommendations []model.RecommendationResponseRaw
+	err := s.call(ctx, func() (err error) {
+		recommendations, err = s.ai.GetRecommendations(ctx, request, menus)
+		return err
+	}, always)
+	return recommendations, err
+}
+
+func (s *resilientAIService) TranslateMenus(ctx context.Context, items []model.TranslationItem, locale string) ([]model.TranslationItem, error) {
+	var translated []model.TranslationItem
+	err := s.call(ctx, func() (err error) {
+		translated, err = s.ai.TranslateMenus(ctx, items, locale)
+		return err
+	}, always)
+	return translated, err
+}
+
+func (s *resilientAIService) SuggestEnrichment(ctx context.Context, menu model.Menu, categories []string) (model.EnrichmentSuggestion, error) {
+	var suggestion model.EnrichmentSuggestion
+	err := s.call(ctx, func() (err error) {
+		suggestion, err = s.ai.SuggestEnrichment(ctx, menu, categories)
+		return err
+	}, always)
+	return suggestion, err
+}
+
+func (s *resilientAIService) ExtractMenus(ctx context.Context, document model.MenuDocument, categories []string) ([]model.MenuDraft, error) {
+	var drafts []model.MenuDraft
+	err := s.call(ctx, func() (err error) {
+		drafts, err = s.ai.ExtractMenus(ctx, document, categories)
+		return err
+	}, always)
+	return drafts, err
+}
+
+func (s *resilientAIService) SummarizeReviews(ctx context.Context, menu model.Menu, reviews []model.Review) (model.ReviewSummary, error) {
+	var summary model.ReviewSummary
+	err := s.call(ctx, func() (err error) {
+		summary, err = s.ai.SummarizeReviews(ctx, menu, reviews)
+		return err
+	}, always)
+	return summary, err
+}
+
+func (s *resilientAIService) GenerateDescriptionStream(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, emit func(string) error) (model.AIUsage, error) {
+	var usage model.AIUsage
+	emitted := false
+	err := s.call(ctx, func() (err error) {
+		var emitErr error
+		usage, err = s.ai.GenerateDescriptionStream(ctx, name, ingredients, options, func(token string) error {
+			emitted = true
+			emitErr = emit(token)
+			return emitErr
+		})
+		if emitErr != nil {
+			return callerError{emitErr}
+		}
+		return err
+	}, func() bool { return !emitted })
+	return usage, err
+}
+
+func (s *resilientAIService) GetRecommendationsStream(ctx context.Context, request model.RecommendationRequest, menus []model.Menu, emit func(model.RecommendationResponseRaw) error) (model.AIUsage, error) {
+	var usage model.AIUsage
+	emitted := false
+	err := s.call(ctx, func() (err error) {
+		var emitErr error
+		usage, err = s.ai.GetRecommendationsStream(ctx, request, menus, func(raw model.RecommendationResponseRaw) error {
+			emitted = true
+			emitErr = emit(raw)
+			return emitErr
+		})
+		if emitErr != nil {
+			return callerError{emitErr}
+		}
+		return err
+	}, func() bool { return !emitted })
+	return usage, err
+}
+
+func (s *resilientAIService) Close() error {
+	return s.ai.Close()
+}
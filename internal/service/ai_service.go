@@ -1,10 +1,34 @@
 package service
 
 import (
+	"context"
+
 	"atalariq/menu-api/internal/model"
 )
 
+// AIService calls stop as soon as ctx is cancelled, e.g. when the HTTP client disconnects
 type AIService interface {
-	GenerateDescription(name string, ingredients []string) (string, error)
-	GetRecommendations(request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error)
+	// GenerateDescription writes one description with the default options
+	GenerateDescription(ctx context.Context, name string, ingredients []string) (string, error)
+	// GenerateDescriptions writes up to count distinct descriptions to choose from
+	GenerateDescriptions(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, count int) ([]string, error)
+	GetRecommendations(ctx context.Context, request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error)
+	TranslateMenus(ctx context.Context, items []model.TranslationItem, locale string) ([]model.TranslationItem, error)
+	// SuggestEnrichment suggests the category of a menu, one of categories when there are
+	// any, along with its calories, dietary tags and allergens
+	SuggestEnrichment(ctx context.Context, menu model.Menu, categories []string) (model.EnrichmentSuggestion, error)
+	// ExtractMenus reads the items of a photo or PDF of a paper menu, the categories are
+	// those the tenant already uses. Providers that cannot read files return
+	// ErrDocumentsNotSupported.
+	ExtractMenus(ctx context.Context, document model.MenuDocument, categories []string) ([]model.MenuDraft, error)
+	// SummarizeReviews sums up what the reviews, newest first, praise and criticize
+	SummarizeReviews(ctx context.Context, menu model.Menu, reviews []model.Review) (model.ReviewSummary, error)
+
+	// Streaming variants pass the output to emit while it is generated and return the
+	// token usage. They stop with the error of emit as soon as it returns one.
+	GenerateDescriptionStream(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, emit func(token string) error) (model.AIUsage, error)
+	GetRecommendationsStream(ctx context.Context, request model.RecommendationRequest, menus []model.Menu, emit func(model.RecommendationResponseRaw) error) (model.AIUsage, error)
+
+	// Close releases the provider connections on shutdown
+	Close() error
 }
@@ -0,0 +1,388 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/model"
+)
+
+var (
+	ErrTenantAIBudgetExceeded = errors.New("the monthly AI budget of the tenant is used up")
+	ErrAIBudgetExceeded       = errors.New("the monthly AI budget of the platform is used up, try again next month")
+)
+
+// aiBudgetRefresh is how long the monthly totals are trusted before they are read again,
+// other instances record calls in the same table
+const aiBudgetRefresh = time.Minute
+
+// AIUsageStore keeps the usage records, it is implemented by repository.AIUsageRepository
+type AIUsageStore interface {
+	Create(record *model.AIUsageRecord) error
+	TotalTokens(tenantID uint, since string) (int64, error)
+	Aggregate(query model.AIUsageQuery) ([]model.AIUsageBucket, error)
+}
+
+// AICaller identifies who makes the AI calls of a context in the usage records
+type AICaller struct {
+	TenantID uint
+	Endpoint string // e.g. "POST /menu/recommendations" or "job generate_description"
+}
+
+type aiCallerKey struct{}
+
+// WithAICaller attaches the caller to the AI calls made with ctx
+func WithAICaller(ctx context.Context, caller AICaller) context.Context {
+	return context.WithValue(ctx, aiCallerKey{}, caller)
+}
+
+func aiCallerOf(ctx context.Context) AICaller {
+	caller, _ := ctx.Value(aiCallerKey{}).(AICaller)
+	if caller.Endpoint == "" {
+		caller.Endpoint = "unknown"
+	}
+	return caller
+}
+
+// usageCounter adds up the provider calls made for one metered AIService call
+type usageCounter struct {
+	mu    sync.Mutex
+	calls int
+	usage model.AIUsage
+}
+
+type usageCounterKey struct{}
+
+// countUsage adds a provider call to the counter of ctx, providers call it even when
+// they report no tokens so that answers served from the cache can be told apart
+func countUsage(ctx context.Context, usage model.AIUsage) {
+	counter, ok := ctx.Value(usageCounterKey{}).(*usageCounter)
+	if !ok {
+		return
+	}
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	counter.calls++
+	counter.usage.PromptTokens += usage.PromptTokens
+	counter.usage.CompletionTokens += usage.CompletionTokens
+	counter.usage.TotalTokens += usage.TotalTokens
+}
+
+// AIBudgetChecker is implemented by MeteredAIService
+type AIBudgetChecker interface {
+	// CheckBudget returns ErrTenantAIBudgetExceeded or ErrAIBudgetExceeded once a monthly
+	// budget is used up, tenant 0 only checks the budget of the platform
+	CheckBudget(tenantID uint) error
+}
+
+// AIBudgetResetsAt is when the monthly budgets start again, the first day of the next month (UTC)
+func AIBudgetResetsAt(now time.Time) time.Time {
+	year, month, _ := now.UTC().Date()
+	return time.Date(year, month+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// MeteredAIService records every AI call with its tokens, latency, model, outcome and
+// caller, and refuses calls once a monthly budget is used up. Wrapped around the cache,
+// it records answers served from the cache with no tokens.
+type MeteredAIService interface {
+	AIService
+	AIBudgetChecker
+	Usage(query model.AIUsageQuery) (model.AIUsageReport, error)
+}
+
+type meteredAIService struct {
+	ai    AIService
+	cfg   config.AIBudget
+	model string // provider and model the answers come from
+	store AIUsageStore
+
+	mu     sync.Mutex
+	totals map[uint]monthTotal // tokens of the month per tenant, 0 for the platform
+}
+
+type monthTotal struct {
+	month    string
+	tokens   int64
+	loadedAt time.Time
+}
+
+// NewMeteredAIService wraps ai, providerModel names the provider and model that answer
+func NewMeteredAIService(ai AIService, cfg config.AIBudget, providerModel string, store AIUsageStore) MeteredAIService {
+	return &meteredAIService{
+		ai:     ai,
+		cfg:    cfg,
+		model:  providerModel,
+		store:  store,
+		totals: make(map[uint]monthTotal),
+	}
+}
+
+// meter checks the budgets, runs call with a usage counter and records it
+func (s *meteredAIService) meter(ctx context.Context, method string, call func(ctx context.Context) error) error {
+	caller := aiCallerOf(ctx)
+	if err := s.CheckBudget(caller.TenantID); err != nil {
+		return err
+	}
+
+	counter := &usageCounter{}
+	started := time.Now()
+	err := call(context.WithValue(ctx, usageCounterKey{}, counter))
+
+	counter.mu.Lock()
+	usage, calls := counter.usage, counter.calls
+	counter.mu.Unlock()
+
+	outcome := model.AIOutcomeOK
+	switch {
+	case err != nil && (ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+		outcome = model.AIOutcomeCancelled
+	case err != nil:
+		outcome = model.AIOutcomeError
+	case calls == 0:
+		outcome = model.AIOutcomeCached
+	}
+
+	record := model.AIUsageRecord{
+		TenantID:         caller.TenantID,
+		Endpoint:         caller.Endpoint,
+		Method:           method,
+		Model:            s.model,
+		Outcome:          outcome,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		LatencyMs:        time.Since(started).Milliseconds(),
+		Day:              started.UTC().Format(time.DateOnly),
+	}
+	if err := s.store.Create(&record); err != nil {
+		log.Printf("Failed to record AI usage: %v", err)
+	}
+	s.addTokens(record)
+	return err
+}
+
+// addTokens keeps the cached monthly totals up to date with the calls of this instance
+func (s *meteredAIService) addTokens(record model.AIUsageRecord) {
+	keys := []uint{0}
+	if record.TenantID != 0 {
+		keys = append(keys, record.TenantID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		if total, ok := s.totals[key]; ok && total.month == record.Day[:7] {
+			total.tokens += int64(record.TotalTokens)
+			s.totals[key] = total
+		}
+	}
+}
+
+// monthTokens is the number of tokens used this month, tenant 0 for the whole platform
+func (s *meteredAIService) monthTokens(tenantID uint, now time.Time) (int64, error) {
+	month := now.UTC().Format("2006-01")
+	s.mu.Lock()
+	total, ok := s.totals[tenantID]
+	s.mu.Unlock()
+	if ok && total.month == month && now.Sub(total.loadedAt) < aiBudgetRefresh {
+		return total.tokens, nil
+	}
+
+	tokens, err := s.store.TotalTokens(tenantID, month+"-01")
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	s.totals[tenantID] = monthTotal{month: month, tokens: tokens, loadedAt: now}
+	s.mu.Unlock()
+	return tokens, nil
+}
+
+// CheckBudget lets the calls through when the totals cannot be read, an unavailable
+// database must not turn off the AI features
+func (s *meteredAIService) CheckBudget(tenantID uint) error {
+	now := time.Now()
+	if s.cfg.TenantMonthlyTokens > 0 && tenantID != 0 {
+		used, err := s.monthTokens(tenantID, now)
+		if err != nil {
+			log.Printf("Failed to read the AI usage of tenant %d: %v", tenantID, err)
+		} else if used >= s.cfg.TenantMonthlyTokens {
+			return ErrTenantAIBudgetExceeded
+		}
+	}
+	if s.cfg.MonthlyTokens > 0 {
+		used, err := s.monthTokens(0, now)
+		if err != nil {
+			log.Printf("Failed to read the AI usage of the platform: %v", err)
+		} else if used >= s.cfg.MonthlyTokens {
+			return ErrAIBudgetExceeded
+		}
+	}
+	return nil
+}
+
+// cost estimates the price of the tokens of a bucket
+func (s *meteredAIService) cost(bucket model.AIUsageBucket) float64 {
+	return (float64(bucket.PromptTokens)*s.cfg.PromptPrice + float64(bucket.CompletionTokens)*s.cfg.CompletionPrice) / 1e6
+}
+
+func (s *meteredAIService) budgetStatus(tenantID uint, limit int64, now time.Time) (model.AIBudgetStatus, error) {
+	month := now.UTC().Format("2006-01")
+	used, err := s.store.TotalTokens(tenantID, month+"-01")
+	if err != nil {
+		return model.AIBudgetStatus{}, err
+	}
+	return model.AIBudgetStatus{
+		TenantID:   tenantID,
+		Month:      month,
+		TokenLimit: limit,
+		TokensUsed: used,
+		Exceeded:   limit > 0 && used >= limit,
+	}, nil
+}
+
+// Usage groups the recorded calls, from the first day of the month to today by default
+func (s *meteredAIService) Usage(query model.AIUsageQuery) (model.AIUsageReport, error) {
+	now := time.Now()
+	if query.GroupBy == "" {
+		query.GroupBy = model.AIUsageByDay
+	}
+	if query.From == "" {
+		query.From = now.UTC().Format("2006-01") + "-01"
+	}
+	if query.To == "" {
+		query.To = now.UTC().Format(time.DateOnly)
+	}
+
+	buckets, err := s.store.Aggregate(query)
+	if err != nil {
+		return model.AIUsageReport{}, err
+	}
+	report := model.AIUsageReport{
+		GroupBy: query.GroupBy,
+		From:    query.From,
+		To:      query.To,
+		Total:   model.AIUsageBucket{Key: "total"},
+		Buckets: make([]model.AIUsageBucket, 0, len(buckets)),
+	}
+	var latency float64
+	for _, bucket := range buckets {
+		bucket.Cost = s.cost(bucket)
+		report.Buckets = append(report.Buckets, bucket)
+
+		report.Total.Calls += bucket.Calls
+		report.Total.Errors += bucket.Errors
+		report.Total.Cached += bucket.Cached
+		report.Total.PromptTokens += bucket.PromptTokens
+		report.Total.CompletionTokens += bucket.CompletionTokens
+		report.Total.TotalTokens += bucket.TotalTokens
+		latency += bucket.AvgLatencyMs * float64(bucket.Calls)
+	}
+	if report.Total.Calls > 0 {
+		report.Total.AvgLatencyMs = latency / float64(report.Total.Calls)
+	}
+	report.Total.Cost = s.cost(report.Total)
+
+	platform, err := s.budgetStatus(0, s.cfg.MonthlyTokens, now)
+	if err != nil {
+		return model.AIUsageReport{}, err
+	}
+	report.Budgets = []model.AIBudgetStatus{platform}
+	if query.TenantID != 0 {
+		tenant, err := s.budgetStatus(query.TenantID, s.cfg.TenantMonthlyTokens, now)
+		if err != nil {
+			return model.AIUsageReport{}, err
+		}
+		report.Budgets = append(report.Budgets, tenant)
+	}
+	return report, nil
+}
+
+func (s *meteredAIService) GenerateDescription(ctx context.Context, name string, ingredients []string) (string, error) {
+	var description string
+	err := s.meter(ctx, "GenerateDescription", func(ctx context.Context) (err error) {
+		description, err = s.ai.GenerateDescription(ctx, name, ingredients)
+		return err
+	})
+	return description, err
+}
+
+func (s *meteredAIService) GenerateDescriptions(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, count int) ([]string, error) {
+	var descriptions []string
+	err := s.meter(ctx, "GenerateDescriptions", func(ctx context.Context) (err error) {
+		descriptions, err = s.ai.GenerateDescriptions(ctx, name, ingredients, options, count)
+		return err
+	})
+	return descriptions, err
+}
+
+func (s *meteredAIService) GetRecommendations(ctx context.Context, request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error) {
+	var recommendations []model.RecommendationResponseRaw
+	err := s.meter(ctx, "GetRecommendations", func(ctx context.Context) (err error) {
+		recommendations, err = s.ai.GetRecommendations(ctx, request, menus)
+		return err
+	})
+	return recommendations, err
+}
+
+func (s *meteredAIService) TranslateMenus(ctx context.Context, items []model.TranslationItem, locale string) ([]model.TranslationItem, error) {
+	var translated []model.TranslationItem
+	err := s.meter(ctx, "TranslateMenus", func(ctx context.Context) (err error) {
+		translated, err = s.ai.TranslateMenus(ctx, items, locale)
+		return err
+	})
+	return translated, err
+}
+
+func (s *meteredAIService) SuggestEnrichment(ctx context.Context, menu model.Menu, categories []string) (model.EnrichmentSuggestion, error) {
+	var suggestion model.EnrichmentSuggestion
+	err := s.meter(ctx, "SuggestEnrichment", func(ctx context.Context) (err error) {
+		suggestion, err = s.ai.SuggestEnrichment(ctx, menu, categories)
+		return err
+	})
+	return suggestion, err
+}
+
+func (s *meteredAIService) ExtractMenus(ctx context.Context, document model.MenuDocument, categories []string) ([]model.MenuDraft, error) {
+	var drafts []model.MenuDraft
+	err := s.meter(ctx, "ExtractMenus", func(ctx context.Context) (err error) {
+		drafts, err = s.ai.ExtractMenus(ctx, document, categories)
+		return err
+	})
+	return drafts, err
+}
+
+func (s *meteredAIService) SummarizeReviews(ctx context.Context, menu model.Menu, reviews []model.Review) (model.ReviewSummary, error) {
+	var summary model.ReviewSummary
+	err := s.meter(ctx, "SummarizeReviews", func(ctx context.Context) (err error) {
+		summary, err = s.ai.SummarizeReviews(ctx, menu, reviews)
+		return err
+	})
+	return summary, err
+}
+
+func (s *meteredAIService) GenerateDescriptionStream(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, emit func(string) error) (model.AIUsage, error) {
+	var usage model.AIUsage
+	err := s.meter(ctx, "GenerateDescriptionStream", func(ctx context.Context) (err error) {
+		usage, err = s.ai.GenerateDescriptionStream(ctx, name, ingredients, options, emit)
+		return err
+	})
+	return usage, err
+}
+
+func (s *meteredAIService) GetRecommendationsStream(ctx context.Context, request model.RecommendationRequest, menus []model.Menu, emit func(model.RecommendationResponseRaw) error) (model.AIUsage, error) {
+	var usage model.AIUsage
+	err := s.meter(ctx, "GetRecommendationsStream", func(ctx context.Context) (err error) {
+		usage, err = s.ai.GetRecommendationsStream(ctx, request, menus, emit)
+		return err
+	})
+	return usage, err
+}
+
+func (s *meteredAIService) Close() error {
+	return s.ai.Close()
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"atalariq/menu-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// JobGenerateDescription generates the description of a menu created without one
+const JobGenerateDescription = "generate_description"
+
+// fallbackDescriptionPrefix starts the placeholder kept when no description could be generated
+const fallbackDescriptionPrefix = "Delicious "
+
+// DefaultDescriptionSweepInterval is how often placeholder descriptions are generated again
+const DefaultDescriptionSweepInterval = time.Hour
+
+// descriptionSweepBatchSize is the number of menus the sweep loads at once
+const descriptionSweepBatchSize = 100
+
+// maxDescriptionSweeps is how many times the sweep queues a generation for a menu before
+// it keeps the placeholder for good, each queued job is retried with backoff on its own
+const maxDescriptionSweeps = 5
+
+var ErrJobQueueDisabled = errors.New("background jobs are not enabled")
+
+func (s *menuService) UseJobQueue(queue JobQueue) {
+	s.jobs = queue
+	queue.Register(JobGenerateDescription, descriptionJobHandler{s})
+}
+
+type descriptionJobHandler struct {
+	menus *menuService
+}
+
+func (h descriptionJobHandler) Run(ctx context.Context, job model.Job) error {
+	s := h.menus
+	menu, err := s.repo.FindByID(model.Scope{TenantID: job.TenantID}, job.MenuID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil // deleted in the meantime
+	}
+	if err != nil {
+		return err
+	}
+	if menu.DescriptionStatus == model.DescriptionStatusReady {
+		return nil // written by hand in the meantime
+	}
+
+	ctx = WithAICaller(ctx, AICaller{TenantID: job.TenantID, Endpoint: "job " + job.Type})
+	description, err := s.ai.GenerateDescription(ctx, menu.Name, menu.Ingredients)
+	if err != nil {
+		return err
+	}
+	if description == "" {
+		return errors.New("empty description from AI")
+	}
+	return s.saveGeneratedDescription(menu, description, model.DescriptionStatusReady)
+}
+
+// Failed keeps a placeholder until the sweep tries again
+func (h descriptionJobHandler) Failed(job model.Job, err error) {
+	log.Printf("Generating the description of menu %d failed after %d attempts: %v", job.MenuID, job.Attempts, err)
+
+	s := h.menus
+	menu, findErr := s.repo.FindByID(model.Scope{TenantID: job.TenantID}, job.MenuID)
+	if findErr != nil || menu.DescriptionStatus != model.DescriptionStatusPending {
+		return
+	}
+	if err := s.saveGeneratedDescription(menu, fallbackDescriptionPrefix+menu.Name, model.DescriptionStatusFallback); err != nil {
+		log.Printf("Failed to store the placeholder description of menu %d: %v", menu.ID, err)
+	}
+}
+
+func (s *menuService) saveGeneratedDescription(menu model.Menu, description, status string) error {
+	updated, err := s.repo.SetGeneratedDescription(menu.TenantID, menu.ID, description, status)
+	if err != nil || !updated {
+		return err
+	}
+	menu.Description = description
+	menu.DescriptionStatus = status
+	s.notifySaved(menu)
+	return nil
+}
+
+// ApplyDescription saves a description picked among the generated candidates
+func (s *menuService) ApplyDescription(scope model.Scope, id uint, description string) (model.Menu, error) {
+	menu, err := s.repo.FindByID(scope.Base(), id)
+	if err != nil {
+		return model.Menu{}, err
+	}
+	menu.Description = description
+	menu.DescriptionStatus = model.DescriptionStatusReady
+	if err := s.repo.Update(&menu); err != nil {
+		return menu, err
+	}
+	s.notifySaved(menu)
+	return menu, nil
+}
+
+func (s *menuService) DescriptionJob(scope model.Scope, menuID uint) (model.Job, error) {
+	if _, err := s.repo.FindByID(scope.Base(), menuID); err != nil {
+		return model.Job{}, err
+	}
+	if s.jobs == nil {
+		return model.Job{}, gorm.ErrRecordNotFound
+	}
+	return s.jobs.Latest(scope.TenantID, JobGenerateDescription, menuID)
+}
+
+// MarkLegacyPlaceholders flags the placeholders stored before description statuses existed,
+// which look like any description. It is run once, when the status column is added.
+func (s *menuService) MarkLegacyPlaceholders() (int64, error) {
+	return s.repo.MarkFallbackDescriptions(fallbackDescriptionPrefix)
+}
+
+// SweepDescriptions queues a generation for every placeholder or pending description
+// without an active job, up to maxDescriptionSweeps times per menu. It returns how many
+// jobs were queued.
+func (s *menuService) SweepDescriptions() (int, error) {
+	if s.jobs == nil {
+		return 0, ErrJobQueueDisabled
+	}
+
+	enqueued := 0
+	var afterID uint
+	for {
+		menus, err := s.repo.FindUngeneratedDescriptions(JobGenerateDescription, maxDescriptionSweeps, afterID, descriptionSweepBatchSize)
+		if err != nil || len(menus) == 0 {
+			return enqueued, err
+		}
+		swept := make([]uint, 0, len(menus))
+		for _, m := range menus {
+			if _, err := s.jobs.Enqueue(model.Job{Type: JobGenerateDescription, TenantID: m.TenantID, MenuID: m.ID}); err != nil {
+				return enqueued, errors.Join(err, s.repo.CountDescriptionSweeps(swept))
+			}
+			swept = append(swept, m.ID)
+			enqueued++
+		}
+		if err := s.repo.CountDescriptionSweeps(swept); err != nil {
+			return enqueued, err
+		}
+		afterID = menus[len(menus)-1].ID
+	}
+}
+
+// StartDescriptionSweep runs MenuService.SweepDescriptions now and then every interval until ctx is cancelled
+func StartDescriptionSweep(ctx context.Context, svc MenuService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			enqueued, err := svc.SweepDescriptions()
+			if err != nil {
+				log.Println("Description sweep failed:", err)
+			} else if enqueued > 0 {
+				log.Printf("Description sweep: %d descriptions queued for generation", enqueued)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"atalariq/menu-api/internal/embedding"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+)
+
+// semanticCandidateLimit bounds how many of the nearest menus a semantic search ranks
+const semanticCandidateLimit = 100
+
+// minSemanticScore drops matches that share next to nothing with the query
+const minSemanticScore = 0.15
+
+// Number of similar menus returned by default and at most
+const (
+	defaultSimilarMenus = 5
+	maxSimilarMenus     = 20
+)
+
+// embeddingBatchSize is the number of menus embedded in one provider call
+const embeddingBatchSize = 50
+
+// EmbeddingService keeps a vector per menu and answers queries by meaning
+type EmbeddingService interface {
+	SemanticSearch(ctx context.Context, scope model.Scope, filter model.MenuFilter) (model.MenuPaginationResponse, error)
+	Similar(ctx context.Context, scope model.Scope, menuID uint, limit int) ([]model.MenuResponse, error)
+	// Backfill embeds every menu without an up to date vector, it returns how many it embedded
+	Backfill(ctx context.Context) (int, error)
+	MenuListener
+}
+
+type embeddingService struct {
+	repo     repository.MenuRepository
+	menus    MenuService
+	embedder embedding.Embedder
+	store    embedding.Store
+}
+
+func NewEmbeddingService(repo repository.MenuRepository, menus MenuService, embedder embedding.Embedder, store embedding.Store) EmbeddingService {
+	return &embeddingService{
+		repo:     repo,
+		menus:    menus,
+		embedder: embedder,
+		store:    store,
+	}
+}
+
+// embeddingText is what a menu is embedded from
+func embeddingText(menu model.Menu) string {
+	var b strings.Builder
+	b.WriteString(menu.Name)
+	if menu.Category != "" {
+		b.WriteString(". Category: " + menu.Category)
+	}
+	if menu.Description != "" {
+		b.WriteString(". " + menu.Description)
+	}
+	if len(menu.Ingredients) > 0 {
+		b.WriteString(". Ingredients: " + strings.Join(menu.Ingredients, ", "))
+	}
+	return b.String()
+}
+
+func (s *embeddingService) checksum(menu model.Menu) string {
+	sum := sha256.Sum256([]byte(s.embedder.Model() + "\n" + embeddingText(menu)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// index embeds the menus whose vector is missing or stale
+func (s *embeddingService) index(ctx context.Context, menus []model.Menu) (int, error) {
+	ids := make([]uint, len(menus))
+	for i, m := range menus {
+		ids[i] = m.ID
+	}
+	checksums, err := s.store.Checksums(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	var stale []model.Menu
+	for _, m := range menus {
+		if checksums[m.ID] != s.checksum(m) {
+			stale = append(stale, m)
+		}
+	}
+	_, err = s.embedMenus(ctx, stale)
+	return len(stale), err
+}
+
+func (s *embeddingService) embedMenus(ctx context.Context, menus []model.Menu) ([]embedding.Vector, error) {
+	var vectors []embedding.Vector
+	for start := 0; start < len(menus); start += embeddingBatchSize {
+		batch := menus[start:min(start+embeddingBatchSize, len(menus))]
+		texts := make([]string, len(batch))
+		for i, m := range batch {
+			texts[i] = embeddingText(m)
+		}
+
+		values, err := s.embedder.Embed(ctx, texts)
+		if err != nil {
+			return vectors, err
+		}
+		for i, m := range batch {
+			vector := embedding.Vector{
+				TenantID: m.TenantID,
+				MenuID:   m.ID,
+				Model:    s.embedder.Model(),
+				Checksum: s.checksum(m),
+				Values:   values[i],
+			}
+			if err := s.store.Upsert(ctx, vector); err != nil {
+				return vectors, err
+			}
+			vectors = append(vectors, vector)
+		}
+	}
+	return vectors, nil
+}
+
+func (s *embeddingService) Backfill(ctx context.Context) (int, error) {
+	indexed := 0
+	var afterID uint
+	for {
+		menus, err := s.repo.FindBatch(afterID, embeddingBatchSize)
+		if err != nil || len(menus) == 0 {
+			return indexed, err
+		}
+		n, err := s.index(ctx, menus)
+		indexed += n
+		if err != nil {
+			return indexed, err
+		}
+		afterID = menus[len(menus)-1].ID
+	}
+}
+
+// SemanticSearch ranks the menus closest in meaning to filter.Query, the other filters
+// still apply. Without a query it is the same as a plain listing.
+func (s *embeddingService) SemanticSearch(ctx context.Context, scope model.Scope, filter model.MenuFilter) (model.MenuPaginationResponse, error) {
+	if strings.TrimSpace(filter.Query) == "" {
+		return s.menus.GetList(scope, filter)
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PerPage < 1 {
+		filter.PerPage = 10
+	}
+
+	values, err := s.embedder.Embed(ctx, []string{filter.Query})
+	if err != nil {
+		return model.MenuPaginationResponse{}, err
+	}
+	scores, err := s.nearest(ctx, embedding.Query{
+		TenantID: scope.TenantID,
+		Model:    s.embedder.Model(),
+		Values:   values[0],
+		Limit:    semanticCandidateLimit,
+	})
+	if err != nil {
+		return model.MenuPaginationResponse{}, err
+	}
+
+	page, perPage := filter.Page, filter.PerPage
+	result := model.MenuPaginationResponse{Page: page, PerPage: perPage, Data: []model.MenuResponse{}}
+	if len(scores) > 0 {
+		// The matches are loaded through the listing so filters, branch prices and translations apply
+		filter.Query = ""
+		filter.IDs = mapKeys(scores)
+		filter.Page, filter.PerPage = 1, len(filter.IDs)
+		if result, err = s.menus.GetList(scope, filter); err != nil {
+			return model.MenuPaginationResponse{}, err
+		}
+	} else if filter.WithFacets {
+		result.Facets = &model.MenuFacets{Categories: []model.FacetCount{}, Tags: []model.TagFacetCount{}, PriceBuckets: []model.PriceBucket{}}
+	}
+
+	menus := rankByScore(result.Data, scores, filter.Sort == "")
+	result.Total = int64(len(menus))
+	result.Page, result.PerPage = page, perPage
+	result.TotalPages = int(math.Ceil(float64(len(menus)) / float64(perPage)))
+	result.Data = menus[min((page-1)*perPage, len(menus)):min(page*perPage, len(menus))]
+	return result, nil
+}
+
+// Similar finds the menus closest in meaning to a menu of the tenant
+func (s *embeddingService) Similar(ctx context.Context, scope model.Scope, menuID uint, limit int) ([]model.MenuResponse, error) {
+	if limit < 1 {
+		limit = defaultSimilarMenus
+	}
+	limit = min(limit, maxSimilarMenus)
+
+	menu, err := s.repo.FindByID(scope.Base(), menuID)
+	if err != nil {
+		return nil, err
+	}
+
+	vector, err := s.store.Get(ctx, scope.TenantID, menuID)
+	if errors.Is(err, embedding.ErrNotFound) || (err == nil && vector.Checksum != s.checksum(menu)) {
+		var vectors []embedding.Vector
+		if vectors, err = s.embedMenus(ctx, []model.Menu{menu}); err == nil {
+			vector = vectors[0]
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := s.nearest(ctx, embedding.Query{
+		TenantID: scope.TenantID,
+		Model:    vector.Model,
+		Values:   vector.Values,
+		Limit:    limit,
+		Exclude:  menuID,
+	})
+	if err != nil || len(scores) == 0 {
+		return []model.MenuResponse{}, err
+	}
+
+	ids := mapKeys(scores)
+	result, err := s.menus.GetList(scope, model.MenuFilter{IDs: ids, Page: 1, PerPage: len(ids)})
+	if err != nil {
+		return nil, err
+	}
+	return rankByScore(result.Data, scores, true), nil
+}
+
+// nearest returns the score of every close enough match by menu ID
+func (s *embeddingService) nearest(ctx context.Context, query embedding.Query) (map[uint]float64, error) {
+	matches, err := s.store.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[uint]float64, len(matches))
+	for _, m := range matches {
+		if m.Score >= minSemanticScore {
+			scores[m.MenuID] = m.Score
+		}
+	}
+	return scores, nil
+}
+
+// rankByScore sets the score of every menu and, when reorder is set, puts the closest first
+func rankByScore(menus []model.MenuResponse, scores map[uint]float64, reorder bool) []model.MenuResponse {
+	ranked := make([]model.MenuResponse, len(menus))
+	for i, m := range menus {
+		m.Score = math.Round(scores[m.ID]*1000) / 1000
+		ranked[i] = m
+	}
+	if reorder {
+		sort.SliceStable(ranked, func(i, j int) bool {
+			if ranked[i].Score != ranked[j].Score {
+				return ranked[i].Score > ranked[j].Score
+			}
+			return ranked[i].ID < ranked[j].ID
+		})
+	}
+	return ranked
+}
+
+func mapKeys(scores map[uint]float64) []uint {
+	ids := make([]uint, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// MenuSaved implements MenuListener, the vector follows the text of the menu
+func (s *embeddingService) MenuSaved(menu model.Menu) {
+	if _, err := s.index(context.Background(), []model.Menu{menu}); err != nil {
+		log.Printf("Failed to embed menu %d: %v", menu.ID, err)
+	}
+}
+
+// MenuDeleted implements MenuListener
+func (s *embeddingService) MenuDeleted(menu model.Menu) {
+	if err := s.store.Delete(context.Background(), menu.ID); err != nil {
+		log.Printf("Failed to delete the embedding of menu %d: %v", menu.ID, err)
+	}
+}
@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"slices"
+	"strings"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+)
+
+var ErrProposalNotPending = errors.New("the proposal was already applied, rejected or superseded")
+
+// defaultProposalLimit is the number of menus given a proposal in one request when none is asked
+const defaultProposalLimit = 20
+
+// maxSuggestedCalories is the most calories a suggestion may give one serving
+const maxSuggestedCalories = 5000
+
+// enrichmentFields are the fields applied when a proposal names none
+var enrichmentFields = []string{model.EnrichCategory, model.EnrichCalories, model.EnrichDietaryTags, model.EnrichAllergens}
+
+// EnrichmentService suggests the category, calories, dietary tags and allergens of menus.
+// The suggestions either fill the empty fields of a new menu or wait as proposals on
+// existing menus until staff review them. When the AI fails they come from ingredient
+// rules and are flagged as a fallback.
+type EnrichmentService interface {
+	Suggest(ctx context.Context, scope model.Scope, request model.EnrichmentRequest) (model.EnrichmentSuggestion, error)
+	// CreateMenu creates the menu with the confident suggestions filling the fields left
+	// empty. When no suggestion can be made the menu is created as is, with a nil suggestion.
+	CreateMenu(ctx context.Context, scope model.Scope, input model.Menu) (model.Menu, *model.EnrichmentSuggestion, error)
+
+	// Proposals on existing menus
+	Propose(ctx context.Context, scope model.Scope, menuID uint) (model.EnrichmentProposal, error)
+	// ProposeMany stops at the first failure, the proposals made before it are kept
+	ProposeMany(ctx context.Context, scope model.Scope, request model.GenerateProposalsRequest) ([]model.EnrichmentProposal, error)
+	ListProposals(scope model.Scope, status string, menuID uint) ([]model.EnrichmentProposal, error)
+	ApplyProposal(scope model.Scope, id uint, request model.ApplyProposalRequest) (model.EnrichmentProposal, model.MenuResponse, error)
+	RejectProposal(scope model.Scope, id uint) (model.EnrichmentProposal, error)
+}
+
+type enrichmentService struct {
+	ai        AIService
+	menuRepo  repository.MenuRepository
+	tags      repository.TagRepository
+	proposals repository.EnrichmentProposalRepository
+	menus     MenuService
+}
+
+func NewEnrichmentService(ai AIService, menuRepo repository.MenuRepository, tags repository.TagRepository, proposals repository.EnrichmentProposalRepository, menus MenuService) EnrichmentService {
+	return &enrichmentService{
+		ai:        ai,
+		menuRepo:  menuRepo,
+		tags:      tags,
+		proposals: proposals,
+		menus:     menus,
+	}
+}
+
+func (s *enrichmentService) Suggest(ctx context.Context, scope model.Scope, request model.EnrichmentRequest) (model.EnrichmentSuggestion, error) {
+	return s.suggest(ctx, scope.TenantID, model.Menu{Name: request.Name, Ingredients: request.Ingredients, Description: request.Description})
+}
+
+func (s *enrichmentService) suggest(ctx context.Context, tenantID uint, menu model.Menu) (model.EnrichmentSuggestion, error) {
+	categories, err := s.menuRepo.FindCategories(tenantID)
+	if err != nil {
+		return model.EnrichmentSuggestion{}, err
+	}
+
+	source := model.EnrichmentSourceAI
+	suggestion, err := s.ai.SuggestEnrichment(ctx, menu, categories)
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, ErrTenantAIBudgetExceeded) || errors.Is(err, ErrAIBudgetExceeded) {
+			return model.EnrichmentSuggestion{}, err
+		}
+		log.Printf("AI enrichment failed, using ingredient rules: %v", err)
+		suggestion, source = suggestOffline(menu, categories), model.EnrichmentSourceFallback
+	}
+	suggestion = cleanSuggestion(suggestion, categories)
+	suggestion.Source = source
+	return suggestion, nil
+}
+
+// cleanSuggestion keeps what a menu can take from an answer: a category of the tenant,
+// plausible calories, known dietary tags and allergens, and confidences from 0 to 1
+func cleanSuggestion(answer model.EnrichmentSuggestion, categories []string) model.EnrichmentSuggestion {
+	var suggestion model.EnrichmentSuggestion
+
+	category := cleanAnswer(answer.Category, promptFieldLimit)
+	if len(categories) == 0 {
+		suggestion.Category = strings.ToLower(category)
+	}
+	for _, known := range categories {
+		if strings.EqualFold(known, category) {
+			suggestion.Category = known
+			break
+		}
+	}
+	if suggestion.Category != "" {
+		suggestion.CategoryConfidence = clampConfidence(answer.CategoryConfidence)
+	}
+
+	if answer.Calories > 0 && answer.Calories <= maxSuggestedCalories {
+		suggestion.Calories = answer.Calories
+		suggestion.CaloriesConfidence = clampConfidence(answer.CaloriesConfidence)
+	}
+
+	suggestion.DietaryTags = cleanLabels(answer.DietaryTags, func(slug string) bool {
+		_, ok := model.DietaryTags[slug]
+		return ok
+	})
+	suggestion.Allergens = cleanLabels(answer.Allergens, func(allergen string) bool {
+		return slices.Contains(model.Allergens, allergen)
+	})
+	return suggestion
+}
+
+// cleanLabels keeps the first mention of each known label, "Gluten Free" reads as "gluten-free"
+func cleanLabels(labels []model.SuggestedLabel, known func(string) bool) []model.SuggestedLabel {
+	cleaned := []model.SuggestedLabel{}
+	seen := make(map[string]bool)
+	for _, label := range labels {
+		name := Slugify(label.Name)
+		if !known(name) || seen[name] {
+			continue
+		}
+		seen[name] = true
+		cleaned = append(cleaned, model.SuggestedLabel{Name: name, Confidence: clampConfidence(label.Confidence)})
+	}
+	return cleaned
+}
+
+func clampConfidence(confidence float64) float64 {
+	if math.IsNaN(confidence) {
+		return 0
+	}
+	return math.Max(0, math.Min(1, confidence))
+}
+
+// applySuggestion sets the fields of menu from the suggestions at least minConfidence sure.
+// Allergens are added to the ones of the menu, never removed. It returns the fields that
+// changed and the dietary tags to add.
+func applySuggestion(menu *model.Menu, suggestion model.EnrichmentSuggestion, fields []string, minConfidence float64) ([]string, []string) {
+	applied := []string{}
+	var tagSlugs []string
+	for _, field := range fields {
+		switch field {
+		case model.EnrichCategory:
+			if suggestion.Category != "" && suggestion.CategoryConfidence >= minConfidence && suggestion.Category != menu.Category {
+				menu.Category = suggestion.Category
+				applied = append(applied, field)
+			}
+		case model.EnrichCalories:
+			if suggestion.Calories > 0 && suggestion.CaloriesConfidence >= minConfidence && suggestion.Calories != menu.Calories {
+				menu.Calories = suggestion.Calories
+				applied = append(applied, field)
+			}
+		case model.EnrichDietaryTags:
+			for _, tag := range suggestion.DietaryTags {
+				if tag.Confidence >= minConfidence && !slices.ContainsFunc(menu.Tags, func(t model.Tag) bool { return t.Slug == tag.Name }) {
+					tagSlugs = append(tagSlugs, tag.Name)
+				}
+			}
+			if len(tagSlugs) > 0 {
+				applied = append(applied, field)
+			}
+		case model.EnrichAllergens:
+			added := false
+			for _, allergen := range suggestion.Allergens {
+				if allergen.Confidence >= minConfidence && !slices.Contains(menu.Allergens, allergen.Name) {
+					menu.Allergens = append(menu.Allergens, allergen.Name)
+					added = true
+				}
+			}
+			if added {
+				applied = append(applied, field)
+			}
+		}
+	}
+	return applied, tagSlugs
+}
+
+// addTags adds the dietary tags to the menu, creating the tags the tenant does not have yet
+func (s *enrichmentService) addTags(tenantID uint, menu model.Menu, slugs []string) ([]model.Tag, error) {
+	existing, err := s.tags.FindBySlugs(tenantID, slugs)
+	if err != nil {
+		return menu.Tags, err
+	}
+	tags := slices.Clone(menu.Tags)
+	for _, slug := range slugs {
+		i := slices.IndexFunc(existing, func(t model.Tag) bool { return t.Slug == slug })
+		if i >= 0 {
+			tags = append(tags, existing[i])
+			continue
+		}
+		tag := model.Tag{TenantID: tenantID, Name: model.DietaryTags[slug], Slug: slug}
+		if err := s.tags.Create(&tag); err != nil {
+			return menu.Tags, err
+		}
+		tags = append(tags, tag)
+	}
+	if err := s.tags.ReplaceMenuTags(tenantID, menu.ID, tags); err != nil {
+		return menu.Tags, err
+	}
+	return tags, nil
+}
+
+func (s *enrichmentService) CreateMenu(ctx context.Context, scope model.Scope, input model.Menu) (model.Menu, *model.EnrichmentSuggestion, error) {
+	suggestion, err := s.suggest(ctx, scope.TenantID, input)
+	if err != nil {
+		if ctx.Err() != nil {
+			return model.Menu{}, nil, ctx.Err()
+		}
+		// A used-up AI budget never stops staff from adding menus
+		log.Printf("Menu enrichment skipped: %v", err)
+		menu, err := s.menus.Create(ctx, scope, input)
+		return menu, nil, err
+	}
+
+	fields := []string{model.EnrichDietaryTags, model.EnrichAllergens}
+	if input.Category == "" {
+		fields = append(fields, model.EnrichCategory)
+	}
+	if input.Calories == 0 {
+		fields = append(fields, model.EnrichCalories)
+	}
+	input.Tags = nil // tags given on input are ignored by Create
+	_, tagSlugs := applySuggestion(&input, suggestion, fields, model.DefaultEnrichmentConfidence)
+
+	menu, err := s.menus.Create(ctx, scope, input)
+	if err != nil {
+		return menu, nil, err
+	}
+	if len(tagSlugs) > 0 {
+		if menu.Tags, err = s.addTags(scope.TenantID, menu, tagSlugs); err != nil {
+			log.Printf("Failed to add the suggested tags of menu %d: %v", menu.ID, err)
+		}
+	}
+	return menu, &suggestion, nil
+}
+
+func (s *enrichmentService) Propose(ctx context.Context, scope model.Scope, menuID uint) (model.EnrichmentProposal, error) {
+	menu, err := s.menuRepo.FindByID(scope.Base(), menuID)
+	if err != nil {
+		return model.EnrichmentProposal{}, err
+	}
+	return s.propose(ctx, menu)
+}
+
+func (s *enrichmentService) propose(ctx context.Context, menu model.Menu) (model.EnrichmentProposal, error) {
+	suggestion, err := s.suggest(ctx, menu.TenantID, menu)
+	if err != nil {
+		return model.EnrichmentProposal{}, err
+	}
+	proposal := model.EnrichmentProposal{
+		TenantID:   menu.TenantID,
+		MenuID:     menu.ID,
+		Status:     model.ProposalStatusPending,
+		Suggestion: suggestion,
+	}
+	err = s.proposals.Create(&proposal)
+	return proposal, err
+}
+
+func (s *enrichmentService) ProposeMany(ctx context.Context, scope model.Scope, request model.GenerateProposalsRequest) ([]model.EnrichmentProposal, error) {
+	var menus []model.Menu
+	if len(request.MenuIDs) > 0 {
+		for _, id := range request.MenuIDs {
+			menu, err := s.menuRepo.FindByID(scope.Base(), id)
+			if err != nil {
+				return nil, err
+			}
+			menus = append(menus, menu)
+		}
+	} else {
+		limit := request.Limit
+		if limit <= 0 {
+			limit = defaultProposalLimit
+		}
+		var err error
+		if menus, err = s.menuRepo.FindUnenriched(scope.TenantID, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	proposals := []model.EnrichmentProposal{}
+	for _, menu := range menus {
+		proposal, err := s.propose(ctx, menu)
+		if err != nil {
+			return proposals, err
+		}
+		proposals = append(proposals, proposal)
+	}
+	return proposals, nil
+}
+
+func (s *enrichmentService) ListProposals(scope model.Scope, status string, menuID uint) ([]model.EnrichmentProposal, error) {
+	return s.proposals.FindAll(scope.TenantID, status, menuID)
+}
+
+// ApplyProposal overwrites the category and calories of the menu, and adds the dietary
+// tags and allergens to the ones it has
+func (s *enrichmentService) ApplyProposal(scope model.Scope, id uint, request model.ApplyProposalRequest) (model.EnrichmentProposal, model.MenuResponse, error) {
+	proposal, err := s.pendingProposal(scope, id)
+	if err != nil {
+		return proposal, model.MenuResponse{}, err
+	}
+	menu, err := s.menuRepo.FindByID(scope.Base(), proposal.MenuID)
+	if err != nil {
+		return proposal, model.MenuResponse{}, err
+	}
+
+	fields := request.Fields
+	if len(fields) == 0 {
+		fields = enrichmentFields
+	}
+	minConfidence := model.DefaultEnrichmentConfidence
+	if request.MinConfidence != nil {
+		minConfidence = *request.MinConfidence
+	}
+	applied, tagSlugs := applySuggestion(&menu, proposal.Suggestion, fields, minConfidence)
+
+	if slices.ContainsFunc(applied, func(field string) bool { return field != model.EnrichDietaryTags }) {
+		if _, err := s.menus.Update(scope, menu.ID, model.UpdateMenuRequest{Menu: menu}); err != nil {
+			return proposal, model.MenuResponse{}, err
+		}
+	}
+	if len(tagSlugs) > 0 {
+		if _, err := s.addTags(scope.TenantID, menu, tagSlugs); err != nil {
+			return proposal, model.MenuResponse{}, err
+		}
+	}
+
+	proposal.Status = model.ProposalStatusApplied
+	proposal.AppliedFields = applied
+	if err := s.updateStatus(&proposal); err != nil {
+		return proposal, model.MenuResponse{}, err
+	}
+	detail, err := s.menus.GetDetail(scope, menu.ID)
+	return proposal, detail, err
+}
+
+func (s *enrichmentService) RejectProposal(scope model.Scope, id uint) (model.EnrichmentProposal, error) {
+	proposal, err := s.pendingProposal(scope, id)
+	if err != nil {
+		return proposal, err
+	}
+	proposal.Status = model.ProposalStatusRejected
+	err = s.updateStatus(&proposal)
+	return proposal, err
+}
+
+func (s *enrichmentService) pendingProposal(scope model.Scope, id uint) (model.EnrichmentProposal, error) {
+	proposal, err := s.proposals.FindByID(scope.TenantID, id)
+	if err != nil {
+		return proposal, err
+	}
+	if proposal.Status != model.ProposalStatusPending {
+		return proposal, ErrProposalNotPending
+	}
+	return proposal, nil
+}
+
+// updateStatus fails when another request applied or rejected the proposal meanwhile
+func (s *enrichmentService) updateStatus(proposal *model.EnrichmentProposal) error {
+	updated, err := s.proposals.UpdateStatus(proposal)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrProposalNotPending
+	}
+	return nil
+}
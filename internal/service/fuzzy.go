@@ -0,0 +1,91 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"atalariq/menu-api/internal/model"
+)
+
+// minNameSimilarity is the edit-distance similarity needed to accept a misspelled menu name
+const minNameSimilarity = 0.75
+
+// matchMenuName finds the menu an AI answer refers to. Models often change the case,
+// drop accents or punctuation, add a suffix ("Kopi Susu (iced)") or make a small typo.
+func matchMenuName(name string, menus []model.Menu) (model.Menu, bool) {
+	for _, m := range menus {
+		if m.Name == name {
+			return m, true
+		}
+	}
+
+	wanted := normalizeName(name)
+	if wanted == "" {
+		return model.Menu{}, false
+	}
+	for _, m := range menus {
+		if normalizeName(m.Name) == wanted {
+			return m, true
+		}
+	}
+
+	// The longest menu name quoted inside the answer
+	best, bestLen := -1, 0
+	for i, m := range menus {
+		candidate := normalizeName(m.Name)
+		if len(candidate) >= 4 && len(candidate) > bestLen && strings.Contains(" "+wanted+" ", " "+candidate+" ") {
+			best, bestLen = i, len(candidate)
+		}
+	}
+	if best >= 0 {
+		return menus[best], true
+	}
+
+	bestScore := 0.0
+	for i, m := range menus {
+		if score := similarity(wanted, normalizeName(m.Name)); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if bestScore >= minNameSimilarity {
+		return menus[best], true
+	}
+	return model.Menu{}, false
+}
+
+// normalizeName lowercases and keeps letters and digits separated by single spaces
+func normalizeName(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// similarity is 1 minus the Levenshtein distance relative to the longer string
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -1,124 +1,176 @@
 package service
 
 import (
-	"atalariq/menu-api/internal/model"
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"os"
+	"net/http"
 	"strings"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/model"
 
 	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
+const defaultGeminiModel = "gemini-2.0-flash"
+
+// geminiService keeps one client for the lifetime of the server, so calls reuse its connections
 type geminiService struct {
-	apiKey string
+	client  *genai.Client
+	model   string
+	timeout time.Duration
 }
 
-func NewGeminiService() AIService {
-	return &geminiService{
-		apiKey: os.Getenv("GEMINI_API_KEY"),
+func NewGeminiService(cfg config.AI) (AIService, error) {
+	if cfg.APIKey == "" {
+		return nil, errors.New("gemini provider requires AI_API_KEY or GEMINI_API_KEY, use AI_PROVIDER=offline to run without a key")
 	}
-}
 
-func (s *geminiService) callGemini(prompt string) (string, error) {
-	ctx := context.Background()
-
-	// Setup client
-	client, err := genai.NewClient(ctx, option.WithAPIKey(s.apiKey))
-	if err != nil {
-		return "", err
+	options := []option.ClientOption{option.WithAPIKey(cfg.APIKey)}
+	if cfg.Transport != nil {
+		// A custom HTTP client replaces the one authenticating with the key
+		options = append(options, option.WithHTTPClient(&http.Client{Transport: &geminiKeyTransport{key: cfg.APIKey, next: cfg.Transport}}))
 	}
-	defer client.Close()
-
-	// Use Gemini flash model
-	model := client.GenerativeModel("gemini-2.0-flash")
-
-	// Generate content based on given prompt
-	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	client, err := genai.NewClient(context.Background(), options...)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return "", errors.New("empty response from AI")
+	gemini := &geminiService{
+		client:  client,
+		model:   cfg.Model,
+		timeout: cfg.Timeout,
 	}
-
-	// Extract and clean up text
-	if textPart, ok := resp.Candidates[0].Content.Parts[0].(genai.Text); ok {
-		cleanText := strings.TrimSpace(string(textPart))
-		cleanText = strings.Trim(cleanText, "\"")
-
-		return cleanText, nil
+	if gemini.model == "" {
+		gemini.model = defaultGeminiModel
 	}
-
-	return "", errors.New("unexpected response format")
+	if gemini.timeout <= 0 {
+		gemini.timeout = config.DefaultAITimeout
+	}
+	return &llmService{generate: gemini.callGemini, stream: gemini.streamGemini, generateFile: gemini.callGeminiFile, close: client.Close}, nil
 }
 
-func (s *geminiService) GenerateDescription(name string, ingredients []string) (string, error) {
-	prompt := fmt.Sprintf(`
-		Role: Senior Culinary Copywriter.
-		Task: Write a menu description for "%s".
+// geminiKeyTransport authenticates the requests the way option.WithAPIKey does
+type geminiKeyTransport struct {
+	key  string
+	next http.RoundTripper
+}
 
-		Ingredients: %s.
+func (t *geminiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("x-goog-api-key", t.key)
+	return t.next.RoundTrip(req)
+}
 
-		Constraints:
-		1. Focus on SENSORY details (texture, temperature, specific flavor notes).
-		2. Do NOT use generic words like "delicious", "yummy", or "tasty".
-		3. Keep it under 20 words.
-		4. Language: English (Elegant & Appetizing).
+func (s *geminiService) generativeModel(schema *jsonSchema) *genai.GenerativeModel {
+	model := s.client.GenerativeModel(s.model)
+	if schema != nil {
+		model.ResponseMIMEType = "application/json"
+		model.ResponseSchema = toGeminiSchema(schema)
+	}
+	return model
+}
 
-		Output example: "Silky steamed milk meets robust espresso, finished with a touch of caramelized sweetness."
+func (s *geminiService) callGemini(ctx context.Context, prompt string, schema *jsonSchema) (string, model.AIUsage, error) {
+	return s.generate(ctx, schema, genai.Text(prompt))
+}
 
-		Result without any intro or chit-chat:
-		`, name, strings.Join(ingredients, ", "))
-	return s.callGemini(prompt)
+// callGeminiFile sends the photo or PDF inline after the prompt
+func (s *geminiService) callGeminiFile(ctx context.Context, prompt string, document model.MenuDocument, schema *jsonSchema) (string, model.AIUsage, error) {
+	return s.generate(ctx, schema, genai.Text(prompt), genai.Blob{MIMEType: document.ContentType, Data: document.Data})
 }
 
-func (s *geminiService) GetRecommendations(request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error) {
-	menuMap := make(map[string]model.Menu)
-	var menuListBuilder strings.Builder
+func (s *geminiService) generate(ctx context.Context, schema *jsonSchema, parts ...genai.Part) (string, model.AIUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
 
-	for _, m := range menus {
-		menuMap[m.Name] = m
+	// Generate content based on given prompt
+	resp, err := s.generativeModel(schema).GenerateContent(ctx, parts...)
+	if err != nil {
+		return "", model.AIUsage{}, err
+	}
+	usage := geminiUsage(resp.UsageMetadata)
 
-		menuListBuilder.WriteString(fmt.Sprintf("- %s (Ingredients: %s, Category: %s)\n",
-			m.Name, strings.Join(m.Ingredients, ", "), m.Category))
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return "", usage, errors.New("empty response from AI")
 	}
 
-	userPreference := request.Preference
-	prompt := fmt.Sprintf(`
-	Role: Strict Menu Recommendation Engine.
-	Context:
-	User Request: "%s"
-	Available Menu:
-	%s
+	// Extract and clean up text
+	if textPart, ok := resp.Candidates[0].Content.Parts[0].(genai.Text); ok {
+		return strings.TrimSpace(string(textPart)), usage, nil
+	}
 
-	Task: Recommend 1-3 items based on the user request.
+	return "", usage, errors.New("unexpected response format")
+}
 
-	CRITICAL INSTRUCTION:
-	1. Output MUST be a valid JSON Array.
-	2. Use the EXACT menu name from the list above.
-	3. Format: [{"menu_name": "Exact Name", "reason": "Why it fits"}]
-	4. No Markdown. No Intro.
-	`, userPreference, menuListBuilder.String())
+func geminiUsage(metadata *genai.UsageMetadata) model.AIUsage {
+	if metadata == nil {
+		return model.AIUsage{}
+	}
+	return model.AIUsage{
+		PromptTokens:     int(metadata.PromptTokenCount),
+		CompletionTokens: int(metadata.CandidatesTokenCount),
+		TotalTokens:      int(metadata.TotalTokenCount),
+	}
+}
 
-	rawResponse, err := s.callGemini(prompt)
-	if err != nil {
-		return nil, err
+func (s *geminiService) streamGemini(ctx context.Context, prompt string, schema *jsonSchema, onChunk func(string) error) (model.AIUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var usage model.AIUsage
+	responses := s.generativeModel(schema).GenerateContentStream(ctx, genai.Text(prompt))
+	for {
+		resp, err := responses.Next()
+		if errors.Is(err, iterator.Done) {
+			return usage, nil
+		}
+		if err != nil {
+			return usage, err
+		}
+
+		// Every response carries the usage so far, the last one the total
+		if resp.UsageMetadata != nil {
+			usage = geminiUsage(resp.UsageMetadata)
+		}
+		if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+			continue
+		}
+		for _, part := range resp.Candidates[0].Content.Parts {
+			if text, ok := part.(genai.Text); ok {
+				if err := onChunk(string(text)); err != nil {
+					return usage, err
+				}
+			}
+		}
 	}
+}
 
-	cleanJSON := strings.TrimSpace(rawResponse)
-	cleanJSON = strings.TrimPrefix(cleanJSON, "```json")
-	cleanJSON = strings.TrimPrefix(cleanJSON, "```")
-	cleanJSON = strings.TrimSuffix(cleanJSON, "```")
+var geminiTypes = map[string]genai.Type{
+	"array":   genai.TypeArray,
+	"object":  genai.TypeObject,
+	"string":  genai.TypeString,
+	"integer": genai.TypeInteger,
+	"number":  genai.TypeNumber,
+}
 
-	var rawRecommendations []model.RecommendationResponseRaw
-	if err := json.Unmarshal([]byte(cleanJSON), &rawRecommendations); err != nil {
-		return nil, fmt.Errorf("failed to parse AI response: %v", err)
+func toGeminiSchema(schema *jsonSchema) *genai.Schema {
+	if schema == nil {
+		return nil
 	}
-
-	return rawRecommendations, nil
+	out := &genai.Schema{
+		Type:     geminiTypes[schema.Type],
+		Items:    toGeminiSchema(schema.Items),
+		Required: schema.Required,
+	}
+	if len(schema.Properties) > 0 {
+		out.Properties = make(map[string]*genai.Schema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			out.Properties[name] = toGeminiSchema(property)
+		}
+	}
+	return out
 }
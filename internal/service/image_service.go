@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+
+	"atalariq/menu-api/internal/imaging"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+	"atalariq/menu-api/internal/storage"
+)
+
+var ErrImageTooLarge = errors.New("image is too large")
+
+// Longest side in pixels of the generated variants
+const (
+	largeImageSize     = 1280
+	thumbnailImageSize = 320
+)
+
+// jpegImageQuality is the quality of the JPEG variants
+const jpegImageQuality = 85
+
+type ImageService interface {
+	Upload(ctx context.Context, scope model.Scope, menuID uint, data []byte) (model.MenuResponse, error)
+	Delete(ctx context.Context, scope model.Scope, menuID uint) error
+	MaxBytes() int64
+	MenuListener
+}
+
+type imageService struct {
+	repo     repository.MenuRepository
+	menus    MenuService
+	storage  storage.Storage
+	maxBytes int64
+}
+
+func NewImageService(repo repository.MenuRepository, menus MenuService, storage storage.Storage, maxBytes int64) ImageService {
+	return &imageService{
+		repo:     repo,
+		menus:    menus,
+		storage:  storage,
+		maxBytes: maxBytes,
+	}
+}
+
+func (s *imageService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// Upload validates the photo by its content, stores it with resized variants, in WebP
+// when it is smaller than JPEG, then replaces the previous image of the menu
+func (s *imageService) Upload(ctx context.Context, scope model.Scope, menuID uint, data []byte) (model.MenuResponse, error) {
+	if int64(len(data)) > s.maxBytes {
+		return model.MenuResponse{}, ErrImageTooLarge
+	}
+
+	existing, err := s.repo.FindByID(scope.Base(), menuID)
+	if err != nil {
+		return model.MenuResponse{}, err
+	}
+
+	contentType, ext, err := imaging.SniffContentType(data)
+	if err != nil {
+		return model.MenuResponse{}, err
+	}
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return model.MenuResponse{}, fmt.Errorf("%w: %v", imaging.ErrUnsupportedFormat, err)
+	}
+
+	prefix, err := imagePrefix(scope.TenantID, menuID)
+	if err != nil {
+		return model.MenuResponse{}, err
+	}
+
+	newImage := &model.MenuImage{Variants: make(map[string]model.ImageVariant)}
+	put := func(variant, name, contentType string, body []byte, bounds image.Rectangle) error {
+		key := prefix + "/" + name
+		if err := s.storage.Put(ctx, key, body, contentType); err != nil {
+			return err
+		}
+		newImage.Variants[variant] = model.ImageVariant{
+			Key:         key,
+			URL:         s.storage.URL(key),
+			ContentType: contentType,
+			Width:       bounds.Dx(),
+			Height:      bounds.Dy(),
+			Size:        len(body),
+		}
+		return nil
+	}
+
+	err = put(model.ImageVariantOriginal, "original."+ext, contentType, data, img.Bounds())
+	if err == nil {
+		// The large variant is the smaller of WebP and JPEG, usually JPEG for photos
+		large := imaging.Fit(img, largeImageSize)
+		var webpData, jpegData []byte
+		if webpData, jpegData, err = encodeVariant(large); err == nil {
+			if webpData != nil {
+				err = put(model.ImageVariantLarge, "large.webp", "image/webp", webpData, large.Bounds())
+			} else {
+				err = put(model.ImageVariantLarge, "large.jpg", "image/jpeg", jpegData, large.Bounds())
+			}
+		}
+	}
+	if err == nil {
+		// The JPEG thumbnail is always kept for clients without WebP support
+		thumbnail := imaging.Fit(img, thumbnailImageSize)
+		var webpData, jpegData []byte
+		if webpData, jpegData, err = encodeVariant(thumbnail); err == nil {
+			err = put(model.ImageVariantThumbnail, "thumbnail.jpg", "image/jpeg", jpegData, thumbnail.Bounds())
+		}
+		if err == nil && webpData != nil {
+			err = put(model.ImageVariantThumbnailWebP, "thumbnail.webp", "image/webp", webpData, thumbnail.Bounds())
+		}
+	}
+	if err != nil {
+		s.deleteVariants(ctx, newImage)
+		return model.MenuResponse{}, err
+	}
+
+	if err := s.repo.UpdateImage(scope.TenantID, menuID, newImage); err != nil {
+		s.deleteVariants(ctx, newImage)
+		return model.MenuResponse{}, err
+	}
+	s.deleteVariants(ctx, existing.Image)
+
+	return s.menus.GetDetail(scope, menuID)
+}
+
+// encodeVariant encodes img as JPEG and as lossless WebP. The WebP data is nil when it
+// is not smaller than the JPEG, which is the case for most photos.
+func encodeVariant(img image.Image) (webpData, jpegData []byte, err error) {
+	if jpegData, err = imaging.EncodeJPEGBytes(img, jpegImageQuality); err != nil {
+		return nil, nil, err
+	}
+	if webpData, err = imaging.EncodeWebPBytes(img); err != nil {
+		return nil, nil, err
+	}
+	if len(webpData) >= len(jpegData) {
+		webpData = nil
+	}
+	return webpData, jpegData, nil
+}
+
+func (s *imageService) Delete(ctx context.Context, scope model.Scope, menuID uint) error {
+	existing, err := s.repo.FindByID(scope.Base(), menuID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdateImage(scope.TenantID, menuID, nil); err != nil {
+		return err
+	}
+	s.deleteVariants(ctx, existing.Image)
+	return nil
+}
+
+// deleteVariants is best effort, an orphaned file must not fail the request
+func (s *imageService) deleteVariants(ctx context.Context, img *model.MenuImage) {
+	if img == nil {
+		return
+	}
+	for _, variant := range img.Variants {
+		if err := s.storage.Delete(ctx, variant.Key); err != nil {
+			log.Printf("Failed to delete image %s: %v", variant.Key, err)
+		}
+	}
+}
+
+// imagePrefix is unique per upload so CDNs never serve a stale image
+func imagePrefix(tenantID, menuID uint) (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("tenants/%d/menus/%d/%s", tenantID, menuID, hex.EncodeToString(buf)), nil
+}
+
+// MenuSaved implements MenuListener, images are only changed through Upload and Delete
+func (s *imageService) MenuSaved(model.Menu) {}
+
+// MenuDeleted implements MenuListener and removes the files of a deleted menu
+func (s *imageService) MenuDeleted(menu model.Menu) {
+	s.deleteVariants(context.Background(), menu.Image)
+}
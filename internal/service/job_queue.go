@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// Defaults of the job queue
+const (
+	DefaultJobWorkers     = 2
+	defaultJobMaxAttempts = 5
+	jobPollInterval       = 5 * time.Second
+	// jobLease is how long a worker owns a job before another worker may claim it again
+	jobLease = 2 * time.Minute
+	// jobRunTimeout cancels a longer run, well before its lease ends so that the job
+	// never runs on two workers at once
+	jobRunTimeout     = jobLease - 30*time.Second
+	jobBackoffBase    = 5 * time.Second
+	jobBackoffMaximum = 10 * time.Minute
+)
+
+// JobHandler runs the jobs of one type
+type JobHandler interface {
+	Run(ctx context.Context, job model.Job) error
+	// Failed is called once the job has used all its attempts
+	Failed(job model.Job, err error)
+}
+
+// JobQueue runs background jobs stored in the database with a pool of workers.
+// A failed job is retried with exponential backoff until MaxAttempts.
+type JobQueue interface {
+	Register(jobType string, handler JobHandler)
+	Enqueue(job model.Job) (model.Job, error)
+	Latest(tenantID uint, jobType string, menuID uint) (model.Job, error)
+	// Start runs the workers until ctx is cancelled
+	Start(ctx context.Context, workers int)
+	// RunDue runs every job that is due now one after the other, it returns how many ran
+	RunDue(ctx context.Context) (int, error)
+}
+
+type jobQueue struct {
+	repo     repository.JobRepository
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+	wake     chan struct{}
+}
+
+func NewJobQueue(repo repository.JobRepository) JobQueue {
+	return &jobQueue{
+		repo:     repo,
+		handlers: make(map[string]JobHandler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+func (q *jobQueue) Register(jobType string, handler JobHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+func (q *jobQueue) handler(jobType string) (JobHandler, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	handler, ok := q.handlers[jobType]
+	return handler, ok
+}
+
+func (q *jobQueue) Enqueue(job model.Job) (model.Job, error) {
+	job.ID = 0
+	job.Status = model.JobStatusQueued
+	job.Attempts = 0
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = defaultJobMaxAttempts
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	if err := q.repo.Create(&job); err != nil {
+		return job, err
+	}
+
+	// Wakes an idle worker instead of waiting for the next poll
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+func (q *jobQueue) Latest(tenantID uint, jobType string, menuID uint) (model.Job, error) {
+	return q.repo.FindLatest(tenantID, jobType, menuID)
+}
+
+func (q *jobQueue) Start(ctx context.Context, workers int) {
+	if workers < 1 {
+		workers = DefaultJobWorkers
+	}
+	for range workers {
+		go q.work(ctx)
+	}
+}
+
+func (q *jobQueue) work(ctx context.Context) {
+	for {
+		if _, err := q.RunDue(ctx); err != nil {
+			log.Println("Job queue:", err)
+		}
+		timer := time.NewTimer(jobPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-q.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (q *jobQueue) RunDue(ctx context.Context) (int, error) {
+	ran := 0
+	for ctx.Err() == nil {
+		job, err := q.repo.Claim(time.Now(), jobLease)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ran, nil
+		}
+		if err != nil {
+			return ran, err
+		}
+		ran++
+		if err := q.run(ctx, job); err != nil {
+			return ran, err
+		}
+	}
+	return ran, nil
+}
+
+// run executes a claimed job and records the outcome
+func (q *jobQueue) run(ctx context.Context, job model.Job) error {
+	leasedUntil := *job.LockedUntil
+	handler, ok := q.handler(job.Type)
+	var err error
+	if ok {
+		runCtx, cancel := context.WithTimeout(ctx, jobRunTimeout)
+		err = handler.Run(runCtx, job)
+		cancel()
+	} else {
+		err = fmt.Errorf("no handler for job type %q", job.Type)
+		job.Attempts = job.MaxAttempts
+	}
+
+	now := time.Now()
+	job.LockedUntil = nil
+	job.UpdatedAt = now
+	switch {
+	case err == nil:
+		job.Status = model.JobStatusDone
+		job.LastError = ""
+	case ctx.Err() != nil:
+		// Shutting down is not the job's fault, it runs again on the next start
+		job.Status = model.JobStatusQueued
+		job.Attempts--
+		job.RunAt = now
+	case job.Attempts < job.MaxAttempts:
+		job.Status = model.JobStatusQueued
+		job.RunAt = now.Add(jobBackoff(job.Attempts))
+		job.LastError = err.Error()
+	default:
+		job.Status = model.JobStatusFailed
+		job.LastError = err.Error()
+	}
+
+	if updateErr := q.repo.Update(&job, leasedUntil); updateErr != nil {
+		if errors.Is(updateErr, repository.ErrJobLeaseLost) {
+			// The worker that claimed the job again records its outcome
+			log.Printf("Job %d outlived its lease, its outcome is dropped", job.ID)
+			return nil
+		}
+		return updateErr
+	}
+	if job.Status == model.JobStatusFailed && ok {
+		handler.Failed(job, err)
+	}
+	return nil
+}
+
+// jobBackoff doubles the delay after every failed attempt, up to jobBackoffMaximum
+func jobBackoff(attempts int) time.Duration {
+	delay := jobBackoffBase
+	for i := 1; i < attempts && delay < jobBackoffMaximum; i++ {
+		delay *= 2
+	}
+	return min(delay, jobBackoffMaximum)
+}
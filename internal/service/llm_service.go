@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"atalariq/menu-api/internal/model"
+)
+
+// llmService implements AIService on top of any text generation model.
+// Providers only supply generate, stream and close, the prompts and parsing are shared.
+// A non-nil schema asks the provider for structured JSON output.
+type llmService struct {
+	// generate answers in one piece with the token usage, when the provider reports it
+	generate func(ctx context.Context, prompt string, schema *jsonSchema) (string, model.AIUsage, error)
+	// stream passes the text to onChunk as it is generated, providers without it answer in one chunk
+	stream func(ctx context.Context, prompt string, schema *jsonSchema, onChunk func(string) error) (model.AIUsage, error)
+	// generateFile answers about an attached photo or PDF, nil when the provider cannot read files
+	generateFile func(ctx context.Context, prompt string, document model.MenuDocument, schema *jsonSchema) (string, model.AIUsage, error)
+	close        func() error
+	// prompts renders the prompts, the built-in templates when nil
+	prompts PromptStore
+}
+
+var (
+	ErrDocumentsNotSupported = errors.New("the AI provider cannot read photos or PDFs")
+	// ErrUnparsableAnswer is returned when the answer is still not the JSON asked for after the repair prompt
+	ErrUnparsableAnswer = errors.New("failed to parse AI response")
+)
+
+// PromptUser is implemented by the providers that send prompts rendered from templates
+type PromptUser interface {
+	UsePrompts(store PromptStore)
+}
+
+func (s *llmService) UsePrompts(store PromptStore) {
+	s.prompts = store
+}
+
+// complete generates the answer and counts its tokens for the usage meter
+func (s *llmService) complete(ctx context.Context, prompt string, schema *jsonSchema) (string, error) {
+	text, usage, err := s.generate(ctx, prompt, schema)
+	countUsage(ctx, usage)
+	return text, err
+}
+
+func (s *llmService) render(name string, data any) (string, error) {
+	if s.prompts == nil {
+		return defaultPrompts().Render(name, data)
+	}
+	return s.prompts.Render(name, data)
+}
+
+// Example answers quoted in prompts
+const (
+	recommendationFormat = `[{"menu_id": 12, "reason": "Why it fits", "confidence": 0.8}]`
+	descriptionsFormat   = `["First description", "Second description"]`
+	translationFormat    = `[{"id": 1, "name": "Translated name", "description": "Translated description"}]`
+	enrichmentFormat     = `[{"category": "drinks", "category_confidence": 0.9, "calories": 180, "calories_confidence": 0.5, "dietary_tags": [{"name": "vegetarian", "confidence": 0.8}], "allergens": [{"name": "milk", "confidence": 0.9}]}]`
+	extractionFormat     = `[{"name": "Nasi Goreng", "price": 25000, "category": "food", "ingredients": ["rice", "egg"], "description": "", "confidence": 0.9}]`
+	reviewSummaryFormat  = `[{"summary": "Loved for its smoky flavor, some find it too oily.", "pros": ["smoky flavor", "generous portion"], "cons": ["oily"]}]`
+)
+
+var (
+	descriptionsSchema = arrayOf(stringSchema)
+
+	recommendationSchema = arrayOf(objectOf(map[string]*jsonSchema{
+		"menu_id":    integerSchema,
+		"reason":     stringSchema,
+		"confidence": numberSchema,
+	}, "menu_id", "reason", "confidence"))
+
+	translationSchema = arrayOf(objectOf(map[string]*jsonSchema{
+		"id":          integerSchema,
+		"name":        stringSchema,
+		"description": stringSchema,
+	}, "id", "name", "description"))
+
+	labelSchema = objectOf(map[string]*jsonSchema{
+		"name":       stringSchema,
+		"confidence": numberSchema,
+	}, "name", "confidence")
+
+	enrichmentSchema = arrayOf(objectOf(map[string]*jsonSchema{
+		"category":            stringSchema,
+		"category_confidence": numberSchema,
+		"calories":            integerSchema,
+		"calories_confidence": numberSchema,
+		"dietary_tags":        arrayOf(labelSchema),
+		"allergens":           arrayOf(labelSchema),
+	}, "category", "category_confidence", "calories", "calories_confidence", "dietary_tags", "allergens"))
+
+	extractionSchema = arrayOf(objectOf(map[string]*jsonSchema{
+		"name":        stringSchema,
+		"price":       numberSchema,
+		"category":    stringSchema,
+		"ingredients": arrayOf(stringSchema),
+		"description": stringSchema,
+		"confidence":  numberSchema,
+	}, "name", "price", "category", "ingredients", "description", "confidence"))
+
+	reviewSummarySchema = arrayOf(objectOf(map[string]*jsonSchema{
+		"summary": stringSchema,
+		"pros":    arrayOf(stringSchema),
+		"cons":    arrayOf(stringSchema),
+	}, "summary", "pros", "cons"))
+)
+
+// generateJSONArray decodes the JSON array answered by the model into out. Prose around
+// the array is ignored, and an unparsable answer is retried once with a repair prompt.
+func (s *llmService) generateJSONArray(ctx context.Context, prompt string, schema *jsonSchema, format string, out any) error {
+	raw, err := s.complete(ctx, prompt, schema)
+	if err != nil {
+		return err
+	}
+	return s.decodeOrRepair(ctx, raw, schema, format, out)
+}
+
+// decodeOrRepair decodes raw into out, asking the model once to repair an unparsable answer
+func (s *llmService) decodeOrRepair(ctx context.Context, raw string, schema *jsonSchema, format string, out any) error {
+	parseErr := decodeJSONArray(raw, out)
+	if parseErr == nil {
+		return nil
+	}
+
+	repairPrompt, err := s.render(PromptRepair, repairPromptData(parseErr, raw, format))
+	if err != nil {
+		return err
+	}
+
+	raw, err = s.complete(ctx, repairPrompt, schema)
+	if err != nil {
+		return err
+	}
+	if err := decodeJSONArray(raw, out); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnparsableAnswer, err)
+	}
+	return nil
+}
+
+func decodeJSONArray(raw string, out any) error {
+	array, err := extractJSONArray(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(array), out)
+}
+
+func (s *llmService) Close() error {
+	if s.close == nil {
+		return nil
+	}
+	return s.close()
+}
+
+// toneStyles is the writing style asked for each tone
+var toneStyles = map[string]string{
+	model.ToneCasual:  "Friendly & Relaxed",
+	model.TonePremium: "Elegant & Appetizing",
+	model.TonePlayful: "Playful & Witty",
+}
+
+func (s *llmService) GenerateDescription(ctx context.Context, name string, ingredients []string) (string, error) {
+	descriptions, err := s.GenerateDescriptions(ctx, name, ingredients, model.DescriptionOptions{}, 1)
+	if err != nil {
+		return "", err
+	}
+	return descriptions[0], nil
+}
+
+func (s *llmService) GenerateDescriptions(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, count int) ([]string, error) {
+	prompt, err := s.render(PromptDescription, descriptionPromptData(name, ingredients, options, count))
+	if err != nil {
+		return nil, err
+	}
+	if count <= 1 {
+		description, err := s.complete(ctx, prompt, nil)
+		if err != nil {
+			return nil, err
+		}
+		return []string{cleanDescription(description)}, nil
+	}
+
+	var answers []string
+	if err := s.generateJSONArray(ctx, prompt, descriptionsSchema, descriptionsFormat, &answers); err != nil {
+		return nil, err
+	}
+	descriptions := uniqueDescriptions(answers, count)
+	if len(descriptions) == 0 {
+		return nil, errors.New("empty response from AI")
+	}
+	return descriptions, nil
+}
+
+// cleanDescription drops the quotes some models wrap the answer in
+func cleanDescription(description string) string {
+	return cleanAnswer(strings.Trim(strings.TrimSpace(description), "\""), maxDescriptionLength)
+}
+
+// uniqueDescriptions keeps up to count distinct, non-empty descriptions in order
+func uniqueDescriptions(answers []string, count int) []string {
+	descriptions := make([]string, 0, count)
+	seen := make(map[string]bool)
+	for _, answer := range answers {
+		description := cleanDescription(answer)
+		key := strings.ToLower(description)
+		if description == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		descriptions = append(descriptions, description)
+		if len(descriptions) == count {
+			break
+		}
+	}
+	return descriptions
+}
+
+func (s *llmService) GetRecommendations(ctx context.Context, request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error) {
+	prompt, err := s.render(PromptRecommendation, recommendationPromptData(request, menus))
+	if err != nil {
+		return nil, err
+	}
+	var rawRecommendations []model.RecommendationResponseRaw
+	err = s.generateJSONArray(ctx, prompt, recommendationSchema, recommendationFormat, &rawRecommendations)
+	return rawRecommendations, err
+}
+
+// streamText streams with the provider, or generates the whole text as one chunk
+func (s *llmService) streamText(ctx context.Context, prompt string, schema *jsonSchema, onChunk func(string) error) (model.AIUsage, error) {
+	if s.stream != nil {
+		usage, err := s.stream(ctx, prompt, schema, onChunk)
+		countUsage(ctx, usage)
+		return usage, err
+	}
+	text, usage, err := s.generate(ctx, prompt, schema)
+	countUsage(ctx, usage)
+	if err != nil {
+		return usage, err
+	}
+	return usage, onChunk(text)
+}
+
+func (s *llmService) GenerateDescriptionStream(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, emit func(string) error) (model.AIUsage, error) {
+	// The quotes some models wrap the answer in are only known at the end, the tokens are
+	// sent as they come and the final description is trimmed by the caller
+	prompt, err := s.render(PromptDescription, descriptionPromptData(name, ingredients, options, 1))
+	if err != nil {
+		return model.AIUsage{}, err
+	}
+	return s.streamText(ctx, prompt, nil, func(chunk string) error {
+		if chunk == "" {
+			return nil
+		}
+		return emit(chunk)
+	})
+}
+
+// GetRecommendationsStream emits every recommendation as soon as its object is complete.
+// An answer that cannot be read item by item is parsed whole at the end, there is no
+// repair prompt since the items already emitted cannot be taken back.
+func (s *llmService) GetRecommendationsStream(ctx context.Context, request model.RecommendationRequest, menus []model.Menu, emit func(model.RecommendationResponseRaw) error) (model.AIUsage, error) {
+	prompt, err := s.render(PromptRecommendation, recommendationPromptData(request, menus))
+	if err != nil {
+		return model.AIUsage{}, err
+	}
+	objects := newJSONObjectStream()
+	emitted := 0
+	var answer strings.Builder
+	usage, err := s.streamText(ctx, prompt, recommendationSchema, func(chunk string) error {
+		answer.WriteString(chunk)
+		for _, object := range objects.write(chunk) {
+			var raw model.RecommendationResponseRaw
+			if json.Unmarshal([]byte(object), &raw) != nil {
+				continue
+			}
+			emitted++
+			if err := emit(raw); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || emitted > 0 {
+		return usage, err
+	}
+
+	var rawRecommendations []model.RecommendationResponseRaw
+	if err := decodeJSONArray(answer.String(), &rawRecommendations); err != nil {
+		return usage, fmt.Errorf("%w: %v", ErrUnparsableAnswer, err)
+	}
+	for _, raw := range rawRecommendations {
+		if err := emit(raw); err != nil {
+			return usage, err
+		}
+	}
+	return usage, nil
+}
+
+func (s *llmService) TranslateMenus(ctx context.Context, items []model.TranslationItem, locale string) ([]model.TranslationItem, error) {
+	data, err := translationPromptData(items, locale)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := s.render(PromptTranslation, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var translated []model.TranslationItem
+	err = s.generateJSONArray(ctx, prompt, translationSchema, translationFormat, &translated)
+	return translated, err
+}
+
+// SuggestEnrichment returns the answer as the model wrote it, the caller keeps the
+// values it knows of
+func (s *llmService) SuggestEnrichment(ctx context.Context, menu model.Menu, categories []string) (model.EnrichmentSuggestion, error) {
+	prompt, err := s.render(PromptEnrichment, enrichmentPromptData(menu, categories))
+	if err != nil {
+		return model.EnrichmentSuggestion{}, err
+	}
+	var suggestions []model.EnrichmentSuggestion
+	if err := s.generateJSONArray(ctx, prompt, enrichmentSchema, enrichmentFormat, &suggestions); err != nil {
+		return model.EnrichmentSuggestion{}, err
+	}
+	if len(suggestions) == 0 {
+		return model.EnrichmentSuggestion{}, errors.New("empty response from AI")
+	}
+	return suggestions[0], nil
+}
+
+// SummarizeReviews returns the answer as the model wrote it, the caller cleans it
+func (s *llmService) SummarizeReviews(ctx context.Context, menu model.Menu, reviews []model.Review) (model.ReviewSummary, error) {
+	prompt, err := s.render(PromptReviewSummary, reviewSummaryPromptData(menu, reviews))
+	if err != nil {
+		return model.ReviewSummary{}, err
+	}
+	var summaries []model.ReviewSummary
+	if err := s.generateJSONArray(ctx, prompt, reviewSummarySchema, reviewSummaryFormat, &summaries); err != nil {
+		return model.ReviewSummary{}, err
+	}
+	if len(summaries) == 0 {
+		return model.ReviewSummary{}, errors.New("empty response from AI")
+	}
+	return summaries[0], nil
+}
+
+// ExtractMenus sends the document along with the prompt, the repair prompt only quotes
+// the previous answer
+func (s *llmService) ExtractMenus(ctx context.Context, document model.MenuDocument, categories []string) ([]model.MenuDraft, error) {
+	if s.generateFile == nil {
+		return nil, ErrDocumentsNotSupported
+	}
+	prompt, err := s.render(PromptExtraction, extractionPromptData(categories))
+	if err != nil {
+		return nil, err
+	}
+	raw, usage, err := s.generateFile(ctx, prompt, document, extractionSchema)
+	countUsage(ctx, usage)
+	if err != nil {
+		return nil, err
+	}
+	var drafts []model.MenuDraft
+	if err := s.decodeOrRepair(ctx, raw, extractionSchema, extractionFormat, &drafts); err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
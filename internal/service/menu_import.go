@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"atalariq/menu-api/internal/model"
+)
+
+// MaxMenuDocumentBytes bounds the photo or PDF of a paper menu
+const MaxMenuDocumentBytes = 10 << 20
+
+var (
+	ErrUnsupportedMenuDocument = errors.New("unsupported menu document, use a JPEG, PNG or WebP photo or a PDF")
+	ErrMenuDocumentTooLarge    = fmt.Errorf("menu document is larger than %d MB", MaxMenuDocumentBytes>>20)
+)
+
+// menuDocumentTypes are the content types every AI provider reads
+var menuDocumentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// SniffMenuDocument detects the type of a paper menu from its content, not the client header
+func SniffMenuDocument(data []byte) (model.MenuDocument, error) {
+	if len(data) > MaxMenuDocumentBytes {
+		return model.MenuDocument{}, ErrMenuDocumentTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	if !menuDocumentTypes[contentType] {
+		return model.MenuDocument{}, ErrUnsupportedMenuDocument
+	}
+	return model.MenuDocument{Data: data, ContentType: contentType}, nil
+}
+
+// ExtractMenus reads the items of a paper menu into drafts to review, nothing is saved.
+// The drafts are valid input of Import.
+func (s *menuService) ExtractMenus(ctx context.Context, scope model.Scope, document model.MenuDocument) ([]model.MenuDraft, error) {
+	categories, err := s.repo.FindCategories(scope.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := s.ai.ExtractMenus(ctx, document, categories)
+	if err != nil {
+		return nil, err
+	}
+	return cleanDrafts(answers, categories), nil
+}
+
+// Lengths a draft is cut to, as validated by the import
+const (
+	maxDraftNameLength        = 100
+	maxDraftCategoryLength    = 50
+	maxDraftIngredients       = 30
+	maxDraftIngredientLength  = 100
+	maxDraftDescriptionLength = 1000
+)
+
+// cleanDrafts drops the items without a name and the repeated ones, and fits the others
+// to the import: lengths within bounds, no negative price, the categories of the tenant
+// in their own spelling
+func cleanDrafts(answers []model.MenuDraft, categories []string) []model.MenuDraft {
+	drafts := []model.MenuDraft{}
+	seen := make(map[string]bool)
+	for _, answer := range answers {
+		name := cleanAnswer(answer.Name, maxDraftNameLength)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		draft := model.MenuDraft{
+			Name:        name,
+			Category:    strings.ToLower(cleanAnswer(answer.Category, maxDraftCategoryLength)),
+			Ingredients: sanitizePromptList(answer.Ingredients, maxDraftIngredientLength),
+			Description: cleanAnswer(answer.Description, maxDraftDescriptionLength),
+			Confidence:  clampConfidence(answer.Confidence),
+		}
+		if answer.Price > 0 && !math.IsInf(answer.Price, 0) {
+			draft.Price = answer.Price
+		}
+		for _, known := range categories {
+			if strings.EqualFold(known, draft.Category) {
+				draft.Category = known
+				break
+			}
+		}
+		if len(draft.Ingredients) > maxDraftIngredients {
+			draft.Ingredients = draft.Ingredients[:maxDraftIngredients]
+		}
+		drafts = append(drafts, draft)
+		if len(drafts) == model.MaxExtractedMenus {
+			break
+		}
+	}
+	return drafts
+}
+
+// Import creates the menus in one transaction, all of them or none. Menus without a
+// description get one from the job queue, or a placeholder when background jobs are off,
+// so that an import never waits for the AI.
+func (s *menuService) Import(scope model.Scope, drafts []model.MenuDraft) ([]model.Menu, error) {
+	menus := make([]model.Menu, len(drafts))
+	for i, draft := range drafts {
+		if draft.Price < 0 {
+			return nil, fmt.Errorf("menu %d: price cannot be negative", i+1)
+		}
+		menu := draft.ToMenu()
+		menu.TenantID = scope.TenantID
+		normalizeAvailability(&menu)
+		switch {
+		case menu.Description != "":
+			menu.DescriptionStatus = model.DescriptionStatusReady
+		case s.jobs != nil:
+			menu.DescriptionStatus = model.DescriptionStatusPending
+		default:
+			menu.Description = fallbackDescriptionPrefix + menu.Name
+			menu.DescriptionStatus = model.DescriptionStatusFallback
+		}
+		menus[i] = menu
+	}
+
+	if err := s.repo.CreateMany(menus); err != nil {
+		return nil, err
+	}
+	for _, menu := range menus {
+		if menu.DescriptionStatus == model.DescriptionStatusPending {
+			if _, err := s.jobs.Enqueue(model.Job{Type: JobGenerateDescription, TenantID: menu.TenantID, MenuID: menu.ID}); err != nil {
+				log.Printf("Failed to queue the description of menu %d, the sweep will retry: %v", menu.ID, err)
+			}
+		}
+		s.notifySaved(menu)
+	}
+	return menus, nil
+}
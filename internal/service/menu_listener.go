@@ -0,0 +1,26 @@
+package service
+
+import "atalariq/menu-api/internal/model"
+
+// MenuListener is notified after a menu is written, so derived data such as
+// stored images can follow the catalog without the menu service knowing about it
+type MenuListener interface {
+	MenuSaved(menu model.Menu)
+	MenuDeleted(menu model.Menu)
+}
+
+func (s *menuService) AddListener(listener MenuListener) {
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *menuService) notifySaved(menu model.Menu) {
+	for _, listener := range s.listeners {
+		listener.MenuSaved(menu)
+	}
+}
+
+func (s *menuService) notifyDeleted(menu model.Menu) {
+	for _, listener := range s.listeners {
+		listener.MenuDeleted(menu)
+	}
+}
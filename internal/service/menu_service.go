@@ -2,28 +2,78 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
 
 	"atalariq/menu-api/internal/model"
 	"atalariq/menu-api/internal/repository"
 )
 
 type MenuService interface {
-	Create(input model.Menu) (model.Menu, error)
-	GetList(filter model.MenuFilter) (model.MenuPaginationResponse, error)
-	GetDetail(id uint) (model.MenuResponse, error)
-	Update(id uint, input model.Menu) (model.Menu, error)
-	Delete(id uint) error
-	GetGrouped(mode string, limit int) (any, error)
+	Create(ctx context.Context, scope model.Scope, input model.Menu) (model.Menu, error)
+	GetList(scope model.Scope, filter model.MenuFilter) (model.MenuPaginationResponse, error)
+	GetDetail(scope model.Scope, id uint) (model.MenuResponse, error)
+	Update(scope model.Scope, id uint, input model.UpdateMenuRequest) (model.Menu, error)
+	Delete(scope model.Scope, id uint) error
+	GetGrouped(scope model.Scope, mode string, limit int) (any, error)
+
+	// Paper menus and bulk import
+	ExtractMenus(ctx context.Context, scope model.Scope, document model.MenuDocument) ([]model.MenuDraft, error)
+	Import(scope model.Scope, drafts []model.MenuDraft) ([]model.Menu, error)
+
+	// Stock and availability management
+	DecrementStock(scope model.Scope, id uint, quantity int) (model.MenuResponse, error)
+	Restock(scope model.Scope, id uint, quantity int) (model.MenuResponse, error)
+	SetAvailability(scope model.Scope, id uint, status string) (model.MenuResponse, error)
+	ResetDailyStock() (int64, error)
+
+	// Translations
+	ListTranslations(scope model.Scope, id uint) ([]model.MenuTranslation, error)
+	SaveTranslation(scope model.Scope, id uint, locale string, input model.TranslationRequest) (model.MenuTranslation, error)
+	DeleteTranslation(scope model.Scope, id uint, locale string) error
+	GenerateTranslations(ctx context.Context, scope model.Scope, request model.GenerateTranslationsRequest) ([]model.TranslationBatchResult, error)
 
 	// Add bridge to access `ai_service.go` methods
-	GenerateDescription(name string, ingredients []string) (string, error)
-	GetRecommendations(request model.RecommendationRequest) ([]model.RecommendationResponse, error)
+	GenerateDescriptions(ctx context.Context, request model.GenerateDescriptionRequest) (model.GenerateDescriptionResponse, error)
+	GetRecommendations(ctx context.Context, scope model.Scope, request model.RecommendationRequest) ([]model.RecommendationResponse, error)
+	GenerateDescriptionStream(ctx context.Context, request model.GenerateDescriptionRequest, emit func(token string) error) (model.AIUsage, error)
+	GetRecommendationsStream(ctx context.Context, scope model.Scope, request model.RecommendationRequest, emit func(model.RecommendationResponse) error) (model.AIUsage, error)
+
+	// Descriptions generated in the background
+	UseJobQueue(queue JobQueue)
+	ApplyDescription(scope model.Scope, id uint, description string) (model.Menu, error)
+	DescriptionJob(scope model.Scope, menuID uint) (model.Job, error)
+	SweepDescriptions() (int, error)
+	MarkLegacyPlaceholders() (int64, error)
+
+	AddListener(listener MenuListener)
 }
 
+var (
+	ErrStockNotTracked   = errors.New("menu does not track stock")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidLocale     = errors.New("invalid locale, expected a language tag such as 'id' or 'en-US'")
+	ErrNoMenusAvailable  = errors.New("no menus are available to recommend")
+)
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// maxRecommendations is the number of menus suggested per request
+const maxRecommendations = 3
+
+// translationBatchSize bounds how many menus are sent to the AI in one translation prompt
+const translationBatchSize = 20
+
 type menuService struct {
-	repo repository.MenuRepository
-	ai   AIService
+	repo      repository.MenuRepository
+	ai        AIService
+	jobs      JobQueue // nil generates descriptions during Create
+	listeners []MenuListener
 }
 
 func NewMenuService(repo repository.MenuRepository, ai AIService) MenuService {
@@ -33,25 +83,45 @@ func NewMenuService(repo repository.MenuRepository, ai AIService) MenuService {
 	}
 }
 
-func (s *menuService) Create(input model.Menu) (model.Menu, error) {
+func (s *menuService) Create(ctx context.Context, scope model.Scope, input model.Menu) (model.Menu, error) {
 	if input.Price < 0 {
 		return model.Menu{}, errors.New("price cannot be negative")
 	}
+	input.TenantID = scope.TenantID
+	input.Image = nil // images are only set through the upload endpoint
+	input.Tags = nil  // and tags through the tag endpoints
+	normalizeAvailability(&input)
+
+	// Ratings only come from reviews
+	input.RatingAverage, input.RatingCount, input.ReviewSummary = 0, 0, nil
 
 	// Use AI to generate description automatically
-	if input.Description == "" {
-		desc, err := s.ai.GenerateDescription(input.Name, input.Ingredients)
+	input.DescriptionStatus = model.DescriptionStatusReady
+	if input.Description == "" && s.jobs != nil {
+		// In the background, so a slow AI never delays the response
+		input.DescriptionStatus = model.DescriptionStatusPending
+	} else if input.Description == "" {
+		desc, err := s.ai.GenerateDescription(ctx, input.Name, input.Ingredients)
 		if err == nil {
 			input.Description = desc
-		} else { // Fallback if AI throw error
-			input.Description = "Delicious " + input.Name
+		} else { // Fallback if AI throw error, the sweep generates it again later
+			input.Description = fallbackDescriptionPrefix + input.Name
+			input.DescriptionStatus = model.DescriptionStatusFallback
 		}
 	}
-	err := s.repo.Create(&input)
-	return input, err
+	if err := s.repo.Create(&input); err != nil {
+		return input, err
+	}
+	if input.DescriptionStatus == model.DescriptionStatusPending {
+		if _, err := s.jobs.Enqueue(model.Job{Type: JobGenerateDescription, TenantID: input.TenantID, MenuID: input.ID}); err != nil {
+			log.Printf("Failed to queue the description of menu %d, the sweep will retry: %v", input.ID, err)
+		}
+	}
+	s.notifySaved(input)
+	return input, nil
 }
 
-func (s *menuService) GetList(filter model.MenuFilter) (model.MenuPaginationResponse, error) {
+func (s *menuService) GetList(scope model.Scope, filter model.MenuFilter) (model.MenuPaginationResponse, error) {
 	if filter.Page < 1 {
 		filter.Page = 1
 	}
@@ -59,32 +129,49 @@ func (s *menuService) GetList(filter model.MenuFilter) (model.MenuPaginationResp
 		filter.PerPage = 10
 	}
 
-	menus, pagination, err := s.repo.FindAll(filter)
+	menus, pagination, err := s.repo.FindAll(scope, filter)
 
 	if err != nil {
 		return model.MenuPaginationResponse{}, err
 	}
 
+	if filter.WithFacets {
+		facets, err := s.repo.Facets(scope, filter)
+		if err != nil {
+			return model.MenuPaginationResponse{}, err
+		}
+		pagination.Facets = &facets
+	}
+
 	var menuResponses []model.MenuResponse
 	for _, m := range menus {
 		menuResponses = append(menuResponses, m.ToResponse())
 	}
 
+	if err := s.localize(scope, menuResponses); err != nil {
+		return model.MenuPaginationResponse{}, err
+	}
+
 	pagination.Data = menuResponses
 	return pagination, err
 }
 
-func (s *menuService) GetDetail(id uint) (model.MenuResponse, error) {
-	menu, err := s.repo.FindByID(id)
+func (s *menuService) GetDetail(scope model.Scope, id uint) (model.MenuResponse, error) {
+	menu, err := s.repo.FindByID(scope, id)
 	if err != nil {
 		return model.MenuResponse{}, err
 	}
 	// Konversi sebelum return
-	return menu.ToResponse(), nil
+	responses := []model.MenuResponse{menu.ToResponse()}
+	if err := s.localize(scope, responses); err != nil {
+		return model.MenuResponse{}, err
+	}
+	return responses[0], nil
 }
 
-func (s *menuService) Update(id uint, input model.Menu) (model.Menu, error) {
-	existing, err := s.repo.FindByID(id)
+func (s *menuService) Update(scope model.Scope, id uint, input model.UpdateMenuRequest) (model.Menu, error) {
+	// Branch overrides must never leak into the base menu
+	existing, err := s.repo.FindByID(scope.Base(), id)
 	if err != nil {
 		return model.Menu{}, err
 	}
@@ -94,55 +181,472 @@ func (s *menuService) Update(id uint, input model.Menu) (model.Menu, error) {
 	existing.Price = input.Price
 	existing.Calories = input.Calories
 	existing.Category = input.Category
-	existing.Description = input.Description
+	if input.Description != existing.Description {
+		existing.Description = input.Description
+		if input.Description != "" {
+			existing.DescriptionStatus = model.DescriptionStatusReady
+		}
+	}
 	existing.Ingredients = input.Ingredients
+	existing.Allergens = input.Allergens
+	if input.Availability != nil {
+		existing.Availability = *input.Availability
+	}
+	if input.Stock.Set {
+		existing.Stock = input.Stock.Value
+	}
+	if input.DailyStock.Set {
+		existing.DailyStock = input.DailyStock.Value
+	}
 	existing.UpdatedAt = input.UpdatedAt
+	normalizeAvailability(&existing)
 
-	err = s.repo.Update(&existing)
-	return existing, err
+	if err := s.repo.Update(&existing); err != nil {
+		return existing, err
+	}
+	s.notifySaved(existing)
+	return existing, nil
 }
 
-func (s *menuService) Delete(id uint) error {
-	_, err := s.repo.FindByID(id)
+func (s *menuService) Delete(scope model.Scope, id uint) error {
+	existing, err := s.repo.FindByID(scope.Base(), id)
 	if err != nil {
 		return err
 	}
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(scope.TenantID, id); err != nil {
+		return err
+	}
+	s.notifyDeleted(existing)
+	return nil
 }
 
-func (s *menuService) GetGrouped(mode string, limit int) (any, error) {
-	return s.repo.GroupBy(mode, limit)
+func (s *menuService) GetGrouped(scope model.Scope, mode string, limit int) (any, error) {
+	return s.repo.GroupBy(scope.TenantID, mode, limit)
 }
 
-func (s *menuService) GenerateDescription(name string, ingredients []string) (string, error) {
-	return s.ai.GenerateDescription(name, ingredients)
+func (s *menuService) DecrementStock(scope model.Scope, id uint, quantity int) (model.MenuResponse, error) {
+	menu, err := s.repo.FindByID(scope.Base(), id)
+	if err != nil {
+		return model.MenuResponse{}, err
+	}
+	if menu.Stock == nil {
+		return model.MenuResponse{}, ErrStockNotTracked
+	}
+
+	ok, err := s.repo.DecrementStock(scope.TenantID, id, quantity)
+	if err != nil {
+		return model.MenuResponse{}, err
+	}
+	if !ok {
+		return model.MenuResponse{}, ErrInsufficientStock
+	}
+
+	return s.GetDetail(scope, id)
 }
 
-func (s *menuService) GetRecommendations(request model.RecommendationRequest) ([]model.RecommendationResponse, error) {
-	menus, _, err := s.repo.FindAll(model.MenuFilter{PerPage: 100})
+func (s *menuService) Restock(scope model.Scope, id uint, quantity int) (model.MenuResponse, error) {
+	if _, err := s.repo.FindByID(scope.Base(), id); err != nil {
+		return model.MenuResponse{}, err
+	}
+	if err := s.repo.Restock(scope.TenantID, id, quantity); err != nil {
+		return model.MenuResponse{}, err
+	}
+	return s.GetDetail(scope, id)
+}
+
+func (s *menuService) SetAvailability(scope model.Scope, id uint, status string) (model.MenuResponse, error) {
+	if _, err := s.repo.FindByID(scope.Base(), id); err != nil {
+		return model.MenuResponse{}, err
+	}
+	if err := s.repo.SetAvailability(scope.TenantID, id, status); err != nil {
+		return model.MenuResponse{}, err
+	}
+	return s.GetDetail(scope, id)
+}
+
+func (s *menuService) ResetDailyStock() (int64, error) {
+	return s.repo.ResetDailyStock()
+}
+
+// normalizeAvailability fills the default status and marks empty stock as sold out
+func normalizeAvailability(menu *model.Menu) {
+	if menu.Availability == "" {
+		menu.Availability = model.AvailabilityAvailable
+	}
+	if menu.Stock != nil && *menu.Stock <= 0 {
+		menu.Availability = model.AvailabilitySoldOut
+	}
+}
+
+// localize replaces name and description with the best translation for the scope locale
+func (s *menuService) localize(scope model.Scope, menus []model.MenuResponse) error {
+	locales := model.LocaleFallbacks(scope.Locale)
+	if len(locales) == 0 || len(menus) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(menus))
+	for i, m := range menus {
+		ids[i] = m.ID
+	}
+
+	translations, err := s.repo.FindTranslations(scope.TenantID, ids, locales)
 	if err != nil {
+		return err
+	}
+
+	// Key: Menu ID, Value: translations by locale
+	byMenu := make(map[uint]map[string]model.MenuTranslation)
+	for _, t := range translations {
+		if byMenu[t.MenuID] == nil {
+			byMenu[t.MenuID] = make(map[string]model.MenuTranslation)
+		}
+		byMenu[t.MenuID][t.Locale] = t
+	}
+
+	for i := range menus {
+		for _, locale := range locales {
+			if t, ok := byMenu[menus[i].ID][locale]; ok {
+				menus[i].Name = t.Name
+				if t.Description != "" {
+					menus[i].Description = t.Description
+				}
+				menus[i].Locale = locale
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (s *menuService) ListTranslations(scope model.Scope, id uint) ([]model.MenuTranslation, error) {
+	if _, err := s.repo.FindByID(scope.Base(), id); err != nil {
 		return nil, err
 	}
 
-	rawRecommendations, err := s.ai.GetRecommendations(request, menus)
+	return s.repo.FindTranslationsForMenu(scope.TenantID, id)
+}
+
+func (s *menuService) SaveTranslation(scope model.Scope, id uint, locale string, input model.TranslationRequest) (model.MenuTranslation, error) {
+	locale = model.NormalizeLocale(locale)
+	if !localePattern.MatchString(locale) {
+		return model.MenuTranslation{}, ErrInvalidLocale
+	}
+	if _, err := s.repo.FindByID(scope.Base(), id); err != nil {
+		return model.MenuTranslation{}, err
+	}
+
+	translation := model.MenuTranslation{
+		TenantID:    scope.TenantID,
+		MenuID:      id,
+		Locale:      locale,
+		Name:        input.Name,
+		Description: input.Description,
+		Source:      model.TranslationSourceManual,
+	}
+	err := s.repo.UpsertTranslation(&translation)
+	return translation, err
+}
+
+func (s *menuService) DeleteTranslation(scope model.Scope, id uint, locale string) error {
+	return s.repo.DeleteTranslation(scope.TenantID, id, model.NormalizeLocale(locale))
+}
+
+// GenerateTranslations asks the AI to translate menus that have no translation yet, in batches per locale
+func (s *menuService) GenerateTranslations(ctx context.Context, scope model.Scope, request model.GenerateTranslationsRequest) ([]model.TranslationBatchResult, error) {
+	limit := request.Limit
+	if limit < 1 {
+		limit = 100
+	}
+
+	var locales []string
+	for _, locale := range request.Locales {
+		locale = model.NormalizeLocale(locale)
+		if !localePattern.MatchString(locale) {
+			return nil, ErrInvalidLocale
+		}
+		locales = append(locales, locale)
+	}
+
+	var results []model.TranslationBatchResult
+	for _, locale := range locales {
+		result := model.TranslationBatchResult{Locale: locale}
+
+		menus, err := s.repo.FindMissingTranslations(scope.TenantID, locale, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		for start := 0; start < len(menus); start += translationBatchSize {
+			// Stop spending tokens once the caller is gone
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			batch := menus[start:min(start+translationBatchSize, len(menus))]
+			translated, failed, err := s.translateBatch(ctx, scope, batch, locale)
+			result.Translated += translated
+			result.Failed += failed
+			if err != nil {
+				result.Error = err.Error()
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (s *menuService) translateBatch(ctx context.Context, scope model.Scope, batch []model.Menu, locale string) (int, int, error) {
+	items := make([]model.TranslationItem, len(batch))
+	pending := make(map[uint]bool, len(batch))
+	for i, m := range batch {
+		items[i] = model.TranslationItem{MenuID: m.ID, Name: m.Name, Description: m.Description}
+		pending[m.ID] = true
+	}
+
+	translatedItems, err := s.ai.TranslateMenus(ctx, items, locale)
+	if err != nil {
+		return 0, len(batch), err
+	}
+
+	translated := 0
+	for _, item := range translatedItems {
+		// Ignore IDs the AI made up or repeated
+		item.Name = cleanAnswer(item.Name, maxTranslatedNameLength)
+		if !pending[item.MenuID] || item.Name == "" {
+			continue
+		}
+		item.Description = cleanAnswer(item.Description, maxDescriptionLength)
+		translation := model.MenuTranslation{
+			TenantID:    scope.TenantID,
+			MenuID:      item.MenuID,
+			Locale:      locale,
+			Name:        item.Name,
+			Description: item.Description,
+			Source:      model.TranslationSourceAI,
+		}
+		if err := s.repo.UpsertTranslation(&translation); err != nil {
+			return translated, len(batch) - translated, err
+		}
+		delete(pending, item.MenuID)
+		translated++
+	}
+
+	return translated, len(pending), nil
+}
+
+// GenerateDescriptions returns request.Candidates alternatives, or fewer when the AI
+// cannot come up with that many different ones. When the AI fails they are written
+// from templates instead and flagged as a fallback.
+func (s *menuService) GenerateDescriptions(ctx context.Context, request model.GenerateDescriptionRequest) (model.GenerateDescriptionResponse, error) {
+	options, err := descriptionOptions(request)
+	if err != nil {
+		return model.GenerateDescriptionResponse{}, err
+	}
+	count := max(request.Candidates, 1)
+	source := model.DescriptionSourceAI
+	candidates, err := s.ai.GenerateDescriptions(ctx, request.Name, request.Ingredients, options, count)
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, ErrTenantAIBudgetExceeded) || errors.Is(err, ErrAIBudgetExceeded) {
+			return model.GenerateDescriptionResponse{}, err
+		}
+		log.Printf("AI description failed, using a template: %v", err)
+		candidates, source = describeOffline(request.Name, request.Ingredients, options, count), model.DescriptionSourceFallback
+	}
+	return model.GenerateDescriptionResponse{Description: candidates[0], Candidates: candidates, Source: source}, nil
+}
+
+func descriptionOptions(request model.GenerateDescriptionRequest) (model.DescriptionOptions, error) {
+	options := request.DescriptionOptions.WithDefaults()
+	if !localePattern.MatchString(options.Language) {
+		return options, ErrInvalidLocale
+	}
+	return options, nil
+}
+
+func (s *menuService) GetRecommendations(ctx context.Context, scope model.Scope, request model.RecommendationRequest) ([]model.RecommendationResponse, error) {
+	// Only a shortlist of the catalog is sent to the AI, sold-out menus are never on it
+	menus, err := s.shortlistMenus(scope, request)
 	if err != nil {
 		return nil, err
 	}
+	if len(menus) == 0 {
+		return nil, ErrNoMenusAvailable
+	}
+
+	request.Locale = scope.Locale
+	rawRecommendations, err := s.ai.GetRecommendations(ctx, request, menus)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("AI recommendations failed, using fallback ranking: %v", err)
+	}
+
+	finalRecommendations := resolveRecommendations(rawRecommendations, menus, model.RecommendationSourceAI)
+	if len(finalRecommendations) == 0 {
+		finalRecommendations = fallbackRecommendations(request, menus)
+	}
+
+	// Menus are suggested with the text the reader sees in the rest of the API
+	responses := make([]model.MenuResponse, len(finalRecommendations))
+	for i, r := range finalRecommendations {
+		responses[i] = r.Menu
+	}
+	if err := s.localize(scope, responses); err != nil {
+		return nil, err
+	}
+	for i := range finalRecommendations {
+		finalRecommendations[i].Menu = responses[i]
+	}
 
-	menuMap := make(map[string]model.Menu)
+	return finalRecommendations, nil
+}
+
+// resolveRecommendations keeps the AI answers that point to a candidate menu, best first
+func resolveRecommendations(raws []model.RecommendationResponseRaw, menus []model.Menu, source string) []model.RecommendationResponse {
+	byID := make(map[uint]model.Menu, len(menus))
 	for _, m := range menus {
-		menuMap[m.Name] = m
+		byID[m.ID] = m
 	}
 
-	var finalRecommendations []model.RecommendationResponse
-	for _, raw := range rawRecommendations {
-		if originalMenu, exists := menuMap[raw.MenuName]; exists {
-			finalRecommendations = append(finalRecommendations, model.RecommendationResponse{
-				Menu:   originalMenu.ToResponse(),
-				Reason: raw.Reason,
-			})
+	var recommendations []model.RecommendationResponse
+	recommended := make(map[uint]bool)
+	for _, raw := range raws {
+		menu, exists := resolveMenu(raw, byID, menus)
+		if !exists || recommended[menu.ID] {
+			continue
 		}
+		recommended[menu.ID] = true
+		recommendations = append(recommendations, newRecommendation(menu, raw, source))
 	}
 
-	return finalRecommendations, nil
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Confidence > recommendations[j].Confidence
+	})
+	if len(recommendations) > maxRecommendations {
+		recommendations = recommendations[:maxRecommendations]
+	}
+	return recommendations
+}
+
+// resolveMenu finds the candidate an AI answer points to, by ID or else by name
+func resolveMenu(raw model.RecommendationResponseRaw, byID map[uint]model.Menu, menus []model.Menu) (model.Menu, bool) {
+	menu, exists := byID[raw.MenuID]
+	if !exists && raw.MenuName != "" {
+		menu, exists = matchMenuName(raw.MenuName, menus)
+	}
+	if !exists {
+		log.Printf("AI recommended unknown menu %d %q", raw.MenuID, raw.MenuName)
+	}
+	return menu, exists
+}
+
+func newRecommendation(menu model.Menu, raw model.RecommendationResponseRaw, source string) model.RecommendationResponse {
+	return model.RecommendationResponse{
+		Menu:       menu.ToResponse(),
+		Reason:     cleanAnswer(raw.Reason, maxReasonLength),
+		Confidence: math.Max(0, math.Min(1, raw.Confidence)),
+		Source:     source,
+	}
+}
+
+// GenerateDescriptionStream streams a single description, request.Candidates is ignored
+func (s *menuService) GenerateDescriptionStream(ctx context.Context, request model.GenerateDescriptionRequest, emit func(string) error) (model.AIUsage, error) {
+	options, err := descriptionOptions(request)
+	if err != nil {
+		return model.AIUsage{}, err
+	}
+	return s.ai.GenerateDescriptionStream(ctx, request.Name, request.Ingredients, options, emit)
+}
+
+// GetRecommendationsStream emits the recommendations in the order the AI gives them,
+// so unlike GetRecommendations they are not sorted by confidence. When the AI fails
+// before suggesting anything usable the fallback ranking is emitted instead.
+func (s *menuService) GetRecommendationsStream(ctx context.Context, scope model.Scope, request model.RecommendationRequest, emit func(model.RecommendationResponse) error) (model.AIUsage, error) {
+	menus, err := s.shortlistMenus(scope, request)
+	if err != nil {
+		return model.AIUsage{}, err
+	}
+	if len(menus) == 0 {
+		return model.AIUsage{}, ErrNoMenusAvailable
+	}
+	request.Locale = scope.Locale
+
+	byID := make(map[uint]model.Menu, len(menus))
+	for _, m := range menus {
+		byID[m.ID] = m
+	}
+	recommended := make(map[uint]bool)
+	// emitErr tells a failed delivery to the client apart from a failed AI call
+	var emitErr error
+	send := func(recommendation model.RecommendationResponse) error {
+		responses := []model.MenuResponse{recommendation.Menu}
+		if err := s.localize(scope, responses); err != nil {
+			return err
+		}
+		recommendation.Menu = responses[0]
+		recommended[recommendation.Menu.ID] = true
+		if err := emit(recommendation); err != nil {
+			emitErr = err
+			return err
+		}
+		return nil
+	}
+
+	usage, err := s.ai.GetRecommendationsStream(ctx, request, menus, func(raw model.RecommendationResponseRaw) error {
+		if len(recommended) >= maxRecommendations {
+			return nil
+		}
+		menu, exists := resolveMenu(raw, byID, menus)
+		if !exists || recommended[menu.ID] {
+			return nil
+		}
+		return send(newRecommendation(menu, raw, model.RecommendationSourceAI))
+	})
+	if emitErr != nil {
+		return usage, emitErr
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return usage, ctx.Err()
+		}
+		log.Printf("AI recommendations failed, using fallback ranking: %v", err)
+	}
+
+	if len(recommended) == 0 {
+		for _, r := range fallbackRecommendations(request, menus) {
+			if err := send(r); err != nil {
+				return usage, err
+			}
+		}
+	}
+	return usage, nil
+}
+
+// fallbackRecommendations ranks by keywords, or suggests the newest menus when nothing matches
+func fallbackRecommendations(request model.RecommendationRequest, menus []model.Menu) []model.RecommendationResponse {
+	recommendations := resolveRecommendations(rankByKeywords(request, menus, maxRecommendations), menus, model.RecommendationSourceFallback)
+	if len(recommendations) > 0 {
+		return recommendations
+	}
+
+	reason := "One of our newest dishes"
+	if isIndonesian(request.Locale) {
+		reason = "Salah satu menu terbaru kami"
+	}
+	newest := slices.Clone(menus)
+	sort.SliceStable(newest, func(i, j int) bool {
+		return newest[i].CreatedAt.After(newest[j].CreatedAt)
+	})
+	for _, m := range newest[:min(maxRecommendations, len(newest))] {
+		recommendations = append(recommendations, model.RecommendationResponse{
+			Menu:       m.ToResponse(),
+			Reason:     reason,
+			Confidence: 0.1,
+			Source:     model.RecommendationSourceFallback,
+		})
+	}
+	return recommendations
 }
@@ -0,0 +1,460 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"atalariq/menu-api/internal/model"
+)
+
+var ErrNotSupportedOffline = errors.New("not supported by the offline AI provider")
+
+// offlineService is a deterministic, rule-based AIService for development and CI.
+// It never calls the network, so the same input always gives the same output.
+type offlineService struct{}
+
+func NewOfflineService() AIService {
+	return &offlineService{}
+}
+
+// descriptionTemplates per tone, each takes the name and the ingredients
+var descriptionTemplates = map[string][]string{
+	model.TonePremium: {
+		"%s made with %s, prepared fresh to order.",
+		"%s featuring %s.",
+		"Our %s brings together %s.",
+	},
+	model.ToneCasual: {
+		"%s with %s, just the way you like it.",
+		"Grab a %s, made with %s.",
+		"Simple, honest %s with %s.",
+	},
+	model.TonePlayful: {
+		"Meet %s, where %s steal the show.",
+		"%s: %s walk into a bowl.",
+		"Warning: %s with %s may cause cravings.",
+	},
+}
+
+func (s *offlineService) GenerateDescription(ctx context.Context, name string, ingredients []string) (string, error) {
+	descriptions, err := s.GenerateDescriptions(ctx, name, ingredients, model.DescriptionOptions{}, 1)
+	if err != nil {
+		return "", err
+	}
+	return descriptions[0], nil
+}
+
+// GenerateDescriptions picks templates of the tone, starting from one chosen by the name.
+// The language and audience are ignored.
+func (s *offlineService) GenerateDescriptions(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, count int) ([]string, error) {
+	descriptions := describeOffline(name, ingredients, options, count)
+	countUsage(ctx, estimateUsage(len(strings.Fields(name))+len(ingredients), len(strings.Fields(strings.Join(descriptions, " ")))))
+	return descriptions, nil
+}
+
+func describeOffline(name string, ingredients []string, options model.DescriptionOptions, count int) []string {
+	options = options.WithDefaults()
+	if len(ingredients) == 0 {
+		return []string{limitWords(name+", prepared fresh to order.", options.MaxWords)}
+	}
+
+	templates := descriptionTemplates[options.Tone]
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	start := int(h.Sum32() % uint32(len(templates)))
+
+	descriptions := make([]string, 0, count)
+	for i := range min(max(count, 1), len(templates)) {
+		template := templates[(start+i)%len(templates)]
+		descriptions = append(descriptions, limitWords(fmt.Sprintf(template, name, joinWords(ingredients)), options.MaxWords))
+	}
+	return descriptions
+}
+
+// limitWords cuts text after maxWords words
+func limitWords(text string, maxWords int) string {
+	words := strings.Fields(text)
+	if len(words) <= maxWords {
+		return text
+	}
+	return strings.TrimRight(strings.Join(words[:maxWords], " "), ",:") + "."
+}
+
+// GenerateDescriptionStream sends the description word by word, the usage is counted in words
+func (s *offlineService) GenerateDescriptionStream(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, emit func(string) error) (model.AIUsage, error) {
+	description := describeOffline(name, ingredients, options, 1)[0]
+	words := strings.Fields(description)
+	usage := estimateUsage(len(strings.Fields(name))+len(ingredients), len(words))
+	countUsage(ctx, usage)
+	for i, word := range words {
+		if err := ctx.Err(); err != nil {
+			return usage, err
+		}
+		if i < len(words)-1 {
+			word += " "
+		}
+		if err := emit(word); err != nil {
+			return usage, err
+		}
+	}
+	return usage, nil
+}
+
+func estimateUsage(prompt, completion int) model.AIUsage {
+	return model.AIUsage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion}
+}
+
+// keywordHints expands common cravings into words found in menu names and ingredients
+var keywordHints = map[string][]string{
+	"coffee":   {"kopi", "espresso", "latte", "cappuccino"},
+	"kopi":     {"coffee", "espresso"},
+	"tea":      {"teh"},
+	"teh":      {"tea"},
+	"wake":     {"coffee", "espresso", "kopi"},
+	"sleepy":   {"coffee", "espresso", "kopi"},
+	"caffeine": {"coffee", "espresso", "kopi", "tea", "teh"},
+	"sweet":    {"sugar", "chocolate", "caramel", "honey", "gula", "manis", "vanilla"},
+	"manis":    {"sugar", "chocolate", "caramel", "honey", "gula"},
+	"spicy":    {"chili", "chilli", "sambal", "pedas", "cabai", "pepper"},
+	"pedas":    {"chili", "chilli", "sambal", "cabai"},
+	"fresh":    {"lime", "lemon", "mint", "ice", "iced", "es"},
+	"cold":     {"ice", "iced", "es"},
+	"dingin":   {"ice", "iced", "es"},
+	"hungry":   {"rice", "nasi", "noodle", "mie", "chicken", "ayam", "beef"},
+	"lapar":    {"rice", "nasi", "noodle", "mie", "ayam"},
+	"healthy":  {"salad", "vegetable", "sayur", "fruit", "grilled"},
+	"light":    {"salad", "fruit", "tea", "teh"},
+}
+
+var stopWords = map[string]bool{
+	"i": true, "a": true, "an": true, "the": true, "me": true, "my": true, "want": true, "need": true,
+	"something": true, "some": true, "to": true, "and": true, "or": true, "with": true, "for": true,
+	"please": true, "up": true, "is": true, "am": true, "feel": true, "like": true, "of": true,
+	"saya": true, "aku": true, "mau": true, "ingin": true, "yang": true, "dan": true, "atau": true,
+	"dengan": true, "untuk": true, "sesuatu": true, "tolong": true,
+}
+
+func (s *offlineService) GetRecommendations(ctx context.Context, request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error) {
+	recommendations := rankByKeywords(request, menus, maxRecommendations)
+	completion := 0
+	for _, r := range recommendations {
+		completion += len(strings.Fields(r.Reason))
+	}
+	countUsage(ctx, estimateUsage(len(strings.Fields(request.Preference))+len(menus), completion))
+	return recommendations, nil
+}
+
+func (s *offlineService) GetRecommendationsStream(ctx context.Context, request model.RecommendationRequest, menus []model.Menu, emit func(model.RecommendationResponseRaw) error) (model.AIUsage, error) {
+	recommendations := rankByKeywords(request, menus, maxRecommendations)
+	usage := estimateUsage(len(strings.Fields(request.Preference))+len(menus), 0)
+	defer func() { countUsage(ctx, usage) }()
+	for _, r := range recommendations {
+		if err := ctx.Err(); err != nil {
+			return usage, err
+		}
+		usage = estimateUsage(usage.PromptTokens, usage.CompletionTokens+len(strings.Fields(r.Reason)))
+		if err := emit(r); err != nil {
+			return usage, err
+		}
+	}
+	return usage, nil
+}
+
+// keywordMatcher holds the words of a request, each keyword remembers the word it came from
+type keywordMatcher map[string]string
+
+func newKeywordMatcher(preference string) keywordMatcher {
+	keywords := make(keywordMatcher)
+	for _, word := range tokenize(preference) {
+		if stopWords[word] {
+			continue
+		}
+		keywords[word] = word
+		for _, hint := range keywordHints[word] {
+			if _, ok := keywords[hint]; !ok {
+				keywords[hint] = word
+			}
+		}
+	}
+	return keywords
+}
+
+// score weighs the keywords found in the name, ingredients, category and description of a menu.
+// It also returns the words of the request that matched, for the reason text.
+func (keywords keywordMatcher) score(menu model.Menu) (int, []string) {
+	fields := []struct {
+		words  []string
+		weight int
+	}{
+		{tokenize(menu.Name), 3},
+		{tokenize(strings.Join(menu.Ingredients, " ")), 2},
+		{tokenize(menu.Category + " " + menu.Description), 1},
+	}
+
+	score := 0
+	var matched []string
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		for _, word := range field.words {
+			for keyword, origin := range keywords {
+				if !wordsMatch(word, keyword) {
+					continue
+				}
+				score += field.weight
+				if !seen[origin] {
+					seen[origin] = true
+					matched = append(matched, origin)
+				}
+			}
+		}
+	}
+	sort.Strings(matched)
+	return score, matched
+}
+
+// rankByKeywords scores menus by the words of the request found in them.
+// It is deterministic and also backs the recommendation fallback.
+func rankByKeywords(request model.RecommendationRequest, menus []model.Menu, limit int) []model.RecommendationResponseRaw {
+	keywords := newKeywordMatcher(searchText(request))
+
+	type scored struct {
+		menu    model.Menu
+		score   int
+		matched []string
+	}
+	var candidates []scored
+	for _, menu := range menus {
+		score, matched := keywords.score(menu)
+		if score > 0 {
+			candidates = append(candidates, scored{menu: menu, score: score, matched: matched})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	reason := "Matches your request: %s"
+	if isIndonesian(request.Locale) {
+		reason = "Cocok dengan permintaan Anda: %s"
+	}
+
+	recommendations := make([]model.RecommendationResponseRaw, 0, len(candidates))
+	for _, c := range candidates {
+		recommendations = append(recommendations, model.RecommendationResponseRaw{
+			MenuID:     c.menu.ID,
+			MenuName:   c.menu.Name,
+			Reason:     fmt.Sprintf(reason, strings.Join(c.matched, ", ")),
+			Confidence: math.Min(0.9, 0.3+0.1*float64(c.score)),
+		})
+	}
+	return recommendations
+}
+
+func isIndonesian(locale string) bool {
+	return strings.HasPrefix(model.NormalizeLocale(locale), "id")
+}
+
+func (s *offlineService) TranslateMenus(ctx context.Context, items []model.TranslationItem, locale string) ([]model.TranslationItem, error) {
+	return nil, ErrNotSupportedOffline
+}
+
+// offlineExtractedMenus is the paper menu the offline provider reads from any document,
+// so that extraction and import can be tried without network access
+var offlineExtractedMenus = []model.MenuDraft{
+	{Name: "Nasi Goreng Spesial", Price: 28000, Category: "food", Ingredients: []string{"rice", "egg", "chicken", "sambal"}, Confidence: 0.9},
+	{Name: "Mie Ayam Bakso", Price: 25000, Category: "food", Ingredients: []string{"noodles", "chicken", "meatball"}, Confidence: 0.9},
+	{Name: "Gado-Gado", Price: 22000, Category: "food", Ingredients: []string{"vegetables", "tofu", "tempeh", "peanut sauce"}, Description: "Vegetables with peanut sauce", Confidence: 0.8},
+	{Name: "Es Teh Manis", Price: 8000, Category: "drinks", Ingredients: []string{"tea", "sugar", "ice"}, Confidence: 0.9},
+	{Name: "Es Campur", Price: 15000, Category: "dessert", Ingredients: []string{"shaved ice", "fruit", "syrup"}, Confidence: 0.6},
+}
+
+func (s *offlineService) ExtractMenus(ctx context.Context, document model.MenuDocument, categories []string) ([]model.MenuDraft, error) {
+	drafts := make([]model.MenuDraft, len(offlineExtractedMenus))
+	for i, draft := range offlineExtractedMenus {
+		draft.Ingredients = slices.Clone(draft.Ingredients)
+		drafts[i] = draft
+	}
+	countUsage(ctx, estimateUsage(len(document.Data)/1024, len(drafts)*20))
+	return drafts, nil
+}
+
+// SuggestEnrichment reads the allergens, dietary tags and category from keywords of the
+// name and ingredients, the calories are a rough guess per kind of dish
+func (s *offlineService) SuggestEnrichment(ctx context.Context, menu model.Menu, categories []string) (model.EnrichmentSuggestion, error) {
+	suggestion := suggestOffline(menu, categories)
+	countUsage(ctx, estimateUsage(len(strings.Fields(menu.Name))+len(menu.Ingredients)+len(categories), len(suggestion.DietaryTags)+len(suggestion.Allergens)+2))
+	return suggestion, nil
+}
+
+// SummarizeReviews quotes the first sentence of the best and the worst rated reviews
+func (s *offlineService) SummarizeReviews(ctx context.Context, menu model.Menu, reviews []model.Review) (model.ReviewSummary, error) {
+	summary := summarizeReviewsOffline(reviews)
+	words := 0
+	for _, review := range reviews {
+		words += len(strings.Fields(review.Text))
+	}
+	countUsage(ctx, estimateUsage(words+len(reviews), len(strings.Fields(summary.Summary))+len(summary.Pros)+len(summary.Cons)))
+	return summary, nil
+}
+
+func summarizeReviewsOffline(reviews []model.Review) model.ReviewSummary {
+	summary := model.ReviewSummary{Pros: []string{}, Cons: []string{}}
+	if len(reviews) == 0 {
+		return summary
+	}
+	total := 0
+	for _, review := range reviews {
+		total += review.Rating
+		point, _, _ := strings.Cut(strings.TrimSpace(review.Text), ".")
+		switch {
+		case point == "":
+		case review.Rating >= 4 && len(summary.Pros) < maxReviewPoints:
+			summary.Pros = append(summary.Pros, point)
+		case review.Rating <= 2 && len(summary.Cons) < maxReviewPoints:
+			summary.Cons = append(summary.Cons, point)
+		}
+	}
+	summary.Summary = fmt.Sprintf("Rated %.1f out of 5 by %d reviewers.", float64(total)/float64(len(reviews)), len(reviews))
+	return summary
+}
+
+// allergenKeywords are words of names and ingredients that contain an allergen
+var allergenKeywords = map[string][]string{
+	"gluten":      {"wheat", "flour", "tepung", "bread", "roti", "noodle", "mie", "pasta", "spaghetti", "bun", "buns", "croissant", "cake", "kue", "batter", "barley"},
+	"crustaceans": {"shrimp", "prawn", "udang", "crab", "kepiting", "lobster"},
+	"eggs":        {"egg", "eggs", "telur", "mayonnaise", "mayo"},
+	"fish":        {"fish", "ikan", "salmon", "tuna", "anchovy", "teri", "cod"},
+	"peanuts":     {"peanut", "kacang"},
+	"soy":         {"soy", "soya", "tofu", "tahu", "tempe", "tempeh", "kecap", "edamame"},
+	"milk":        {"milk", "susu", "cheese", "keju", "butter", "mentega", "cream", "yogurt", "latte", "cappuccino"},
+	"tree-nuts":   {"almond", "cashew", "mete", "walnut", "hazelnut", "pistachio"},
+	"celery":      {"celery", "seledri"},
+	"mustard":     {"mustard"},
+	"sesame":      {"sesame", "wijen", "tahini"},
+	"sulphites":   {"wine"},
+	"molluscs":    {"squid", "cumi", "octopus", "clam", "kerang", "oyster", "mussel"},
+}
+
+// meatKeywords rule out the vegetarian tags, along with the fish and seafood allergens
+var meatKeywords = []string{"chicken", "ayam", "beef", "sapi", "daging", "meat", "pork", "babi", "bacon", "ham", "lamb", "kambing", "duck", "bebek", "sausage", "sosis"}
+
+var spicyKeywords = []string{"chili", "chilli", "sambal", "cabai", "cabe", "pedas", "spicy", "jalapeno", "balado", "rica"}
+
+// categoryKinds are the kinds of dish the rules tell apart, with their keywords and the
+// category names they go by
+var categoryKinds = []struct {
+	name     string
+	keywords []string
+	names    []string
+	calories int
+}{
+	{"drinks", []string{"coffee", "kopi", "tea", "teh", "juice", "jus", "es", "iced", "latte", "cappuccino", "espresso", "smoothie", "soda", "water", "air", "milkshake", "lemonade"}, []string{"drink", "drinks", "beverage", "beverages", "minuman"}, 150},
+	{"dessert", []string{"cake", "kue", "pudding", "puding", "gelato", "chocolate", "cokelat", "brownie", "pancake", "waffle", "dessert", "tiramisu", "sundae"}, []string{"dessert", "desserts", "sweets", "pencuci"}, 350},
+	{"food", nil, []string{"food", "foods", "makanan", "main", "meal", "meals"}, 550},
+}
+
+func suggestOffline(menu model.Menu, categories []string) model.EnrichmentSuggestion {
+	nameWords := tokenize(menu.Name)
+	ingredientWords := tokenize(strings.Join(menu.Ingredients, " "))
+	// An ingredient containing a keyword is more telling than a name
+	confidence := func(keywords []string) float64 {
+		switch {
+		case containsKeyword(ingredientWords, keywords):
+			return 0.8
+		case containsKeyword(nameWords, keywords):
+			return 0.5
+		}
+		return 0
+	}
+
+	suggestion := model.EnrichmentSuggestion{DietaryTags: []model.SuggestedLabel{}, Allergens: []model.SuggestedLabel{}}
+	for _, allergen := range model.Allergens {
+		if c := confidence(allergenKeywords[allergen]); c > 0 {
+			suggestion.Allergens = append(suggestion.Allergens, model.SuggestedLabel{Name: allergen, Confidence: c})
+		}
+	}
+	if c := confidence(spicyKeywords); c > 0 {
+		suggestion.DietaryTags = append(suggestion.DietaryTags, model.SuggestedLabel{Name: "spicy", Confidence: c})
+	}
+	// Only a list of ingredients tells what a dish does not contain
+	animal := slices.Concat(meatKeywords, allergenKeywords["fish"], allergenKeywords["crustaceans"], allergenKeywords["molluscs"])
+	if len(ingredientWords) > 0 && confidence(animal) == 0 {
+		suggestion.DietaryTags = append(suggestion.DietaryTags, model.SuggestedLabel{Name: "vegetarian", Confidence: 0.5})
+		if confidence(slices.Concat(allergenKeywords["milk"], allergenKeywords["eggs"], []string{"honey", "madu"})) == 0 {
+			suggestion.DietaryTags = append(suggestion.DietaryTags, model.SuggestedLabel{Name: "vegan", Confidence: 0.4})
+		}
+	}
+
+	// The name tells the kind of dish better than the ingredients, a latte is a drink
+	// but milk in a cake is not
+	kind, kindConfidence := categoryKinds[len(categoryKinds)-1], 0.4
+	for _, k := range categoryKinds[:len(categoryKinds)-1] {
+		if containsKeyword(nameWords, k.keywords) {
+			kind, kindConfidence = k, 0.6
+			break
+		}
+	}
+	suggestion.Calories, suggestion.CaloriesConfidence = kind.calories, 0.2
+	if len(categories) == 0 {
+		suggestion.Category, suggestion.CategoryConfidence = kind.name, kindConfidence
+		return suggestion
+	}
+	for _, category := range categories {
+		if containsKeyword(tokenize(category), kind.names) {
+			suggestion.Category, suggestion.CategoryConfidence = category, kindConfidence
+			break
+		}
+	}
+	return suggestion
+}
+
+func containsKeyword(words, keywords []string) bool {
+	for _, word := range words {
+		for _, keyword := range keywords {
+			if wordsMatch(word, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *offlineService) Close() error {
+	return nil
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// wordsMatch also accepts simple plurals and prefixes such as "noodles" for "noodle"
+func wordsMatch(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if len(a) < 4 || len(b) < 4 {
+		return false
+	}
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
+
+// joinWords lists words as "a, b and c"
+func joinWords(words []string) string {
+	if len(words) == 1 {
+		return words[0]
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/model"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = "gpt-4o-mini"
+)
+
+// openAIService talks to any OpenAI-compatible chat completions endpoint,
+// such as OpenAI itself, Ollama (http://localhost:11434/v1) or llama.cpp server
+type openAIService struct {
+	baseURL string
+	apiKey  string
+	model   string
+	timeout time.Duration
+	client  *http.Client
+}
+
+func NewOpenAIService(cfg config.AI) (AIService, error) {
+	openAI := &openAIService{
+		baseURL: cfg.BaseURL,
+		apiKey:  cfg.APIKey,
+		model:   cfg.Model,
+		timeout: cfg.Timeout,
+		client:  &http.Client{Transport: cfg.Transport},
+	}
+	if openAI.baseURL == "" {
+		openAI.baseURL = defaultOpenAIBaseURL
+	}
+	if openAI.model == "" {
+		openAI.model = defaultOpenAIModel
+	}
+	if openAI.timeout <= 0 {
+		openAI.timeout = config.DefaultAITimeout
+	}
+	// Local servers usually run without a key, only the hosted API requires one
+	if openAI.apiKey == "" && openAI.baseURL == defaultOpenAIBaseURL {
+		return nil, errors.New("openai provider requires AI_API_KEY or OPENAI_API_KEY, or AI_BASE_URL of a local server")
+	}
+	return &llmService{generate: openAI.complete, stream: openAI.stream, generateFile: openAI.completeFile, close: openAI.close}, nil
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequestMessage has a string content, or content parts for attachments
+type chatRequestMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type chatCompletionRequest struct {
+	Model          string               `json:"model"`
+	Messages       []chatRequestMessage `json:"messages"`
+	Temperature    float64              `json:"temperature"`
+	ResponseFormat map[string]any       `json:"response_format,omitempty"`
+	Stream         bool                 `json:"stream,omitempty"`
+	StreamOptions  map[string]any       `json:"stream_options,omitempty"`
+}
+
+// responseFormat asks for output matching the schema. json_schema requires an object
+// at the root, so arrays are wrapped in {"items": [...]} and found again by extractJSONArray.
+func responseFormat(schema *jsonSchema) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	root := schema
+	if schema.Type == "array" {
+		root = objectOf(map[string]*jsonSchema{"items": schema}, "items")
+	}
+	return map[string]any{
+		"type": "json_schema",
+		"json_schema": map[string]any{
+			"name":   "response",
+			"strict": true,
+			"schema": root.toMap(),
+		},
+	}
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// chatCompletionChunk is one event of a streamed completion, the usage only comes
+// with the last one and only when stream_options asks for it
+type chatCompletionChunk struct {
+	Choices []struct {
+		Delta chatMessage `json:"delta"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (u *chatUsage) toAIUsage() model.AIUsage {
+	if u == nil {
+		return model.AIUsage{}
+	}
+	return model.AIUsage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens, TotalTokens: u.TotalTokens}
+}
+
+func (s *openAIService) close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// post sends the chat completion request, the caller closes the response body
+func (s *openAIService) post(ctx context.Context, request chatCompletionRequest) (*http.Response, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+	return s.client.Do(req)
+}
+
+func (s *openAIService) complete(ctx context.Context, prompt string, schema *jsonSchema) (string, model.AIUsage, error) {
+	return s.completeContent(ctx, prompt, schema)
+}
+
+// completeFile attaches a photo as an image and a PDF as a file, both inline as data URLs
+func (s *openAIService) completeFile(ctx context.Context, prompt string, document model.MenuDocument, schema *jsonSchema) (string, model.AIUsage, error) {
+	dataURL := "data:" + document.ContentType + ";base64," + base64.StdEncoding.EncodeToString(document.Data)
+	attachment := map[string]any{"type": "image_url", "image_url": map[string]any{"url": dataURL}}
+	if document.ContentType == "application/pdf" {
+		attachment = map[string]any{"type": "file", "file": map[string]any{"filename": "menu.pdf", "file_data": dataURL}}
+	}
+	return s.completeContent(ctx, []map[string]any{{"type": "text", "text": prompt}, attachment}, schema)
+}
+
+// completeContent sends one user message, content is a string or a list of content parts
+func (s *openAIService) completeContent(ctx context.Context, content any, schema *jsonSchema) (string, model.AIUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	resp, err := s.post(ctx, chatCompletionRequest{
+		Model:          s.model,
+		Messages:       []chatRequestMessage{{Role: "user", Content: content}},
+		Temperature:    0.7,
+		ResponseFormat: responseFormat(schema),
+	})
+	if err != nil {
+		return "", model.AIUsage{}, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return "", model.AIUsage{}, err
+	}
+
+	var completion chatCompletionResponse
+	if err := json.Unmarshal(raw, &completion); err != nil {
+		return "", model.AIUsage{}, &ProviderError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(raw))}
+	}
+	usage := completion.Usage.toAIUsage()
+	if resp.StatusCode != http.StatusOK {
+		if completion.Error != nil {
+			return "", usage, &ProviderError{StatusCode: resp.StatusCode, Message: completion.Error.Message}
+		}
+		return "", usage, &ProviderError{StatusCode: resp.StatusCode}
+	}
+	if len(completion.Choices) == 0 || strings.TrimSpace(completion.Choices[0].Message.Content) == "" {
+		return "", usage, errors.New("empty response from AI")
+	}
+
+	return strings.TrimSpace(completion.Choices[0].Message.Content), usage, nil
+}
+
+// stream reads the completion as Server-Sent Events, one "data:" line per chunk until "[DONE]"
+func (s *openAIService) stream(ctx context.Context, prompt string, schema *jsonSchema, onChunk func(string) error) (model.AIUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var usage model.AIUsage
+	resp, err := s.post(ctx, chatCompletionRequest{
+		Model:          s.model,
+		Messages:       []chatRequestMessage{{Role: "user", Content: prompt}},
+		Temperature:    0.7,
+		ResponseFormat: responseFormat(schema),
+		Stream:         true,
+		StreamOptions:  map[string]any{"include_usage": true},
+	})
+	if err != nil {
+		return usage, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		var completion chatCompletionResponse
+		if json.Unmarshal(raw, &completion) == nil && completion.Error != nil {
+			return usage, &ProviderError{StatusCode: resp.StatusCode, Message: completion.Error.Message}
+		}
+		return usage, &ProviderError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(raw))}
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		data = strings.TrimSpace(data)
+		if !ok || data == "" {
+			continue
+		}
+		if data == "[DONE]" {
+			return usage, nil
+		}
+
+		var chunk chatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return usage, fmt.Errorf("invalid stream chunk from AI provider: %s", data)
+		}
+		if chunk.Error != nil {
+			return usage, fmt.Errorf("AI provider error: %s", chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage.toAIUsage()
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			if err := onChunk(chunk.Choices[0].Delta.Content); err != nil {
+				return usage, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return usage, err
+	}
+	// Some compatible servers close the stream without [DONE]
+	return usage, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"atalariq/menu-api/internal/model"
+)
+
+// Limits of the untrusted text pasted into prompts, in characters. Longer text is cut.
+const (
+	promptFieldLimit  = 100   // names, categories, ingredients, audience
+	promptTextLimit   = 1000  // preferences, messages and descriptions
+	promptAnswerLimit = 32000 // answers sent back to be repaired, a full paper menu fits
+)
+
+// Limits of the text taken from AI answers, in characters
+const (
+	maxReasonLength         = 300
+	maxDescriptionLength    = 1000
+	maxTranslatedNameLength = 200
+)
+
+var ErrPreferenceTooLong = errors.New("preference is too long, the limit is 500 characters")
+
+// untrustedTagPattern matches the tags delimiting untrusted data in the prompt templates,
+// so that data cannot close its block and write instructions after it
+var untrustedTagPattern = regexp.MustCompile(`(?i)<\s*/?\s*untrusted[^>]*>`)
+
+// sanitizePromptText makes text written by customers or restaurants safe to paste
+// between the untrusted tags of a prompt. Line breaks become spaces, so the text cannot
+// pass for a new section of the prompt, and control and invisible formatting characters
+// (zero-width spaces, bidirectional overrides) that hide instructions are dropped.
+func sanitizePromptText(text string, limit int) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		case r == utf8.RuneError, unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+		default:
+			b.WriteRune(r)
+		}
+	}
+	text = untrustedTagPattern.ReplaceAllString(b.String(), " ")
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > limit {
+		text = strings.TrimSpace(string(runes[:limit]))
+	}
+	return text
+}
+
+func sanitizePromptList(items []string, limit int) []string {
+	sanitized := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = sanitizePromptText(item, limit); item != "" {
+			sanitized = append(sanitized, item)
+		}
+	}
+	return sanitized
+}
+
+// sanitizeMenus copies the fields of the menus a prompt shows
+func sanitizeMenus(menus []model.Menu) []model.Menu {
+	sanitized := make([]model.Menu, len(menus))
+	for i, m := range menus {
+		sanitized[i] = model.Menu{
+			ID:          m.ID,
+			Name:        sanitizePromptText(m.Name, promptFieldLimit),
+			Category:    sanitizePromptText(m.Category, promptFieldLimit),
+			Ingredients: sanitizePromptList(m.Ingredients, promptFieldLimit),
+			Price:       m.Price,
+		}
+	}
+	return sanitized
+}
+
+func sanitizeHistory(turns []model.RecommendationTurn) []model.RecommendationTurn {
+	sanitized := make([]model.RecommendationTurn, len(turns))
+	for i, turn := range turns {
+		suggestions := make([]model.SuggestedMenu, len(turn.Suggestions))
+		for j, s := range turn.Suggestions {
+			suggestions[j] = model.SuggestedMenu{MenuID: s.MenuID, Name: sanitizePromptText(s.Name, promptFieldLimit), Price: s.Price}
+		}
+		sanitized[i] = model.RecommendationTurn{Message: sanitizePromptText(turn.Message, promptTextLimit), Suggestions: suggestions}
+	}
+	return sanitized
+}
+
+// promptJSON quotes untrusted values in templates, the quotes and escapes keep the
+// value in one piece and HTML escaping leaves no angle brackets behind
+func promptJSON(value any) (string, error) {
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
+
+// cleanAnswer keeps text written by the AI to one line of at most limit characters,
+// whatever an injected instruction asked it to write
+func cleanAnswer(text string, limit int) string {
+	return sanitizePromptText(text, limit)
+}
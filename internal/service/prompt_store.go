@@ -0,0 +1,551 @@
+package service
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+)
+
+// Names of the prompt templates
+const (
+	PromptDescription    = "description"
+	PromptRecommendation = "recommendation"
+	PromptTranslation    = "translation"
+	PromptEnrichment     = "enrichment"
+	PromptExtraction     = "extraction"
+	PromptReviewSummary  = "review_summary"
+	PromptRepair         = "repair"
+)
+
+var promptNames = []string{PromptDescription, PromptRecommendation, PromptTranslation, PromptEnrichment, PromptExtraction, PromptReviewSummary, PromptRepair}
+
+// promptRefreshInterval is how often the active versions are read again, so that an
+// activation on another instance is picked up
+const promptRefreshInterval = 30 * time.Second
+
+//go:embed prompts/*.tmpl
+var promptFiles embed.FS
+
+var (
+	ErrUnknownPrompt  = errors.New("unknown prompt template")
+	ErrInvalidPrompt  = errors.New("invalid prompt template")
+	ErrPromptReadOnly = errors.New("prompt templates are read-only without a database")
+)
+
+// The prompt data hold sanitized text, see sanitizePromptText. Templates should still
+// quote untrusted values with the json function between <untrusted_...> tags.
+
+// DescriptionPromptData is rendered by the description template
+type DescriptionPromptData struct {
+	Name        string
+	Ingredients []string
+	Style       string // writing style of the tone
+	MaxWords    int
+	Language    string // language name, e.g. "Indonesian"
+	Audience    string
+	Count       int    // number of alternatives, more than 1 asks for a JSON array
+	Format      string // example of the JSON array
+}
+
+// RecommendationPromptData is rendered by the recommendation template
+type RecommendationPromptData struct {
+	Preference string
+	History    []model.RecommendationTurn
+	Menus      []model.Menu
+	Language   string
+	Format     string
+}
+
+// TranslationPromptData is rendered by the translation template
+type TranslationPromptData struct {
+	Language string
+	Items    string // the menus to translate as JSON
+	Format   string
+}
+
+// EnrichmentPromptData is rendered by the enrichment template
+type EnrichmentPromptData struct {
+	Name        string
+	Ingredients []string
+	Description string
+	Categories  []string // the categories of the tenant, any category when empty
+	DietaryTags []string
+	Allergens   []string
+	Format      string
+}
+
+// ExtractionPromptData is rendered by the extraction template, the photo or PDF is sent
+// along with the prompt
+type ExtractionPromptData struct {
+	Categories []string // the categories of the tenant, any category when empty
+	MaxItems   int
+	Format     string
+}
+
+// ReviewSummaryPromptData is rendered by the review summary template
+type ReviewSummaryPromptData struct {
+	Name     string
+	Reviews  []PromptReview // newest first
+	Average  float64        // rating of the reviews given
+	MaxItems int            // most pros and most cons
+	Format   string
+}
+
+// PromptReview is a review without its author
+type PromptReview struct {
+	Rating int
+	Text   string
+}
+
+// RepairPromptData is rendered by the repair template, sent once when an answer is not
+// the JSON asked for. The answer is untrusted, it may echo an injection from the data.
+type RepairPromptData struct {
+	Error  string // why the answer could not be parsed
+	Answer string // the previous answer
+	Format string
+}
+
+var promptFuncs = template.FuncMap{"join": strings.Join, "json": promptJSON}
+
+// PromptStore renders the prompts sent to the AI from named text/template templates.
+// Version 0 of each template is a file, database versions created through the admin
+// API replace it once activated.
+type PromptStore interface {
+	Render(name string, data any) (string, error)
+	// ActiveVersion is the version Render uses for name, 0 for the file template
+	ActiveVersion(name string) int
+	Templates() ([]model.PromptTemplateSummary, error)
+	Versions(name string) ([]model.PromptTemplate, error)
+	// CreateVersion stores a new inactive version, it must render the sample data
+	CreateVersion(name, body, note string) (model.PromptTemplate, error)
+	Activate(name string, version int) (model.PromptTemplate, error)
+	Preview(name string, request model.PromptPreviewRequest) (model.PromptPreview, error)
+}
+
+type promptStore struct {
+	files      map[string]*template.Template
+	fileBodies map[string]string
+	repo       repository.PromptTemplateRepository // nil serves the files only
+
+	mu       sync.RWMutex
+	active   map[string]activePrompt
+	loadedAt time.Time
+}
+
+type activePrompt struct {
+	version  int
+	template *template.Template
+}
+
+// NewPromptStore reads the built-in templates, replaced by the .tmpl files of dir
+// when it is not empty
+func NewPromptStore(dir string, repo repository.PromptTemplateRepository) (PromptStore, error) {
+	s := &promptStore{files: make(map[string]*template.Template), fileBodies: make(map[string]string), repo: repo}
+	for _, name := range promptNames {
+		body, err := promptFiles.ReadFile("prompts/" + name + ".tmpl")
+		if err != nil {
+			return nil, err
+		}
+		if dir != "" {
+			custom, err := os.ReadFile(filepath.Join(dir, name+".tmpl"))
+			switch {
+			case err == nil:
+				body = custom
+			case !errors.Is(err, os.ErrNotExist):
+				return nil, err
+			}
+		}
+		if s.files[name], err = parsePrompt(name, string(body)); err != nil {
+			return nil, fmt.Errorf("prompt template %s: %w", name, err)
+		}
+		s.fileBodies[name] = string(body)
+	}
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// defaultPrompts serves the built-in templates to providers not given a store
+var defaultPrompts = sync.OnceValue(func() PromptStore {
+	store, err := NewPromptStore("", nil)
+	if err != nil {
+		panic(err)
+	}
+	return store
+})
+
+// parsePrompt also executes the template with sample data, so a field that does not
+// exist is reported now rather than on the next AI call
+func parsePrompt(name, body string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(promptFuncs).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrompt, err)
+	}
+	if _, err := execute(tmpl, samplePromptData(name, model.PromptSampleMenu{})); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrompt, err)
+	}
+	return tmpl, nil
+}
+
+func execute(tmpl *template.Template, data any) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+func knownPrompt(name string) bool {
+	for _, known := range promptNames {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// refresh reads the active database versions, a version that no longer parses is
+// logged and the file template is used instead
+func (s *promptStore) refresh() error {
+	active := make(map[string]activePrompt)
+	if s.repo != nil {
+		templates, err := s.repo.FindActive()
+		if err != nil {
+			return err
+		}
+		for _, t := range templates {
+			tmpl, err := parsePrompt(t.Name, t.Body)
+			if err != nil {
+				log.Printf("Prompt template %s version %d is ignored: %v", t.Name, t.Version, err)
+				continue
+			}
+			active[t.Name] = activePrompt{t.Version, tmpl}
+		}
+	}
+
+	s.mu.Lock()
+	s.active = active
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// current returns the active template of name and its version
+func (s *promptStore) current(name string) (*template.Template, int) {
+	s.mu.RLock()
+	stale := s.repo != nil && time.Since(s.loadedAt) > promptRefreshInterval
+	s.mu.RUnlock()
+	if stale {
+		if err := s.refresh(); err != nil {
+			log.Println("Failed to refresh prompt templates:", err)
+			// The loaded versions are kept until the next interval
+			s.mu.Lock()
+			s.loadedAt = time.Now()
+			s.mu.Unlock()
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if prompt, ok := s.active[name]; ok {
+		return prompt.template, prompt.version
+	}
+	return s.files[name], 0
+}
+
+func (s *promptStore) Render(name string, data any) (string, error) {
+	if !knownPrompt(name) {
+		return "", ErrUnknownPrompt
+	}
+	tmpl, _ := s.current(name)
+	return execute(tmpl, data)
+}
+
+func (s *promptStore) ActiveVersion(name string) int {
+	_, version := s.current(name)
+	return version
+}
+
+func (s *promptStore) Templates() ([]model.PromptTemplateSummary, error) {
+	summaries := make([]model.PromptTemplateSummary, 0, len(promptNames))
+	for _, name := range promptNames {
+		versions, err := s.Versions(name)
+		if err != nil {
+			return nil, err
+		}
+		summary := model.PromptTemplateSummary{Name: name, LatestVersion: versions[len(versions)-1].Version}
+		for _, v := range versions {
+			if v.Active {
+				summary.ActiveVersion = v.Version
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// Versions lists the file template as version 0, then the database versions
+func (s *promptStore) Versions(name string) ([]model.PromptTemplate, error) {
+	if !knownPrompt(name) {
+		return nil, ErrUnknownPrompt
+	}
+	file := model.PromptTemplate{Name: name, Body: s.fileBodies[name], Active: true, Source: model.PromptSourceFile}
+	if s.repo == nil {
+		return []model.PromptTemplate{file}, nil
+	}
+
+	stored, err := s.repo.FindVersions(name)
+	if err != nil {
+		return nil, err
+	}
+	versions := []model.PromptTemplate{file}
+	for _, t := range stored {
+		t.Source = model.PromptSourceDatabase
+		if t.Active {
+			versions[0].Active = false
+		}
+		versions = append(versions, t)
+	}
+	return versions, nil
+}
+
+func (s *promptStore) CreateVersion(name, body, note string) (model.PromptTemplate, error) {
+	if !knownPrompt(name) {
+		return model.PromptTemplate{}, ErrUnknownPrompt
+	}
+	if s.repo == nil {
+		return model.PromptTemplate{}, ErrPromptReadOnly
+	}
+	if _, err := parsePrompt(name, body); err != nil {
+		return model.PromptTemplate{}, err
+	}
+
+	created := model.PromptTemplate{Name: name, Body: body, Note: note}
+	if err := s.repo.Create(&created); err != nil {
+		return created, err
+	}
+	created.Source = model.PromptSourceDatabase
+	return created, nil
+}
+
+// Activate switches to version right away on this instance, the others follow within
+// promptRefreshInterval. Version 0 goes back to the file template.
+func (s *promptStore) Activate(name string, version int) (model.PromptTemplate, error) {
+	if !knownPrompt(name) {
+		return model.PromptTemplate{}, ErrUnknownPrompt
+	}
+	if s.repo == nil {
+		return model.PromptTemplate{}, ErrPromptReadOnly
+	}
+	if err := s.repo.Activate(name, version); err != nil {
+		return model.PromptTemplate{}, err
+	}
+	if err := s.refresh(); err != nil {
+		return model.PromptTemplate{}, err
+	}
+
+	versions, err := s.Versions(name)
+	if err != nil {
+		return model.PromptTemplate{}, err
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return model.PromptTemplate{}, fmt.Errorf("version %d of prompt %s disappeared", version, name)
+}
+
+func (s *promptStore) Preview(name string, request model.PromptPreviewRequest) (model.PromptPreview, error) {
+	if !knownPrompt(name) {
+		return model.PromptPreview{}, ErrUnknownPrompt
+	}
+	preview := model.PromptPreview{Name: name}
+
+	var tmpl *template.Template
+	var err error
+	switch {
+	case request.Body != "":
+		if tmpl, err = parsePrompt(name, request.Body); err != nil {
+			return preview, err
+		}
+	case request.Version != nil:
+		if tmpl, err = s.version(name, *request.Version); err != nil {
+			return preview, err
+		}
+		preview.Version = request.Version
+	default:
+		var version int
+		tmpl, version = s.current(name)
+		preview.Version = &version
+	}
+
+	if preview.Prompt, err = execute(tmpl, samplePromptData(name, request.Menu)); err != nil {
+		return preview, fmt.Errorf("%w: %v", ErrInvalidPrompt, err)
+	}
+	return preview, nil
+}
+
+// version parses a stored version, it returns gorm.ErrRecordNotFound when it does not exist
+func (s *promptStore) version(name string, version int) (*template.Template, error) {
+	if version == 0 {
+		return s.files[name], nil
+	}
+	if s.repo == nil {
+		return nil, ErrPromptReadOnly
+	}
+	stored, err := s.repo.FindVersion(name, version)
+	if err != nil {
+		return nil, err
+	}
+	return parsePrompt(name, stored.Body)
+}
+
+// samplePromptData fills the data of a template from one menu
+func samplePromptData(name string, sample model.PromptSampleMenu) any {
+	if sample.Name == "" {
+		sample.Name = "Nasi Goreng"
+	}
+	if sample.Category == "" {
+		sample.Category = "food"
+	}
+	if sample.Description == "" {
+		sample.Description = "Fried rice with a fried egg and sambal"
+	}
+	if len(sample.Ingredients) == 0 {
+		sample.Ingredients = []string{"rice", "egg", "sambal"}
+	}
+	menu := model.Menu{ID: 1, Name: sample.Name, Category: sample.Category, Description: sample.Description, Ingredients: sample.Ingredients}
+
+	switch name {
+	case PromptDescription:
+		return descriptionPromptData(menu.Name, menu.Ingredients, model.DescriptionOptions{}, 1)
+	case PromptRecommendation:
+		return recommendationPromptData(model.RecommendationRequest{
+			Preference: "Something filling but not too spicy",
+			History: []model.RecommendationTurn{{
+				Message:     "I am hungry",
+				Suggestions: []model.SuggestedMenu{{MenuID: menu.ID, Name: menu.Name, Price: 25000}},
+			}},
+		}, []model.Menu{menu, {ID: 2, Name: "Es Teh", Category: "drinks", Ingredients: []string{"tea", "ice"}}})
+	case PromptTranslation:
+		data, _ := translationPromptData([]model.TranslationItem{{MenuID: menu.ID, Name: menu.Name, Description: menu.Description}}, "id")
+		return data
+	case PromptEnrichment:
+		return enrichmentPromptData(menu, []string{"dessert", "drinks", "food"})
+	case PromptExtraction:
+		return extractionPromptData([]string{"dessert", "drinks", "food"})
+	case PromptReviewSummary:
+		return reviewSummaryPromptData(menu, []model.Review{
+			{Rating: 5, Text: "Smoky and just spicy enough, a big portion"},
+			{Rating: 2, Text: "Too oily for me"},
+		})
+	case PromptRepair:
+		return repairPromptData(errors.New("invalid character 'H' looking for beginning of value"), "Here are the menus: [{", extractionFormat)
+	}
+	return nil
+}
+
+func descriptionPromptData(name string, ingredients []string, options model.DescriptionOptions, count int) DescriptionPromptData {
+	options = options.WithDefaults()
+	return DescriptionPromptData{
+		Name:        sanitizePromptText(name, promptFieldLimit),
+		Ingredients: sanitizePromptList(ingredients, promptFieldLimit),
+		Style:       toneStyles[options.Tone],
+		MaxWords:    options.MaxWords,
+		Language:    model.LanguageName(options.Language),
+		Audience:    sanitizePromptText(options.Audience, promptFieldLimit),
+		Count:       max(count, 1),
+		Format:      descriptionsFormat,
+	}
+}
+
+func recommendationPromptData(request model.RecommendationRequest, menus []model.Menu) RecommendationPromptData {
+	return RecommendationPromptData{
+		Preference: sanitizePromptText(request.Preference, promptTextLimit),
+		History:    sanitizeHistory(request.History),
+		Menus:      sanitizeMenus(menus),
+		Language:   model.LanguageName(request.Locale),
+		Format:     recommendationFormat,
+	}
+}
+
+func translationPromptData(items []model.TranslationItem, locale string) (TranslationPromptData, error) {
+	sanitized := make([]model.TranslationItem, len(items))
+	for i, item := range items {
+		sanitized[i] = model.TranslationItem{
+			MenuID:      item.MenuID,
+			Name:        sanitizePromptText(item.Name, promptFieldLimit),
+			Description: sanitizePromptText(item.Description, promptTextLimit),
+		}
+	}
+	input, err := json.Marshal(sanitized)
+	if err != nil {
+		return TranslationPromptData{}, err
+	}
+	return TranslationPromptData{Language: model.LanguageName(locale), Items: string(input), Format: translationFormat}, nil
+}
+
+func enrichmentPromptData(menu model.Menu, categories []string) EnrichmentPromptData {
+	dietaryTags := make([]string, 0, len(model.DietaryTags))
+	for slug := range model.DietaryTags {
+		dietaryTags = append(dietaryTags, slug)
+	}
+	sort.Strings(dietaryTags)
+	return EnrichmentPromptData{
+		Name:        sanitizePromptText(menu.Name, promptFieldLimit),
+		Ingredients: sanitizePromptList(menu.Ingredients, promptFieldLimit),
+		Description: sanitizePromptText(menu.Description, promptTextLimit),
+		Categories:  sanitizePromptList(categories, promptFieldLimit),
+		DietaryTags: dietaryTags,
+		Allergens:   model.Allergens,
+		Format:      enrichmentFormat,
+	}
+}
+
+func extractionPromptData(categories []string) ExtractionPromptData {
+	return ExtractionPromptData{
+		Categories: sanitizePromptList(categories, promptFieldLimit),
+		MaxItems:   model.MaxExtractedMenus,
+		Format:     extractionFormat,
+	}
+}
+
+func reviewSummaryPromptData(menu model.Menu, reviews []model.Review) ReviewSummaryPromptData {
+	data := ReviewSummaryPromptData{
+		Name:     sanitizePromptText(menu.Name, promptFieldLimit),
+		Reviews:  make([]PromptReview, len(reviews)),
+		MaxItems: maxReviewPoints,
+		Format:   reviewSummaryFormat,
+	}
+	total := 0
+	for i, review := range reviews {
+		data.Reviews[i] = PromptReview{Rating: review.Rating, Text: sanitizePromptText(review.Text, promptTextLimit)}
+		total += review.Rating
+	}
+	if len(reviews) > 0 {
+		data.Average = float64(total) / float64(len(reviews))
+	}
+	return data
+}
+
+func repairPromptData(parseErr error, answer, format string) RepairPromptData {
+	return RepairPromptData{
+		Error:  sanitizePromptText(parseErr.Error(), promptTextLimit),
+		Answer: sanitizePromptText(answer, promptAnswerLimit),
+		Format: format,
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var ErrSessionNotFound = errors.New("recommendation session not found or expired")
+
+// DefaultRecommendationSessionTTL is how long a session lives after its last message
+const DefaultRecommendationSessionTTL = 30 * time.Minute
+
+// maxSessionTurns bounds the history kept per session, and so the prompt size
+const maxSessionTurns = 10
+
+// RecommendationSessionService lets a customer refine recommendations over several messages,
+// every message is answered knowing the earlier ones and what was suggested for them
+type RecommendationSessionService interface {
+	Start(ctx context.Context, scope model.Scope, message string) (model.RecommendationSessionResponse, error)
+	Send(ctx context.Context, scope model.Scope, sessionID, message string) (model.RecommendationSessionResponse, error)
+	Get(scope model.Scope, sessionID string) (model.RecommendationSessionResponse, error)
+}
+
+type recommendationSessionService struct {
+	repo  repository.RecommendationSessionRepository
+	menus MenuService
+	ttl   time.Duration
+}
+
+func NewRecommendationSessionService(repo repository.RecommendationSessionRepository, menus MenuService, ttl time.Duration) RecommendationSessionService {
+	if ttl <= 0 {
+		ttl = DefaultRecommendationSessionTTL
+	}
+	return &recommendationSessionService{
+		repo:  repo,
+		menus: menus,
+		ttl:   ttl,
+	}
+}
+
+func (s *recommendationSessionService) Start(ctx context.Context, scope model.Scope, message string) (model.RecommendationSessionResponse, error) {
+	// Expired sessions are cleaned up here rather than by a background job
+	if _, err := s.repo.DeleteExpired(time.Now()); err != nil {
+		log.Printf("Failed to delete expired recommendation sessions: %v", err)
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		return model.RecommendationSessionResponse{}, err
+	}
+	session := model.RecommendationSession{
+		ID:        id,
+		TenantID:  scope.TenantID,
+		Turns:     []model.RecommendationTurn{},
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+
+	var recommendations []model.RecommendationResponse
+	if message != "" {
+		if recommendations, err = s.reply(ctx, scope, &session, message); err != nil {
+			return model.RecommendationSessionResponse{}, err
+		}
+	}
+	if err := s.repo.Create(&session); err != nil {
+		return model.RecommendationSessionResponse{}, err
+	}
+	return sessionResponse(session, recommendations), nil
+}
+
+func (s *recommendationSessionService) Send(ctx context.Context, scope model.Scope, sessionID, message string) (model.RecommendationSessionResponse, error) {
+	session, err := s.find(scope, sessionID)
+	if err != nil {
+		return model.RecommendationSessionResponse{}, err
+	}
+
+	recommendations, err := s.reply(ctx, scope, &session, message)
+	if err != nil {
+		return model.RecommendationSessionResponse{}, err
+	}
+	if err := s.repo.Update(&session); err != nil {
+		return model.RecommendationSessionResponse{}, err
+	}
+	return sessionResponse(session, recommendations), nil
+}
+
+func (s *recommendationSessionService) Get(scope model.Scope, sessionID string) (model.RecommendationSessionResponse, error) {
+	session, err := s.find(scope, sessionID)
+	if err != nil {
+		return model.RecommendationSessionResponse{}, err
+	}
+	return sessionResponse(session, nil), nil
+}
+
+func (s *recommendationSessionService) find(scope model.Scope, sessionID string) (model.RecommendationSession, error) {
+	session, err := s.repo.Find(scope.TenantID, sessionID, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return session, ErrSessionNotFound
+	}
+	return session, err
+}
+
+// reply recommends for the message with the session history, then records the turn
+func (s *recommendationSessionService) reply(ctx context.Context, scope model.Scope, session *model.RecommendationSession, message string) ([]model.RecommendationResponse, error) {
+	recommendations, err := s.menus.GetRecommendations(ctx, scope, model.RecommendationRequest{
+		Preference: message,
+		History:    session.Turns,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	turn := model.RecommendationTurn{
+		Message:     message,
+		Suggestions: make([]model.SuggestedMenu, len(recommendations)),
+		CreatedAt:   now,
+	}
+	for i, r := range recommendations {
+		turn.Suggestions[i] = model.SuggestedMenu{MenuID: r.Menu.ID, Name: r.Menu.Name, Price: r.Menu.Price, Reason: r.Reason}
+	}
+
+	session.Turns = append(session.Turns, turn)
+	if len(session.Turns) > maxSessionTurns {
+		session.Turns = session.Turns[len(session.Turns)-maxSessionTurns:]
+	}
+	session.ExpiresAt = now.Add(s.ttl)
+	session.UpdatedAt = now
+	return recommendations, nil
+}
+
+func sessionResponse(session model.RecommendationSession, recommendations []model.RecommendationResponse) model.RecommendationSessionResponse {
+	if recommendations == nil {
+		recommendations = []model.RecommendationResponse{}
+	}
+	return model.RecommendationSessionResponse{
+		SessionID:       session.ID,
+		ExpiresAt:       session.ExpiresAt,
+		Recommendations: recommendations,
+		Turns:           session.Turns,
+	}
+}
+
+func newSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
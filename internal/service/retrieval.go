@@ -0,0 +1,412 @@
+package service
+
+import (
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"atalariq/menu-api/internal/model"
+)
+
+// recommendationShortlistSize bounds how many menus are described in a recommendation prompt,
+// whatever the size of the catalog
+const recommendationShortlistSize = 20
+
+// retrievalPageSize is the number of menus read at a time while shortlisting
+const retrievalPageSize = 200
+
+// similarityWeight scales the text similarity (0 to 1) against the keyword score
+const similarityWeight = 2.0
+
+// constraintPattern finds limits such as "under 50k", "below Rp 25.000", "max 500 calories"
+// or "di bawah 30 ribu"
+var constraintPattern = regexp.MustCompile(`(?i)\b(under|below|less than|cheaper than|no more than|at most|up to|max(?:imum)?|budget(?: of| is)?|within|over|above|more than|at least|min(?:imum)?|di ?bawah|kurang dari|maks(?:imal)?|di ?atas|lebih dari|minimal)\s*(?:rp\.?|idr|\$)?\s*(\d[\d.,]*)\s*(k|rb|ribu|jt|juta|kcal|kkal|cal|calories|calorie|kalori)?\b`)
+
+// lowerBoundPattern tells the qualifiers that start a minimum instead of a maximum
+var lowerBoundPattern = regexp.MustCompile(`(?i)^(over|above|more than|at least|min(?:imum)?|di ?atas|lebih dari|minimal)$`)
+
+var calorieUnits = map[string]bool{"kcal": true, "kkal": true, "cal": true, "calorie": true, "calories": true, "kalori": true}
+
+// dietPhrases map the ways a diet is asked for to the tag slug it is filtered by
+var dietPhrases = []struct {
+	slug    string
+	phrases []string
+}{
+	{"vegan", []string{"vegan"}},
+	{"vegetarian", []string{"vegetarian", "veggie", "tanpa daging"}},
+	{"gluten-free", []string{"gluten free", "tanpa gluten"}},
+	{"dairy-free", []string{"dairy free", "lactose free", "tanpa susu"}},
+	{"halal", []string{"halal"}},
+}
+
+// categoryHints let "a drink" find a category named "Beverages" or "Minuman"
+var categoryHints = map[string][]string{
+	"drink":   {"beverage", "minuman"},
+	"minum":   {"drink", "beverage", "minuman"},
+	"minuman": {"drink", "beverage"},
+	"snack":   {"cemilan", "camilan"},
+	"cemilan": {"snack"},
+	"camilan": {"snack"},
+	"dessert": {"pencuci"},
+	"makanan": {"food"},
+}
+
+// exclusionPattern finds what the customer does not want, e.g. "without dairy" or "tanpa pedas"
+var exclusionPattern = regexp.MustCompile(`(?i)\b(?:without|no|not|tanpa|jangan|bukan)\s+(?:too\s+|very\s+|any\s+|terlalu\s+)?([\p{L}]+)`)
+
+// exclusionHints expand an excluded word into the ingredients it stands for
+var exclusionHints = map[string][]string{
+	"dairy":  {"milk", "cheese", "cream", "butter", "yogurt", "susu", "keju", "krim"},
+	"susu":   {"milk", "dairy", "cheese", "cream", "keju"},
+	"meat":   {"beef", "chicken", "pork", "lamb", "ayam", "sapi", "daging", "babi"},
+	"daging": {"beef", "chicken", "pork", "lamb", "ayam", "sapi", "meat"},
+	"nuts":   {"nut", "peanut", "almond", "cashew", "kacang"},
+	"kacang": {"nut", "peanut", "almond", "cashew"},
+	"spicy":  {"chili", "chilli", "sambal", "cabai", "pedas"},
+	"pedas":  {"chili", "chilli", "sambal", "cabai", "spicy"},
+	"sugar":  {"gula", "sweet", "manis"},
+	"gula":   {"sugar", "sweet", "manis"},
+	"pork":   {"babi"},
+	"babi":   {"pork"},
+}
+
+// cheaperPattern asks for less than what was suggested before
+var cheaperPattern = regexp.MustCompile(`(?i)\b(cheaper|less expensive|more affordable|lebih murah)\b`)
+
+// recommendationConstraints are the hard limits found in a preference
+type recommendationConstraints struct {
+	filter      model.MenuFilter
+	exclude     []string // words no shortlisted menu may contain
+	constrained bool
+	text        string // the preference without the limits, used for scoring
+}
+
+// refine applies the constraints of a later message on top of the earlier ones
+func (c *recommendationConstraints) refine(next recommendationConstraints) {
+	if next.filter.MaxPrice > 0 {
+		c.filter.MaxPrice = next.filter.MaxPrice
+	}
+	if next.filter.MinPrice > 0 {
+		c.filter.MinPrice = next.filter.MinPrice
+	}
+	if next.filter.MaxCal > 0 {
+		c.filter.MaxCal = next.filter.MaxCal
+	}
+	if next.filter.Category != "" {
+		c.filter.Category = next.filter.Category
+	}
+	if len(next.filter.Tags) > 0 {
+		c.filter.Tags = uniqueWords(append(c.filter.Tags, next.filter.Tags...))
+		c.filter.TagsMode = model.TagMatchAll
+	}
+	c.exclude = uniqueWords(append(c.exclude, next.exclude...))
+	c.constrained = c.constrained || next.constrained
+	c.text = strings.TrimSpace(c.text + " " + next.text)
+}
+
+// conversationConstraints combines the constraints of every turn of a session, the latest
+// winning. "Cheaper" lowers the budget below the cheapest menu suggested the turn before.
+func conversationConstraints(request model.RecommendationRequest, facets model.MenuFacets) recommendationConstraints {
+	c := recommendationConstraints{filter: model.MenuFilter{AvailableOnly: true}}
+	messages := make([]string, 0, len(request.History)+1)
+	for _, turn := range request.History {
+		messages = append(messages, turn.Message)
+	}
+	messages = append(messages, request.Preference)
+
+	for i, message := range messages {
+		c.refine(parseConstraints(message, facets))
+		if i == 0 || !cheaperPattern.MatchString(message) {
+			continue
+		}
+		suggestions := request.History[i-1].Suggestions
+		if len(suggestions) == 0 {
+			continue
+		}
+		lowest := suggestions[0].Price
+		for _, suggestion := range suggestions {
+			lowest = math.Min(lowest, suggestion.Price)
+		}
+		if budget := math.Nextafter(lowest, 0); c.filter.MaxPrice == 0 || budget < c.filter.MaxPrice {
+			c.filter.MaxPrice = budget
+		}
+		c.constrained = true
+	}
+	return c
+}
+
+// searchText is the wording menus are scored against: every message of the conversation
+// without its limits and exclusions, which are applied as filters instead
+func searchText(request model.RecommendationRequest) string {
+	parts := make([]string, 0, len(request.History)+1)
+	for _, turn := range request.History {
+		parts = append(parts, stripConstraints(turn.Message))
+	}
+	parts = append(parts, stripConstraints(request.Preference))
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+func stripConstraints(preference string) string {
+	text := constraintPattern.ReplaceAllString(preference, " ")
+	text = exclusionPattern.ReplaceAllString(text, " ")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// parseConstraints turns budget, calories, category and diet wording into a menu filter.
+// Categories and diets only count when the catalog has them, facets tells which it has.
+func parseConstraints(preference string, facets model.MenuFacets) recommendationConstraints {
+	c := recommendationConstraints{
+		filter: model.MenuFilter{AvailableOnly: true},
+		text:   preference,
+	}
+
+	for _, match := range constraintPattern.FindAllStringSubmatch(preference, -1) {
+		unit := strings.ToLower(match[3])
+		amount, ok := parseAmount(match[2], unit)
+		if !ok {
+			continue
+		}
+		lower := lowerBoundPattern.MatchString(match[1])
+		switch {
+		case calorieUnits[unit]:
+			if !lower { // MenuFilter has no minimum calories
+				c.filter.MaxCal = int(amount)
+			}
+		case lower:
+			c.filter.MinPrice = amount
+		default:
+			c.filter.MaxPrice = amount
+		}
+		c.constrained = true
+	}
+	// Limits go first, "no more than 30k" is not an exclusion
+	withoutLimits := constraintPattern.ReplaceAllString(preference, " ")
+	for _, match := range exclusionPattern.FindAllStringSubmatch(withoutLimits, -1) {
+		word := strings.ToLower(match[1])
+		if stopWords[word] {
+			continue
+		}
+		c.exclude = append(c.exclude, word)
+		c.exclude = append(c.exclude, exclusionHints[word]...)
+		c.constrained = true
+	}
+	c.text = stripConstraints(preference)
+
+	words := tokenize(c.text)
+	phrase := " " + strings.Join(words, " ") + " "
+
+	available := make(map[string]bool, len(facets.Tags))
+	for _, tag := range facets.Tags {
+		available[tag.Slug] = true
+	}
+	for _, diet := range dietPhrases {
+		for _, p := range diet.phrases {
+			if available[diet.slug] && strings.Contains(phrase, " "+p+" ") {
+				c.filter.Tags = append(c.filter.Tags, diet.slug)
+				c.filter.TagsMode = model.TagMatchAll
+				c.constrained = true
+				break
+			}
+		}
+	}
+
+	// A category is only applied when the wording points to exactly one
+	var expanded []string
+	for _, word := range words {
+		expanded = append(expanded, word)
+		expanded = append(expanded, categoryHints[word]...)
+	}
+	var categories []string
+	for _, facet := range facets.Categories {
+		if facet.Value != "" && containsAllWords(expanded, tokenize(facet.Value)) {
+			categories = append(categories, facet.Value)
+		}
+	}
+	if len(categories) == 1 {
+		c.filter.Category = categories[0]
+		c.constrained = true
+	}
+
+	return c
+}
+
+// parseAmount reads "25.000", "25,000", "12.5" and "50" followed by k, rb, ribu, jt or juta
+func parseAmount(number, unit string) (float64, bool) {
+	number = strings.TrimRight(number, ".,")
+	if thousandsPattern.MatchString(number) {
+		number = strings.NewReplacer(".", "", ",", "").Replace(number)
+	} else {
+		number = strings.ReplaceAll(number, ",", ".")
+	}
+	amount, err := strconv.ParseFloat(number, 64)
+	if err != nil || amount <= 0 {
+		return 0, false
+	}
+
+	switch unit {
+	case "k", "rb", "ribu":
+		amount *= 1000
+	case "jt", "juta":
+		amount *= 1000000
+	}
+	return amount, true
+}
+
+var thousandsPattern = regexp.MustCompile(`^\d{1,3}([.,]\d{3})+$`)
+
+func containsAllWords(words, wanted []string) bool {
+	if len(wanted) == 0 {
+		return false
+	}
+	for _, w := range wanted {
+		found := false
+		for _, word := range words {
+			if wordsMatch(word, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAnyWord(words, unwanted []string) bool {
+	for _, u := range unwanted {
+		for _, word := range words {
+			if wordsMatch(word, u) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func uniqueWords(words []string) []string {
+	seen := make(map[string]bool, len(words))
+	unique := words[:0]
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			unique = append(unique, w)
+		}
+	}
+	return unique
+}
+
+// shortlistMenus picks the candidates of a recommendation prompt from the whole catalog:
+// the preference limits are applied in the query, then the menus are ranked by keywords
+// and text similarity and only the best are kept
+func (s *menuService) shortlistMenus(scope model.Scope, request model.RecommendationRequest) ([]model.Menu, error) {
+	if utf8.RuneCountInString(request.Preference) > model.MaxPreferenceLength {
+		return nil, ErrPreferenceTooLong
+	}
+	facets, err := s.repo.Facets(scope, model.MenuFilter{AvailableOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	constraints := conversationConstraints(request, facets)
+	menus, err := s.shortlist(scope, constraints.filter, constraints.exclude, constraints.text)
+	if err != nil || len(menus) > 0 || !constraints.constrained {
+		return menus, err
+	}
+
+	// Nothing meets every limit, the closest menus are still worth suggesting.
+	// Exclusions are kept, they are often allergies.
+	// Only the parsed limits are logged, the preference is customer text
+	f := constraints.filter
+	log.Printf("No menu meets the limits (category %q, price %.0f-%.0f, max %d kcal, %d tags), shortlisting without them",
+		f.Category, f.MinPrice, f.MaxPrice, f.MaxCal, len(f.Tags))
+	return s.shortlist(scope, model.MenuFilter{AvailableOnly: true}, constraints.exclude, constraints.text)
+}
+
+func (s *menuService) shortlist(scope model.Scope, filter model.MenuFilter, exclude []string, text string) ([]model.Menu, error) {
+	type candidate struct {
+		menu  model.Menu
+		score float64
+	}
+
+	keywords := newKeywordMatcher(text)
+	query := trigrams(text)
+
+	var best []candidate
+	filter.Page, filter.PerPage = 1, retrievalPageSize
+	for {
+		menus, pagination, err := s.repo.FindAll(scope, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, menu := range menus {
+			if containsAnyWord(tokenize(menuText(menu)), exclude) {
+				continue
+			}
+			score, _ := keywords.score(menu)
+			best = append(best, candidate{
+				menu:  menu,
+				score: float64(score) + similarityWeight*trigramSimilarity(query, trigrams(menuText(menu))),
+			})
+		}
+
+		// Ties keep the catalog order, newest first
+		sort.SliceStable(best, func(i, j int) bool {
+			return best[i].score > best[j].score
+		})
+		if len(best) > recommendationShortlistSize {
+			best = best[:recommendationShortlistSize]
+		}
+
+		if filter.Page >= pagination.TotalPages {
+			break
+		}
+		filter.Page++
+	}
+
+	menus := make([]model.Menu, len(best))
+	for i, c := range best {
+		menus[i] = c.menu
+	}
+	return menus, nil
+}
+
+func menuText(menu model.Menu) string {
+	return menu.Name + " " + menu.Category + " " + strings.Join(menu.Ingredients, " ") + " " + menu.Description
+}
+
+// trigrams counts the letter trigrams of every word, which matches spelling variants
+// and word forms ("noodles", "mi goreng") that exact keywords miss
+func trigrams(text string) map[string]int {
+	grams := make(map[string]int)
+	for _, word := range tokenize(text) {
+		if stopWords[word] {
+			continue
+		}
+		padded := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			grams[string(padded[i:i+3])]++
+		}
+	}
+	return grams
+}
+
+// trigramSimilarity is the cosine similarity of two trigram counts
+func trigramSimilarity(a, b map[string]int) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for gram, count := range a {
+		dot += float64(count * b[gram])
+		normA += float64(count * count)
+	}
+	for _, count := range b {
+		normB += float64(count * count)
+	}
+	return dot / math.Sqrt(normA*normB)
+}
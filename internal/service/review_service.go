@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+)
+
+var ErrNoReviewsToSummarize = errors.New("the menu has no approved reviews to summarize")
+
+// anonymousAuthor signs the reviews left without a name
+const anonymousAuthor = "Anonymous"
+
+// maxSummarizedReviews is the number of newest approved reviews a summary is made from
+const maxSummarizedReviews = 30
+
+// Limits of a review summary taken from an AI answer
+const (
+	maxReviewPoints        = 3   // pros, and cons
+	maxReviewSummaryLength = 300 // characters
+	maxReviewPointLength   = 100 // characters
+)
+
+// ReviewService stores the reviews of diners. Reviews wait for moderation, only the
+// approved ones are shown by default and make the rating of the menu.
+type ReviewService interface {
+	CreateReview(scope model.Scope, menuID uint, request model.ReviewRequest) (model.Review, error)
+	// ListReviews lists the reviews with the status, newest first, of every menu when menuID is 0
+	ListReviews(scope model.Scope, menuID uint, status string) ([]model.Review, error)
+	ModerateReview(scope model.Scope, id uint, status string) (model.Review, error)
+	// SummarizeReviews asks the AI for the pros and cons of the newest approved reviews,
+	// and stores the summary on the menu
+	SummarizeReviews(ctx context.Context, scope model.Scope, menuID uint) (model.ReviewSummary, error)
+}
+
+type reviewService struct {
+	repo     repository.ReviewRepository
+	menuRepo repository.MenuRepository
+	ai       AIService
+}
+
+func NewReviewService(repo repository.ReviewRepository, menuRepo repository.MenuRepository, ai AIService) ReviewService {
+	return &reviewService{
+		repo:     repo,
+		menuRepo: menuRepo,
+		ai:       ai,
+	}
+}
+
+func (s *reviewService) CreateReview(scope model.Scope, menuID uint, request model.ReviewRequest) (model.Review, error) {
+	// Reviews are about the menu, whichever branch served it
+	if _, err := s.menuRepo.FindByID(scope.Base(), menuID); err != nil {
+		return model.Review{}, err
+	}
+
+	review := model.Review{
+		TenantID: scope.TenantID,
+		MenuID:   menuID,
+		Rating:   request.Rating,
+		Text:     strings.TrimSpace(request.Text),
+		Author:   strings.TrimSpace(request.Author),
+		Status:   model.ReviewStatusPending,
+	}
+	if review.Author == "" {
+		review.Author = anonymousAuthor
+	}
+	err := s.repo.Create(&review)
+	return review, err
+}
+
+func (s *reviewService) ListReviews(scope model.Scope, menuID uint, status string) ([]model.Review, error) {
+	if menuID != 0 {
+		if _, err := s.menuRepo.FindByID(scope.Base(), menuID); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.FindAll(scope.TenantID, menuID, status, 0)
+}
+
+func (s *reviewService) ModerateReview(scope model.Scope, id uint, status string) (model.Review, error) {
+	review, err := s.repo.FindByID(scope.TenantID, id)
+	if err != nil {
+		return model.Review{}, err
+	}
+	if review.Status == status {
+		return review, nil
+	}
+
+	review.Status = status
+	review.UpdatedAt = time.Now()
+	err = s.repo.UpdateStatus(&review)
+	return review, err
+}
+
+func (s *reviewService) SummarizeReviews(ctx context.Context, scope model.Scope, menuID uint) (model.ReviewSummary, error) {
+	menu, err := s.menuRepo.FindByID(scope.Base(), menuID)
+	if err != nil {
+		return model.ReviewSummary{}, err
+	}
+	reviews, err := s.repo.FindAll(scope.TenantID, menuID, model.ReviewStatusApproved, maxSummarizedReviews)
+	if err != nil {
+		return model.ReviewSummary{}, err
+	}
+	if len(reviews) == 0 {
+		return model.ReviewSummary{}, ErrNoReviewsToSummarize
+	}
+
+	answer, err := s.ai.SummarizeReviews(ctx, menu, reviews)
+	if err != nil {
+		return model.ReviewSummary{}, err
+	}
+	summary := cleanReviewSummary(answer)
+	summary.ReviewCount = len(reviews)
+	summary.GeneratedAt = time.Now().UTC()
+	if err := s.repo.SaveSummary(scope.TenantID, menuID, summary); err != nil {
+		return model.ReviewSummary{}, err
+	}
+	return summary, nil
+}
+
+// cleanReviewSummary keeps a one-line summary and the first distinct pros and cons
+func cleanReviewSummary(answer model.ReviewSummary) model.ReviewSummary {
+	return model.ReviewSummary{
+		Summary: cleanAnswer(answer.Summary, maxReviewSummaryLength),
+		Pros:    cleanReviewPoints(answer.Pros),
+		Cons:    cleanReviewPoints(answer.Cons),
+	}
+}
+
+func cleanReviewPoints(points []string) []string {
+	cleaned := []string{}
+	seen := make(map[string]bool)
+	for _, point := range points {
+		point = cleanAnswer(point, maxReviewPointLength)
+		key := strings.ToLower(point)
+		if point == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		cleaned = append(cleaned, point)
+		if len(cleaned) == maxReviewPoints {
+			break
+		}
+	}
+	return cleaned
+}
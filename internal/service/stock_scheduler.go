@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ParseResetTime parses a daily reset time in "HH:MM" format
+func ParseResetTime(value string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid reset time %q, expected HH:MM", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// NextDailyRun returns the next occurrence of hour:minute strictly after now, in now's location
+func NextDailyRun(now time.Time, hour, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// StartDailyStockReset runs MenuService.ResetDailyStock every day at hour:minute until ctx is cancelled
+func StartDailyStockReset(ctx context.Context, svc MenuService, hour, minute int, loc *time.Location) {
+	go func() {
+		for {
+			next := NextDailyRun(time.Now().In(loc), hour, minute)
+			timer := time.NewTimer(time.Until(next))
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				affected, err := svc.ResetDailyStock()
+				if err != nil {
+					log.Println("Daily stock reset failed:", err)
+					continue
+				}
+				log.Printf("Daily stock reset: %d menus restored", affected)
+			}
+		}
+	}()
+}
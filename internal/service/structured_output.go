@@ -0,0 +1,149 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var ErrNoJSONArray = errors.New("no JSON array found in AI response")
+
+// jsonSchema describes the JSON a model must answer with. Providers translate it
+// to their structured output format (Gemini response schema, OpenAI json_schema).
+type jsonSchema struct {
+	Type       string // "array", "object", "string", "integer" or "number"
+	Items      *jsonSchema
+	Properties map[string]*jsonSchema
+	Required   []string
+}
+
+func arrayOf(items *jsonSchema) *jsonSchema {
+	return &jsonSchema{Type: "array", Items: items}
+}
+
+// objectOf builds an object schema where every property is required
+func objectOf(properties map[string]*jsonSchema, order ...string) *jsonSchema {
+	return &jsonSchema{Type: "object", Properties: properties, Required: order}
+}
+
+var (
+	stringSchema  = &jsonSchema{Type: "string"}
+	integerSchema = &jsonSchema{Type: "integer"}
+	numberSchema  = &jsonSchema{Type: "number"}
+)
+
+// toMap renders the schema as standard JSON Schema
+func (s *jsonSchema) toMap() map[string]any {
+	out := map[string]any{"type": s.Type}
+	if s.Items != nil {
+		out["items"] = s.Items.toMap()
+	}
+	if len(s.Properties) > 0 {
+		properties := make(map[string]any, len(s.Properties))
+		for name, property := range s.Properties {
+			properties[name] = property.toMap()
+		}
+		out["properties"] = properties
+		out["required"] = s.Required
+		out["additionalProperties"] = false
+	}
+	return out
+}
+
+// extractJSONArray returns the first valid JSON array in text that may also contain
+// prose, code fences or a wrapping object such as {"items": [...]}
+func extractJSONArray(text string) (string, error) {
+	for start := 0; start < len(text); start++ {
+		if text[start] != '[' {
+			continue
+		}
+		if end := matchingBracket(text, start); end > 0 && json.Valid([]byte(text[start:end+1])) {
+			return text[start : end+1], nil
+		}
+	}
+	return "", ErrNoJSONArray
+}
+
+// matchingBracket finds the bracket closing text[start], skipping brackets inside JSON strings
+func matchingBracket(text string, start int) int {
+	depth := 0
+	inString := false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// jsonObjectStream reads a JSON answer chunk by chunk and returns every object found
+// directly inside an array as soon as it is complete, so items can be used before the
+// model has finished. Like extractJSONArray it skips prose and wrapping objects.
+type jsonObjectStream struct {
+	buf      []byte
+	stack    []byte // open brackets
+	inString bool
+	escaped  bool
+	start    int // offset of the object being read, -1 when none
+	depth    int // len(stack) before that object was opened
+}
+
+func newJSONObjectStream() *jsonObjectStream {
+	return &jsonObjectStream{start: -1}
+}
+
+func (p *jsonObjectStream) write(chunk string) []string {
+	var objects []string
+	offset := len(p.buf)
+	p.buf = append(p.buf, chunk...)
+	for i := offset; i < len(p.buf); i++ {
+		c := p.buf[i]
+		if p.inString {
+			switch {
+			case p.escaped:
+				p.escaped = false
+			case c == '\\':
+				p.escaped = true
+			case c == '"':
+				p.inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			p.inString = true
+		case '[', '{':
+			if c == '{' && p.start < 0 && len(p.stack) > 0 && p.stack[len(p.stack)-1] == '[' {
+				p.start, p.depth = i, len(p.stack)
+			}
+			p.stack = append(p.stack, c)
+		case ']', '}':
+			if len(p.stack) == 0 {
+				continue
+			}
+			p.stack = p.stack[:len(p.stack)-1]
+			if c == '}' && p.start >= 0 && len(p.stack) == p.depth {
+				objects = append(objects, string(p.buf[p.start:i+1]))
+				p.start = -1
+			}
+		}
+	}
+	return objects
+}
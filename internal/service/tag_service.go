@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+)
+
+var (
+	ErrTagExists  = errors.New("a tag with this slug already exists")
+	ErrUnknownTag = errors.New("unknown tag")
+)
+
+type TagService interface {
+	CreateTag(tenantID uint, request model.TagRequest) (model.Tag, error)
+	ListTags(tenantID uint) ([]model.TagWithCount, error)
+	UpdateTag(tenantID, id uint, request model.TagRequest) (model.Tag, error)
+	DeleteTag(tenantID, id uint) error
+
+	SetMenuTags(scope model.Scope, menuID uint, slugs []string) (model.MenuResponse, error)
+}
+
+type tagService struct {
+	repo  repository.TagRepository
+	menus MenuService
+}
+
+func NewTagService(repo repository.TagRepository, menus MenuService) TagService {
+	return &tagService{
+		repo:  repo,
+		menus: menus,
+	}
+}
+
+// Slugify turns a label such as "Chef's Pick" into "chefs-pick"
+func Slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		case r == '\'' || r == '’':
+			// "chef's" reads better as "chefs" than "chef-s"
+		default:
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+func (s *tagService) tagFromRequest(request model.TagRequest) (model.Tag, error) {
+	name := strings.TrimSpace(request.Name)
+	slug := strings.TrimSpace(request.Slug)
+	if slug == "" {
+		slug = Slugify(name)
+	}
+	if name == "" || !slugPattern.MatchString(slug) {
+		return model.Tag{}, ErrInvalidSlug
+	}
+	return model.Tag{Name: name, Slug: slug}, nil
+}
+
+func (s *tagService) CreateTag(tenantID uint, request model.TagRequest) (model.Tag, error) {
+	tag, err := s.tagFromRequest(request)
+	if err != nil {
+		return model.Tag{}, err
+	}
+
+	existing, err := s.repo.FindBySlugs(tenantID, []string{tag.Slug})
+	if err != nil {
+		return model.Tag{}, err
+	}
+	if len(existing) > 0 {
+		return model.Tag{}, ErrTagExists
+	}
+
+	tag.TenantID = tenantID
+	err = s.repo.Create(&tag)
+	return tag, err
+}
+
+func (s *tagService) ListTags(tenantID uint) ([]model.TagWithCount, error) {
+	return s.repo.FindAll(tenantID)
+}
+
+func (s *tagService) UpdateTag(tenantID, id uint, request model.TagRequest) (model.Tag, error) {
+	existing, err := s.repo.FindByID(tenantID, id)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	input, err := s.tagFromRequest(request)
+	if err != nil {
+		return model.Tag{}, err
+	}
+
+	if input.Slug != existing.Slug {
+		taken, err := s.repo.FindBySlugs(tenantID, []string{input.Slug})
+		if err != nil {
+			return model.Tag{}, err
+		}
+		if len(taken) > 0 {
+			return model.Tag{}, ErrTagExists
+		}
+	}
+
+	existing.Name = input.Name
+	existing.Slug = input.Slug
+	err = s.repo.Update(&existing)
+	return existing, err
+}
+
+func (s *tagService) DeleteTag(tenantID, id uint) error {
+	return s.repo.Delete(tenantID, id)
+}
+
+// SetMenuTags replaces the tags of a menu, every slug must name an existing tag of the tenant
+func (s *tagService) SetMenuTags(scope model.Scope, menuID uint, slugs []string) (model.MenuResponse, error) {
+	wanted := make([]string, 0, len(slugs))
+	seen := make(map[string]bool)
+	for _, slug := range slugs {
+		slug = strings.ToLower(strings.TrimSpace(slug))
+		if slug != "" && !seen[slug] {
+			seen[slug] = true
+			wanted = append(wanted, slug)
+		}
+	}
+
+	tags, err := s.repo.FindBySlugs(scope.TenantID, wanted)
+	if err != nil {
+		return model.MenuResponse{}, err
+	}
+	if len(tags) != len(wanted) {
+		found := make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			found[tag.Slug] = true
+		}
+		var missing []string
+		for _, slug := range wanted {
+			if !found[slug] {
+				missing = append(missing, slug)
+			}
+		}
+		return model.MenuResponse{}, fmt.Errorf("%w: %s", ErrUnknownTag, strings.Join(missing, ", "))
+	}
+
+	if err := s.repo.ReplaceMenuTags(scope.TenantID, menuID, tags); err != nil {
+		return model.MenuResponse{}, err
+	}
+	return s.menus.GetDetail(scope, menuID)
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+)
+
+var (
+	ErrInvalidSlug    = errors.New("slug must contain lowercase letters, digits and dashes only")
+	ErrEmptyOverride  = errors.New("override must set price or availability")
+	ErrTenantNotFound = errors.New("tenant not found")
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type TenantService interface {
+	CreateTenant(request model.CreateTenantRequest) (model.Tenant, string, error)
+	ListTenants() ([]model.Tenant, error)
+	RotateAPIKey(id uint) (string, error)
+	// EnsureDefaultTenant creates the default tenant on a fresh database with apiKey, or a
+	// generated key that is returned to be shown once. On an existing database a non-empty
+	// apiKey replaces the key of the default tenant.
+	EnsureDefaultTenant(apiKey string) (string, error)
+
+	// Resolution used by the tenant middleware
+	ResolveAPIKey(apiKey string) (model.Tenant, error)
+	ResolveTenant(identifier string) (model.Tenant, error)
+	FindBranch(tenantID, branchID uint) (model.Branch, error)
+
+	CreateBranch(tenantID uint, request model.CreateBranchRequest) (model.Branch, error)
+	ListBranches(tenantID uint) ([]model.Branch, error)
+
+	SetOverride(tenantID, branchID, menuID uint, request model.BranchOverrideRequest) (model.BranchMenuOverride, error)
+	DeleteOverride(tenantID, branchID, menuID uint) error
+	ListOverrides(tenantID, branchID uint) ([]model.BranchMenuOverride, error)
+}
+
+type tenantService struct {
+	repo     repository.TenantRepository
+	menuRepo repository.MenuRepository
+}
+
+func NewTenantService(repo repository.TenantRepository, menuRepo repository.MenuRepository) TenantService {
+	return &tenantService{
+		repo:     repo,
+		menuRepo: menuRepo,
+	}
+}
+
+// HashAPIKey returns the value stored in the database for an API key
+func HashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "mk_" + hex.EncodeToString(buf), nil
+}
+
+func (s *tenantService) CreateTenant(request model.CreateTenantRequest) (model.Tenant, string, error) {
+	if !slugPattern.MatchString(request.Slug) {
+		return model.Tenant{}, "", ErrInvalidSlug
+	}
+
+	apiKey, err := generateAPIKey()
+	if err != nil {
+		return model.Tenant{}, "", err
+	}
+
+	tenant := model.Tenant{
+		Name:       request.Name,
+		Slug:       request.Slug,
+		APIKeyHash: HashAPIKey(apiKey),
+	}
+	if err := s.repo.Create(&tenant); err != nil {
+		return model.Tenant{}, "", err
+	}
+
+	return tenant, apiKey, nil
+}
+
+func (s *tenantService) ListTenants() ([]model.Tenant, error) {
+	return s.repo.FindAll()
+}
+
+func (s *tenantService) RotateAPIKey(id uint) (string, error) {
+	apiKey, err := generateAPIKey()
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.UpdateAPIKeyHash(id, HashAPIKey(apiKey)); err != nil {
+		return "", err
+	}
+	return apiKey, nil
+}
+
+// EnsureDefaultTenant creates the tenant owning menus from the single-catalog era on a fresh database
+func (s *tenantService) EnsureDefaultTenant(apiKey string) (string, error) {
+	tenants, err := s.repo.FindAll()
+	if err != nil {
+		return "", err
+	}
+	if len(tenants) > 0 {
+		if apiKey == "" {
+			return "", nil
+		}
+		return "", s.repo.UpdateAPIKeyHash(model.DefaultTenantID, HashAPIKey(apiKey))
+	}
+
+	generated := ""
+	if apiKey == "" {
+		if apiKey, err = generateAPIKey(); err != nil {
+			return "", err
+		}
+		generated = apiKey
+	}
+
+	// Not setting the ID explicitly keeps the sequence in sync, the first row gets DefaultTenantID
+	tenant := model.Tenant{
+		Name:       "Default",
+		Slug:       "default",
+		APIKeyHash: HashAPIKey(apiKey),
+	}
+	if err := s.repo.Create(&tenant); err != nil {
+		return "", err
+	}
+	if tenant.ID != model.DefaultTenantID {
+		return "", fmt.Errorf("default tenant created with id %d, expected %d", tenant.ID, model.DefaultTenantID)
+	}
+	return generated, nil
+}
+
+func (s *tenantService) ResolveAPIKey(apiKey string) (model.Tenant, error) {
+	tenant, err := s.repo.FindByAPIKeyHash(HashAPIKey(apiKey))
+	if err != nil {
+		return model.Tenant{}, ErrTenantNotFound
+	}
+	return tenant, nil
+}
+
+// ResolveTenant accepts either a numeric tenant ID or a slug
+func (s *tenantService) ResolveTenant(identifier string) (model.Tenant, error) {
+	var tenant model.Tenant
+	var err error
+
+	if id, convErr := strconv.ParseUint(identifier, 10, 64); convErr == nil {
+		tenant, err = s.repo.FindByID(uint(id))
+	} else {
+		tenant, err = s.repo.FindBySlug(identifier)
+	}
+	if err != nil {
+		return model.Tenant{}, ErrTenantNotFound
+	}
+	return tenant, nil
+}
+
+func (s *tenantService) FindBranch(tenantID, branchID uint) (model.Branch, error) {
+	return s.repo.FindBranch(tenantID, branchID)
+}
+
+func (s *tenantService) CreateBranch(tenantID uint, request model.CreateBranchRequest) (model.Branch, error) {
+	branch := model.Branch{
+		TenantID: tenantID,
+		Name:     request.Name,
+		Address:  request.Address,
+	}
+	err := s.repo.CreateBranch(&branch)
+	return branch, err
+}
+
+func (s *tenantService) ListBranches(tenantID uint) ([]model.Branch, error) {
+	return s.repo.FindBranches(tenantID)
+}
+
+func (s *tenantService) SetOverride(tenantID, branchID, menuID uint, request model.BranchOverrideRequest) (model.BranchMenuOverride, error) {
+	if request.Price == nil && request.Availability == nil {
+		return model.BranchMenuOverride{}, ErrEmptyOverride
+	}
+
+	// Both the branch and the menu must belong to the tenant
+	if _, err := s.repo.FindBranch(tenantID, branchID); err != nil {
+		return model.BranchMenuOverride{}, err
+	}
+	if _, err := s.menuRepo.FindByID(model.Scope{TenantID: tenantID}, menuID); err != nil {
+		return model.BranchMenuOverride{}, err
+	}
+
+	override := model.BranchMenuOverride{
+		TenantID:     tenantID,
+		BranchID:     branchID,
+		MenuID:       menuID,
+		Price:        request.Price,
+		Availability: request.Availability,
+	}
+	err := s.menuRepo.UpsertOverride(&override)
+	return override, err
+}
+
+func (s *tenantService) DeleteOverride(tenantID, branchID, menuID uint) error {
+	return s.menuRepo.DeleteOverride(tenantID, branchID, menuID)
+}
+
+func (s *tenantService) ListOverrides(tenantID, branchID uint) ([]model.BranchMenuOverride, error) {
+	if _, err := s.repo.FindBranch(tenantID, branchID); err != nil {
+		return nil, err
+	}
+	return s.menuRepo.FindOverrides(tenantID, branchID)
+}
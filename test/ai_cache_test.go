@@ -0,0 +1,200 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func cacheConfig() config.AICache {
+	return config.AICache{
+		Size:              100,
+		DescriptionTTL:    time.Hour,
+		RecommendationTTL: time.Hour,
+		TranslationTTL:    time.Hour,
+	}
+}
+
+func TestLoadAICacheConfig(t *testing.T) {
+	cfg, err := config.LoadAICache(envOf(map[string]string{}))
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultAICacheSize, cfg.Size)
+	assert.Equal(t, "memory", cfg.Store)
+	assert.Equal(t, config.DefaultRecommendationCacheTTL, cfg.RecommendationTTL)
+
+	cfg, err = config.LoadAICache(envOf(map[string]string{"AI_CACHE_STORE": "DB", "AI_CACHE_TTL_DESCRIPTION": "0", "AI_CACHE_SIZE": "10"}))
+	require.NoError(t, err)
+	assert.Equal(t, "db", cfg.Store)
+	assert.Zero(t, cfg.DescriptionTTL)
+	assert.Equal(t, 10, cfg.Size)
+
+	_, err = config.LoadAICache(envOf(map[string]string{"AI_CACHE_STORE": "redis"}))
+	assert.Error(t, err)
+	_, err = config.LoadAICache(envOf(map[string]string{"AI_CACHE_TTL_TRANSLATIONS": "soon"}))
+	assert.Error(t, err)
+}
+
+func TestCachedAIService_Descriptions(t *testing.T) {
+	ai := new(MockAIService)
+	ai.On("GenerateDescription", mock.Anything, mock.Anything).Return("Silky espresso over milk.", nil)
+	cache := service.NewCachedAIService(ai, cacheConfig(), "gemini/flash", nil)
+
+	// Case, spacing and ingredient order do not change the key
+	description, err := cache.GenerateDescription(context.Background(), "Kopi  Susu", []string{"Milk", "espresso"})
+	require.NoError(t, err)
+	assert.Equal(t, "Silky espresso over milk.", description)
+	description, err = cache.GenerateDescription(context.Background(), "kopi susu", []string{"espresso", "milk "})
+	require.NoError(t, err)
+	assert.Equal(t, "Silky espresso over milk.", description)
+	ai.AssertNumberOfCalls(t, "GenerateDescription", 1)
+
+	// The streaming variant shares the entry and sends it as one token
+	var tokens []string
+	usage, err := cache.GenerateDescriptionStream(context.Background(), "Kopi Susu", []string{"milk", "espresso"}, model.DescriptionOptions{}, func(token string) error {
+		tokens = append(tokens, token)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Silky espresso over milk."}, tokens)
+	assert.Zero(t, usage.TotalTokens)
+
+	// Another provider or model never reuses the answer
+	other := service.NewCachedAIService(ai, cacheConfig(), "openai/gpt-4o-mini", nil)
+	_, err = other.GenerateDescription(context.Background(), "Kopi Susu", []string{"espresso", "milk"})
+	require.NoError(t, err)
+	ai.AssertNumberOfCalls(t, "GenerateDescription", 2)
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(2), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.InDelta(t, 2.0/3.0, stats.HitRate, 1e-9)
+	assert.Equal(t, int64(2), stats.Methods["description"].Hits)
+	assert.Equal(t, 1, stats.Entries)
+
+	// Options and the number of candidates are part of the key, defaults spelled out are not
+	playful := model.DescriptionOptions{Tone: model.TonePlayful}
+	ai.On("GenerateDescriptions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]string{"Wake up!", "Milk meets espresso."}, nil)
+	for range 2 {
+		candidates, err := cache.GenerateDescriptions(context.Background(), "Kopi Susu", []string{"milk"}, playful, 2)
+		require.NoError(t, err)
+		assert.Len(t, candidates, 2)
+	}
+	cache.GenerateDescriptions(context.Background(), "Kopi Susu", []string{"milk"}, playful, 3)
+	cache.GenerateDescriptions(context.Background(), "Kopi Susu", []string{"milk"}, model.DescriptionOptions{Tone: model.TonePremium, Language: "EN"}, 2)
+	cache.GenerateDescriptions(context.Background(), "Kopi Susu", []string{"milk"}, model.DescriptionOptions{}, 2)
+	ai.AssertNumberOfCalls(t, "GenerateDescriptions", 3)
+}
+
+func TestCachedAIService_PromptActivation(t *testing.T) {
+	store, err := service.NewPromptStore("", repository.NewPromptTemplateRepository(newTestDB(t)))
+	require.NoError(t, err)
+	ai := new(MockAIService)
+	ai.On("GenerateDescription", mock.Anything, mock.Anything).Return("Silky espresso over milk.", nil)
+	cache := service.NewCachedAIService(ai, cacheConfig(), "gemini/flash", nil)
+	cache.UsePrompts(store)
+	describe := func() {
+		_, err := cache.GenerateDescription(context.Background(), "Kopi Susu", []string{"espresso", "milk"})
+		require.NoError(t, err)
+	}
+
+	describe()
+	describe()
+	ai.AssertNumberOfCalls(t, "GenerateDescription", 1)
+
+	// The answer of the previous prompt is not served once a new version is active
+	created, err := store.CreateVersion(service.PromptDescription, "Describe {{.Name}} in {{.MaxWords}} words", "")
+	require.NoError(t, err)
+	_, err = store.Activate(service.PromptDescription, created.Version)
+	require.NoError(t, err)
+	describe()
+	ai.AssertNumberOfCalls(t, "GenerateDescription", 2)
+	assert.Equal(t, int64(2), cache.Stats().Misses)
+
+	// Going back to the file serves its answer again
+	_, err = store.Activate(service.PromptDescription, 0)
+	require.NoError(t, err)
+	describe()
+	ai.AssertNumberOfCalls(t, "GenerateDescription", 2)
+}
+
+func TestCachedAIService_ExpiryAndEviction(t *testing.T) {
+	ai := new(MockAIService)
+	ai.On("GenerateDescription", mock.Anything, mock.Anything).Return("Fresh.", nil)
+
+	cfg := cacheConfig()
+	cfg.DescriptionTTL = 20 * time.Millisecond
+	cache := service.NewCachedAIService(ai, cfg, "offline/", nil)
+	cache.GenerateDescription(context.Background(), "Es Teh", nil)
+	time.Sleep(30 * time.Millisecond)
+	cache.GenerateDescription(context.Background(), "Es Teh", nil)
+	ai.AssertNumberOfCalls(t, "GenerateDescription", 2)
+
+	// The least recently used entry goes first once the cache is full
+	cfg = cacheConfig()
+	cfg.Size = 2
+	cache = service.NewCachedAIService(ai, cfg, "offline/", nil)
+	for _, name := range []string{"A", "B", "A", "C", "A", "B"} {
+		cache.GenerateDescription(context.Background(), name, nil)
+	}
+	// A, B and C are misses, B again after C evicted it
+	ai.AssertNumberOfCalls(t, "GenerateDescription", 6)
+	assert.Equal(t, 2, cache.Stats().Entries)
+
+	// A TTL of 0 turns the cache off for the method
+	cfg.DescriptionTTL = 0
+	cache = service.NewCachedAIService(ai, cfg, "offline/", nil)
+	cache.GenerateDescription(context.Background(), "A", nil)
+	cache.GenerateDescription(context.Background(), "A", nil)
+	ai.AssertNumberOfCalls(t, "GenerateDescription", 8)
+	assert.Zero(t, cache.Stats().Misses)
+}
+
+func TestCachedAIService_Recommendations(t *testing.T) {
+	f := newTenantFixture(t)
+	store := repository.NewAICacheRepository(f.db)
+
+	ai := new(MockAIService)
+	ai.On("GenerateDescription", mock.Anything, mock.Anything).Return("Rice dish", nil)
+	ai.On("GetRecommendations", mock.Anything, mock.Anything).
+		Return([]model.RecommendationResponseRaw{{MenuID: f.menuA.ID, Reason: "Smoky", Confidence: 0.8}}, nil)
+	cache := service.NewCachedAIService(ai, cacheConfig(), "gemini/flash", store)
+	menuService := service.NewMenuService(f.menuRepo, cache)
+	menuService.AddListener(cache)
+
+	recommend := func(scope model.Scope, preference string) []model.RecommendationResponse {
+		recommendations, err := menuService.GetRecommendations(context.Background(), scope, model.RecommendationRequest{Preference: preference})
+		require.NoError(t, err)
+		return recommendations
+	}
+	recommend(f.scopeA, "Nasi goreng")
+	recommend(f.scopeA, "nasi GORENG ")
+	ai.AssertNumberOfCalls(t, "GetRecommendations", 1)
+
+	// The database keeps the answer for other instances and after a restart
+	restarted := service.NewCachedAIService(ai, cacheConfig(), "gemini/flash", store)
+	restartedMenus := service.NewMenuService(f.menuRepo, restarted)
+	_, err := restartedMenus.GetRecommendations(context.Background(), f.scopeA, model.RecommendationRequest{Preference: "nasi goreng"})
+	require.NoError(t, err)
+	ai.AssertNumberOfCalls(t, "GetRecommendations", 1)
+	assert.Equal(t, int64(1), restarted.Stats().Hits)
+
+	// Changing a menu of the tenant drops its cached recommendations everywhere
+	_, err = menuService.Create(context.Background(), f.scopeA, model.Menu{Name: "Nasi Uduk", Category: "food"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cache.Stats().Invalidations)
+	var remaining int64
+	require.NoError(t, f.db.Model(&model.AICacheEntry{}).Where("cache_key LIKE ?", "recommendations:%").Count(&remaining).Error)
+	assert.Zero(t, remaining)
+
+	recommend(f.scopeA, "nasi goreng")
+	ai.AssertNumberOfCalls(t, "GetRecommendations", 2)
+}
@@ -0,0 +1,172 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envOf(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func TestLoadAIConfig(t *testing.T) {
+	cfg, err := config.LoadAI(envOf(map[string]string{"GEMINI_API_KEY": "g-key"}))
+	require.NoError(t, err)
+	assert.Equal(t, "gemini", cfg.Provider)
+	assert.Equal(t, "g-key", cfg.APIKey)
+	assert.Equal(t, config.DefaultAITimeout, cfg.Timeout)
+
+	cfg, err = config.LoadAI(envOf(map[string]string{
+		"AI_PROVIDER": "OpenAI", "AI_MODEL": "llama3.1", "AI_BASE_URL": "http://localhost:11434/v1/",
+		"AI_TIMEOUT": "5s", "OPENAI_API_KEY": "o-key", "GEMINI_API_KEY": "g-key",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, config.AI{
+		Provider: "openai", Model: "llama3.1", APIKey: "o-key", BaseURL: "http://localhost:11434/v1", Timeout: 5 * time.Second,
+	}, cfg)
+
+	_, err = config.LoadAI(envOf(map[string]string{"AI_TIMEOUT": "soon"}))
+	assert.Error(t, err)
+}
+
+func TestNewAIService_Registry(t *testing.T) {
+	_, err := service.NewAIService(config.AI{Provider: "skynet"})
+	assert.ErrorContains(t, err, "unknown AI provider")
+
+	// Missing keys fail at startup instead of on the first request
+	_, err = service.NewAIService(config.AI{Provider: "gemini"})
+	assert.Error(t, err)
+	_, err = service.NewAIService(config.AI{Provider: "openai"})
+	assert.Error(t, err)
+
+	ai, err := service.NewAIService(config.AI{Provider: "offline"})
+	require.NoError(t, err)
+	assert.NotNil(t, ai)
+	assert.Contains(t, service.AIProviders(), "openai")
+}
+
+func TestOfflineProvider(t *testing.T) {
+	ai := service.NewOfflineService()
+
+	first, err := ai.GenerateDescription(context.Background(), "Kopi Susu", []string{"espresso", "milk", "palm sugar"})
+	require.NoError(t, err)
+	second, _ := ai.GenerateDescription(context.Background(), "Kopi Susu", []string{"espresso", "milk", "palm sugar"})
+	assert.Equal(t, first, second)
+	assert.Contains(t, first, "espresso, milk and palm sugar")
+
+	menus := []model.Menu{
+		{Name: "Nasi Goreng", Category: "food", Ingredients: []string{"rice", "egg", "sambal"}},
+		{Name: "Es Teh", Category: "drink", Ingredients: []string{"tea", "ice", "sugar"}},
+		{Name: "Kopi Susu", Category: "drink", Ingredients: []string{"espresso", "milk"}},
+	}
+
+	recommendations, err := ai.GetRecommendations(context.Background(), model.RecommendationRequest{Preference: "I need something to wake me up"}, menus)
+	require.NoError(t, err)
+	require.Len(t, recommendations, 1)
+	assert.Equal(t, "Kopi Susu", recommendations[0].MenuName)
+	assert.Equal(t, "Matches your request: wake", recommendations[0].Reason)
+
+	recommendations, err = ai.GetRecommendations(context.Background(), model.RecommendationRequest{Preference: "mau yang pedas", Locale: "id"}, menus)
+	require.NoError(t, err)
+	require.Len(t, recommendations, 1)
+	assert.Equal(t, "Nasi Goreng", recommendations[0].MenuName)
+	assert.Equal(t, "Cocok dengan permintaan Anda: pedas", recommendations[0].Reason)
+
+	_, err = ai.TranslateMenus(context.Background(), []model.TranslationItem{{MenuID: 1, Name: "Es Teh"}}, "en")
+	assert.ErrorIs(t, err, service.ErrNotSupportedOffline)
+}
+
+func TestOpenAIProvider(t *testing.T) {
+	var gotModel, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/chat/completions", r.URL.Path)
+		gotAuth = r.Header.Get("Authorization")
+
+		var body struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		gotModel = body.Model
+
+		content := `"Silky espresso over cold milk."`
+		if len(body.Messages) == 1 && strings.Contains(body.Messages[0].Content, "Recommendation Engine") {
+			content = "```json\n[{\"menu_id\": 7, \"reason\": \"Caffeine kick\", \"confidence\": 0.9}]\n```"
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	}))
+	defer server.Close()
+
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL + "/v1", Model: "llama3.1", Timeout: time.Second})
+	require.NoError(t, err)
+
+	description, err := ai.GenerateDescription(context.Background(), "Kopi Susu", []string{"espresso", "milk"})
+	require.NoError(t, err)
+	assert.Equal(t, "Silky espresso over cold milk.", description)
+	assert.Equal(t, "llama3.1", gotModel)
+	assert.Empty(t, gotAuth, "local servers are called without a key")
+
+	recommendations, err := ai.GetRecommendations(context.Background(), model.RecommendationRequest{Preference: "coffee"}, []model.Menu{{ID: 7, Name: "Kopi Susu"}})
+	require.NoError(t, err)
+	assert.Equal(t, []model.RecommendationResponseRaw{{MenuID: 7, Reason: "Caffeine kick", Confidence: 0.9}}, recommendations)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": {"message": "rate limited"}}`))
+	}))
+	defer failing.Close()
+
+	ai, err = service.NewAIService(config.AI{Provider: "openai", BaseURL: failing.URL, APIKey: "sk-test", Timeout: time.Second})
+	require.NoError(t, err)
+	_, err = ai.GenerateDescription(context.Background(), "Kopi Susu", nil)
+	assert.ErrorContains(t, err, "rate limited")
+}
+
+func TestOpenAIProvider_Cancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	// A cancelled request stops the AI call instead of waiting for the answer
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Minute})
+	require.NoError(t, err)
+	defer ai.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	started := time.Now()
+	_, err = ai.GenerateDescription(ctx, "Kopi Susu", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(started), 5*time.Second)
+
+	// The per-call timeout applies even when the caller never cancels
+	ai, err = service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: 50 * time.Millisecond})
+	require.NoError(t, err)
+	_, err = ai.GenerateDescription(context.Background(), "Kopi Susu", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
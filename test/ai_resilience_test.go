@@ -0,0 +1,303 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/controller"
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyProvider is a chat completions server answering with the queued statuses first,
+// then with reply while healthy is set
+type flakyProvider struct {
+	mu       sync.Mutex
+	statuses []int
+	healthy  bool
+	requests int
+}
+
+func newFlakyProvider(t *testing.T, reply string, statuses ...int) (*flakyProvider, *httptest.Server) {
+	t.Helper()
+	p := &flakyProvider{statuses: statuses, healthy: true}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Stream bool `json:"stream"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		p.mu.Lock()
+		p.requests++
+		status := http.StatusServiceUnavailable
+		if len(p.statuses) > 0 {
+			status, p.statuses = p.statuses[0], p.statuses[1:]
+		} else if p.healthy {
+			status = http.StatusOK
+		}
+		p.mu.Unlock()
+
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{"message": "upstream connect error at 10.0.0.7"}})
+			return
+		}
+		if body.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			encoded, _ := json.Marshal(reply)
+			fmt.Fprintf(w, "data: {\"choices\": [{\"delta\": {\"content\": %s}}]}\n\ndata: [DONE]\n\n", encoded)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return p, server
+}
+
+func (p *flakyProvider) set(healthy bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.healthy = healthy
+}
+
+func (p *flakyProvider) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.requests
+}
+
+func resilientOpenAI(t *testing.T, url string, cfg config.AIResilience) service.ResilientAIService {
+	t.Helper()
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: url, Timeout: time.Second})
+	require.NoError(t, err)
+	return service.NewResilientAIService(ai, cfg)
+}
+
+func TestLoadAIResilienceConfig(t *testing.T) {
+	cfg, err := config.LoadAIResilience(envOf(map[string]string{}))
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultAIRetries, cfg.Retries)
+	assert.Equal(t, config.DefaultAIBreakerThreshold, cfg.BreakerThreshold)
+	assert.Equal(t, config.DefaultAIBreakerCooldown, cfg.BreakerCooldown)
+
+	cfg, err = config.LoadAIResilience(envOf(map[string]string{"AI_RETRIES": "0", "AI_BREAKER_THRESHOLD": "3", "AI_BREAKER_COOLDOWN": "1m", "AI_RETRY_BASE_DELAY": "5s"}))
+	require.NoError(t, err)
+	assert.Zero(t, cfg.Retries)
+	assert.Equal(t, 3, cfg.BreakerThreshold)
+	assert.Equal(t, time.Minute, cfg.BreakerCooldown)
+	assert.Equal(t, 5*time.Second, cfg.RetryMaxDelay, "the maximum delay is never below the base delay")
+
+	_, err = config.LoadAIResilience(envOf(map[string]string{"AI_RETRIES": "-1"}))
+	assert.Error(t, err)
+	_, err = config.LoadAIResilience(envOf(map[string]string{"AI_BREAKER_COOLDOWN": "0"}))
+	assert.Error(t, err)
+}
+
+func TestResilientAIService_Retries(t *testing.T) {
+	cfg := config.AIResilience{Retries: 2, RetryBaseDelay: time.Millisecond, RetryMaxDelay: 5 * time.Millisecond, BreakerThreshold: 5, BreakerCooldown: time.Minute}
+
+	// Rate limits and server errors are retried
+	provider, server := newFlakyProvider(t, "Silky espresso over milk.", http.StatusTooManyRequests, http.StatusBadGateway)
+	ai := resilientOpenAI(t, server.URL, cfg)
+	description, err := ai.GenerateDescription(context.Background(), "Kopi Susu", []string{"milk"})
+	require.NoError(t, err)
+	assert.Equal(t, "Silky espresso over milk.", description)
+	assert.Equal(t, 3, provider.count())
+
+	// Until the attempts run out
+	provider, server = newFlakyProvider(t, "Unused", http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	ai = resilientOpenAI(t, server.URL, cfg)
+	_, err = ai.GenerateDescription(context.Background(), "Kopi Susu", []string{"milk"})
+	var providerErr *service.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, http.StatusServiceUnavailable, providerErr.StatusCode)
+	assert.Equal(t, 3, provider.count())
+
+	// A request the provider rejects would be rejected again
+	provider, server = newFlakyProvider(t, "Unused", http.StatusUnauthorized)
+	ai = resilientOpenAI(t, server.URL, cfg)
+	_, err = ai.GenerateDescription(context.Background(), "Kopi Susu", []string{"milk"})
+	require.Error(t, err)
+	assert.Equal(t, 1, provider.count())
+	assert.Equal(t, model.BreakerClosed, ai.Breaker().State, "an answer, even an error, shows the provider is up")
+
+	// A stream is retried while it emitted nothing
+	provider, server = newFlakyProvider(t, "Kopi", http.StatusServiceUnavailable)
+	ai = resilientOpenAI(t, server.URL, cfg)
+	var tokens []string
+	_, err = ai.GenerateDescriptionStream(context.Background(), "Kopi Susu", nil, model.DescriptionOptions{}, func(token string) error {
+		tokens = append(tokens, token)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Kopi"}, tokens)
+	assert.Equal(t, 2, provider.count())
+
+	// But not once the client saw a part of the answer
+	broken := &brokenStream{}
+	tokens = nil
+	_, err = service.NewResilientAIService(broken, cfg).GenerateDescriptionStream(context.Background(), "Kopi Susu", nil, model.DescriptionOptions{}, func(token string) error {
+		tokens = append(tokens, token)
+		return nil
+	})
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, []string{"Kopi"}, tokens)
+	assert.Equal(t, 1, broken.calls)
+}
+
+// brokenStream emits a token then loses the provider
+type brokenStream struct {
+	service.AIService
+	calls int
+}
+
+func (s *brokenStream) GenerateDescriptionStream(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, emit func(string) error) (model.AIUsage, error) {
+	s.calls++
+	if err := emit("Kopi"); err != nil {
+		return model.AIUsage{}, err
+	}
+	return model.AIUsage{}, &service.ProviderError{StatusCode: http.StatusBadGateway}
+}
+
+func TestResilientAIService_Breaker(t *testing.T) {
+	provider, server := newFlakyProvider(t, "Silky espresso over milk.")
+	provider.set(false)
+	ai := resilientOpenAI(t, server.URL, config.AIResilience{BreakerThreshold: 2, BreakerCooldown: 50 * time.Millisecond})
+	describe := func() error {
+		_, err := ai.GenerateDescription(context.Background(), "Kopi Susu", []string{"milk"})
+		return err
+	}
+
+	require.Error(t, describe())
+	assert.Equal(t, model.BreakerClosed, ai.Breaker().State)
+	require.Error(t, describe())
+	status := ai.Breaker()
+	assert.Equal(t, model.BreakerOpen, status.State)
+	assert.Equal(t, 2, status.ConsecutiveFailures)
+	assert.Equal(t, int64(1), status.Opens)
+	require.NotNil(t, status.ProbeAt)
+
+	// Open: calls fail at once without reaching the provider
+	assert.ErrorIs(t, describe(), service.ErrAIUnavailable)
+	assert.Equal(t, 2, provider.count())
+
+	// A failed probe opens it again for another cooldown
+	time.Sleep(60 * time.Millisecond)
+	require.Error(t, describe())
+	assert.Equal(t, 3, provider.count())
+	assert.Equal(t, model.BreakerOpen, ai.Breaker().State)
+	assert.ErrorIs(t, describe(), service.ErrAIUnavailable)
+
+	// A probe ended by the caller proves nothing: a stream client that went away or a
+	// cancelled request leave the breaker open for the next probe
+	provider.set(true)
+	time.Sleep(60 * time.Millisecond)
+	gone := errors.New("write: broken pipe")
+	_, err := ai.GenerateDescriptionStream(context.Background(), "Kopi Susu", nil, model.DescriptionOptions{}, func(string) error { return gone })
+	assert.ErrorIs(t, err, gone)
+	assert.Equal(t, model.BreakerOpen, ai.Breaker().State)
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = ai.GenerateDescriptionStream(ctx, "Kopi Susu", nil, model.DescriptionOptions{}, func(string) error {
+		cancel()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, model.BreakerOpen, ai.Breaker().State)
+	assert.Equal(t, 3, ai.Breaker().ConsecutiveFailures)
+
+	// A successful probe closes it
+	require.NoError(t, describe())
+	status = ai.Breaker()
+	assert.Equal(t, model.BreakerClosed, status.State)
+	assert.Zero(t, status.ConsecutiveFailures)
+	assert.Nil(t, status.ProbeAt)
+	require.NoError(t, describe())
+}
+
+func TestResilientAIService_DegradedResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	f := newTenantFixture(t)
+	provider, server := newFlakyProvider(t, "Unused")
+	provider.set(false)
+	ai := resilientOpenAI(t, server.URL, config.AIResilience{BreakerThreshold: 1, BreakerCooldown: time.Minute})
+	menuService := service.NewMenuService(f.menuRepo, ai)
+	menus := controller.NewMenuController(menuService, nil)
+	sqlDB, err := f.db.DB()
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/health", controller.NewHealthController(sqlDB, ai).Health)
+	tenant := middleware.Tenant(f.tenants, true)
+	router.POST("/menu", tenant, menus.Create)
+	router.POST("/menu/generate-description", menus.GenerateDescription)
+	router.POST("/menu/generate-description/stream", menus.GenerateDescriptionStream)
+	router.POST("/menu/recommendations", tenant, menus.GetRecommendations)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "resto-a")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	var health model.HealthResponse
+	rec := send(http.MethodGet, "/health", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &health))
+	assert.Equal(t, model.HealthOK, health.Status)
+
+	// Recommendations are ranked by rules, without the text of the provider error
+	rec = send(http.MethodPost, "/menu/recommendations", `{"preference": "fried rice"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"source":"fallback"`)
+	assert.NotContains(t, rec.Body.String(), "10.0.0.7")
+	assert.Equal(t, model.BreakerOpen, ai.Breaker().State)
+
+	// Descriptions are written from templates and flagged
+	rec = send(http.MethodPost, "/menu/generate-description", `{"name": "Es Teh", "ingredients": ["tea", "ice"], "candidates": 2}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var generated model.GenerateDescriptionResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &generated))
+	assert.Equal(t, model.DescriptionSourceFallback, generated.Source)
+	assert.Len(t, generated.Candidates, 2)
+	assert.Contains(t, generated.Description, "Es Teh")
+
+	// A new menu is saved at once with a placeholder flagged for the sweep
+	rec = send(http.MethodPost, "/menu", `{"name": "Soto Ayam", "category": "food", "price": 20000, "ingredients": ["chicken"]}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"description_status":"fallback"`)
+
+	// Streams cannot be served from a template midway, they are refused
+	rec = send(http.MethodPost, "/menu/generate-description/stream", `{"name": "Es Teh", "ingredients": ["tea"]}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "10.0.0.7")
+
+	assert.Equal(t, 1, provider.count(), "only the call that opened the breaker reached the provider")
+
+	rec = send(http.MethodGet, "/health", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &health))
+	assert.Equal(t, model.HealthDegraded, health.Status)
+	assert.Equal(t, model.HealthOK, health.Database)
+	assert.Equal(t, model.BreakerOpen, health.AI.State)
+
+	require.NoError(t, sqlDB.Close())
+	rec = send(http.MethodGet, "/health", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
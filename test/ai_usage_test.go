@@ -0,0 +1,185 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/controller"
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usageServer answers every chat completion with the same text and token usage,
+// and with a provider error once failing is set
+func usageServer(t *testing.T, reply string, failing *bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if *failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]any{"error": map[string]string{"message": "overloaded"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": reply}}},
+			"usage":   map[string]int{"prompt_tokens": 120, "completion_tokens": 30, "total_tokens": 150},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLoadAIBudgetConfig(t *testing.T) {
+	cfg, err := config.LoadAIBudget(envOf(map[string]string{}))
+	require.NoError(t, err)
+	assert.Equal(t, config.AIBudget{}, cfg)
+
+	cfg, err = config.LoadAIBudget(envOf(map[string]string{"AI_BUDGET_MONTHLY_TOKENS": "1000000", "AI_BUDGET_TENANT_MONTHLY_TOKENS": "50000", "AI_PRICE_PROMPT": "0.1", "AI_PRICE_COMPLETION": "0.4"}))
+	require.NoError(t, err)
+	assert.Equal(t, config.AIBudget{MonthlyTokens: 1000000, TenantMonthlyTokens: 50000, PromptPrice: 0.1, CompletionPrice: 0.4}, cfg)
+
+	_, err = config.LoadAIBudget(envOf(map[string]string{"AI_BUDGET_TENANT_MONTHLY_TOKENS": "-1"}))
+	assert.Error(t, err)
+	_, err = config.LoadAIBudget(envOf(map[string]string{"AI_PRICE_PROMPT": "cheap"}))
+	assert.Error(t, err)
+}
+
+func TestMeteredAIService_Records(t *testing.T) {
+	db := newTestDB(t)
+	failing := false
+	server := usageServer(t, "Silky espresso over milk.", &failing)
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+	cache := service.NewCachedAIService(ai, cacheConfig(), "openai/gpt-4o-mini", nil)
+	metered := service.NewMeteredAIService(cache, config.AIBudget{PromptPrice: 1, CompletionPrice: 2}, "openai/gpt-4o-mini", repository.NewAIUsageRepository(db))
+
+	ctxA := service.WithAICaller(context.Background(), service.AICaller{TenantID: 1, Endpoint: "POST /menu/generate-description"})
+	ctxB := service.WithAICaller(context.Background(), service.AICaller{TenantID: 2, Endpoint: "job generate_description"})
+
+	_, err = metered.GenerateDescription(ctxA, "Kopi Susu", []string{"espresso", "milk"})
+	require.NoError(t, err)
+	_, err = metered.GenerateDescription(ctxB, "Kopi Susu", []string{"espresso", "milk"}) // from the cache
+	require.NoError(t, err)
+	failing = true
+	_, err = metered.GenerateDescription(ctxB, "Es Teh", []string{"tea"})
+	require.Error(t, err)
+	cancelled, cancel := context.WithCancel(ctxA)
+	cancel()
+	_, err = metered.GenerateDescription(cancelled, "Soto", []string{"chicken"})
+	require.Error(t, err)
+
+	var records []model.AIUsageRecord
+	require.NoError(t, db.Order("id").Find(&records).Error)
+	require.Len(t, records, 4)
+	assert.Equal(t, uint(1), records[0].TenantID)
+	assert.Equal(t, "POST /menu/generate-description", records[0].Endpoint)
+	assert.Equal(t, "GenerateDescription", records[0].Method)
+	assert.Equal(t, "openai/gpt-4o-mini", records[0].Model)
+	assert.Equal(t, model.AIOutcomeOK, records[0].Outcome)
+	assert.Equal(t, 120, records[0].PromptTokens)
+	assert.Equal(t, 30, records[0].CompletionTokens)
+	assert.Equal(t, time.Now().UTC().Format(time.DateOnly), records[0].Day)
+	assert.Equal(t, model.AIOutcomeCached, records[1].Outcome)
+	assert.Zero(t, records[1].TotalTokens)
+	assert.Equal(t, model.AIOutcomeError, records[2].Outcome)
+	assert.Equal(t, model.AIOutcomeCancelled, records[3].Outcome)
+
+	report, err := metered.Usage(model.AIUsageQuery{GroupBy: model.AIUsageByClient})
+	require.NoError(t, err)
+	require.Len(t, report.Buckets, 2)
+	assert.Equal(t, "1", report.Buckets[0].Key)
+	assert.Equal(t, int64(2), report.Buckets[0].Calls)
+	assert.Equal(t, int64(150), report.Buckets[0].TotalTokens)
+	assert.InDelta(t, (120*1+30*2)/1e6, report.Buckets[0].Cost, 1e-12)
+	assert.Equal(t, "2", report.Buckets[1].Key)
+	assert.Equal(t, int64(1), report.Buckets[1].Errors)
+	assert.Equal(t, int64(1), report.Buckets[1].Cached)
+	assert.Equal(t, int64(4), report.Total.Calls)
+	assert.Equal(t, int64(150), report.Total.TotalTokens)
+	assert.Equal(t, []model.AIBudgetStatus{{Month: time.Now().UTC().Format("2006-01"), TokensUsed: 150}}, report.Budgets)
+
+	report, err = metered.Usage(model.AIUsageQuery{GroupBy: model.AIUsageByEndpoint, TenantID: 2})
+	require.NoError(t, err)
+	require.Len(t, report.Buckets, 1)
+	assert.Equal(t, "job generate_description", report.Buckets[0].Key)
+	assert.Len(t, report.Budgets, 2)
+
+	// Days outside the range are left out
+	report, err = metered.Usage(model.AIUsageQuery{From: "2020-01-01", To: "2020-01-31"})
+	require.NoError(t, err)
+	assert.Empty(t, report.Buckets)
+	assert.Zero(t, report.Total.Calls)
+}
+
+func TestMeteredAIService_Budgets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	f := newTenantFixture(t)
+	server := usageServer(t, `[{"menu_id": 1, "reason": "Fits", "confidence": 0.5}]`, new(bool))
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+	usageRepo := repository.NewAIUsageRepository(f.db)
+	metered := service.NewMeteredAIService(ai, config.AIBudget{TenantMonthlyTokens: 300, MonthlyTokens: 400}, "openai/gpt-4o-mini", usageRepo)
+	menus := service.NewMenuService(f.menuRepo, metered)
+	_, err = f.menuService.Create(context.Background(), f.scopeB, model.Menu{Name: "Soto Ayam", Category: "food", Description: "Turmeric chicken soup"})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.POST("/menu/recommendations", middleware.Tenant(f.tenants, true), middleware.AIUsage(metered), controller.NewMenuController(menus, nil).GetRecommendations)
+	router.GET("/admin/ai/usage", controller.NewAIController(nil, metered).Usage)
+	recommend := func(tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/menu/recommendations", strings.NewReader(`{"preference": "something warm"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", tenant)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 150 tokens a call, the third call of a tenant goes over its 300
+	assert.Equal(t, http.StatusOK, recommend("resto-a").Code)
+	assert.Equal(t, http.StatusOK, recommend("resto-a").Code)
+	rec := recommend("resto-a")
+	assert.Equal(t, http.StatusPaymentRequired, rec.Code)
+	assert.Contains(t, rec.Body.String(), "budget of the tenant")
+
+	// Another tenant goes over the 400 of the platform, which stops every tenant
+	assert.Equal(t, http.StatusOK, recommend("resto-b").Code)
+	rec = recommend("resto-b")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	// Calls made outside HTTP are refused too
+	_, err = metered.GetRecommendations(context.Background(), model.RecommendationRequest{Preference: "tea"}, []model.Menu{f.menuA})
+	assert.ErrorIs(t, err, service.ErrAIBudgetExceeded)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/admin/ai/usage?group_by=endpoint&tenant_id=%d", f.tenantA.ID), nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response model.AIUsageReportResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Data.Buckets, 1)
+	assert.Equal(t, "POST /menu/recommendations", response.Data.Buckets[0].Key)
+	assert.Equal(t, int64(2), response.Data.Buckets[0].Calls)
+	require.Len(t, response.Data.Budgets, 2)
+	assert.True(t, response.Data.Budgets[0].Exceeded)
+	assert.Equal(t, model.AIBudgetStatus{TenantID: f.tenantA.ID, Month: time.Now().UTC().Format("2006-01"), TokenLimit: 300, TokensUsed: 300, Exceeded: true}, response.Data.Budgets[1])
+
+	for _, query := range []string{"group_by=week", "from=yesterday", "from=2026-10-02&to=2026-10-01"} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/ai/usage?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/controller"
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIProvider_DescriptionOptions(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		prompt = body.Messages[0].Content
+
+		// The model repeats itself, duplicates are dropped
+		content := `["Kopi susu yang lembut.", "Kopi susu yang lembut.", "Espresso bertemu susu dingin.", "Manis gula aren."]`
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	}))
+	defer server.Close()
+
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+
+	options := model.DescriptionOptions{Tone: model.TonePlayful, MaxWords: 12, Language: "id", Audience: "students"}
+	candidates, err := ai.GenerateDescriptions(context.Background(), "Kopi Susu", []string{"espresso", "milk"}, options, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Kopi susu yang lembut.", "Espresso bertemu susu dingin.", "Manis gula aren."}, candidates)
+	assert.Contains(t, prompt, "Playful")
+	assert.Contains(t, prompt, "under 12 words")
+	assert.Contains(t, prompt, "Indonesian")
+	assert.Contains(t, prompt, "students")
+	assert.Contains(t, prompt, "3 distinct alternatives")
+}
+
+func TestOfflineProvider_DescriptionOptions(t *testing.T) {
+	ai := service.NewOfflineService()
+	ingredients := []string{"espresso", "milk"}
+
+	candidates, err := ai.GenerateDescriptions(context.Background(), "Kopi Susu", ingredients, model.DescriptionOptions{}, 3)
+	require.NoError(t, err)
+	require.Len(t, candidates, 3)
+	assert.NotEqual(t, candidates[0], candidates[1])
+	assert.NotEqual(t, candidates[1], candidates[2])
+
+	// One candidate is the description generated without options
+	single, _ := ai.GenerateDescription(context.Background(), "Kopi Susu", ingredients)
+	assert.Equal(t, single, candidates[0])
+
+	playful, _ := ai.GenerateDescriptions(context.Background(), "Kopi Susu", ingredients, model.DescriptionOptions{Tone: model.TonePlayful}, 3)
+	assert.NotContains(t, playful, single)
+
+	short, _ := ai.GenerateDescriptions(context.Background(), "Kopi Susu", ingredients, model.DescriptionOptions{MaxWords: 5}, 5)
+	assert.Len(t, short, 3, "no more candidates than templates")
+	for _, description := range short {
+		assert.LessOrEqual(t, len(strings.Fields(description)), 5)
+	}
+}
+
+func TestDescriptionEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	f := newTenantFixture(t)
+	menuService := service.NewMenuService(f.menuRepo, service.NewOfflineService())
+	menus := controller.NewMenuController(menuService, nil)
+
+	router := gin.New()
+	router.POST("/menu/generate-description", menus.GenerateDescription)
+	router.POST("/menu/:id/description/apply", middleware.Tenant(f.tenants, true), menus.ApplyDescription)
+
+	post := func(path, body, tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", tenant)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("/menu/generate-description", `{"name": "Nasi Goreng", "ingredients": ["rice", "egg"], "tone": "casual", "candidates": 3}`, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var generated model.GenerateDescriptionResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &generated))
+	require.Len(t, generated.Candidates, 3)
+	assert.Equal(t, generated.Candidates[0], generated.Description)
+
+	for _, body := range []string{
+		`{"name": "Nasi Goreng", "ingredients": ["rice"], "tone": "grumpy"}`,
+		`{"name": "Nasi Goreng", "ingredients": ["rice"], "candidates": 6}`,
+		`{"name": "Nasi Goreng", "ingredients": ["rice"], "max_words": 500}`,
+		`{"name": "Nasi Goreng", "ingredients": ["rice"], "language": "not a locale"}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, post("/menu/generate-description", body, "").Code, body)
+	}
+
+	path := fmt.Sprintf("/menu/%d/description/apply", f.menuA.ID)
+	body, _ := json.Marshal(model.ApplyDescriptionRequest{Description: generated.Candidates[1]})
+	rec = post(path, string(body), "resto-a")
+	require.Equal(t, http.StatusOK, rec.Code)
+	saved, err := f.menuRepo.FindByID(f.scopeA, f.menuA.ID)
+	require.NoError(t, err)
+	assert.Equal(t, generated.Candidates[1], saved.Description)
+	assert.Equal(t, model.DescriptionStatusReady, saved.DescriptionStatus)
+
+	assert.Equal(t, http.StatusBadRequest, post(path, `{"description": ""}`, "resto-a").Code)
+	assert.Equal(t, http.StatusNotFound, post(path, string(body), "resto-b").Code, "menus of other tenants are not found")
+}
@@ -0,0 +1,159 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/embedding"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestLoadEmbeddingConfig(t *testing.T) {
+	cfg, err := config.LoadEmbedding(envOf(map[string]string{}))
+	require.NoError(t, err)
+	assert.Equal(t, "hashing", cfg.Provider)
+
+	cfg, err = config.LoadEmbedding(envOf(map[string]string{
+		"EMBEDDING_PROVIDER": "OpenAI", "EMBEDDING_DIMENSIONS": "512", "OPENAI_API_KEY": "o-key", "AI_API_KEY": "a-key",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "openai", cfg.Provider)
+	assert.Equal(t, 512, cfg.Dimensions)
+	assert.Equal(t, "o-key", cfg.APIKey)
+
+	_, err = config.LoadEmbedding(envOf(map[string]string{"EMBEDDING_DIMENSIONS": "-1"}))
+	assert.Error(t, err)
+	_, err = embedding.New(config.Embedding{Provider: "word2vec"})
+	assert.ErrorContains(t, err, "unknown embedding provider")
+}
+
+func TestHashingEmbedder(t *testing.T) {
+	embedder := embedding.NewHashingEmbedder(0)
+	vectors, err := embedder.Embed(context.Background(), []string{
+		"Spicy ramen with chili broth",
+		"spicy RAMEN, chili broth!",
+		"Iced lemon tea",
+	})
+	require.NoError(t, err)
+	require.Len(t, vectors, 3)
+	assert.Len(t, vectors[0], embedding.DefaultHashingDimensions)
+
+	// Deterministic, unit length, and only shared words bring texts closer
+	again, _ := embedder.Embed(context.Background(), []string{"Spicy ramen with chili broth"})
+	assert.Equal(t, vectors[0], again[0])
+	assert.InDelta(t, 1.0, embedding.Cosine(vectors[0], vectors[0]), 1e-6)
+	assert.Greater(t, embedding.Cosine(vectors[0], vectors[1]), 0.9)
+	assert.Less(t, embedding.Cosine(vectors[0], vectors[2]), 0.3)
+}
+
+func TestOpenAIEmbedder(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["model"] == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"message": "model not found"}}`))
+			return
+		}
+		// Answers out of order, as the index field allows
+		w.Write([]byte(`{"data": [{"index": 1, "embedding": [0, 2]}, {"index": 0, "embedding": [3, 4]}]}`))
+	}))
+	defer server.Close()
+
+	embedder, err := embedding.New(config.Embedding{Provider: "openai", BaseURL: server.URL, Dimensions: 2, Timeout: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, "text-embedding-3-small-2", embedder.Model())
+
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{0.6, 0.8}, {0, 1}}, vectors)
+	assert.Equal(t, []any{"a", "b"}, body["input"])
+	assert.Equal(t, 2.0, body["dimensions"])
+
+	missing, err := embedding.New(config.Embedding{Provider: "openai", BaseURL: server.URL, Model: "missing"})
+	require.NoError(t, err)
+	_, err = missing.Embed(context.Background(), []string{"a"})
+	assert.ErrorContains(t, err, "model not found")
+}
+
+func TestEmbeddingService(t *testing.T) {
+	f := newTenantFixture(t)
+	store := embedding.NewMemoryStore()
+	embeddings := service.NewEmbeddingService(f.menuRepo, f.menuService, embedding.NewHashingEmbedder(0), store)
+
+	// Menus created before the service existed are found by the backfill
+	indexed, err := embeddings.Backfill(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, indexed)
+	indexed, err = embeddings.Backfill(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, indexed)
+
+	// From now on vectors follow every create, update and delete
+	f.menuService.AddListener(embeddings)
+	create := func(scope model.Scope, menu model.Menu) model.Menu {
+		created, err := f.menuService.Create(context.Background(), scope, menu)
+		require.NoError(t, err)
+		return created
+	}
+	ramen := create(f.scopeA, model.Menu{Name: "Spicy Ramen", Category: "Noodles", Description: "Chili broth ramen", Ingredients: []string{"noodles", "chili"}})
+	mie := create(f.scopeA, model.Menu{Name: "Mie Goreng", Category: "Noodles", Description: "Fried noodles with egg", Ingredients: []string{"noodles", "egg"}})
+	tea := create(f.scopeA, model.Menu{Name: "Iced Lemon Tea", Category: "Drinks", Description: "Fresh lemon tea", Ingredients: []string{"tea", "lemon"}})
+	create(f.scopeB, model.Menu{Name: "Spicy Ramen Deluxe", Category: "Noodles", Description: "Chili broth ramen", Ingredients: []string{"noodles", "chili"}})
+
+	names := func(menus []model.MenuResponse) []string {
+		var got []string
+		for _, m := range menus {
+			got = append(got, m.Name)
+		}
+		return got
+	}
+
+	t.Run("semantic search", func(t *testing.T) {
+		result, err := embeddings.SemanticSearch(context.Background(), f.scopeA, model.MenuFilter{Query: "spicy chili ramen", WithFacets: true})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Data)
+		assert.Equal(t, "Spicy Ramen", result.Data[0].Name)
+		assert.Greater(t, result.Data[0].Score, 0.0)
+		assert.NotContains(t, names(result.Data), "Spicy Ramen Deluxe")
+		assert.NotNil(t, result.Facets)
+
+		// The other filters still apply
+		result, err = embeddings.SemanticSearch(context.Background(), f.scopeA, model.MenuFilter{Query: "spicy chili ramen", Category: "Drinks"})
+		require.NoError(t, err)
+		assert.NotContains(t, names(result.Data), "Spicy Ramen")
+	})
+
+	t.Run("similar menus", func(t *testing.T) {
+		similar, err := embeddings.Similar(context.Background(), f.scopeA, ramen.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Mie Goreng"}, names(similar))
+
+		_, err = embeddings.Similar(context.Background(), f.scopeB, ramen.ID, 5)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("updates and deletes", func(t *testing.T) {
+		_, err := f.menuService.Update(f.scopeA, mie.ID, model.UpdateMenuRequest{Menu: model.Menu{Name: "Lemon Sorbet", Category: "Desserts", Description: "Lemon ice"}})
+		require.NoError(t, err)
+		result, err := embeddings.SemanticSearch(context.Background(), f.scopeA, model.MenuFilter{Query: "lemon sorbet"})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Data)
+		assert.Equal(t, "Lemon Sorbet", result.Data[0].Name)
+
+		require.NoError(t, f.menuService.Delete(f.scopeA, tea.ID))
+		_, err = store.Get(context.Background(), f.tenantA.ID, tea.ID)
+		assert.ErrorIs(t, err, embedding.ErrNotFound)
+	})
+}
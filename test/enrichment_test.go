@@ -0,0 +1,220 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/controller"
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEnrichmentService(f tenantFixture, ai service.AIService) service.EnrichmentService {
+	return service.NewEnrichmentService(ai, f.menuRepo, repository.NewTagRepository(f.db), repository.NewEnrichmentProposalRepository(f.db), f.menuService)
+}
+
+func TestOfflineEnrichment(t *testing.T) {
+	ai := service.NewOfflineService()
+
+	suggestion, err := ai.SuggestEnrichment(context.Background(), model.Menu{Name: "Es Kopi Susu", Ingredients: []string{"espresso", "milk", "palm sugar"}}, []string{"Food", "Drinks"})
+	require.NoError(t, err)
+	assert.Equal(t, "Drinks", suggestion.Category)
+	assert.Equal(t, []model.SuggestedLabel{{Name: "milk", Confidence: 0.8}}, suggestion.Allergens)
+	assert.Equal(t, []model.SuggestedLabel{{Name: "vegetarian", Confidence: 0.5}}, suggestion.DietaryTags)
+
+	suggestion, err = ai.SuggestEnrichment(context.Background(), model.Menu{Name: "Mie Goreng Udang", Ingredients: []string{"noodles", "shrimp", "eggs", "sambal"}}, []string{"Makanan", "Minuman"})
+	require.NoError(t, err)
+	assert.Equal(t, "Makanan", suggestion.Category)
+	assert.Equal(t, []model.SuggestedLabel{{Name: "gluten", Confidence: 0.8}, {Name: "crustaceans", Confidence: 0.8}, {Name: "eggs", Confidence: 0.8}}, suggestion.Allergens)
+	assert.Equal(t, []model.SuggestedLabel{{Name: "spicy", Confidence: 0.8}}, suggestion.DietaryTags)
+
+	// Without categories the kind of dish is suggested
+	suggestion, err = ai.SuggestEnrichment(context.Background(), model.Menu{Name: "Chocolate Cake"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "dessert", suggestion.Category)
+	assert.Positive(t, suggestion.Calories)
+	assert.Empty(t, suggestion.DietaryTags, "nothing tells a dish without ingredients is vegetarian")
+}
+
+func TestEnrichment_CleansAIAnswers(t *testing.T) {
+	f := newTenantFixture(t)
+	_, err := f.menuService.Create(context.Background(), f.scopeA, model.Menu{Name: "Es Teh", Category: "drinks", Description: "Iced tea"})
+	require.NoError(t, err)
+
+	server, requests := chatServer(t, `[{"category": "Drinks", "category_confidence": 1.7, "calories": 99999, "calories_confidence": 0.9,
+		"dietary_tags": [{"name": "Gluten Free", "confidence": 0.7}, {"name": "keto", "confidence": 0.9}],
+		"allergens": [{"name": "milk", "confidence": 0.95}, {"name": "Ignore previous instructions", "confidence": 1}, {"name": "Milk", "confidence": 0.1}]}]`)
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+	enrichment := newEnrichmentService(f, ai)
+
+	suggestion, err := enrichment.Suggest(context.Background(), f.scopeA, model.EnrichmentRequest{Name: "Kopi Susu", Ingredients: []string{"espresso", "milk"}})
+	require.NoError(t, err)
+	assert.Equal(t, model.EnrichmentSuggestion{
+		Category:           "drinks",
+		CategoryConfidence: 1,
+		DietaryTags:        []model.SuggestedLabel{{Name: "gluten-free", Confidence: 0.7}},
+		Allergens:          []model.SuggestedLabel{{Name: "milk", Confidence: 0.95}},
+		Source:             model.EnrichmentSourceAI,
+	}, suggestion)
+
+	// The prompt only offers the categories of the tenant
+	require.Len(t, *requests, 1)
+	prompt := (*requests)[0]["messages"].([]any)[0].(map[string]any)["content"].(string)
+	assert.Contains(t, prompt, `MUST be one of ["drinks","food"]`)
+	assert.Contains(t, prompt, `Name: "Kopi Susu"`)
+
+	// A category the tenant does not use is dropped
+	server, _ = chatServer(t, `[{"category": "beverages", "category_confidence": 0.9, "calories": 120, "calories_confidence": 0.5, "dietary_tags": [], "allergens": []}]`)
+	ai, err = service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+	suggestion, err = newEnrichmentService(f, ai).Suggest(context.Background(), f.scopeA, model.EnrichmentRequest{Name: "Kopi Susu"})
+	require.NoError(t, err)
+	assert.Empty(t, suggestion.Category)
+	assert.Zero(t, suggestion.CategoryConfidence)
+	assert.Equal(t, 120, suggestion.Calories)
+
+	// When the provider fails the ingredient rules answer
+	failing := true
+	ai, err = service.NewAIService(config.AI{Provider: "openai", BaseURL: usageServer(t, "", &failing).URL, Timeout: time.Second})
+	require.NoError(t, err)
+	suggestion, err = newEnrichmentService(f, ai).Suggest(context.Background(), f.scopeA, model.EnrichmentRequest{Name: "Kopi Susu", Ingredients: []string{"espresso", "milk"}})
+	require.NoError(t, err)
+	assert.Equal(t, model.EnrichmentSourceFallback, suggestion.Source)
+	assert.Equal(t, "drinks", suggestion.Category)
+	assert.Equal(t, []model.SuggestedLabel{{Name: "milk", Confidence: 0.8}}, suggestion.Allergens)
+}
+
+func TestEnrichment_CreateAndProposals(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	f := newTenantFixture(t)
+	_, err := f.menuService.Create(context.Background(), f.scopeA, model.Menu{Name: "Es Teh", Category: "drinks", Calories: 90, Description: "Iced tea"})
+	require.NoError(t, err)
+	enrichment := newEnrichmentService(f, service.NewOfflineService())
+	enrichments := controller.NewEnrichmentController(enrichment)
+	menus := controller.NewMenuController(f.menuService, nil)
+	menus.UseEnrichment(enrichment)
+
+	router := gin.New()
+	api := router.Group("/menu", middleware.Tenant(f.tenants, true))
+	api.POST("", menus.Create)
+	api.POST("/enrichment/suggest", enrichments.Suggest)
+	api.POST("/:id/enrichment", enrichments.Propose)
+	api.POST("/enrichment/proposals", enrichments.GenerateProposals)
+	api.GET("/enrichment/proposals", enrichments.ListProposals)
+	api.POST("/enrichment/proposals/:proposal_id/apply", enrichments.ApplyProposal)
+	api.POST("/enrichment/proposals/:proposal_id/reject", enrichments.RejectProposal)
+	send := func(tenant, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", tenant)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Opt-in at create time: only the confident suggestions fill the empty fields
+	rec := send("resto-a", http.MethodPost, "/menu?enrich=true", `{"name": "Kopi Susu Pedas", "price": 22000, "ingredients": ["espresso", "milk", "chili"], "description": "Espresso, milk and a kick"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created model.MenuSuccessResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "drinks", created.Data.Category)
+	assert.Zero(t, created.Data.Calories, "a guess 0.2 sure is left out")
+	assert.Equal(t, []string{"milk"}, created.Data.Allergens)
+	require.Len(t, created.Data.Tags, 1)
+	assert.Equal(t, "spicy", created.Data.Tags[0].Slug)
+	assert.Equal(t, "Spicy", created.Data.Tags[0].Name)
+	require.NotNil(t, created.Enrichment)
+	assert.Equal(t, 150, created.Enrichment.Calories)
+
+	detail, err := f.menuService.GetDetail(f.scopeA, created.Data.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"milk"}, detail.Allergens)
+	assert.Len(t, detail.Tags, 1)
+
+	// Categories given by staff are kept, and nothing is suggested without the opt-in
+	rec = send("resto-a", http.MethodPost, "/menu?enrich=true", `{"name": "Es Jeruk", "category": "specials", "ingredients": ["orange"], "description": "Fresh orange"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"category":"specials"`)
+	rec = send("resto-a", http.MethodPost, "/menu", `{"name": "Es Susu", "ingredients": ["milk"], "description": "Cold milk"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "enrichment")
+	assert.Contains(t, rec.Body.String(), `"category":""`)
+
+	rec = send("resto-a", http.MethodPost, "/menu/enrichment/suggest", `{"name": "Es Susu", "ingredients": ["milk"]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"category":"drinks"`)
+	assert.Equal(t, http.StatusBadRequest, send("resto-a", http.MethodPost, "/menu/enrichment/suggest", `{"ingredients": ["milk"]}`).Code)
+
+	// A proposal waits for review, a newer one supersedes it
+	propose := func(menuID uint) model.EnrichmentProposal {
+		t.Helper()
+		rec := send("resto-a", http.MethodPost, fmt.Sprintf("/menu/%d/enrichment", menuID), "")
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var response model.EnrichmentProposalResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response.Data
+	}
+	first := propose(f.menuA.ID)
+	assert.Equal(t, model.ProposalStatusPending, first.Status)
+	second := propose(f.menuA.ID)
+
+	var list model.EnrichmentProposalListResponse
+	rec = send("resto-a", http.MethodGet, fmt.Sprintf("/menu/enrichment/proposals?menu_id=%d", f.menuA.ID), "")
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Data, 2)
+	assert.Equal(t, second.ID, list.Data[0].ID)
+	assert.Equal(t, model.ProposalStatusSuperseded, list.Data[1].Status)
+	assert.Equal(t, http.StatusBadRequest, send("resto-a", http.MethodGet, "/menu/enrichment/proposals?status=done", "").Code)
+
+	// Proposals of another tenant cannot be seen or applied
+	rec = send("resto-b", http.MethodGet, "/menu/enrichment/proposals", "")
+	assert.JSONEq(t, `{"data": []}`, rec.Body.String())
+	assert.Equal(t, http.StatusNotFound, send("resto-b", http.MethodPost, fmt.Sprintf("/menu/enrichment/proposals/%d/apply", second.ID), "").Code)
+	assert.Equal(t, http.StatusNotFound, send("resto-b", http.MethodPost, fmt.Sprintf("/menu/%d/enrichment", f.menuA.ID), "").Code)
+
+	// Applying picks the fields, the category the menu already has is left alone
+	path := fmt.Sprintf("/menu/enrichment/proposals/%d/apply", second.ID)
+	rec = send("resto-a", http.MethodPost, path, `{"fields": ["category", "calories"], "min_confidence": 0.1}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var applied model.AppliedProposalResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &applied))
+	assert.Equal(t, model.ProposalStatusApplied, applied.Data.Status)
+	assert.Equal(t, []string{"calories"}, applied.Data.AppliedFields)
+	assert.Equal(t, "food", applied.Menu.Category)
+	assert.Equal(t, 550, applied.Menu.Calories)
+	assert.Equal(t, http.StatusConflict, send("resto-a", http.MethodPost, path, "").Code)
+	assert.Equal(t, http.StatusConflict, send("resto-a", http.MethodPost, fmt.Sprintf("/menu/enrichment/proposals/%d/reject", first.ID), "").Code)
+	assert.Equal(t, http.StatusBadRequest, send("resto-a", http.MethodPost, path, `{"fields": ["price"]}`).Code)
+
+	rejected := propose(f.menuA.ID)
+	rec = send("resto-a", http.MethodPost, fmt.Sprintf("/menu/enrichment/proposals/%d/reject", rejected.ID), "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"rejected"`)
+
+	// In bulk, the menus missing a category or calories get a proposal
+	rec = send("resto-a", http.MethodPost, "/menu/enrichment/proposals", `{}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	var names []uint
+	for _, p := range list.Data {
+		names = append(names, p.MenuID)
+	}
+	assert.Len(t, names, 3, "Kopi Susu Pedas, Es Jeruk and Es Susu have no calories")
+	assert.NotContains(t, names, f.menuA.ID)
+	rec = send("resto-a", http.MethodPost, "/menu/enrichment/proposals", `{"menu_ids": [999]}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,171 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/eval"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func evalDataset() eval.Dataset {
+	return eval.Dataset{
+		Catalog: []model.Menu{
+			{ID: 1, Name: "Kopi Susu", Category: "drinks"},
+			{ID: 2, Name: "Ayam Geprek", Category: "food"},
+			{ID: 3, Name: "Gado-Gado", Category: "food"},
+		},
+		BannedWords: eval.DefaultBannedWords,
+		Descriptions: []eval.DescriptionCase{
+			{ID: "kopi", Name: "Kopi Susu", Ingredients: []string{"espresso", "milk"}, DescriptionOptions: model.DescriptionOptions{MaxWords: 8}, Count: 2, MustMention: []string{"milk"}},
+		},
+		Recommendations: []eval.RecommendationCase{
+			{ID: "mild", Preference: "Nothing spicy", Expect: []string{"Gado-Gado"}, Avoid: []string{"Ayam Geprek"}},
+		},
+	}
+}
+
+func checksOf(result eval.CaseResult) map[string]bool {
+	checks := make(map[string]bool)
+	for _, check := range result.Checks {
+		checks[check.Name] = check.Passed
+	}
+	return checks
+}
+
+func TestEval_ScoresConstraints(t *testing.T) {
+	dataset := evalDataset()
+	ai := new(MockAIService)
+	ai.On("GenerateDescriptions", "Kopi Susu", []string{"espresso", "milk"}, mock.Anything, 2).
+		Return([]string{"A Delicious, tasty coffee with milk.", "Espresso and palm sugar, shaken over ice for a slow afternoon."}, nil)
+	ai.On("GetRecommendations", mock.Anything, dataset.Catalog).Return([]model.RecommendationResponseRaw{
+		{MenuID: 2, Reason: "Crispy", Confidence: 1.5},
+		{MenuID: 99, Reason: "Pizza", Confidence: 0.5},
+		{MenuName: "Gado-Gado", Reason: "Fresh", Confidence: 0.8},
+	}, nil)
+
+	result := eval.Run(context.Background(), "baseline", ai, dataset)
+	require.Len(t, result.Cases, 2)
+
+	description := result.Cases[0]
+	assert.Equal(t, map[string]bool{
+		eval.CheckValidJSON:   true,
+		eval.CheckCount:       true,
+		eval.CheckWordCount:   false,
+		eval.CheckBannedWords: false,
+		eval.CheckMentions:    false,
+	}, checksOf(description))
+	assert.InDelta(t, 0.4, description.Score, 1e-9)
+	for _, check := range description.Checks {
+		if check.Name == eval.CheckBannedWords {
+			assert.Equal(t, `#1 says "delicious", #1 says "tasty"`, check.Detail)
+		}
+	}
+
+	// A menu named without its ID is looked up by name, as the service does
+	recommendation := result.Cases[1]
+	assert.Equal(t, map[string]bool{
+		eval.CheckValidJSON:  true,
+		eval.CheckCount:      true,
+		eval.CheckInCatalog:  false,
+		eval.CheckConfidence: false,
+		eval.CheckExpected:   true,
+		eval.CheckAvoided:    false,
+	}, checksOf(recommendation))
+	assert.Equal(t, "Gado-Gado (0.80): Fresh", recommendation.Output[2])
+
+	assert.InDelta(t, (0.4+0.5)/2, result.Score, 1e-9)
+	assert.Equal(t, eval.CheckSummary{Passed: 2, Total: 2}, result.Checks[eval.CheckCount])
+
+	var report bytes.Buffer
+	require.NoError(t, eval.WriteResult(&report, result))
+	assert.Contains(t, report.String(), "score 45.0%")
+	assert.Contains(t, report.String(), "recommendation/mild")
+}
+
+func TestEval_UnparsableAndFailedAnswers(t *testing.T) {
+	dataset := evalDataset()
+	server, _ := chatServer(t, "Sorry, I cannot help with that.")
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+
+	result := eval.Run(context.Background(), "baseline", ai, dataset)
+	for _, c := range result.Cases {
+		assert.Equal(t, map[string]bool{eval.CheckValidJSON: false}, checksOf(c), c.ID)
+		assert.Zero(t, c.Score)
+	}
+	assert.Zero(t, result.Errors, "an unparsable answer is scored, not an error")
+
+	failing := true
+	ai, err = service.NewAIService(config.AI{Provider: "openai", BaseURL: usageServer(t, "", &failing).URL, Timeout: time.Second})
+	require.NoError(t, err)
+	result = eval.Run(context.Background(), "baseline", ai, dataset)
+	assert.Equal(t, 2, result.Errors)
+	assert.Empty(t, result.Cases[0].Checks)
+}
+
+func TestEval_GoldenDatasetRecordAndReplay(t *testing.T) {
+	dataset, err := eval.LoadDataset(filepath.Join("..", "eval", "golden.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, dataset.Descriptions)
+	require.NotEmpty(t, dataset.Recommendations)
+
+	recorder := eval.NewRecorder(service.NewOfflineService())
+	recorded := eval.Run(context.Background(), "baseline", recorder, dataset)
+	assert.Zero(t, recorded.Errors)
+	assert.Equal(t, recorded.Checks[eval.CheckBannedWords].Total, recorded.Checks[eval.CheckBannedWords].Passed)
+
+	path := filepath.Join(t.TempDir(), "answers.json")
+	require.NoError(t, eval.Recording{"baseline": recorder.Answers()}.Save(path))
+	recording, err := eval.LoadRecording(path)
+	require.NoError(t, err)
+
+	replayed := eval.Run(context.Background(), "baseline", eval.NewReplay(recording["baseline"]), dataset)
+	assert.Equal(t, recorded, replayed)
+
+	// The committed recording is kept in step with the dataset
+	committed, err := eval.LoadRecording(filepath.Join("..", "eval", "recordings", "baseline.json"))
+	require.NoError(t, err)
+	assert.Equal(t, recorded, eval.Run(context.Background(), "baseline", eval.NewReplay(committed["baseline"]), dataset))
+
+	// An edited case must be recorded again
+	dataset.Descriptions[0].Ingredients = append(dataset.Descriptions[0].Ingredients, "shallots")
+	replayed = eval.Run(context.Background(), "baseline", eval.NewReplay(recording["baseline"]), dataset)
+	assert.Contains(t, replayed.Cases[0].Error, eval.ErrStaleRecording.Error())
+	assert.Equal(t, 1, replayed.Errors)
+}
+
+func TestEval_CompareVariants(t *testing.T) {
+	dataset := evalDataset()
+	baselineAI := new(MockAIService)
+	baselineAI.On("GenerateDescriptions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]string{"Espresso with cold milk.", "Milk coffee sweetened with palm sugar."}, nil)
+	baselineAI.On("GetRecommendations", mock.Anything, mock.Anything).Return([]model.RecommendationResponseRaw{{MenuID: 3, Reason: "Mild", Confidence: 0.9}}, nil)
+	candidateAI := new(MockAIService)
+	candidateAI.On("GenerateDescriptions", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]string{"A tasty espresso with cold milk.", "Milk coffee sweetened with palm sugar."}, nil)
+	candidateAI.On("GetRecommendations", mock.Anything, mock.Anything).Return([]model.RecommendationResponseRaw{{MenuID: 3, Reason: "Mild", Confidence: 0.9}}, nil)
+
+	comparison := eval.Compare(
+		eval.Run(context.Background(), "baseline", baselineAI, dataset),
+		eval.Run(context.Background(), "candidate", candidateAI, dataset),
+	)
+	assert.True(t, comparison.Regressed())
+	require.Len(t, comparison.Cases, 1, "only the cases scored differently")
+	assert.Equal(t, eval.CaseDiff{ID: "kopi", Kind: eval.KindDescription, Baseline: 1, Candidate: 0.8, Broken: []string{eval.CheckBannedWords}}, comparison.Cases[0])
+	assert.Contains(t, comparison.Checks, eval.CheckDiff{Name: eval.CheckBannedWords, Baseline: 1, Candidate: 0})
+
+	var report bytes.Buffer
+	require.NoError(t, eval.WriteComparison(&report, comparison))
+	assert.Contains(t, report.String(), "broke banned_words")
+	assert.Contains(t, report.String(), "-10.0")
+}
@@ -0,0 +1,355 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/controller"
+	"atalariq/menu-api/internal/imaging"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+	"atalariq/menu-api/internal/service"
+	"atalariq/menu-api/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+	"gorm.io/gorm"
+)
+
+func testImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 7), G: uint8(y * 13), B: uint8(x ^ y), A: uint8(255 - x%3*40)})
+		}
+	}
+	return img
+}
+
+// testArtwork is a flat two-color checkerboard, like a logo or an illustration
+func testArtwork(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{R: 200, G: 40, B: 40, A: 255}
+			if (x/40+y/40)%2 == 0 {
+				c = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	return encodePNG(t, testImage(width, height))
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// assertLosslessWebP encodes src and checks that every pixel decodes unchanged
+func assertLosslessWebP(t *testing.T, src *image.NRGBA, name string) {
+	t.Helper()
+	size := src.Bounds().Size()
+
+	data, err := imaging.EncodeWebPBytes(src)
+	require.NoError(t, err, name)
+
+	decoded, err := webp.Decode(bytes.NewReader(data))
+	require.NoError(t, err, "%s size %v", name, size)
+	require.Equal(t, src.Bounds(), decoded.Bounds(), name)
+
+	// VP8L is lossless, every pixel must survive
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			want := src.NRGBAAt(x, y)
+			got := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA)
+			require.Equal(t, want, got, "%s size %v pixel (%d,%d)", name, size, x, y)
+		}
+	}
+}
+
+// randomRuns fills an image with runs of random length drawn from a small palette,
+// some rows repeat the row above so both kinds of backward reference are used
+func randomRuns(rng *rand.Rand, width, height, colors int) *image.NRGBA {
+	palette := make([]color.NRGBA, colors)
+	for i := range palette {
+		palette[i] = color.NRGBA{R: uint8(rng.Intn(256)), G: uint8(rng.Intn(256)), B: uint8(rng.Intn(256)), A: uint8(255 - rng.Intn(2)*rng.Intn(256))}
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	c, left := palette[0], 0
+	for y := 0; y < height; y++ {
+		if y > 0 && rng.Intn(4) == 0 {
+			copy(img.Pix[y*img.Stride:(y+1)*img.Stride], img.Pix[(y-1)*img.Stride:y*img.Stride])
+			continue
+		}
+		for x := 0; x < width; x++ {
+			if left == 0 {
+				c, left = palette[rng.Intn(colors)], 1+rng.Intn(3*maxRun(rng))
+			}
+			img.SetNRGBA(x, y, c)
+			left--
+		}
+	}
+	return img
+}
+
+// maxRun picks short runs most of the time and runs longer than a backward reference sometimes
+func maxRun(rng *rand.Rand) int {
+	if rng.Intn(8) == 0 {
+		return 3000
+	}
+	return 4
+}
+
+func TestEncodeWebP_RoundTrip(t *testing.T) {
+	// The runs of flat artwork are written as backward references, 5000 pixels exceed the longest one
+	for _, src := range []*image.NRGBA{testImage(1, 1), testImage(3, 2), testImage(64, 48), testImage(257, 13), testArtwork(200, 150), testArtwork(1, 5000)} {
+		assertLosslessWebP(t, src, "fixed")
+	}
+}
+
+func TestEncodeWebP_RandomRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sizes := [][2]int{{1, 1}, {1, 2}, {2, 1}, {1, 9000}, {9000, 1}, {1, 777}, {777, 1}, {5, 5}, {64, 64}, {300, 7}, {7, 300}, {513, 129}}
+	for _, size := range sizes {
+		for _, colors := range []int{1, 2, 5, 256} {
+			for round := 0; round < 3; round++ {
+				assertLosslessWebP(t, randomRuns(rng, size[0], size[1], colors), fmt.Sprintf("%d colors round %d", colors, round))
+			}
+		}
+	}
+
+	// Single-colour images are one long run, also with transparency
+	for _, c := range []color.NRGBA{{R: 255, G: 255, B: 255, A: 255}, {A: 0}, {R: 12, G: 200, B: 99, A: 128}} {
+		for _, size := range [][2]int{{1, 1}, {1, 10000}, {10000, 1}, {200, 300}} {
+			img := image.NewNRGBA(image.Rect(0, 0, size[0], size[1]))
+			draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+			assertLosslessWebP(t, img, fmt.Sprintf("uniform %v", c))
+		}
+	}
+}
+
+func TestEncodeWebP_Size(t *testing.T) {
+	// Flat artwork compresses far below its JPEG
+	artwork := testArtwork(320, 320)
+	data, err := imaging.EncodeWebPBytes(artwork)
+	require.NoError(t, err)
+	jpegData, err := imaging.EncodeJPEGBytes(artwork, 85)
+	require.NoError(t, err)
+	assert.Less(t, len(data), 1024)
+	assert.Less(t, len(data), len(jpegData)/4)
+
+	// Busy images stay about the size of their pixels, the upload keeps the JPEG then
+	busy := testImage(320, 160)
+	data, err = imaging.EncodeWebPBytes(busy)
+	require.NoError(t, err)
+	assert.Less(t, len(data), 320*160*4)
+	jpegData, err = imaging.EncodeJPEGBytes(busy, 85)
+	require.NoError(t, err)
+	assert.Greater(t, len(data), len(jpegData))
+}
+
+func TestImaging_SniffAndFit(t *testing.T) {
+	contentType, ext, err := imaging.SniffContentType(testPNG(t, 4, 4))
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	assert.Equal(t, "png", ext)
+
+	// The content decides, not the file name or declared type
+	_, _, err = imaging.SniffContentType([]byte("<html><script>alert(1)</script></html>"))
+	assert.ErrorIs(t, err, imaging.ErrUnsupportedFormat)
+
+	fitted := imaging.Fit(testImage(2000, 1000), 320)
+	assert.Equal(t, 320, fitted.Bounds().Dx())
+	assert.Equal(t, 160, fitted.Bounds().Dy())
+
+	// Smaller images are never upscaled
+	small := testImage(100, 50)
+	assert.Equal(t, small.Bounds(), imaging.Fit(small, 320).Bounds())
+}
+
+func TestLocalStorage_RejectsTraversal(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewLocalStorage(dir, "/uploads")
+	require.NoError(t, err)
+
+	for _, key := range []string{"../escape.png", "a/../../b.png", "/abs.png", `a\b.png`, ""} {
+		assert.ErrorIs(t, store.Put(context.Background(), key, []byte("x"), "image/png"), storage.ErrInvalidKey, key)
+	}
+
+	require.NoError(t, store.Put(context.Background(), "menus/1/a.png", []byte("x"), "image/png"))
+	assert.FileExists(t, filepath.Join(dir, "menus", "1", "a.png"))
+	assert.Equal(t, "/uploads/menus/1/a.png", store.URL("menus/1/a.png"))
+
+	require.NoError(t, store.Delete(context.Background(), "menus/1/a.png"))
+	assert.NoFileExists(t, filepath.Join(dir, "menus", "1", "a.png"))
+}
+
+func TestS3Storage_SignedRequests(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20240102/ap-southeast-1/s3/aws4_request, SignedHeaders=") ||
+			r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) ||
+			r.Header.Get("X-Amz-Date") != "20240102T030405Z" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path] = body
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	store, err := storage.NewS3Storage(storage.S3Config{
+		Endpoint:  server.URL,
+		Region:    "ap-southeast-1",
+		Bucket:    "menus",
+		AccessKey: "AKID",
+		SecretKey: "secret",
+		PublicURL: "https://cdn.example.com/",
+		Now:       func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) },
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(context.Background(), "tenants/1/photo.webp", []byte("webp"), "image/webp"))
+	assert.Equal(t, []byte("webp"), objects["/menus/tenants/1/photo.webp"])
+	assert.Equal(t, "https://cdn.example.com/tenants/1/photo.webp", store.URL("tenants/1/photo.webp"))
+
+	require.NoError(t, store.Delete(context.Background(), "tenants/1/photo.webp"))
+	assert.Empty(t, objects)
+}
+
+func TestImageService_UploadReplacesVariants(t *testing.T) {
+	db := newTestDB(t)
+	repo := repository.NewMenuRepository(db)
+	menus := service.NewMenuService(repo, new(MockAIService))
+
+	dir := t.TempDir()
+	store, err := storage.NewLocalStorage(dir, "/uploads")
+	require.NoError(t, err)
+	images := service.NewImageService(repo, menus, store, 1<<20)
+	menus.AddListener(images)
+
+	scope := model.Scope{TenantID: model.DefaultTenantID}
+	menu, err := menus.Create(context.Background(), scope, model.Menu{Name: "Sate Ayam", Category: "food", Price: 30000, Description: "Grilled skewers"})
+	require.NoError(t, err)
+
+	res, err := images.Upload(context.Background(), scope, menu.ID, testPNG(t, 1600, 800))
+	require.NoError(t, err)
+	require.NotNil(t, res.Image)
+	assert.Equal(t, 1600, res.Image.Width)
+	assert.True(t, strings.HasPrefix(res.Image.URL, "/uploads/tenants/1/menus/"))
+	// A photo is smaller as JPEG, the WebP variants are left out
+	assert.True(t, strings.HasSuffix(res.Image.LargeURL, "/large.jpg"))
+	assert.True(t, strings.HasSuffix(res.Image.ThumbnailURL, "/thumbnail.jpg"))
+	assert.Empty(t, res.Image.ThumbnailWebPURL)
+
+	stored, err := repo.FindByID(scope, menu.ID)
+	require.NoError(t, err)
+	first := stored.Image
+	assert.Equal(t, 1280, first.Variants[model.ImageVariantLarge].Width)
+	assert.Equal(t, 320, first.Variants[model.ImageVariantThumbnail].Width)
+	for _, variant := range first.Variants {
+		assert.FileExists(t, filepath.Join(dir, filepath.FromSlash(variant.Key)))
+	}
+
+	// Non-images and oversized files are rejected before anything is stored
+	_, err = images.Upload(context.Background(), scope, menu.ID, []byte("not an image at all"))
+	assert.ErrorIs(t, err, imaging.ErrUnsupportedFormat)
+	_, err = images.Upload(context.Background(), scope, menu.ID, make([]byte, 2<<20))
+	assert.ErrorIs(t, err, service.ErrImageTooLarge)
+
+	// Updating the menu keeps its image
+	_, err = menus.Update(scope, menu.ID, model.UpdateMenuRequest{Menu: model.Menu{Name: "Sate Ayam", Category: "food", Price: 32000}})
+	require.NoError(t, err)
+	stored, err = repo.FindByID(scope, menu.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first, stored.Image)
+
+	// A new upload replaces the files of the previous one, flat artwork is smaller as WebP
+	res, err = images.Upload(context.Background(), scope, menu.ID, encodePNG(t, testArtwork(400, 200)))
+	require.NoError(t, err)
+	for _, variant := range first.Variants {
+		assert.NoFileExists(t, filepath.Join(dir, filepath.FromSlash(variant.Key)))
+	}
+	assert.True(t, strings.HasSuffix(res.Image.LargeURL, "/large.webp"))
+	assert.True(t, strings.HasSuffix(res.Image.ThumbnailURL, "/thumbnail.jpg"))
+	assert.True(t, strings.HasSuffix(res.Image.ThumbnailWebPURL, "/thumbnail.webp"))
+
+	// Other tenants cannot touch the image
+	_, err = images.Upload(context.Background(), model.Scope{TenantID: 99}, menu.ID, testPNG(t, 10, 10))
+	assert.Error(t, err)
+
+	// Deleting the menu removes its files
+	stored, err = repo.FindByID(scope, menu.ID)
+	require.NoError(t, err)
+	require.NoError(t, menus.Delete(scope, menu.ID))
+	for _, variant := range stored.Image.Variants {
+		_, statErr := os.Stat(filepath.Join(dir, filepath.FromSlash(variant.Key)))
+		assert.True(t, os.IsNotExist(statErr))
+	}
+}
+
+func TestDeleteImageEndpoint_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(MockRepository)
+	store, err := storage.NewLocalStorage(t.TempDir(), "/uploads")
+	require.NoError(t, err)
+	images := service.NewImageService(mockRepo, service.NewMenuService(mockRepo, new(MockAIService)), store, 1<<20)
+	router := gin.New()
+	router.DELETE("/menu/:id/image", controller.NewImageController(images).Delete)
+	remove := func(id string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/menu/"+id+"/image", nil))
+		return rec.Code
+	}
+
+	mockRepo.On("FindByID", mock.Anything, uint(1)).Return(model.Menu{}, gorm.ErrRecordNotFound)
+	mockRepo.On("FindByID", mock.Anything, uint(2)).Return(model.Menu{}, errors.New("connection refused"))
+	mockRepo.On("FindByID", mock.Anything, uint(3)).Return(model.Menu{ID: 3, Name: "Latte"}, nil)
+
+	assert.Equal(t, http.StatusNotFound, remove("1"))
+	assert.Equal(t, http.StatusInternalServerError, remove("2"), "a database failure is not a missing menu")
+	assert.Equal(t, http.StatusOK, remove("3"))
+}
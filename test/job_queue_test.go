@@ -0,0 +1,202 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// runRetries makes the jobs waiting for their backoff due, then runs them
+func runRetries(t *testing.T, db *gorm.DB, queue service.JobQueue) int {
+	t.Helper()
+	require.NoError(t, db.Model(&model.Job{}).Where("status = ?", model.JobStatusQueued).
+		Update("run_at", time.Now().Add(-time.Second)).Error)
+	ran, err := queue.RunDue(context.Background())
+	require.NoError(t, err)
+	return ran
+}
+
+func TestJobRepository_Claim(t *testing.T) {
+	db := newTestDB(t)
+	repo := repository.NewJobRepository(db)
+	require.NoError(t, repo.Create(&model.Job{TenantID: 1, Type: "test", Status: model.JobStatusQueued, MaxAttempts: 3, RunAt: time.Now()}))
+	require.NoError(t, repo.Create(&model.Job{TenantID: 1, Type: "test", Status: model.JobStatusQueued, MaxAttempts: 3, RunAt: time.Now().Add(time.Hour)}))
+
+	job, err := repo.Claim(time.Now(), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusRunning, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+
+	// A running job belongs to its worker and a future job is not due yet
+	_, err = repo.Claim(time.Now(), time.Minute)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Once the lease ends, e.g. after a crash, the job is claimed again
+	again, err := repo.Claim(time.Now().Add(2*time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, again.ID)
+	assert.Equal(t, 2, again.Attempts)
+
+	// The first worker can no longer record its outcome, the one holding the lease can
+	firstLease := *job.LockedUntil
+	job.Status = model.JobStatusDone
+	job.LockedUntil = nil
+	assert.ErrorIs(t, repo.Update(&job, firstLease), repository.ErrJobLeaseLost)
+	again.Status = model.JobStatusFailed
+	again.LastError = "timeout"
+	leasedUntil := *again.LockedUntil
+	again.LockedUntil = nil
+	require.NoError(t, repo.Update(&again, leasedUntil))
+	assert.ErrorIs(t, repo.Update(&again, leasedUntil), repository.ErrJobLeaseLost, "a finished job is not running anymore")
+
+	var stored model.Job
+	require.NoError(t, db.First(&stored, again.ID).Error)
+	assert.Equal(t, model.JobStatusFailed, stored.Status)
+	assert.Equal(t, 2, stored.Attempts)
+}
+
+func TestDescriptionJobs_RetryWithBackoff(t *testing.T) {
+	f := newTenantFixture(t)
+	ai := new(MockAIService)
+	ai.On("GenerateDescription", "Soto Ayam", mock.Anything).Return("", errors.New("quota exceeded")).Times(2)
+	ai.On("GenerateDescription", "Soto Ayam", mock.Anything).Return("Turmeric broth with shredded chicken.", nil)
+
+	queue := service.NewJobQueue(repository.NewJobRepository(f.db))
+	menuService := service.NewMenuService(f.menuRepo, ai)
+	menuService.UseJobQueue(queue)
+
+	// The menu is created at once, the AI is only called by the worker
+	menu, err := menuService.Create(context.Background(), f.scopeA, model.Menu{Name: "Soto Ayam", Category: "food", Ingredients: []string{"chicken"}})
+	require.NoError(t, err)
+	assert.Equal(t, model.DescriptionStatusPending, menu.DescriptionStatus)
+	assert.Empty(t, menu.Description)
+	ai.AssertNotCalled(t, "GenerateDescription", mock.Anything, mock.Anything)
+
+	job, err := menuService.DescriptionJob(f.scopeA, menu.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusQueued, job.Status)
+	_, err = menuService.DescriptionJob(f.scopeB, menu.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	ran, err := queue.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, ran)
+	job, _ = menuService.DescriptionJob(f.scopeA, menu.ID)
+	assert.Equal(t, model.JobStatusQueued, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, "quota exceeded", job.LastError)
+	assert.True(t, job.RunAt.After(time.Now()), "the retry waits for its backoff")
+
+	ran, err = queue.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, ran)
+
+	runRetries(t, f.db, queue)
+	runRetries(t, f.db, queue)
+	job, _ = menuService.DescriptionJob(f.scopeA, menu.ID)
+	assert.Equal(t, model.JobStatusDone, job.Status)
+	assert.Equal(t, 3, job.Attempts)
+
+	detail, err := menuService.GetDetail(f.scopeA, menu.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Turmeric broth with shredded chicken.", detail.Description)
+	assert.Equal(t, model.DescriptionStatusReady, detail.DescriptionStatus)
+}
+
+func TestDescriptionJobs_FallbackAndSweep(t *testing.T) {
+	f := newTenantFixture(t)
+	ai := new(MockAIService)
+	ai.On("GenerateDescription", mock.Anything, mock.Anything).Return("", errors.New("AI unavailable")).Times(5)
+
+	queue := service.NewJobQueue(repository.NewJobRepository(f.db))
+	menuService := service.NewMenuService(f.menuRepo, ai)
+	menuService.UseJobQueue(queue)
+
+	menu, err := menuService.Create(context.Background(), f.scopeA, model.Menu{Name: "Rawon", Category: "food"})
+	require.NoError(t, err)
+	for range 5 {
+		runRetries(t, f.db, queue)
+	}
+
+	// Every attempt failed, the placeholder is shown meanwhile
+	job, _ := menuService.DescriptionJob(f.scopeA, menu.ID)
+	assert.Equal(t, model.JobStatusFailed, job.Status)
+	detail, _ := menuService.GetDetail(f.scopeA, menu.ID)
+	assert.Equal(t, "Delicious Rawon", detail.Description)
+	assert.Equal(t, model.DescriptionStatusFallback, detail.DescriptionStatus)
+
+	// A placeholder stored before description statuses existed is flagged by the migration
+	legacy := model.Menu{TenantID: f.tenantA.ID, Name: "Gado Gado", Description: "Delicious Gado Gado"}
+	require.NoError(t, f.menuRepo.Create(&legacy))
+	marked, err := menuService.MarkLegacyPlaceholders()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), marked)
+	// but later sweeps leave a description written like a placeholder alone
+	lookalike := model.Menu{TenantID: f.tenantA.ID, Name: "Es Teh", Description: "Delicious Es Teh"}
+	require.NoError(t, f.menuRepo.Create(&lookalike))
+	// and a description written by hand while pending is never replaced
+	edited, err := menuService.Create(context.Background(), f.scopeA, model.Menu{Name: "Pecel", Category: "food"})
+	require.NoError(t, err)
+	edited.Description = "Written by the chef"
+	_, err = menuService.Update(f.scopeA, edited.ID, model.UpdateMenuRequest{Menu: edited})
+	require.NoError(t, err)
+
+	ai.On("GenerateDescription", mock.Anything, mock.Anything).Return("Generated at last.", nil)
+	enqueued, err := menuService.SweepDescriptions()
+	require.NoError(t, err)
+	assert.Equal(t, 2, enqueued, "Pecel still has its job")
+	enqueued, err = menuService.SweepDescriptions()
+	require.NoError(t, err)
+	assert.Zero(t, enqueued, "jobs are not queued twice")
+
+	ran, err := queue.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, ran)
+
+	for id, want := range map[uint]string{menu.ID: "Generated at last.", legacy.ID: "Generated at last.", edited.ID: "Written by the chef", lookalike.ID: "Delicious Es Teh"} {
+		detail, err := menuService.GetDetail(f.scopeA, id)
+		require.NoError(t, err)
+		assert.Equal(t, want, detail.Description)
+		assert.Equal(t, model.DescriptionStatusReady, detail.DescriptionStatus)
+	}
+	ai.AssertNotCalled(t, "GenerateDescription", "Pecel", mock.Anything)
+}
+
+func TestDescriptionJobs_SweepGivesUp(t *testing.T) {
+	f := newTenantFixture(t)
+	menuService := service.NewMenuService(f.menuRepo, new(MockAIService))
+	menuService.UseJobQueue(service.NewJobQueue(repository.NewJobRepository(f.db)))
+
+	menu, err := menuService.Create(context.Background(), f.scopeA, model.Menu{Name: "Rawon", Category: "food"})
+	require.NoError(t, err)
+	failAll := func() {
+		require.NoError(t, f.db.Model(&model.Job{}).Where("status = ?", model.JobStatusQueued).
+			Update("status", model.JobStatusFailed).Error)
+	}
+
+	// Every sweep queues a new generation once the previous job failed, five times at most
+	for sweep := 1; sweep <= 5; sweep++ {
+		failAll()
+		enqueued, err := menuService.SweepDescriptions()
+		require.NoError(t, err)
+		assert.Equal(t, 1, enqueued, "sweep %d", sweep)
+	}
+	failAll()
+	enqueued, err := menuService.SweepDescriptions()
+	require.NoError(t, err)
+	assert.Zero(t, enqueued)
+
+	var jobs int64
+	require.NoError(t, f.db.Model(&model.Job{}).Where("menu_id = ?", menu.ID).Count(&jobs).Error)
+	assert.Equal(t, int64(6), jobs, "the job queued on creation and one per sweep")
+}
@@ -0,0 +1,170 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/controller"
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// importRouter serves the extraction and import routes with menus read by ai
+func importRouter(f tenantFixture, ai service.AIService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	menus := controller.NewMenuController(service.NewMenuService(f.menuRepo, ai), nil)
+	router := gin.New()
+	api := router.Group("/menu", middleware.Tenant(f.tenants, true))
+	api.POST("/extract", menus.ExtractMenus)
+	api.POST("/import", menus.ImportMenus)
+	return router
+}
+
+func uploadMenuDocument(t *testing.T, router *gin.Engine, tenant string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if data != nil {
+		part, err := form.CreateFormFile("file", "menu")
+		require.NoError(t, err)
+		_, err = part.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/menu/extract", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("X-Tenant-ID", tenant)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func importMenus(router *gin.Engine, tenant, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/menu/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-ID", tenant)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMenuExtraction_OfflineToImport(t *testing.T) {
+	f := newTenantFixture(t)
+	router := importRouter(f, service.NewOfflineService())
+
+	rec := uploadMenuDocument(t, router, "resto-a", testPNG(t, 8, 8))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var extracted model.MenuExtractionResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &extracted))
+	require.Len(t, extracted.Data, 5)
+	assert.Equal(t, model.MenuDraft{Name: "Nasi Goreng Spesial", Price: 28000, Category: "food", Ingredients: []string{"rice", "egg", "chicken", "sambal"}, Confidence: 0.9}, extracted.Data[0])
+
+	// Nothing is saved until the reviewed drafts are imported
+	list, err := f.menuService.GetList(f.scopeA, model.MenuFilter{})
+	require.NoError(t, err)
+	assert.Len(t, list.Data, 1)
+
+	extracted.Data[4].Price = 12000
+	body, err := json.Marshal(model.MenuImportRequest{Menus: extracted.Data})
+	require.NoError(t, err)
+	rec = importMenus(router, "resto-a", string(body))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var imported model.MenuImportResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &imported))
+	require.Len(t, imported.Data, 5)
+	for _, menu := range imported.Data {
+		assert.NotZero(t, menu.ID)
+		assert.Equal(t, model.AvailabilityAvailable, menu.Availability)
+	}
+	assert.Equal(t, 12000.0, imported.Data[4].Price)
+	// Without background jobs the missing descriptions are placeholders, printed ones are kept
+	assert.Equal(t, model.DescriptionStatusFallback, imported.Data[0].DescriptionStatus)
+	assert.Equal(t, "Vegetables with peanut sauce", imported.Data[2].Description)
+	assert.Equal(t, model.DescriptionStatusReady, imported.Data[2].DescriptionStatus)
+
+	list, err = f.menuService.GetList(f.scopeA, model.MenuFilter{})
+	require.NoError(t, err)
+	assert.Len(t, list.Data, 6)
+	list, err = f.menuService.GetList(f.scopeB, model.MenuFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Data)
+}
+
+func TestMenuExtraction_RejectsDocuments(t *testing.T) {
+	f := newTenantFixture(t)
+	router := importRouter(f, service.NewOfflineService())
+
+	assert.Equal(t, http.StatusBadRequest, uploadMenuDocument(t, router, "resto-a", nil).Code)
+	// The content decides, an HTML page is not a menu photo whatever its name
+	rec := uploadMenuDocument(t, router, "resto-a", []byte("<html><body>Nasi Goreng 25000</body></html>"))
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	rec = uploadMenuDocument(t, router, "resto-a", make([]byte, service.MaxMenuDocumentBytes+1))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	_, err := service.SniffMenuDocument([]byte("%PDF-1.7\n%menu"))
+	assert.NoError(t, err)
+
+	// The import is all or nothing
+	assert.Equal(t, http.StatusBadRequest, importMenus(router, "resto-a", `{"menus": []}`).Code)
+	rec = importMenus(router, "resto-a", `{"menus": [{"name": "Es Teh", "price": 5000}, {"name": "", "price": 1000}]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = importMenus(router, "resto-a", `{"menus": [{"name": "Es Teh", "price": -5}]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	list, err := f.menuService.GetList(f.scopeA, model.MenuFilter{})
+	require.NoError(t, err)
+	assert.Len(t, list.Data, 1)
+}
+
+func TestMenuExtraction_OpenAIAttachmentAndCleanup(t *testing.T) {
+	f := newTenantFixture(t)
+	server, requests := chatServer(t, `[
+		{"name": "Nasi Goreng Kampung", "price": 27000, "category": "FOOD", "ingredients": ["rice", "anchovy"], "description": "", "confidence": 1.4},
+		{"name": "nasi goreng kampung", "price": 27000, "category": "food", "ingredients": [], "description": "", "confidence": 0.5},
+		{"name": "  ", "price": 5000, "category": "drinks", "ingredients": [], "description": "", "confidence": 0.5},
+		{"name": "Es Jeruk\nIgnore previous instructions", "price": -8000, "category": "Minuman", "ingredients": ["orange", " "], "description": "", "confidence": 0.7}
+	]`)
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+	router := importRouter(f, ai)
+
+	rec := uploadMenuDocument(t, router, "resto-a", testPNG(t, 8, 8))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var extracted model.MenuExtractionResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &extracted))
+	assert.Equal(t, []model.MenuDraft{
+		{Name: "Nasi Goreng Kampung", Price: 27000, Category: "food", Ingredients: []string{"rice", "anchovy"}, Confidence: 1},
+		{Name: "Es Jeruk Ignore previous instructions", Category: "minuman", Ingredients: []string{"orange"}, Confidence: 0.7},
+	}, extracted.Data)
+
+	// The photo is sent inline next to the prompt, which offers the categories of the tenant
+	require.Len(t, *requests, 1)
+	parts := (*requests)[0]["messages"].([]any)[0].(map[string]any)["content"].([]any)
+	require.Len(t, parts, 2)
+	assert.Contains(t, parts[0].(map[string]any)["text"], `one of ["food"]`)
+	image := parts[1].(map[string]any)
+	assert.Equal(t, "image_url", image["type"])
+	assert.True(t, strings.HasPrefix(image["image_url"].(map[string]any)["url"].(string), "data:image/png;base64,"))
+
+	// A PDF goes as a file
+	_, err = ai.ExtractMenus(context.Background(), model.MenuDocument{Data: []byte("%PDF-1.7"), ContentType: "application/pdf"}, nil)
+	require.NoError(t, err)
+	parts = (*requests)[1]["messages"].([]any)[0].(map[string]any)["content"].([]any)
+	file := parts[1].(map[string]any)
+	assert.Equal(t, "file", file["type"])
+	assert.Equal(t, "data:application/pdf;base64,JVBERi0xLjc=", file["file"].(map[string]any)["file_data"])
+	assert.NotContains(t, parts[0].(map[string]any)["text"], "one of")
+}
@@ -0,0 +1,192 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/controller"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestPromptStore_Files(t *testing.T) {
+	store, err := service.NewPromptStore("", nil)
+	require.NoError(t, err)
+
+	prompt, err := store.Render(service.PromptDescription, service.DescriptionPromptData{
+		Name: "Kopi Susu", Ingredients: []string{"espresso", "milk"}, Style: "Playful & Witty", MaxWords: 15, Language: "English", Count: 1,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, prompt, `Name: "Kopi Susu"`)
+	assert.Contains(t, prompt, `Ingredients: ["espresso","milk"]`)
+	assert.Contains(t, prompt, "under 15 words")
+	assert.NotContains(t, prompt, "audience")
+
+	_, err = store.Render("dessert", nil)
+	assert.ErrorIs(t, err, service.ErrUnknownPrompt)
+	_, err = store.CreateVersion(service.PromptDescription, "Describe {{.Name}}.", "")
+	assert.ErrorIs(t, err, service.ErrPromptReadOnly)
+
+	// PROMPT_TEMPLATE_DIR replaces the built-in files it contains
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "description.tmpl"), []byte("Describe {{.Name}} in {{.MaxWords}} words."), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "repair.tmpl"), []byte("Fix {{.Answer}}, it is {{.Error}}. Use {{.Format}}"), 0o644))
+	store, err = service.NewPromptStore(dir, nil)
+	require.NoError(t, err)
+	prompt, err = store.Render(service.PromptDescription, service.DescriptionPromptData{Name: "Es Teh", MaxWords: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "Describe Es Teh in 10 words.", prompt)
+	prompt, err = store.Render(service.PromptRepair, service.RepairPromptData{Error: "not JSON", Answer: "Sure!", Format: "[]"})
+	require.NoError(t, err)
+	assert.Equal(t, "Fix Sure!, it is not JSON. Use []", prompt)
+	versions, err := store.Versions(service.PromptRecommendation)
+	require.NoError(t, err)
+	assert.Contains(t, versions[0].Body, "Recommendation Engine")
+
+	// A file using a field that does not exist stops the server at startup
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "translation.tmpl"), []byte("Translate {{.Menus}}"), 0o644))
+	_, err = service.NewPromptStore(dir, nil)
+	assert.ErrorIs(t, err, service.ErrInvalidPrompt)
+}
+
+func TestPromptStore_DatabaseVersions(t *testing.T) {
+	db := newTestDB(t)
+	store, err := service.NewPromptStore("", repository.NewPromptTemplateRepository(db))
+	require.NoError(t, err)
+
+	_, err = store.CreateVersion(service.PromptRecommendation, "Pick one of {{range .Menus}}{{.Nme}}{{end}}", "")
+	assert.ErrorIs(t, err, service.ErrInvalidPrompt)
+	_, err = store.CreateVersion(service.PromptRecommendation, "Pick one of {{range .Menus}}", "")
+	assert.ErrorIs(t, err, service.ErrInvalidPrompt)
+
+	created, err := store.CreateVersion(service.PromptRecommendation, `Wish: {{.Preference}}. Pick from{{range .Menus}} [{{.ID}}] {{.Name}}{{end}} as {{.Format}}`, "Shorter")
+	require.NoError(t, err)
+	assert.Equal(t, 1, created.Version)
+	assert.False(t, created.Active)
+
+	// Served by the provider once activated
+	server, requests := chatServer(t, `[{"menu_id": 3, "reason": "Cold", "confidence": 0.7}]`)
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+	ai.(service.PromptUser).UsePrompts(store)
+	sentPrompt := func() string {
+		_, err := ai.GetRecommendations(context.Background(), model.RecommendationRequest{Preference: "iced tea"}, []model.Menu{{ID: 3, Name: "Es Teh"}})
+		require.NoError(t, err)
+		return (*requests)[len(*requests)-1]["messages"].([]any)[0].(map[string]any)["content"].(string)
+	}
+	assert.Contains(t, sentPrompt(), "Recommendation Engine")
+
+	activated, err := store.Activate(service.PromptRecommendation, 1)
+	require.NoError(t, err)
+	assert.True(t, activated.Active)
+	assert.True(t, strings.HasPrefix(sentPrompt(), "Wish: iced tea. Pick from [3] Es Teh as [{"))
+
+	summaries, err := store.Templates()
+	require.NoError(t, err)
+	assert.Contains(t, summaries, model.PromptTemplateSummary{Name: service.PromptRecommendation, ActiveVersion: 1, LatestVersion: 1})
+	assert.Contains(t, summaries, model.PromptTemplateSummary{Name: service.PromptDescription})
+
+	// Another instance reads the active version from the database
+	other, err := service.NewPromptStore("", repository.NewPromptTemplateRepository(db))
+	require.NoError(t, err)
+	preview, err := other.Preview(service.PromptRecommendation, model.PromptPreviewRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, *preview.Version)
+	assert.True(t, strings.HasPrefix(preview.Prompt, "Wish: "))
+
+	_, err = store.Activate(service.PromptRecommendation, 7)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Version 0 goes back to the file
+	_, err = store.Activate(service.PromptRecommendation, 0)
+	require.NoError(t, err)
+	assert.Contains(t, sentPrompt(), "Recommendation Engine")
+	versions, err := store.Versions(service.PromptRecommendation)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, model.PromptSourceFile, versions[0].Source)
+	assert.True(t, versions[0].Active)
+	assert.False(t, versions[1].Active)
+}
+
+func TestPromptEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store, err := service.NewPromptStore("", repository.NewPromptTemplateRepository(newTestDB(t)))
+	require.NoError(t, err)
+	prompts := controller.NewPromptController(store)
+
+	router := gin.New()
+	router.GET("/admin/prompts", prompts.ListPrompts)
+	router.GET("/admin/prompts/:name/versions", prompts.ListVersions)
+	router.POST("/admin/prompts/:name/versions", prompts.CreateVersion)
+	router.POST("/admin/prompts/:name/versions/:version/activate", prompts.ActivateVersion)
+	router.POST("/admin/prompts/:name/preview", prompts.Preview)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	previewOf := func(rec *httptest.ResponseRecorder) model.PromptPreview {
+		t.Helper()
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var response model.PromptPreviewResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	// A draft is previewed against the sample menu given before it is saved
+	draft := `{"body": "Sell {{.Name}} ({{join .Ingredients \" + \"}}) in {{.Language}}", "menu": {"name": "Soto Ayam", "ingredients": ["chicken", "turmeric"]}}`
+	preview := previewOf(send(http.MethodPost, "/admin/prompts/description/preview", draft))
+	assert.Equal(t, "Sell Soto Ayam (chicken + turmeric) in English", preview.Prompt)
+	assert.Nil(t, preview.Version)
+
+	preview = previewOf(send(http.MethodPost, "/admin/prompts/translation/preview", `{}`))
+	assert.Contains(t, preview.Prompt, `"name":"Nasi Goreng"`)
+	assert.Contains(t, preview.Prompt, "into Indonesian")
+
+	rec := send(http.MethodPost, "/admin/prompts/description/versions", `{"body": "Sell {{.Name}}.", "note": "Minimal"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, "/admin/prompts/description/versions/1/activate", "").Code)
+	assert.Equal(t, "Sell Nasi Goreng.", previewOf(send(http.MethodPost, "/admin/prompts/description/preview", `{}`)).Prompt)
+	preview = previewOf(send(http.MethodPost, "/admin/prompts/description/preview", `{"version": 0}`))
+	assert.Contains(t, preview.Prompt, "Senior Culinary Copywriter")
+
+	rec = send(http.MethodGet, "/admin/prompts/description/versions", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var versions model.PromptVersionListResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &versions))
+	require.Len(t, versions.Data, 2)
+	assert.Equal(t, "Minimal", versions.Data[1].Note)
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/admin/prompts", "").Code)
+
+	for _, c := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodPost, "/admin/prompts/description/versions", `{"body": "Sell {{.Price}}"}`, http.StatusBadRequest},
+		{http.MethodPost, "/admin/prompts/description/versions", `{"note": "no body"}`, http.StatusBadRequest},
+		{http.MethodPost, "/admin/prompts/description/preview", `{"version": 4}`, http.StatusNotFound},
+		{http.MethodPost, "/admin/prompts/description/versions/-1/activate", "", http.StatusBadRequest},
+		{http.MethodPost, "/admin/prompts/description/versions/4/activate", "", http.StatusNotFound},
+		{http.MethodGet, "/admin/prompts/dessert/versions", "", http.StatusNotFound},
+	} {
+		assert.Equal(t, c.status, send(c.method, c.path, c.body).Code, c.method+" "+c.path+" "+c.body)
+	}
+}
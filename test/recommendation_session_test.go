@@ -0,0 +1,123 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// firstCandidateAI recommends the first candidate and records what it was given
+type firstCandidateAI struct {
+	MockAIService
+	request    model.RecommendationRequest
+	candidates []string
+}
+
+func (a *firstCandidateAI) GetRecommendations(ctx context.Context, request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error) {
+	a.request = request
+	a.candidates = nil
+	for _, m := range menus {
+		a.candidates = append(a.candidates, m.Name)
+	}
+	return []model.RecommendationResponseRaw{{MenuID: menus[0].ID, Reason: "fits", Confidence: 0.9}}, nil
+}
+
+func TestRecommendationSession_Refines(t *testing.T) {
+	db := newTestDB(t)
+	ai := new(firstCandidateAI)
+	menus := service.NewMenuService(repository.NewMenuRepository(db), ai)
+	sessions := service.NewRecommendationSessionService(repository.NewRecommendationSessionRepository(db), menus, time.Hour)
+
+	ids := make(map[string]uint)
+	for _, m := range []model.Menu{
+		{Name: "Latte", Category: "Coffee", Price: 30000, Ingredients: []string{"espresso", "milk"}},
+		{Name: "Kopi Susu", Category: "Coffee", Price: 25000, Ingredients: []string{"coffee", "milk", "palm sugar"}},
+		{Name: "Cold Brew", Category: "Coffee", Price: 28000, Ingredients: []string{"coffee", "water"}},
+		{Name: "Americano", Category: "Coffee", Price: 20000, Ingredients: []string{"espresso", "water"}},
+	} {
+		m.Description = m.Name
+		created, err := menus.Create(context.Background(), testScope, m)
+		require.NoError(t, err)
+		ids[m.Name] = created.ID
+	}
+
+	session, err := sessions.Start(context.Background(), testScope, "I want a latte")
+	require.NoError(t, err)
+	require.Len(t, session.Recommendations, 1)
+	assert.Equal(t, "Latte", session.Recommendations[0].Menu.Name)
+	assert.Empty(t, ai.request.History)
+
+	// "Cheaper" means cheaper than what was suggested
+	session, err = sessions.Send(context.Background(), testScope, session.SessionID, "something cheaper")
+	require.NoError(t, err)
+	require.Len(t, ai.request.History, 1)
+	assert.Equal(t, "I want a latte", ai.request.History[0].Message)
+	assert.Equal(t, ids["Latte"], ai.request.History[0].Suggestions[0].MenuID)
+	assert.NotContains(t, ai.candidates, "Latte")
+	assert.NotEmpty(t, ai.candidates)
+
+	// Exclusions stack on top of the earlier limits
+	session, err = sessions.Send(context.Background(), testScope, session.SessionID, "no, without dairy")
+	require.NoError(t, err)
+	assert.Len(t, ai.request.History, 2)
+	assert.ElementsMatch(t, []string{"Cold Brew", "Americano"}, ai.candidates)
+	assert.Len(t, session.Turns, 3)
+
+	stored, err := sessions.Get(testScope, session.SessionID)
+	require.NoError(t, err)
+	assert.Len(t, stored.Turns, 3)
+	assert.Equal(t, "no, without dairy", stored.Turns[2].Message)
+
+	// Sessions belong to their tenant
+	_, err = sessions.Get(model.Scope{TenantID: 99}, session.SessionID)
+	assert.ErrorIs(t, err, service.ErrSessionNotFound)
+}
+
+func TestRecommendationSession_Expires(t *testing.T) {
+	db := newTestDB(t)
+	menus := service.NewMenuService(repository.NewMenuRepository(db), service.NewOfflineService())
+	sessions := service.NewRecommendationSessionService(repository.NewRecommendationSessionRepository(db), menus, 20*time.Millisecond)
+
+	_, err := menus.Create(context.Background(), testScope, model.Menu{Name: "Es Teh", Description: "Iced tea"})
+	require.NoError(t, err)
+
+	session, err := sessions.Start(context.Background(), testScope, "")
+	require.NoError(t, err)
+	assert.Empty(t, session.Recommendations)
+
+	// Every message moves the expiry forward
+	_, err = sessions.Send(context.Background(), testScope, session.SessionID, "something cold")
+	require.NoError(t, err)
+
+	time.Sleep(40 * time.Millisecond)
+	_, err = sessions.Send(context.Background(), testScope, session.SessionID, "another one")
+	assert.ErrorIs(t, err, service.ErrSessionNotFound)
+}
+
+func TestRecommendationPrompt_IncludesHistory(t *testing.T) {
+	server, requests := chatServer(t, `[{"menu_id": 2, "reason": "Cheaper", "confidence": 0.8}]`)
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+
+	_, err = ai.GetRecommendations(context.Background(), model.RecommendationRequest{
+		Preference: "something cheaper",
+		History: []model.RecommendationTurn{{
+			Message:     "I want a latte",
+			Suggestions: []model.SuggestedMenu{{MenuID: 1, Name: "Latte", Price: 30000}},
+		}},
+	}, []model.Menu{{ID: 2, Name: "Americano"}})
+	require.NoError(t, err)
+
+	prompt := (*requests)[0]["messages"].([]any)[0].(map[string]any)["content"].(string)
+	assert.Contains(t, prompt, `Customer: "I want a latte"`)
+	assert.Contains(t, prompt, `[1] "Latte"`)
+	assert.Contains(t, prompt, `User Request: "something cheaper"`)
+}
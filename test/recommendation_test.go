@@ -0,0 +1,254 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// chatServer answers every chat completion with the next reply and records the requests
+func chatServer(t *testing.T, replies ...string) (*httptest.Server, *[]map[string]any) {
+	t.Helper()
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+
+		reply := replies[min(len(requests), len(replies))-1]
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": reply}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestStructuredRecommendations_TolerantParsing(t *testing.T) {
+	server, requests := chatServer(t,
+		`Sure! Here are my picks: {"items": [{"menu_id": 1, "reason": "Wakes you [up]", "confidence": 0.8}]} Enjoy!`,
+	)
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+
+	recommendations, err := ai.GetRecommendations(context.Background(), model.RecommendationRequest{Preference: "coffee"}, []model.Menu{{Name: "Kopi Susu"}})
+	require.NoError(t, err)
+	assert.Equal(t, []model.RecommendationResponseRaw{{MenuID: 1, Reason: "Wakes you [up]", Confidence: 0.8}}, recommendations)
+
+	// The schema is sent as structured output, wrapped in an object as json_schema requires
+	require.Len(t, *requests, 1)
+	format := (*requests)[0]["response_format"].(map[string]any)
+	assert.Equal(t, "json_schema", format["type"])
+	schema := format["json_schema"].(map[string]any)["schema"].(map[string]any)
+	assert.Equal(t, "object", schema["type"])
+	assert.Equal(t, "array", schema["properties"].(map[string]any)["items"].(map[string]any)["type"])
+
+	// Plain text answers such as descriptions are not constrained
+	_, err = ai.GenerateDescription(context.Background(), "Kopi Susu", nil)
+	require.NoError(t, err)
+	assert.Nil(t, (*requests)[1]["response_format"])
+}
+
+func TestStructuredRecommendations_RepairRetry(t *testing.T) {
+	server, requests := chatServer(t,
+		`I would recommend the Kopi Susu because it has caffeine.`,
+		`[{"menu_id": 1, "reason": "Caffeine", "confidence": 0.7}]`,
+	)
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+
+	recommendations, err := ai.GetRecommendations(context.Background(), model.RecommendationRequest{Preference: "coffee"}, []model.Menu{{Name: "Kopi Susu"}})
+	require.NoError(t, err)
+	assert.Len(t, recommendations, 1)
+
+	require.Len(t, *requests, 2)
+	repair := (*requests)[1]["messages"].([]any)[0].(map[string]any)["content"].(string)
+	assert.Contains(t, repair, "could not be parsed")
+	assert.Contains(t, repair, "I would recommend the Kopi Susu")
+
+	// Only one retry, then the error surfaces
+	server, requests = chatServer(t, `no idea`)
+	ai, err = service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: time.Second})
+	require.NoError(t, err)
+	_, err = ai.GetRecommendations(context.Background(), model.RecommendationRequest{Preference: "coffee"}, []model.Menu{{Name: "Kopi Susu"}})
+	assert.ErrorContains(t, err, "failed to parse AI response")
+	assert.Len(t, *requests, 2)
+}
+
+// Older answers and small local models sometimes give names instead of IDs
+func TestGetRecommendations_FuzzyMenuNames(t *testing.T) {
+	mockAI := new(MockAIService)
+	svc := service.NewMenuService(repository.NewMenuRepository(newTestDB(t)), mockAI)
+
+	for _, name := range []string{"Kopi Susu Gula Aren", "Nasi Goreng", "Es Teh", "Crème Brûlée"} {
+		_, err := svc.Create(context.Background(), testScope, model.Menu{Name: name, Description: name})
+		require.NoError(t, err)
+	}
+	mockAI.On("GetRecommendations", mock.Anything, mock.Anything).Return([]model.RecommendationResponseRaw{
+		{MenuName: "kopi susu gula aren", Reason: "case"},
+		{MenuName: "Pizza Margherita", Reason: "made up"},
+		{MenuName: "Nasi Gorng", Reason: "typo"},
+		{MenuName: "Kopi Susu Gula Aren", Reason: "duplicate"},
+		{MenuName: "Creme Brulee", Reason: "accents"},
+	}, nil).Once()
+	mockAI.On("GetRecommendations", mock.Anything, mock.Anything).Return([]model.RecommendationResponseRaw{
+		{MenuName: "Es Teh (less sugar)", Reason: "suffix"},
+	}, nil).Once()
+
+	names := func() []string {
+		result, err := svc.GetRecommendations(context.Background(), testScope, model.RecommendationRequest{Preference: "anything"})
+		require.NoError(t, err)
+		var got []string
+		for _, r := range result {
+			got = append(got, r.Menu.Name+": "+r.Reason)
+		}
+		return got
+	}
+	assert.Equal(t, []string{
+		"Kopi Susu Gula Aren: case",
+		"Nasi Goreng: typo",
+		"Crème Brûlée: accents",
+	}, names())
+	assert.Equal(t, []string{"Es Teh: suffix"}, names())
+}
+
+func newRecommendationService(t *testing.T, names ...string) (service.MenuService, *MockAIService, []model.Menu) {
+	t.Helper()
+	mockAI := new(MockAIService)
+	svc := service.NewMenuService(repository.NewMenuRepository(newTestDB(t)), mockAI)
+
+	var menus []model.Menu
+	for _, name := range names {
+		menu, err := svc.Create(context.Background(), testScope, model.Menu{
+			Name: name, Description: name, Ingredients: []string{"secret"}, Category: "food",
+		})
+		require.NoError(t, err)
+		menus = append(menus, menu)
+	}
+	return svc, mockAI, menus
+}
+
+func TestGetRecommendations_ByID(t *testing.T) {
+	// Two menus share a name, only the ID tells them apart
+	svc, mockAI, menus := newRecommendationService(t, "Es Teh", "Es Teh", "Nasi Goreng")
+
+	mockAI.On("GetRecommendations", mock.Anything, mock.Anything).Return([]model.RecommendationResponseRaw{
+		{MenuID: menus[1].ID, Reason: "second tea", Confidence: 0.4},
+		{MenuID: 999, Reason: "not a candidate", Confidence: 1},
+		{MenuID: menus[2].ID, Reason: "rice", Confidence: 1.7},
+		{MenuID: menus[1].ID, Reason: "repeated", Confidence: 0.9},
+	}, nil)
+
+	result, err := svc.GetRecommendations(context.Background(), testScope, model.RecommendationRequest{Preference: "anything"})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	// Best first, confidence clamped to [0, 1]
+	assert.Equal(t, menus[2].ID, result[0].Menu.ID)
+	assert.Equal(t, 1.0, result[0].Confidence)
+	assert.Equal(t, menus[1].ID, result[1].Menu.ID)
+	assert.Equal(t, "second tea", result[1].Reason)
+	assert.Equal(t, model.RecommendationSourceAI, result[1].Source)
+}
+
+func TestGetRecommendations_Fallback(t *testing.T) {
+	svc, mockAI, menus := newRecommendationService(t, "Kopi Susu", "Es Teh", "Nasi Goreng", "Mie Ayam")
+
+	// AI failures fall back to keyword ranking
+	mockAI.On("GetRecommendations", mock.Anything, mock.Anything).
+		Return([]model.RecommendationResponseRaw(nil), errors.New("quota exceeded")).Once()
+	result, err := svc.GetRecommendations(context.Background(), testScope, model.RecommendationRequest{Preference: "I need coffee"})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, menus[0].ID, result[0].Menu.ID)
+	assert.Equal(t, model.RecommendationSourceFallback, result[0].Source)
+	assert.Greater(t, result[0].Confidence, 0.0)
+
+	// Nothing usable and no keyword match still suggests something, deterministically
+	mockAI.On("GetRecommendations", mock.Anything, mock.Anything).
+		Return([]model.RecommendationResponseRaw{{MenuID: 999}}, nil)
+	first, err := svc.GetRecommendations(context.Background(), testScope, model.RecommendationRequest{Preference: "surprise me"})
+	require.NoError(t, err)
+	second, err := svc.GetRecommendations(context.Background(), testScope, model.RecommendationRequest{Preference: "surprise me"})
+	require.NoError(t, err)
+	require.Len(t, first, 3)
+	assert.Equal(t, first, second)
+	assert.Equal(t, model.RecommendationSourceFallback, first[0].Source)
+
+	// An empty catalog is an error, never an empty 200
+	t.Run("empty catalog", func(t *testing.T) {
+		empty, _, _ := newRecommendationService(t)
+		_, err := empty.GetRecommendations(context.Background(), testScope, model.RecommendationRequest{Preference: "anything"})
+		assert.ErrorIs(t, err, service.ErrNoMenusAvailable)
+	})
+}
+
+func TestGetRecommendations_ShortlistsWholeCatalog(t *testing.T) {
+	db := newTestDB(t)
+	mockAI := new(MockAIService)
+	svc := service.NewMenuService(repository.NewMenuRepository(db), mockAI)
+
+	// The matching menus are the oldest of a catalog far larger than one prompt
+	old := time.Now().Add(-time.Hour)
+	menus := []model.Menu{
+		{Name: "Spicy Ramen", Category: "Noodles", Price: 28000, Calories: 650, Ingredients: []string{"noodle", "chili"}, CreatedAt: old},
+		{Name: "Iced Lychee Tea", Category: "Drinks", Price: 18000, Calories: 120, Ingredients: []string{"tea", "lychee"}, CreatedAt: old},
+		{Name: "Garden Salad", Category: "Salads", Price: 35000, Calories: 250, Ingredients: []string{"lettuce"}, CreatedAt: old},
+	}
+	for i := range 250 {
+		menus = append(menus, model.Menu{
+			Name: fmt.Sprintf("Dish %d", i), Category: "Mains", Price: 45000, Calories: 800, Ingredients: []string{"rice"},
+		})
+	}
+	require.NoError(t, db.CreateInBatches(&menus, 100).Error)
+
+	var candidates []model.Menu
+	mockAI.On("GetRecommendations", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		candidates = args.Get(1).([]model.Menu)
+	}).Return([]model.RecommendationResponseRaw(nil), nil)
+
+	shortlist := func(preference string) []model.Menu {
+		_, err := svc.GetRecommendations(context.Background(), testScope, model.RecommendationRequest{Preference: preference})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(candidates), 20)
+		return candidates
+	}
+
+	// The best match is found however old it is
+	got := shortlist("something spicy with noodles")
+	require.NotEmpty(t, got)
+	assert.Equal(t, "Spicy Ramen", got[0].Name)
+
+	// Budgets are hard limits
+	got = shortlist("I am hungry, under Rp 30.000")
+	require.NotEmpty(t, got)
+	for _, m := range got {
+		assert.LessOrEqual(t, m.Price, 30000.0, m.Name)
+	}
+
+	// So are calories and categories
+	got = shortlist("a cold drink")
+	require.Len(t, got, 1)
+	assert.Equal(t, "Iced Lychee Tea", got[0].Name)
+	got = shortlist("anything below 300 kcal")
+	require.Len(t, got, 2)
+	assert.ElementsMatch(t, []string{"Iced Lychee Tea", "Garden Salad"}, []string{got[0].Name, got[1].Name})
+
+	// Limits nothing meets are dropped rather than recommending nothing
+	got = shortlist("dinner under 5k")
+	assert.Len(t, got, 20)
+}
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -19,29 +20,149 @@ type MockAIService struct {
 	mock.Mock
 }
 
-func (m *MockAIService) GenerateDescription(name string, ingredients []string) (string, error) {
+func (m *MockAIService) GenerateDescription(ctx context.Context, name string, ingredients []string) (string, error) {
 	args := m.Called(name, ingredients)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockAIService) GetRecommendations(request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error) {
+func (m *MockAIService) GenerateDescriptions(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, count int) ([]string, error) {
+	args := m.Called(name, ingredients, options, count)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAIService) GetRecommendations(ctx context.Context, request model.RecommendationRequest, menus []model.Menu) ([]model.RecommendationResponseRaw, error) {
 	args := m.Called(request, menus)
 	return args.Get(0).([]model.RecommendationResponseRaw), args.Error(1)
 }
 
+func (m *MockAIService) TranslateMenus(ctx context.Context, items []model.TranslationItem, locale string) ([]model.TranslationItem, error) {
+	args := m.Called(items, locale)
+	return args.Get(0).([]model.TranslationItem), args.Error(1)
+}
+
+// The streaming methods emit the tokens or items given to Return, then return the usage and error
+func (m *MockAIService) GenerateDescriptionStream(ctx context.Context, name string, ingredients []string, options model.DescriptionOptions, emit func(string) error) (model.AIUsage, error) {
+	args := m.Called(name, ingredients, options)
+	for _, token := range args.Get(0).([]string) {
+		if err := emit(token); err != nil {
+			return model.AIUsage{}, err
+		}
+	}
+	return args.Get(1).(model.AIUsage), args.Error(2)
+}
+
+func (m *MockAIService) GetRecommendationsStream(ctx context.Context, request model.RecommendationRequest, menus []model.Menu, emit func(model.RecommendationResponseRaw) error) (model.AIUsage, error) {
+	args := m.Called(request, menus)
+	for _, raw := range args.Get(0).([]model.RecommendationResponseRaw) {
+		if err := emit(raw); err != nil {
+			return model.AIUsage{}, err
+		}
+	}
+	return args.Get(1).(model.AIUsage), args.Error(2)
+}
+
+func (m *MockAIService) SuggestEnrichment(ctx context.Context, menu model.Menu, categories []string) (model.EnrichmentSuggestion, error) {
+	args := m.Called(menu, categories)
+	return args.Get(0).(model.EnrichmentSuggestion), args.Error(1)
+}
+
+func (m *MockAIService) ExtractMenus(ctx context.Context, document model.MenuDocument, categories []string) ([]model.MenuDraft, error) {
+	args := m.Called(document, categories)
+	return args.Get(0).([]model.MenuDraft), args.Error(1)
+}
+
+func (m *MockAIService) SummarizeReviews(ctx context.Context, menu model.Menu, reviews []model.Review) (model.ReviewSummary, error) {
+	args := m.Called(menu, reviews)
+	return args.Get(0).(model.ReviewSummary), args.Error(1)
+}
+
+func (m *MockAIService) Close() error { return nil }
+
 func (m *MockRepository) Create(menu *model.Menu) error {
 	args := m.Called(menu)
 	return args.Error(0)
 }
 
-func (m *MockRepository) FindAll(filter model.MenuFilter) ([]model.Menu, model.PaginationResponse, error) {
-	return nil, model.PaginationResponse{}, nil
+func (m *MockRepository) CreateMany(menus []model.Menu) error {
+	args := m.Called(menus)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindAll(scope model.Scope, filter model.MenuFilter) ([]model.Menu, model.MenuPaginationResponse, error) {
+	return nil, model.MenuPaginationResponse{}, nil
+}
+
+func (m *MockRepository) FindByID(scope model.Scope, id uint) (model.Menu, error) {
+	args := m.Called(scope, id)
+	return args.Get(0).(model.Menu), args.Error(1)
+}
+
+func (m *MockRepository) Update(menu *model.Menu) error                              { return nil }
+func (m *MockRepository) Delete(tenantID, id uint) error                             { return nil }
+func (m *MockRepository) GroupBy(tenantID uint, mode string, limit int) (any, error) { return nil, nil }
+
+func (m *MockRepository) DecrementStock(tenantID, id uint, quantity int) (bool, error) {
+	args := m.Called(tenantID, id, quantity)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) Restock(tenantID, id uint, quantity int) error {
+	args := m.Called(tenantID, id, quantity)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetAvailability(tenantID, id uint, status string) error {
+	args := m.Called(tenantID, id, status)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ResetDailyStock() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) Facets(scope model.Scope, filter model.MenuFilter) (model.MenuFacets, error) {
+	return model.MenuFacets{}, nil
+}
+func (m *MockRepository) FindBatch(afterID uint, limit int) ([]model.Menu, error)     { return nil, nil }
+func (m *MockRepository) UpdateImage(tenantID, id uint, image *model.MenuImage) error { return nil }
+
+func (m *MockRepository) SetGeneratedDescription(tenantID, id uint, description, status string) (bool, error) {
+	return false, nil
+}
+func (m *MockRepository) MarkFallbackDescriptions(placeholderPrefix string) (int64, error) {
+	return 0, nil
+}
+func (m *MockRepository) FindUngeneratedDescriptions(jobType string, maxSweeps int, afterID uint, limit int) ([]model.Menu, error) {
+	return nil, nil
+}
+func (m *MockRepository) CountDescriptionSweeps(ids []uint) error { return nil }
+
+func (m *MockRepository) UpsertOverride(override *model.BranchMenuOverride) error { return nil }
+func (m *MockRepository) DeleteOverride(tenantID, branchID, menuID uint) error    { return nil }
+func (m *MockRepository) FindOverrides(tenantID, branchID uint) ([]model.BranchMenuOverride, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) FindTranslations(tenantID uint, menuIDs []uint, locales []string) ([]model.MenuTranslation, error) {
+	return nil, nil
+}
+func (m *MockRepository) FindTranslationsForMenu(tenantID, menuID uint) ([]model.MenuTranslation, error) {
+	return nil, nil
+}
+func (m *MockRepository) UpsertTranslation(translation *model.MenuTranslation) error   { return nil }
+func (m *MockRepository) DeleteTranslation(tenantID, menuID uint, locale string) error { return nil }
+func (m *MockRepository) FindMissingTranslations(tenantID uint, locale string, limit int) ([]model.Menu, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) FindCategories(tenantID uint) ([]string, error) { return nil, nil }
+func (m *MockRepository) FindUnenriched(tenantID uint, limit int) ([]model.Menu, error) {
+	return nil, nil
 }
 
-func (m *MockRepository) FindByID(id uint) (model.Menu, error)        { return model.Menu{}, nil }
-func (m *MockRepository) Update(menu *model.Menu) error               { return nil }
-func (m *MockRepository) Delete(id uint) error                        { return nil }
-func (m *MockRepository) GroupBy(mode string, limit int) (any, error) { return nil, nil }
+// testScope is the tenant used by service tests
+var testScope = model.Scope{TenantID: model.DefaultTenantID}
 
 func TestCreateMenu_Success(t *testing.T) {
 	mockRepo := new(MockRepository)
@@ -60,11 +181,14 @@ func TestCreateMenu_Success(t *testing.T) {
 		Return("Tasty Burger generated by Mock", nil)
 
 	expectedDataSaved := input
+	expectedDataSaved.TenantID = model.DefaultTenantID
 	expectedDataSaved.Description = "Tasty Burger generated by Mock"
+	expectedDataSaved.DescriptionStatus = model.DescriptionStatusReady
+	expectedDataSaved.Availability = model.AvailabilityAvailable
 
 	mockRepo.On("Create", &expectedDataSaved).Return(nil)
 
-	result, err := svc.Create(input)
+	result, err := svc.Create(context.Background(), testScope, input)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "Tasty Burger generated by Mock", result.Description)
@@ -84,11 +208,14 @@ func TestCreateMenu_AIFailure_Fallback(t *testing.T) {
 		Return("", errors.New("gemini quota exceeded"))
 
 	expectedFallback := input
+	expectedFallback.TenantID = model.DefaultTenantID
 	expectedFallback.Description = "Delicious Burger"
+	expectedFallback.DescriptionStatus = model.DescriptionStatusFallback
+	expectedFallback.Availability = model.AvailabilityAvailable
 
 	mockRepo.On("Create", &expectedFallback).Return(nil)
 
-	svc.Create(input)
+	svc.Create(context.Background(), testScope, input)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -0,0 +1,153 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/controller"
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestCreateMenu_ZeroStockIsSoldOut(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockAI := new(MockAIService)
+	svc := service.NewMenuService(mockRepo, mockAI)
+
+	input := model.Menu{Name: "Croissant", Description: "Buttery layers", Stock: intPtr(0)}
+
+	mockRepo.On("Create", mock.MatchedBy(func(m *model.Menu) bool {
+		return m.Availability == model.AvailabilitySoldOut
+	})).Return(nil)
+
+	result, err := svc.Create(context.Background(), testScope, input)
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsSoldOut())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDecrementStock_NotTracked(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := service.NewMenuService(mockRepo, new(MockAIService))
+
+	mockRepo.On("FindByID", testScope, uint(1)).Return(model.Menu{ID: 1, Name: "Latte"}, nil)
+
+	_, err := svc.DecrementStock(testScope, 1, 1)
+
+	assert.ErrorIs(t, err, service.ErrStockNotTracked)
+	mockRepo.AssertNotCalled(t, "DecrementStock", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDecrementStock_Insufficient(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := service.NewMenuService(mockRepo, new(MockAIService))
+
+	mockRepo.On("FindByID", testScope, uint(1)).Return(model.Menu{ID: 1, Stock: intPtr(1)}, nil)
+	mockRepo.On("DecrementStock", testScope.TenantID, uint(1), 2).Return(false, nil)
+
+	_, err := svc.DecrementStock(testScope, 1, 2)
+
+	assert.ErrorIs(t, err, service.ErrInsufficientStock)
+}
+
+func TestDecrementStock_LastItemSoldOut(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := service.NewMenuService(mockRepo, new(MockAIService))
+
+	mockRepo.On("FindByID", testScope, uint(1)).Return(model.Menu{ID: 1, Stock: intPtr(1), Availability: model.AvailabilityAvailable}, nil).Once()
+	mockRepo.On("DecrementStock", testScope.TenantID, uint(1), 1).Return(true, nil)
+	mockRepo.On("FindByID", testScope, uint(1)).Return(model.Menu{ID: 1, Stock: intPtr(0), Availability: model.AvailabilitySoldOut}, nil).Once()
+
+	result, err := svc.DecrementStock(testScope, 1, 1)
+
+	assert.NoError(t, err)
+	assert.True(t, result.SoldOut)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDecrementStockEndpoint_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(MockRepository)
+	router := gin.New()
+	router.POST("/menu/:id/stock/decrement", controller.NewMenuController(service.NewMenuService(mockRepo, new(MockAIService)), nil).DecrementStock)
+	decrement := func(id string) int {
+		req := httptest.NewRequest(http.MethodPost, "/menu/"+id+"/stock/decrement", strings.NewReader(`{"quantity": 1}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	mockRepo.On("FindByID", mock.Anything, uint(1)).Return(model.Menu{}, gorm.ErrRecordNotFound)
+	mockRepo.On("FindByID", mock.Anything, uint(2)).Return(model.Menu{}, errors.New("connection refused"))
+	mockRepo.On("FindByID", mock.Anything, uint(3)).Return(model.Menu{ID: 3, Name: "Latte"}, nil)
+
+	assert.Equal(t, http.StatusNotFound, decrement("1"))
+	assert.Equal(t, http.StatusInternalServerError, decrement("2"), "a database failure is not a missing menu")
+	assert.Equal(t, http.StatusConflict, decrement("3"))
+}
+
+func TestUpdateMenu_KeepsOmittedStock(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	f := newTenantFixture(t)
+	router := gin.New()
+	router.PUT("/menu/:id", middleware.Tenant(f.tenants, true), controller.NewMenuController(f.menuService, nil).Update)
+	put := func(body string) model.Menu {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/menu/%d", f.menuA.ID), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "resto-a")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		stored, err := f.menuRepo.FindByID(f.scopeA, f.menuA.ID)
+		require.NoError(t, err)
+		return stored
+	}
+	require.NoError(t, f.menuRepo.SetAvailability(f.tenantA.ID, f.menuA.ID, model.AvailabilitySoldOut))
+
+	// Editing the details leaves the stock managed by the stock endpoints alone
+	stored := put(`{"name": "Nasi Goreng Spesial", "category": "food", "price": 27000}`)
+	assert.Equal(t, "Nasi Goreng Spesial", stored.Name)
+	assert.Equal(t, model.AvailabilitySoldOut, stored.Availability)
+	require.NotNil(t, stored.Stock)
+	assert.Equal(t, 5, *stored.Stock)
+
+	// Sent fields are applied, null stops tracking
+	stored = put(`{"name": "Nasi Goreng", "availability": "available", "stock": 12, "daily_stock": 20}`)
+	assert.Equal(t, model.AvailabilityAvailable, stored.Availability)
+	assert.Equal(t, intPtr(12), stored.Stock)
+	assert.Equal(t, intPtr(20), stored.DailyStock)
+	stored = put(`{"name": "Nasi Goreng", "stock": null}`)
+	assert.Nil(t, stored.Stock)
+	assert.Equal(t, intPtr(20), stored.DailyStock)
+}
+
+func TestNextDailyRun(t *testing.T) {
+	loc := time.UTC
+
+	before := time.Date(2025, 1, 10, 5, 0, 0, 0, loc)
+	assert.Equal(t, time.Date(2025, 1, 10, 6, 0, 0, 0, loc), service.NextDailyRun(before, 6, 0))
+
+	after := time.Date(2025, 1, 10, 6, 0, 0, 0, loc)
+	assert.Equal(t, time.Date(2025, 1, 11, 6, 0, 0, 0, loc), service.NextDailyRun(after, 6, 0))
+
+	_, _, err := service.ParseResetTime("25:00")
+	assert.Error(t, err)
+}
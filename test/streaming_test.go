@@ -0,0 +1,244 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"atalariq/menu-api/internal/config"
+	"atalariq/menu-api/internal/controller"
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+func parseEvents(t *testing.T, body string) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var event sseEvent
+		for _, line := range strings.Split(block, "\n") {
+			if name, ok := strings.CutPrefix(line, "event:"); ok {
+				event.name = name
+			}
+			if data, ok := strings.CutPrefix(line, "data:"); ok {
+				event.data = data
+			}
+		}
+		require.NotEmpty(t, event.name, "event without a name: %q", block)
+		events = append(events, event)
+	}
+	return events
+}
+
+// chunks splits text in pieces of n bytes, the way a model streams tokens
+func chunks(text string, n int) []string {
+	var pieces []string
+	for len(text) > n {
+		pieces = append(pieces, text[:n])
+		text = text[n:]
+	}
+	return append(pieces, text)
+}
+
+func TestOpenAIProvider_Stream(t *testing.T) {
+	const firstItem = `{"menu_id": 7, "reason": "Strong [coffee] with \"crema\" }", "confidence": 0.9}`
+	firstReceived := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Stream        bool           `json:"stream"`
+			StreamOptions map[string]any `json:"stream_options"`
+			Messages      []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.True(t, body.Stream)
+		assert.Equal(t, true, body.StreamOptions["include_usage"])
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		send := func(data string) {
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			w.(http.Flusher).Flush()
+		}
+		delta := func(text string) {
+			encoded, _ := json.Marshal(text)
+			send(fmt.Sprintf(`{"choices": [{"delta": {"content": %s}}]}`, encoded))
+		}
+
+		if strings.Contains(body.Messages[0].Content, "Recommendation Engine") {
+			for _, piece := range chunks(`{"items": [`+firstItem+`, `, 7) {
+				delta(piece)
+			}
+			// The first item reaches the caller while the model is still answering
+			select {
+			case <-firstReceived:
+			case <-time.After(2 * time.Second):
+				t.Error("the first recommendation was not emitted before the answer finished")
+			}
+			for _, piece := range chunks(`{"menu_id": 8, "reason": "Sweet", "confidence": 0.6}]}`, 7) {
+				delta(piece)
+			}
+		} else {
+			for _, token := range []string{"Silky ", "espresso ", "over cold milk."} {
+				delta(token)
+			}
+		}
+		send(`{"choices": [], "usage": {"prompt_tokens": 40, "completion_tokens": 9, "total_tokens": 49}}`)
+		send("[DONE]")
+	}))
+	defer server.Close()
+
+	ai, err := service.NewAIService(config.AI{Provider: "openai", BaseURL: server.URL, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	var tokens []string
+	usage, err := ai.GenerateDescriptionStream(context.Background(), "Kopi Susu", []string{"espresso", "milk"}, model.DescriptionOptions{}, func(token string) error {
+		tokens = append(tokens, token)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Silky ", "espresso ", "over cold milk."}, tokens)
+	assert.Equal(t, model.AIUsage{PromptTokens: 40, CompletionTokens: 9, TotalTokens: 49}, usage)
+
+	var items []model.RecommendationResponseRaw
+	usage, err = ai.GetRecommendationsStream(context.Background(), model.RecommendationRequest{Preference: "coffee"}, []model.Menu{{ID: 7}, {ID: 8}},
+		func(raw model.RecommendationResponseRaw) error {
+			if len(items) == 0 {
+				close(firstReceived)
+			}
+			items = append(items, raw)
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []model.RecommendationResponseRaw{
+		{MenuID: 7, Reason: `Strong [coffee] with "crema" }`, Confidence: 0.9},
+		{MenuID: 8, Reason: "Sweet", Confidence: 0.6},
+	}, items)
+	assert.Equal(t, 49, usage.TotalTokens)
+
+	// A failing emit, e.g. a disconnected client, stops the stream with its error
+	gone := errors.New("client gone")
+	_, err = ai.GenerateDescriptionStream(context.Background(), "Kopi Susu", nil, model.DescriptionOptions{}, func(string) error { return gone })
+	assert.ErrorIs(t, err, gone)
+}
+
+func TestStreamingEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	f := newTenantFixture(t)
+	menuService := service.NewMenuService(f.menuRepo, service.NewOfflineService())
+	_, err := menuService.Create(context.Background(), f.scopeA, model.Menu{Name: "Es Kopi Susu", Category: "drinks", Price: 18000, Ingredients: []string{"espresso", "milk"}})
+	require.NoError(t, err)
+
+	menus := controller.NewMenuController(menuService, nil)
+	router := gin.New()
+	router.POST("/menu/generate-description/stream", menus.GenerateDescriptionStream)
+	router.POST("/menu/recommendations/stream", middleware.Tenant(f.tenants, true), menus.GetRecommendationsStream)
+
+	post := func(path, body, tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", tenant)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("description tokens then done", func(t *testing.T) {
+		rec := post("/menu/generate-description/stream", `{"name": "Kopi Susu", "ingredients": ["espresso", "milk"]}`, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/event-stream")
+
+		events := parseEvents(t, rec.Body.String())
+		var text strings.Builder
+		for _, event := range events[:len(events)-1] {
+			require.Equal(t, model.StreamEventToken, event.name)
+			var token model.StreamToken
+			require.NoError(t, json.Unmarshal([]byte(event.data), &token))
+			text.WriteString(token.Text)
+		}
+		var done model.DescriptionStreamDone
+		require.Equal(t, model.StreamEventDone, events[len(events)-1].name)
+		require.NoError(t, json.Unmarshal([]byte(events[len(events)-1].data), &done))
+		assert.Equal(t, text.String(), done.Description)
+		assert.Greater(t, len(events), 3)
+		assert.Positive(t, done.Usage.TotalTokens)
+	})
+
+	t.Run("recommendations then done", func(t *testing.T) {
+		rec := post("/menu/recommendations/stream", `{"preference": "iced coffee"}`, "resto-a")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		events := parseEvents(t, rec.Body.String())
+		require.Len(t, events, 2)
+		assert.Equal(t, model.StreamEventRecommendation, events[0].name)
+		var recommendation model.RecommendationResponse
+		require.NoError(t, json.Unmarshal([]byte(events[0].data), &recommendation))
+		assert.Equal(t, "Es Kopi Susu", recommendation.Menu.Name)
+
+		var done model.RecommendationStreamDone
+		require.NoError(t, json.Unmarshal([]byte(events[1].data), &done))
+		assert.Equal(t, 1, done.Count)
+	})
+
+	t.Run("errors before the first event keep their status", func(t *testing.T) {
+		rec := post("/menu/recommendations/stream", `{"preference": "coffee"}`, "resto-b")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+	})
+}
+
+func TestGetRecommendationsStream_FallbackAndCancel(t *testing.T) {
+	f := newTenantFixture(t)
+
+	// The AI breaks before naming a known menu, the fallback ranking is streamed instead
+	ai := new(MockAIService)
+	ai.On("GetRecommendationsStream", mock.Anything, mock.Anything).
+		Return([]model.RecommendationResponseRaw{{MenuID: 9999, Reason: "Unknown"}}, model.AIUsage{TotalTokens: 12}, errors.New("connection reset"))
+	menuService := service.NewMenuService(f.menuRepo, ai)
+
+	var got []model.RecommendationResponse
+	usage, err := menuService.GetRecommendationsStream(context.Background(), f.scopeA, model.RecommendationRequest{Preference: "nasi goreng"},
+		func(r model.RecommendationResponse) error {
+			got = append(got, r)
+			return nil
+		})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, f.menuA.ID, got[0].Menu.ID)
+	assert.Equal(t, model.RecommendationSourceFallback, got[0].Source)
+	assert.Equal(t, 12, usage.TotalTokens)
+
+	// A client that disconnects after the first item stops the stream
+	for _, name := range []string{"Nasi Uduk", "Nasi Kuning"} {
+		_, err := f.menuService.Create(context.Background(), f.scopeA, model.Menu{Name: name, Category: "food", Description: "Rice dish"})
+		require.NoError(t, err)
+	}
+	offline := service.NewMenuService(f.menuRepo, service.NewOfflineService())
+	gone := errors.New("client gone")
+	got = nil
+	_, err = offline.GetRecommendationsStream(context.Background(), f.scopeA, model.RecommendationRequest{Preference: "nasi"},
+		func(r model.RecommendationResponse) error {
+			got = append(got, r)
+			return gone
+		})
+	assert.ErrorIs(t, err, gone)
+	assert.Len(t, got, 1)
+}
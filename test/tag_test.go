@@ -0,0 +1,152 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "chefs-pick", service.Slugify("Chef's Pick"))
+	assert.Equal(t, "spicy", service.Slugify("  Spicy!! "))
+	assert.Equal(t, "new-2024", service.Slugify("New (2024)"))
+}
+
+func tagSlugs(menu model.MenuResponse) []string {
+	var slugs []string
+	for _, tag := range menu.Tags {
+		slugs = append(slugs, tag.Slug)
+	}
+	return slugs
+}
+
+func menuNames(result model.MenuPaginationResponse) []string {
+	var names []string
+	for _, menu := range result.Data {
+		names = append(names, menu.Name)
+	}
+	return names
+}
+
+func TestTags_FilterAndFacets(t *testing.T) {
+	db := newTestDB(t)
+	repo := repository.NewMenuRepository(db)
+	menus := service.NewMenuService(repo, new(MockAIService))
+	tags := service.NewTagService(repository.NewTagRepository(db), menus)
+	scope := model.Scope{TenantID: model.DefaultTenantID}
+
+	create := func(name, category string, price float64) model.Menu {
+		menu, err := menus.Create(context.Background(), scope, model.Menu{Name: name, Category: category, Price: price, Description: name})
+		require.NoError(t, err)
+		return menu
+	}
+	rendang := create("Rendang", "food", 45000)
+	sambal := create("Ayam Sambal", "food", 30000)
+	tea := create("Es Teh", "drink", 8000)
+	create("Kopi Susu", "drink", 22000)
+
+	spicy, err := tags.CreateTag(scope.TenantID, model.TagRequest{Name: "Spicy"})
+	require.NoError(t, err)
+	_, err = tags.CreateTag(scope.TenantID, model.TagRequest{Name: "Chef's Pick"})
+	require.NoError(t, err)
+	_, err = tags.CreateTag(scope.TenantID, model.TagRequest{Name: "New"})
+	require.NoError(t, err)
+
+	_, err = tags.CreateTag(scope.TenantID, model.TagRequest{Name: "spicy"})
+	assert.ErrorIs(t, err, service.ErrTagExists)
+
+	detail, err := tags.SetMenuTags(scope, rendang.ID, []string{"spicy", "chefs-pick", "Spicy"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chefs-pick", "spicy"}, tagSlugs(detail))
+	_, err = tags.SetMenuTags(scope, sambal.ID, []string{"spicy"})
+	require.NoError(t, err)
+	_, err = tags.SetMenuTags(scope, tea.ID, []string{"new"})
+	require.NoError(t, err)
+
+	_, err = tags.SetMenuTags(scope, tea.ID, []string{"new", "vegan"})
+	assert.ErrorIs(t, err, service.ErrUnknownTag)
+
+	filter := model.MenuFilter{Page: 1, PerPage: 10, Sort: "name:asc", WithFacets: true}
+
+	// OR matches any tag, AND requires every tag
+	filter.Tags = []string{"chefs-pick", "new"}
+	result, err := menus.GetList(scope, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Es Teh", "Rendang"}, menuNames(result))
+
+	filter.Tags, filter.TagsMode = []string{"spicy", "chefs-pick"}, model.TagMatchAll
+	result, err = menus.GetList(scope, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Rendang"}, menuNames(result))
+
+	// Facets ignore their own filter so other values stay visible
+	filter.Tags, filter.TagsMode = []string{"spicy"}, model.TagMatchAny
+	filter.Category = "food"
+	result, err = menus.GetList(scope, filter)
+	require.NoError(t, err)
+	require.NotNil(t, result.Facets)
+	assert.Equal(t, []model.FacetCount{{Value: "food", Count: 2}}, result.Facets.Categories)
+	assert.Equal(t, []model.TagFacetCount{
+		{Slug: "spicy", Name: "Spicy", Count: 2},
+		{Slug: "chefs-pick", Name: "Chef's Pick", Count: 1},
+	}, result.Facets.Tags)
+	assert.Equal(t, []model.PriceBucket{
+		{Min: 30000, Max: 35000, Count: 1},
+		{Min: 35000, Max: 40000, Count: 0},
+		{Min: 40000, Max: 45000, Count: 0},
+		{Min: 45000, Max: 50000, Count: 1},
+	}, result.Facets.PriceBuckets)
+
+	filter = model.MenuFilter{Page: 1, PerPage: 10, WithFacets: true, Category: "drink"}
+	result, err = menus.GetList(scope, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []model.FacetCount{{Value: "drink", Count: 2}, {Value: "food", Count: 2}}, result.Facets.Categories)
+	assert.Equal(t, []model.TagFacetCount{{Slug: "new", Name: "New", Count: 1}}, result.Facets.Tags)
+
+	// Facets are opt-out, recommendations never pay for them
+	filter.WithFacets = false
+	result, err = menus.GetList(scope, filter)
+	require.NoError(t, err)
+	assert.Nil(t, result.Facets)
+
+	// Deleting a tag removes it from menus
+	require.NoError(t, tags.DeleteTag(scope.TenantID, spicy.ID))
+	detail, err = menus.GetDetail(scope, sambal.ID)
+	require.NoError(t, err)
+	assert.Empty(t, detail.Tags)
+
+	list, err := tags.ListTags(scope.TenantID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "chefs-pick", list[0].Slug)
+	assert.Equal(t, int64(1), list[0].MenuCount)
+}
+
+func TestTags_TenantIsolation(t *testing.T) {
+	f := newTenantFixture(t)
+	tags := service.NewTagService(repository.NewTagRepository(f.db), f.menuService)
+
+	_, err := tags.CreateTag(f.tenantA.ID, model.TagRequest{Name: "Spicy"})
+	require.NoError(t, err)
+
+	// Same slug is allowed for another tenant, but tenant B can neither use A's tag nor tag A's menu
+	_, err = tags.CreateTag(f.tenantB.ID, model.TagRequest{Name: "Spicy"})
+	require.NoError(t, err)
+	_, err = tags.SetMenuTags(f.scopeB, f.menuA.ID, []string{"spicy"})
+	assert.Error(t, err)
+
+	detail, err := tags.SetMenuTags(f.scopeA, f.menuA.ID, []string{"spicy"})
+	require.NoError(t, err)
+	require.Len(t, detail.Tags, 1)
+
+	result, err := f.menuService.GetList(f.scopeB, model.MenuFilter{Page: 1, PerPage: 10, Tags: []string{"spicy"}, WithFacets: true})
+	require.NoError(t, err)
+	assert.Empty(t, result.Data)
+	assert.Empty(t, result.Facets.Tags)
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"atalariq/menu-api/internal/middleware"
+	"atalariq/menu-api/internal/model"
+	"atalariq/menu-api/internal/repository"
+	"atalariq/menu-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	supported := []string{"en", "id"}
+
+	assert.Equal(t, "id", middleware.NegotiateLocale("", "id-ID,id;q=0.9,en;q=0.8", supported, "en"))
+	assert.Equal(t, "en", middleware.NegotiateLocale("", "fr-FR, en;q=0.5, id;q=0.4", supported, "en"))
+	assert.Equal(t, "id", middleware.NegotiateLocale("id_ID", "en", supported, "en"), "lang= wins over the header")
+	assert.Equal(t, "en", middleware.NegotiateLocale("", "ja, *;q=0.1", supported, "en"))
+	assert.Equal(t, "en", middleware.NegotiateLocale("", "id;q=0", supported, "en"))
+}
+
+func TestLocalizedMenus(t *testing.T) {
+	db := newTestDB(t)
+	repo := repository.NewMenuRepository(db)
+	mockAI := new(MockAIService)
+	svc := service.NewMenuService(repo, mockAI)
+
+	coffee, err := svc.Create(context.Background(), testScope, model.Menu{Name: "Palm Sugar Latte", Description: "Espresso with palm sugar"})
+	require.NoError(t, err)
+	tea, err := svc.Create(context.Background(), testScope, model.Menu{Name: "Iced Tea", Description: "Jasmine tea over ice"})
+	require.NoError(t, err)
+
+	_, err = svc.SaveTranslation(testScope, coffee.ID, "id", model.TranslationRequest{
+		Name: "Kopi Susu Gula Aren", Description: "Espresso dengan gula aren",
+	})
+	require.NoError(t, err)
+
+	indonesian := model.Scope{TenantID: testScope.TenantID, Locale: "id-id"}
+
+	detail, err := svc.GetDetail(indonesian, coffee.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Kopi Susu Gula Aren", detail.Name)
+	assert.Equal(t, "id", detail.Locale)
+
+	// Without a translation the base text is served
+	detail, err = svc.GetDetail(indonesian, tea.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Iced Tea", detail.Name)
+	assert.Empty(t, detail.Locale)
+
+	// Search matches the text of the requested locale
+	found, err := svc.GetList(indonesian, model.MenuFilter{Query: "Gula", Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	require.Len(t, found.Data, 1)
+	assert.Equal(t, coffee.ID, found.Data[0].ID)
+
+	found, err = svc.GetList(indonesian, model.MenuFilter{Query: "Latte", Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	assert.Empty(t, found.Data, "base name is hidden behind the translation")
+
+	found, err = svc.GetList(indonesian, model.MenuFilter{Query: "Tea", Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	assert.Len(t, found.Data, 1, "untranslated menus are searched by their base text")
+
+	found, err = svc.GetList(testScope, model.MenuFilter{Query: "Latte", Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	assert.Len(t, found.Data, 1)
+
+	_, err = svc.SaveTranslation(testScope, coffee.ID, "not a locale", model.TranslationRequest{Name: "x"})
+	assert.ErrorIs(t, err, service.ErrInvalidLocale)
+}
+
+func TestGenerateTranslations(t *testing.T) {
+	db := newTestDB(t)
+	repo := repository.NewMenuRepository(db)
+	mockAI := new(MockAIService)
+	svc := service.NewMenuService(repo, mockAI)
+
+	coffee, err := svc.Create(context.Background(), testScope, model.Menu{Name: "Latte", Description: "Milky coffee"})
+	require.NoError(t, err)
+	tea, err := svc.Create(context.Background(), testScope, model.Menu{Name: "Iced Tea", Description: "Cold tea"})
+	require.NoError(t, err)
+
+	// The AI answers for one menu and invents another ID, both must not be trusted blindly
+	mockAI.On("TranslateMenus", mock.Anything, "id").Return([]model.TranslationItem{
+		{MenuID: coffee.ID, Name: "Latte", Description: "Kopi susu"},
+		{MenuID: 999, Name: "Bogus"},
+	}, nil).Once()
+
+	results, err := svc.GenerateTranslations(context.Background(), testScope, model.GenerateTranslationsRequest{Locales: []string{"ID"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, model.TranslationBatchResult{Locale: "id", Translated: 1, Failed: 1}, results[0])
+
+	// Only the missing menu is sent on the next run
+	mockAI.On("TranslateMenus", []model.TranslationItem{{MenuID: tea.ID, Name: "Iced Tea", Description: "Cold tea"}}, "id").
+		Return([]model.TranslationItem{{MenuID: tea.ID, Name: "Es Teh", Description: "Teh dingin"}}, nil).Once()
+
+	results, err = svc.GenerateTranslations(context.Background(), testScope, model.GenerateTranslationsRequest{Locales: []string{"id"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, results[0].Translated)
+
+	translations, err := svc.ListTranslations(testScope, tea.ID)
+	require.NoError(t, err)
+	require.Len(t, translations, 1)
+	assert.Equal(t, model.TranslationSourceAI, translations[0].Source)
+	mockAI.AssertExpectations(t)
+}